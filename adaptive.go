@@ -0,0 +1,114 @@
+package httpio
+
+import "time"
+
+const (
+	// adaptiveStart is the concurrency an adaptive download starts with
+	adaptiveStart = 2
+	// adaptiveGain is the gain in throughput a raised concurrency has to
+	// bring to be kept
+	adaptiveGain = 0.1
+	// adaptiveHold is the number of rounds the concurrency is held after
+	// it was lowered, before raising it is tried again
+	adaptiveHold = 4
+)
+
+// WithAdaptiveConcurrency starts the download with a few concurrent chunk
+// requests and tunes the concurrency to the measured throughput, up to the
+// given maximum. The concurrency is raised as long as that improves the
+// throughput, and lowered again when it doesn't or chunk requests fail.
+func WithAdaptiveConcurrency(max int) Option {
+	return func(f *RemoteFile) error {
+		if max < 1 {
+			max = 1
+		}
+
+		f.concurrency = max
+		f.adaptiveConcurrency = true
+
+		return nil
+	}
+}
+
+// adaptive measures the throughput of the chunks in rounds, a round being
+// as many chunks as the limit allows at once
+type adaptive struct {
+	now   func() time.Time
+	start time.Time
+	bytes int64
+	count int
+	// rate is the throughput of the previous round, raised reports whether
+	// the limit was raised for the current round
+	rate   float64
+	raised bool
+	hold   int
+}
+
+// adapt makes the limiter tune its limit to the throughput, starting with
+// a limit of a few
+func (l *limiter) adapt(now func() time.Time) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.limit = min(adaptiveStart, l.max)
+	l.adaptive = &adaptive{now: now, start: now()}
+}
+
+// complete records a chunk of the given size, at the end of a round the
+// limit is raised by one when the previous raise paid off, and lowered when
+// it didn't
+func (l *limiter) complete(n int64) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	a := l.adaptive
+	if a == nil {
+		return
+	}
+
+	a.bytes += n
+	a.count++
+	if a.count < l.limit {
+		return
+	}
+
+	now := a.now()
+	elapsed := now.Sub(a.start).Seconds()
+	if elapsed <= 0 {
+		return
+	}
+	rate := float64(a.bytes) / elapsed
+
+	switch {
+	case a.raised && rate < a.rate*(1+adaptiveGain):
+		l.limit = max(l.limit-1, 1)
+		a.raised = false
+		a.hold = adaptiveHold
+	case a.hold > 0:
+		a.hold--
+		a.raised = false
+	case l.limit < l.max:
+		l.limit++
+		a.raised = true
+		l.broadcast()
+	default:
+		a.raised = false
+	}
+
+	a.rate, a.start, a.bytes, a.count = rate, now, 0, 0
+}
+
+// fail lowers the limit of an adaptive limiter by one after a failed chunk
+// request, holding it there for a few rounds
+func (l *limiter) fail() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.adaptive == nil {
+		return
+	}
+
+	l.limit = max(l.limit-1, 1)
+	l.adaptive.raised = false
+	l.adaptive.hold = adaptiveHold
+}
@@ -0,0 +1,76 @@
+package httpio
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+)
+
+// WithBearerToken authorizes the requests with the given bearer token
+func WithBearerToken(token string) Option {
+	return WithTokenProvider(func(context.Context) (string, error) {
+		return token, nil
+	})
+}
+
+// WithBasicAuth authorizes the requests with the given username and password
+func WithBasicAuth(username, password string) Option {
+	return withAuthorizer(func(req *http.Request) error {
+		req.SetBasicAuth(username, password)
+
+		return nil
+	})
+}
+
+// WithTokenProvider authorizes every request with a bearer token from the
+// given provider, so expiring credentials are refreshed during long
+// downloads. The provider is called right before each request is sent and
+// should cache its token for as long as it's valid.
+func WithTokenProvider(provider func(ctx context.Context) (string, error)) Option {
+	return withAuthorizer(func(req *http.Request) error {
+		token, err := provider(req.Context())
+		if err != nil {
+			return fmt.Errorf("unable to get token: %w", err)
+		}
+
+		req.Header.Set(headerAuthorization, "Bearer "+token)
+
+		return nil
+	})
+}
+
+// withAuthorizer authorizes the requests to the host of the file and its
+// subdomains, leaving the requests redirected to other domains without
+// credentials
+func withAuthorizer(authorize func(req *http.Request) error) Option {
+	return func(f *RemoteFile) error {
+		f.transportWrappers = append(f.transportWrappers, func(rt http.RoundTripper) http.RoundTripper {
+			return &authTransport{
+				base:      rt,
+				host:      f.req.URL.Hostname(),
+				authorize: authorize,
+			}
+		})
+
+		return nil
+	}
+}
+
+type authTransport struct {
+	base      http.RoundTripper
+	host      string
+	authorize func(req *http.Request) error
+}
+
+func (t *authTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if !isDomainOrSubdomain(req.URL.Hostname(), t.host) {
+		return t.base.RoundTrip(req)
+	}
+
+	req = req.Clone(req.Context())
+	if err := t.authorize(req); err != nil {
+		return nil, err
+	}
+
+	return t.base.RoundTrip(req)
+}
@@ -0,0 +1,165 @@
+package httpio_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sync/atomic"
+	"testing"
+
+	"github.com/jobstoit/httpio"
+)
+
+func newAuthTestServer(authorized func(r *http.Request) bool) *testServer {
+	return newTestServerWithHandler(func(h http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !authorized(r) {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+
+			h.ServeHTTP(w, r)
+		})
+	})
+}
+
+func TestGetBearerToken(t *testing.T) {
+	svr := newAuthTestServer(func(r *http.Request) bool {
+		return r.Header.Get("Authorization") == "Bearer secret"
+	})
+	defer svr.Close()
+
+	u := svr.URL().JoinPath("assets", "test_5mb").String()
+
+	remoteFile, err := httpio.Get(u, httpio.WithChunkSize(1024*1024), httpio.WithBearerToken("secret"))
+	if err != nil {
+		t.Fatalf("unable to get file: %v", err)
+	}
+	defer remoteFile.Close()
+
+	if _, err := io.Copy(io.Discard, remoteFile); err != nil {
+		t.Errorf("unable to read file: %v", err)
+	}
+}
+
+func TestGetBasicAuth(t *testing.T) {
+	svr := newAuthTestServer(func(r *http.Request) bool {
+		user, pass, ok := r.BasicAuth()
+		return ok && user == "user" && pass == "pass"
+	})
+	defer svr.Close()
+
+	u := svr.URL().JoinPath("assets", "test_5mb").String()
+
+	remoteFile, err := httpio.Get(u, httpio.WithChunkSize(1024*1024), httpio.WithBasicAuth("user", "pass"))
+	if err != nil {
+		t.Fatalf("unable to get file: %v", err)
+	}
+	defer remoteFile.Close()
+
+	if _, err := io.Copy(io.Discard, remoteFile); err != nil {
+		t.Errorf("unable to read file: %v", err)
+	}
+}
+
+func TestGetTokenProvider(t *testing.T) {
+	// every token is valid for two requests
+	var issued, used atomic.Int32
+	svr := newAuthTestServer(func(r *http.Request) bool {
+		n := used.Add(1)
+		return r.Header.Get("Authorization") == fmt.Sprintf("Bearer token-%d", (n+1)/2)
+	})
+	defer svr.Close()
+
+	var calls int32
+	provider := func(context.Context) (string, error) {
+		calls++
+		if calls%2 == 1 {
+			issued.Add(1)
+		}
+
+		return fmt.Sprintf("token-%d", issued.Load()), nil
+	}
+
+	u := svr.URL().JoinPath("assets", "test_5mb").String()
+
+	remoteFile, err := httpio.Get(u, httpio.WithChunkSize(1024*1024), httpio.WithConcurrency(1), httpio.WithTokenProvider(provider))
+	if err != nil {
+		t.Fatalf("unable to get file: %v", err)
+	}
+	defer remoteFile.Close()
+
+	if _, err := io.Copy(io.Discard, remoteFile); err != nil {
+		t.Fatalf("unable to read file: %v", err)
+	}
+
+	if n := issued.Load(); n < 3 {
+		t.Errorf("expected the token to be refreshed during the download, got %d tokens", n)
+	}
+}
+
+func TestGetTokenProviderError(t *testing.T) {
+	svr := newTestServer()
+	defer svr.Close()
+
+	u := svr.URL().JoinPath("assets", "test_5mb").String()
+	errToken := errors.New("token expired")
+
+	_, err := httpio.Get(u, httpio.WithTokenProvider(func(context.Context) (string, error) {
+		return "", errToken
+	}))
+	if !errors.Is(err, errToken) {
+		t.Errorf("expected the error of the provider, got: %v", err)
+	}
+}
+
+func TestGetBearerTokenRedirected(t *testing.T) {
+	var leaked atomic.Int32
+	origin := newTestServerWithHandler(func(h http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Header.Get("Authorization") != "" {
+				leaked.Add(1)
+			}
+
+			h.ServeHTTP(w, r)
+		})
+	})
+	defer origin.Close()
+
+	// the same server under another host name
+	location := origin.URL()
+	location.Host = "localhost:" + location.Port()
+	location = location.JoinPath("assets", "test_5mb")
+
+	var authorized atomic.Int32
+	svr := newTestServerWithHandler(func(http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Header.Get("Authorization") == "Bearer secret" {
+				authorized.Add(1)
+			}
+
+			http.Redirect(w, r, location.String(), http.StatusFound)
+		})
+	})
+	defer svr.Close()
+
+	remoteFile, err := httpio.Get(svr.URL().String(), httpio.WithChunkSize(1024*1024), httpio.WithBearerToken("secret"))
+	if err != nil {
+		t.Fatalf("unable to get file: %v", err)
+	}
+	defer remoteFile.Close()
+
+	if _, err := io.Copy(io.Discard, remoteFile); err != nil {
+		t.Fatalf("unable to read file: %v", err)
+	}
+
+	if authorized.Load() == 0 {
+		t.Error("expected the request to the host of the file to be authorized")
+	}
+
+	if n := leaked.Load(); n != 0 {
+		t.Errorf("expected the token not to be sent to another domain, got it %d times", n)
+	}
+}
@@ -0,0 +1,94 @@
+package httpio
+
+import (
+	"context"
+	"io"
+	"sync"
+	"time"
+)
+
+const (
+	// bandwidthBurst is the time of unused bandwidth a limited reader may
+	// catch up on at once
+	bandwidthBurst = time.Millisecond * 250
+	// maxBandwidthRead is the largest read of a limited body, so the delays
+	// stay short
+	maxBandwidthRead = 32 * 1024
+)
+
+// WithBandwidthLimit limits the rate at which the responses for the file
+// are read to the given number of bytes per second, a rate of 0 or less
+// doesn't limit it. The files of GetAll and a Downloader share the limit.
+func WithBandwidthLimit(bytesPerSec int64) Option {
+	return func(f *RemoteFile) error {
+		f.bandwidth = newBandwidth(bytesPerSec)
+
+		return nil
+	}
+}
+
+func withBandwidth(b *bandwidth) Option {
+	return func(f *RemoteFile) error {
+		f.bandwidth = b
+
+		return nil
+	}
+}
+
+// bandwidth schedules the bytes read from the responses at a fixed rate, a
+// nil bandwidth is unlimited
+type bandwidth struct {
+	rate float64
+
+	mu sync.Mutex
+	// next is the time the bytes read so far are due at the rate
+	next time.Time
+}
+
+func newBandwidth(bytesPerSec int64) *bandwidth {
+	if bytesPerSec <= 0 {
+		return nil
+	}
+
+	return &bandwidth{rate: float64(bytesPerSec)}
+}
+
+// wait waits until the given number of bytes that were read are due
+func (b *bandwidth) wait(ctx context.Context, n int) error {
+	b.mu.Lock()
+	now := time.Now()
+	if earliest := now.Add(-bandwidthBurst); b.next.Before(earliest) {
+		b.next = earliest
+	}
+	b.next = b.next.Add(time.Duration(float64(n) / b.rate * float64(time.Second)))
+	delay := b.next.Sub(now)
+	b.mu.Unlock()
+
+	if delay <= 0 {
+		return nil
+	}
+
+	return sleep(ctx, delay)
+}
+
+// bandwidthBody reads a body within the bandwidth
+type bandwidthBody struct {
+	io.ReadCloser
+	ctx       context.Context
+	bandwidth *bandwidth
+}
+
+func (b *bandwidthBody) Read(p []byte) (int, error) {
+	if len(p) > maxBandwidthRead {
+		p = p[:maxBandwidthRead]
+	}
+
+	n, err := b.ReadCloser.Read(p)
+	if n > 0 {
+		if werr := b.bandwidth.wait(b.ctx, n); werr != nil {
+			return n, werr
+		}
+	}
+
+	return n, err
+}
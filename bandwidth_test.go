@@ -0,0 +1,39 @@
+package httpio_test
+
+import (
+	"bytes"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/jobstoit/httpio"
+)
+
+func TestGetBandwidthLimit(t *testing.T) {
+	svr := newTestServer()
+	defer svr.Close()
+
+	u := svr.URL().JoinPath("assets", "test_5mb").String()
+	expected, _ := testdata.ReadFile("testdata/test_5mb")
+
+	start := time.Now()
+	remoteFile, err := httpio.Get(u, httpio.WithChunkSize(1024*1024), httpio.WithBandwidthLimit(8*1024*1024))
+	if err != nil {
+		t.Fatalf("unable to get file: %v", err)
+	}
+	defer remoteFile.Close()
+
+	actual, err := io.ReadAll(remoteFile)
+	if err != nil {
+		t.Fatalf("unable to read file: %v", err)
+	}
+
+	if !bytes.Equal(expected, actual) {
+		t.Error("mismatched content")
+	}
+
+	// 5mb at 8mb/s, minus the burst
+	if elapsed := time.Since(start); elapsed < time.Millisecond*300 {
+		t.Errorf("expected the download to be limited, took %s", elapsed)
+	}
+}
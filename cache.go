@@ -0,0 +1,116 @@
+package httpio
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// Cache stores serialized cache entries, implementations have to be safe
+// for concurrent use
+type Cache interface {
+	Get(key string) ([]byte, bool)
+	Set(key string, value []byte)
+	Delete(key string)
+}
+
+// MemoryCache is an unbounded in-memory Cache
+type MemoryCache struct {
+	mu      sync.RWMutex
+	entries map[string][]byte
+}
+
+// NewMemoryCache returns an empty MemoryCache
+func NewMemoryCache() *MemoryCache {
+	return &MemoryCache{
+		entries: map[string][]byte{},
+	}
+}
+
+// Get implements Cache
+func (c *MemoryCache) Get(key string) ([]byte, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	value, ok := c.entries[key]
+
+	return value, ok
+}
+
+// Set implements Cache
+func (c *MemoryCache) Set(key string, value []byte) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.entries[key] = value
+}
+
+// Delete implements Cache
+func (c *MemoryCache) Delete(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	delete(c.entries, key)
+}
+
+// DiskCache is a Cache storing the entries as files in a directory, which
+// persists them between runs
+type DiskCache struct {
+	dir string
+}
+
+// NewDiskCache returns a DiskCache in the given directory, creating it
+// when it doesn't exist
+func NewDiskCache(dir string) (*DiskCache, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+
+	return &DiskCache{dir: dir}, nil
+}
+
+// path returns the path of the entry, named by the hash of the key
+func (c *DiskCache) path(key string) string {
+	sum := sha256.Sum256([]byte(key))
+
+	return filepath.Join(c.dir, hex.EncodeToString(sum[:]))
+}
+
+// Get implements Cache
+func (c *DiskCache) Get(key string) ([]byte, bool) {
+	value, err := os.ReadFile(c.path(key))
+	if err != nil {
+		return nil, false
+	}
+
+	return value, true
+}
+
+// Set implements Cache, the entry is written to a temporary file first so
+// a concurrent Get never reads a partial entry
+func (c *DiskCache) Set(key string, value []byte) {
+	tmp, err := os.CreateTemp(c.dir, ".tmp-*")
+	if err != nil {
+		return
+	}
+
+	_, err = tmp.Write(value)
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+
+	if err == nil {
+		err = os.Rename(tmp.Name(), c.path(key))
+	}
+
+	if err != nil {
+		os.Remove(tmp.Name())
+	}
+}
+
+// Delete implements Cache
+func (c *DiskCache) Delete(key string) {
+	os.Remove(c.path(key))
+}
@@ -0,0 +1,103 @@
+package httpio
+
+import (
+	"hash/fnv"
+	"io"
+	"math/rand"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// ChaosConfig configures the faults WithChaos injects into chunk requests,
+// the rates are fractions between 0 and 1 of the chunk requests
+type ChaosConfig struct {
+	// Seed makes the injected faults reproducible, the faults of a chunk
+	// request only depend on the seed, its range and its attempt
+	Seed int64
+	// DelayRate is the fraction of requests that are delayed up to MaxDelay
+	DelayRate float64
+	MaxDelay  time.Duration
+	// TruncateRate is the fraction of responses that end early
+	TruncateRate float64
+	// FailRate is the fraction of requests that fail with ErrChaos
+	FailRate float64
+}
+
+// chaosTransport injects faults into the range requests it sends
+type chaosTransport struct {
+	base   http.RoundTripper
+	config ChaosConfig
+
+	mu       sync.Mutex
+	attempts map[string]int
+}
+
+func (t *chaosTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	rangeHeader := req.Header.Get(headerRange)
+	if rangeHeader == "" {
+		return t.base.RoundTrip(req)
+	}
+
+	rnd := t.rand(rangeHeader)
+
+	if rnd.Float64() < t.config.DelayRate && t.config.MaxDelay > 0 {
+		delay := time.Duration(rnd.Int63n(int64(t.config.MaxDelay)))
+		if err := sleep(req.Context(), delay); err != nil {
+			return nil, err
+		}
+	}
+
+	if rnd.Float64() < t.config.FailRate {
+		return nil, ErrChaos
+	}
+
+	truncate := rnd.Float64() < t.config.TruncateRate
+	cut := rnd.Float64()
+
+	res, err := t.base.RoundTrip(req)
+	if err != nil || !truncate || res.ContentLength <= 0 {
+		return res, err
+	}
+
+	res.Body = &truncatedBody{
+		ReadCloser: res.Body,
+		remaining:  int64(cut * float64(res.ContentLength)),
+	}
+
+	return res, nil
+}
+
+// rand returns the random source for the next attempt of the given range
+func (t *chaosTransport) rand(rangeHeader string) *rand.Rand {
+	t.mu.Lock()
+	attempt := t.attempts[rangeHeader]
+	t.attempts[rangeHeader]++
+	t.mu.Unlock()
+
+	h := fnv.New64a()
+	io.WriteString(h, rangeHeader)
+
+	return rand.New(rand.NewSource((t.config.Seed ^ int64(h.Sum64())) + int64(attempt)))
+}
+
+// truncatedBody ends the body with io.ErrUnexpectedEOF after the remaining bytes
+type truncatedBody struct {
+	io.ReadCloser
+	remaining int64
+}
+
+func (b *truncatedBody) Read(p []byte) (int, error) {
+	if b.remaining <= 0 {
+		return 0, io.ErrUnexpectedEOF
+	}
+
+	if int64(len(p)) > b.remaining {
+		p = p[:b.remaining]
+	}
+
+	n, err := b.ReadCloser.Read(p)
+	b.remaining -= int64(n)
+
+	return n, err
+}
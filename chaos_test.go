@@ -0,0 +1,65 @@
+package httpio_test
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/jobstoit/httpio"
+)
+
+func TestGetChaos(t *testing.T) {
+	svr := newTestServer()
+	defer svr.Close()
+
+	u := svr.URL().JoinPath("assets", "test_5mb").String()
+	expected, _ := testdata.ReadFile("testdata/test_5mb")
+
+	tests := []struct {
+		name   string
+		config httpio.ChaosConfig
+		check  func(t *testing.T, actual []byte, err error)
+	}{
+		{
+			name:   "delay",
+			config: httpio.ChaosConfig{Seed: 1, DelayRate: 1, MaxDelay: time.Millisecond * 20},
+			check: func(t *testing.T, actual []byte, err error) {
+				if err != nil || !bytes.Equal(expected, actual) {
+					t.Errorf("expected delayed chunks to be downloaded, got error: %v", err)
+				}
+			},
+		},
+		{
+			name:   "fail",
+			config: httpio.ChaosConfig{Seed: 1, FailRate: 1},
+			check: func(t *testing.T, _ []byte, err error) {
+				if !errors.Is(err, httpio.ErrChaos) {
+					t.Errorf("expected ErrChaos, got: %v", err)
+				}
+			},
+		},
+		{
+			name:   "truncate",
+			config: httpio.ChaosConfig{Seed: 1, TruncateRate: 1},
+			check: func(t *testing.T, _ []byte, err error) {
+				if !errors.Is(err, io.ErrUnexpectedEOF) {
+					t.Errorf("expected an unexpected EOF, got: %v", err)
+				}
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			remoteFile, err := httpio.Get(u, httpio.WithChaos(test.config), httpio.WithChunkSize(1024*1024))
+			if err != nil {
+				t.Fatalf("failed to setup request: %v", err)
+			}
+
+			actual, err := io.ReadAll(remoteFile)
+			test.check(t, actual, err)
+		})
+	}
+}
@@ -0,0 +1,300 @@
+package httpio
+
+import (
+	"bytes"
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"hash/crc32"
+	"io"
+	"net/http"
+	"strings"
+)
+
+const (
+	headerReprDigest        = "Repr-Digest"
+	headerContentDigest     = "Content-Digest"
+	headerContentMD5        = "Content-MD5"
+	headerAmzChecksumType   = "X-Amz-Checksum-Type"
+	amzChecksumTypeComposed = "COMPOSITE"
+)
+
+// amzChecksumHeaders are the S3 checksum headers with the algorithm of each
+var amzChecksumHeaders = []struct {
+	header    string
+	algorithm string
+}{
+	{"X-Amz-Checksum-Sha256", "sha-256"},
+	{"X-Amz-Checksum-Sha1", "sha-1"},
+	{"X-Amz-Checksum-Crc32c", "crc32c"},
+	{"X-Amz-Checksum-Crc32", "crc32"},
+}
+
+// checksumAlgorithms are the supported checksum algorithms by their names
+// in the hash algorithms for HTTP digest fields registry
+var checksumAlgorithms = map[string]func() hash.Hash{
+	"sha-512": sha512.New,
+	"sha-384": sha512.New384,
+	"sha-256": sha256.New,
+	"sha-1":   sha1.New,
+	"md5":     md5.New,
+	"crc32c":  func() hash.Hash { return crc32.New(crc32.MakeTable(crc32.Castagnoli)) },
+	"crc32":   func() hash.Hash { return crc32.NewIEEE() },
+}
+
+// Checksum is a digest of the content computed with the named algorithm,
+// like "sha-256"
+type Checksum struct {
+	Algorithm string
+	Sum       []byte
+}
+
+func (c Checksum) String() string {
+	return c.Algorithm + ":" + hex.EncodeToString(c.Sum)
+}
+
+// sriAlgorithms are the algorithms of subresource integrity metadata from
+// strongest to weakest
+var sriAlgorithms = []struct {
+	prefix    string
+	algorithm string
+}{
+	{"sha512-", "sha-512"},
+	{"sha384-", "sha-384"},
+	{"sha256-", "sha-256"},
+}
+
+// WithChecksum verifies the downloaded file against the given checksum,
+// either a hex encoded SHA-256, SHA-384 or SHA-512 digest or subresource
+// integrity metadata like "sha384-base64". The reader returns a
+// ChecksumError instead of the end of the file when it doesn't match. The
+// file is only verified when it's read from the start.
+func WithChecksum(checksum string) Option {
+	return func(f *RemoteFile) error {
+		c, err := parseChecksum(checksum)
+		if err != nil {
+			return err
+		}
+
+		f.checksums = append(f.checksums, c)
+
+		return nil
+	}
+}
+
+// parseChecksum parses a hex encoded digest, with the algorithm derived
+// from its length, or the strongest hash of subresource integrity metadata
+func parseChecksum(value string) (Checksum, error) {
+	value = strings.TrimSpace(value)
+
+	if sum, err := hex.DecodeString(value); err == nil {
+		for _, algorithm := range []string{"sha-256", "sha-384", "sha-512"} {
+			if len(sum) == checksumAlgorithms[algorithm]().Size() {
+				return Checksum{Algorithm: algorithm, Sum: sum}, nil
+			}
+		}
+	}
+
+	for _, sri := range sriAlgorithms {
+		for _, field := range strings.Fields(value) {
+			encoded, ok := strings.CutPrefix(field, sri.prefix)
+			if !ok {
+				continue
+			}
+
+			// options after the digest are reserved and ignored
+			encoded, _, _ = strings.Cut(encoded, "?")
+			if c, ok := newChecksum(sri.algorithm, encoded); ok {
+				return c, nil
+			}
+		}
+	}
+
+	return Checksum{}, fmt.Errorf("invalid checksum: '%s'", value)
+}
+
+// parseDigestField parses the supported digests of a Repr-Digest or
+// Content-Digest field of RFC 9530, like "sha-256=:base64:"
+func parseDigestField(value string) []Checksum {
+	var checksums []Checksum
+	for _, member := range strings.Split(value, ",") {
+		member, _, _ = strings.Cut(member, ";")
+		algorithm, sum, ok := strings.Cut(strings.TrimSpace(member), "=")
+		if !ok {
+			continue
+		}
+
+		if len(sum) < 2 || sum[0] != ':' || sum[len(sum)-1] != ':' {
+			continue
+		}
+
+		if checksum, ok := newChecksum(strings.ToLower(algorithm), sum[1:len(sum)-1]); ok {
+			checksums = append(checksums, checksum)
+		}
+	}
+
+	return checksums
+}
+
+// newChecksum returns the checksum of a supported algorithm from its
+// base64 encoded sum
+func newChecksum(algorithm, encoded string) (Checksum, bool) {
+	newHash, ok := checksumAlgorithms[algorithm]
+	if !ok {
+		return Checksum{}, false
+	}
+
+	sum, err := base64.StdEncoding.DecodeString(strings.TrimSpace(encoded))
+	if err != nil || len(sum) != newHash().Size() {
+		return Checksum{}, false
+	}
+
+	return Checksum{Algorithm: algorithm, Sum: sum}, true
+}
+
+// contentChecksums returns the checksums of the body of a response
+func contentChecksums(header http.Header) []Checksum {
+	checksums := parseDigestField(header.Get(headerContentDigest))
+	if md5sum := header.Get(headerContentMD5); md5sum != "" {
+		if checksum, ok := newChecksum("md5", md5sum); ok {
+			checksums = append(checksums, checksum)
+		}
+	}
+
+	return checksums
+}
+
+// reprChecksums returns the checksums of the whole file reported in a
+// response, also when the response is for a range of the file. Composite
+// S3 checksums of multipart uploads can't be verified and are left out.
+func reprChecksums(header http.Header) []Checksum {
+	checksums := parseDigestField(header.Get(headerReprDigest))
+	if strings.EqualFold(header.Get(headerAmzChecksumType), amzChecksumTypeComposed) {
+		return checksums
+	}
+
+	for _, amz := range amzChecksumHeaders {
+		if value := header.Get(amz.header); value != "" {
+			if checksum, ok := newChecksum(amz.algorithm, value); ok {
+				checksums = append(checksums, checksum)
+			}
+		}
+	}
+
+	return checksums
+}
+
+// fileChecksums returns the checksums of the whole file reported in the
+// response, the checksums of the body only cover the whole file when it
+// isn't a range
+func fileChecksums(res *http.Response) []Checksum {
+	checksums := reprChecksums(res.Header)
+	if res.StatusCode != http.StatusPartialContent {
+		checksums = append(checksums, contentChecksums(res.Header)...)
+	}
+
+	return checksums
+}
+
+// verifyBody makes the body of the response fail with a ChecksumError at
+// its end when it doesn't match the checksums reported for it. Bodies the
+// transport decompressed aren't verified, the checksums are of the
+// compressed content.
+func verifyBody(res *http.Response) {
+	if res.Uncompressed || (res.StatusCode != http.StatusOK && res.StatusCode != http.StatusPartialContent) {
+		return
+	}
+
+	checksums := contentChecksums(res.Header)
+	if res.StatusCode == http.StatusOK {
+		checksums = append(checksums, reprChecksums(res.Header)...)
+	}
+
+	if len(checksums) == 0 {
+		return
+	}
+
+	res.Body = &checksumReader{
+		ReadCloser: res.Body,
+		verifier:   newChecksumVerifier(res.Request.URL.String(), checksums),
+	}
+}
+
+// checksumVerifier computes the checksums of the content written to it
+type checksumVerifier struct {
+	name      string
+	checksums []Checksum
+	hashes    []hash.Hash
+}
+
+func newChecksumVerifier(name string, checksums []Checksum) *checksumVerifier {
+	v := &checksumVerifier{name: name, checksums: checksums}
+	for _, checksum := range checksums {
+		v.hashes = append(v.hashes, checksumAlgorithms[checksum.Algorithm]())
+	}
+
+	return v
+}
+
+func (v *checksumVerifier) Write(p []byte) (int, error) {
+	for _, h := range v.hashes {
+		h.Write(p)
+	}
+
+	return len(p), nil
+}
+
+// verify returns a ChecksumError for the first checksum the content doesn't match
+func (v *checksumVerifier) verify() error {
+	for i, checksum := range v.checksums {
+		if sum := v.hashes[i].Sum(nil); !bytes.Equal(sum, checksum.Sum) {
+			return &ChecksumError{
+				Name:     v.name,
+				Expected: checksum.String(),
+				Actual:   Checksum{Algorithm: checksum.Algorithm, Sum: sum}.String(),
+			}
+		}
+	}
+
+	return nil
+}
+
+// checksumReader verifies a body once it's read to the end
+type checksumReader struct {
+	io.ReadCloser
+	verifier *checksumVerifier
+}
+
+func (r *checksumReader) Read(p []byte) (int, error) {
+	n, err := r.ReadCloser.Read(p)
+	_, _ = r.verifier.Write(p[:n])
+
+	if err == io.EOF {
+		if err := r.verifier.verify(); err != nil {
+			return n, err
+		}
+	}
+
+	return n, err
+}
+
+// checksumInspector verifies the whole file against the checksums reported
+// by the server, it's only used by a download starting at the beginning
+type checksumInspector struct {
+	verifier *checksumVerifier
+}
+
+func (ins *checksumInspector) Inspect(_ int64, p []byte) error {
+	_, err := ins.verifier.Write(p)
+
+	return err
+}
+
+func (ins *checksumInspector) Done() error {
+	return ins.verifier.verify()
+}
@@ -0,0 +1,165 @@
+package httpio_test
+
+import (
+	"bytes"
+	"crypto/md5"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jobstoit/httpio"
+)
+
+// newChecksumServer returns a server serving the data with the Content-MD5
+// of every response, the bytes at the corrupt offset are altered after
+// computing it
+func newChecksumServer(data []byte, header http.Header, corrupt int) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rec := httptest.NewRecorder()
+		http.ServeContent(rec, r, "", time.Time{}, bytes.NewReader(data))
+
+		body := rec.Body.Bytes()
+		sum := md5.Sum(body)
+
+		if first, ok := contentRangeStart(rec.Header().Get("Content-Range")); ok && corrupt >= first && corrupt < first+len(body) {
+			body[corrupt-first] ^= 0xff
+		}
+
+		for name, values := range rec.Header() {
+			w.Header()[name] = values
+		}
+		for name, values := range header {
+			w.Header()[name] = values
+		}
+
+		if r.Method != http.MethodHead && rec.Code == http.StatusPartialContent {
+			w.Header().Set("Content-MD5", base64.StdEncoding.EncodeToString(sum[:]))
+		}
+		w.WriteHeader(rec.Code)
+		_, _ = w.Write(body)
+	}))
+}
+
+// contentRangeStart returns the first byte of the given Content-Range
+func contentRangeStart(contentRange string) (int, bool) {
+	var first, last, size int
+	if _, err := fmt.Sscanf(contentRange, "bytes %d-%d/%d", &first, &last, &size); err != nil {
+		return 0, false
+	}
+
+	return first, true
+}
+
+func TestGetChecksums(t *testing.T) {
+	data := bytes.Repeat([]byte("0123456789"), 1000)
+	sha := sha256.Sum256(data)
+	crc := binary.BigEndian.AppendUint32(nil, crc32.ChecksumIEEE(data))
+	wrong := sha256.Sum256([]byte("other"))
+
+	tests := []struct {
+		name    string
+		header  http.Header
+		corrupt int
+		fail    bool
+	}{
+		{"chunk checksums", nil, -1, false},
+		{"corrupt chunk", nil, 4321, true},
+		{"repr-digest", http.Header{"Repr-Digest": {"sha-256=:" + base64.StdEncoding.EncodeToString(sha[:]) + ":"}}, -1, false},
+		{"wrong repr-digest", http.Header{"Repr-Digest": {"sha-256=:" + base64.StdEncoding.EncodeToString(wrong[:]) + ":"}}, -1, true},
+		{"unsupported repr-digest", http.Header{"Repr-Digest": {"unixsum=:AAAA:"}}, -1, false},
+		{"s3 checksum", http.Header{"X-Amz-Checksum-Crc32": {base64.StdEncoding.EncodeToString(crc)}}, -1, false},
+		{"wrong s3 checksum", http.Header{"X-Amz-Checksum-Crc32": {"AAAAAA=="}}, -1, true},
+		{"composite s3 checksum", http.Header{"X-Amz-Checksum-Crc32": {"AAAAAA=="}, "X-Amz-Checksum-Type": {"COMPOSITE"}}, -1, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svr := newChecksumServer(data, tt.header, tt.corrupt)
+			defer svr.Close()
+
+			remoteFile, err := httpio.Get(svr.URL, httpio.WithChunkSize(1024))
+			if err != nil {
+				t.Fatalf("failed to setup request: %v", err)
+			}
+			defer remoteFile.Close()
+
+			body, err := io.ReadAll(remoteFile)
+
+			var checksumErr *httpio.ChecksumError
+			if tt.fail {
+				if !errors.As(err, &checksumErr) {
+					t.Errorf("expected a ChecksumError, got: %v", err)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unable to read file: %v", err)
+			}
+
+			if !bytes.Equal(body, data) {
+				t.Errorf("expected %d bytes of the file, got %d", len(data), len(body))
+			}
+		})
+	}
+}
+
+func TestGetWithChecksum(t *testing.T) {
+	data := bytes.Repeat([]byte("0123456789"), 1000)
+	sha := sha256.Sum256(data)
+	sha384 := sha512.Sum384(data)
+
+	tests := []struct {
+		name     string
+		checksum string
+		fail     bool
+	}{
+		{"sha-256 hex", hex.EncodeToString(sha[:]), false},
+		{"wrong hex", strings.Repeat("0", 64), true},
+		{"sri", "sha384-" + base64.StdEncoding.EncodeToString(sha384[:]), false},
+		{"strongest sri", "sha256-AAAA sha384-" + base64.StdEncoding.EncodeToString(sha384[:]) + "?opt", false},
+		{"wrong sri", "sha256-" + base64.StdEncoding.EncodeToString(make([]byte, 32)), true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svr := newChecksumServer(data, nil, -1)
+			defer svr.Close()
+
+			remoteFile, err := httpio.Get(svr.URL, httpio.WithChunkSize(1024), httpio.WithChecksum(tt.checksum))
+			if err != nil {
+				t.Fatalf("failed to setup request: %v", err)
+			}
+			defer remoteFile.Close()
+
+			_, err = io.Copy(io.Discard, remoteFile)
+
+			var checksumErr *httpio.ChecksumError
+			if tt.fail != errors.As(err, &checksumErr) {
+				t.Errorf("expected a ChecksumError: %t, got: %v", tt.fail, err)
+			}
+
+			if !tt.fail && err != nil {
+				t.Errorf("unable to read file: %v", err)
+			}
+		})
+	}
+
+	for _, invalid := range []string{"", "abc", "md5-AAAA", "sha256-invalid"} {
+		if _, err := httpio.Get("http://localhost", httpio.WithChecksum(invalid)); err == nil {
+			t.Errorf("expected an error for checksum '%s'", invalid)
+		}
+	}
+}
@@ -0,0 +1,171 @@
+package httpio
+
+import (
+	"bytes"
+	"encoding/gob"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// WithChunkCache stores the chunks of a file with an ETag or Last-Modified
+// in the given cache, keyed by the URL, the validator and the range of the
+// chunk, so reading the same revision of the file again is served from the
+// cache instead of the network. The metadata is still requested to learn
+// the revision of the file. Use a DiskCache to keep the chunks between
+// runs, the chunks served from the cache are counted as cache hits.
+func WithChunkCache(cache Cache) Option {
+	return func(f *RemoteFile) error {
+		f.transportWrappers = append(f.transportWrappers, func(rt http.RoundTripper) http.RoundTripper {
+			return &chunkCacheTransport{base: rt, cache: cache, stats: &f.stats}
+		})
+
+		return nil
+	}
+}
+
+// chunkCacheHeaders are the headers of a range response that are stored
+var chunkCacheHeaders = []string{headerContentRange, headerContentType, headerETag, headerLastModified}
+
+// chunkEntry is a stored range response
+type chunkEntry struct {
+	Header http.Header
+	Body   []byte
+}
+
+// chunkCacheTransport serves the range requests conditional on a validator
+// with If-Range, which are the requests for chunks of a known revision,
+// from the cache and stores their responses
+type chunkCacheTransport struct {
+	base  http.RoundTripper
+	cache Cache
+	stats *stats
+}
+
+func (t *chunkCacheTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	rangeHeader, validator := req.Header.Get(headerRange), req.Header.Get(headerIfRange)
+	if req.Method != http.MethodGet || rangeHeader == "" || validator == "" || strings.Contains(rangeHeader, ",") {
+		return t.base.RoundTrip(req)
+	}
+
+	key := chunkCacheKey(req.URL.String(), validator, rangeHeader)
+	if res := t.load(key, req); res != nil {
+		t.stats.cacheHits.Add(1)
+
+		return res, nil
+	}
+
+	res, err := t.base.RoundTrip(req)
+	if err != nil || res.StatusCode != http.StatusPartialContent || res.Header.Get(headerContentEncoding) != "" {
+		return res, err
+	}
+
+	first, last, _, ok := parseContentRange(res.Header.Get(headerContentRange))
+	if !ok {
+		return res, nil
+	}
+
+	res.Body = &chunkCacheBody{
+		ReadCloser: res.Body,
+		length:     last - first + 1,
+		store: func(body []byte) {
+			t.store(key, res.Header, body)
+		},
+	}
+
+	return res, nil
+}
+
+func chunkCacheKey(url, validator, rangeHeader string) string {
+	return "chunk " + url + " " + validator + " " + rangeHeader
+}
+
+// load returns the stored response of the key, or nil when there's none
+func (t *chunkCacheTransport) load(key string, req *http.Request) *http.Response {
+	data, ok := t.cache.Get(key)
+	if !ok {
+		return nil
+	}
+
+	entry := &chunkEntry{}
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(entry); err != nil {
+		t.cache.Delete(key)
+		return nil
+	}
+
+	first, last, _, ok := parseContentRange(entry.Header.Get(headerContentRange))
+	if !ok || last-first+1 != int64(len(entry.Body)) {
+		t.cache.Delete(key)
+		return nil
+	}
+
+	return partialResponse(req, entry.Header.Clone(), entry.Body)
+}
+
+// partialResponse returns a 206 Partial Content response to the request
+// with the given headers and body
+func partialResponse(req *http.Request, header http.Header, body []byte) *http.Response {
+	header.Set(headerContentLength, strconv.Itoa(len(body)))
+
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", http.StatusPartialContent, http.StatusText(http.StatusPartialContent)),
+		StatusCode:    http.StatusPartialContent,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}
+}
+
+func (t *chunkCacheTransport) store(key string, header http.Header, body []byte) {
+	entry := &chunkEntry{Header: storedHeader(header), Body: body}
+
+	buf := &bytes.Buffer{}
+	if err := gob.NewEncoder(buf).Encode(entry); err != nil {
+		return
+	}
+
+	t.cache.Set(key, buf.Bytes())
+}
+
+// storedHeader returns the headers of a range response that are stored
+func storedHeader(header http.Header) http.Header {
+	stored := http.Header{}
+	for _, name := range chunkCacheHeaders {
+		if value := header.Get(name); value != "" {
+			stored.Set(name, value)
+		}
+	}
+
+	return stored
+}
+
+// chunkCacheBody keeps the bytes read from the body and stores them once
+// the whole range is read
+type chunkCacheBody struct {
+	io.ReadCloser
+	length int64
+	buf    []byte
+	store  func([]byte)
+}
+
+func (b *chunkCacheBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	if b.store != nil {
+		b.buf = append(b.buf, p[:n]...)
+
+		if int64(len(b.buf)) > b.length {
+			b.store, b.buf = nil, nil
+		} else if int64(len(b.buf)) == b.length {
+			b.store(b.buf)
+			b.store, b.buf = nil, nil
+		}
+	}
+
+	return n, err
+}
@@ -0,0 +1,106 @@
+package httpio_test
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/jobstoit/httpio"
+)
+
+func TestChunkCache(t *testing.T) {
+	expected, _ := testdata.ReadFile("testdata/test_5mb")
+
+	var ranges atomic.Int32
+	etag := `"v1"`
+	svr := newTestServerWithHandler(func(http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Header.Get("Range") != "" {
+				ranges.Add(1)
+			}
+
+			w.Header().Set("ETag", etag)
+			http.ServeContent(w, r, "test_5mb.bin", time.Time{}, bytes.NewReader(expected))
+		})
+	})
+	defer svr.Close()
+
+	cache, err := httpio.NewDiskCache(t.TempDir())
+	if err != nil {
+		t.Fatalf("unable to create cache: %v", err)
+	}
+
+	read := func() httpio.Stats {
+		remoteFile, err := httpio.Get(svr.URL().JoinPath("test_5mb.bin").String(),
+			httpio.WithChunkSize(1024*1024),
+			httpio.WithChunkCache(cache),
+		)
+		if err != nil {
+			t.Fatalf("failed to setup request: %v", err)
+		}
+		defer remoteFile.Close()
+
+		actual, err := io.ReadAll(remoteFile)
+		if err != nil {
+			t.Fatalf("unable to read file: %v", err)
+		}
+
+		if !bytes.Equal(expected, actual) {
+			t.Error("mismatched content")
+		}
+
+		return remoteFile.Stats()
+	}
+
+	read()
+	if n := ranges.Load(); n != 5 {
+		t.Fatalf("expected 5 range requests but got %d", n)
+	}
+
+	ranges.Store(0)
+	if stats := read(); stats.CacheHits != 5 {
+		t.Errorf("expected 5 cache hits but got %d", stats.CacheHits)
+	}
+
+	if n := ranges.Load(); n != 0 {
+		t.Errorf("expected the chunks to be served from the cache, got %d range requests", n)
+	}
+
+	// a new revision isn't served from the cache
+	etag = `"v2"`
+	ranges.Store(0)
+	read()
+
+	if n := ranges.Load(); n != 5 {
+		t.Errorf("expected the new revision to be requested, got %d range requests", n)
+	}
+}
+
+func TestDiskCache(t *testing.T) {
+	dir := t.TempDir()
+
+	cache, err := httpio.NewDiskCache(dir)
+	if err != nil {
+		t.Fatalf("unable to create cache: %v", err)
+	}
+
+	cache.Set("key", []byte("value"))
+
+	// the entries persist in the directory
+	reopened, err := httpio.NewDiskCache(dir)
+	if err != nil {
+		t.Fatalf("unable to reopen cache: %v", err)
+	}
+
+	if value, ok := reopened.Get("key"); !ok || string(value) != "value" {
+		t.Errorf("expected 'value' but got '%s', %v", value, ok)
+	}
+
+	reopened.Delete("key")
+	if _, ok := cache.Get("key"); ok {
+		t.Error("expected the entry to be deleted")
+	}
+}
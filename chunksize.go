@@ -0,0 +1,89 @@
+package httpio
+
+import (
+	"fmt"
+	"time"
+)
+
+const (
+	DefaultMinChunkSize = 1024 * 1024      // 1mb
+	DefaultMaxChunkSize = 1024 * 1024 * 64 // 64mb
+
+	// stableTolerance is how far the throughput of a chunk may deviate
+	// from the average for the throughput to count as stable
+	stableTolerance = 0.25
+	// stableChunks is the number of chunks in a row with a stable
+	// throughput after which the chunk size is doubled
+	stableChunks = 3
+)
+
+// WithAdaptiveChunkSize starts the download with small chunks and doubles
+// the chunk size every time the throughput of the chunks has stabilized, up
+// to the maximum set with WithMinMaxChunkSize. Small files are split over
+// multiple connections while large files end up with few requests. The
+// chunks are planned as the download progresses, so they're fetched in order
+// regardless of WithScheduler. Downloads resumed by DownloadFile keep fixed
+// chunks, as their state records chunks of a fixed size.
+func WithAdaptiveChunkSize() Option {
+	return func(f *RemoteFile) error {
+		f.adaptiveChunkSize = true
+
+		return nil
+	}
+}
+
+// WithMinMaxChunkSize bounds the chunk size of WithAdaptiveChunkSize, which
+// starts at the minimum. It's DefaultMinChunkSize to DefaultMaxChunkSize by
+// default.
+func WithMinMaxChunkSize(minSize, maxSize int64) Option {
+	return func(f *RemoteFile) error {
+		minSize, maxSize = max(minSize, 1), max(maxSize, 1)
+		if minSize > maxSize {
+			return fmt.Errorf("minimum chunk size %d exceeds the maximum of %d", minSize, maxSize)
+		}
+
+		f.minChunkSize, f.maxChunkSize = minSize, maxSize
+
+		return nil
+	}
+}
+
+// chunkSizer grows the size of the planned chunks as the throughput of the
+// chunks stabilizes. The calls are serialized by the engine.
+type chunkSizer struct {
+	size int64
+	max  int64
+	// rate is the moving average of the throughput of a chunk, stable is
+	// the number of chunks in a row that were close to it
+	rate   float64
+	stable int
+}
+
+func newChunkSizer(minSize, maxSize int64) *chunkSizer {
+	return &chunkSizer{size: minSize, max: maxSize}
+}
+
+// complete records a chunk of the given length that took the given time
+func (s *chunkSizer) complete(n int64, elapsed time.Duration) {
+	if elapsed <= 0 || s.size >= s.max {
+		return
+	}
+
+	rate := float64(n) / elapsed.Seconds()
+	if s.rate == 0 {
+		s.rate = rate
+		return
+	}
+
+	if rate >= s.rate*(1-stableTolerance) && rate <= s.rate*(1+stableTolerance) {
+		s.stable++
+	} else {
+		s.stable = 0
+	}
+	s.rate = (s.rate + rate) / 2
+
+	if s.stable >= stableChunks {
+		s.size = min(s.size*2, s.max)
+		s.stable = 0
+	}
+}
@@ -0,0 +1,33 @@
+package httpio
+
+import (
+	"testing"
+	"time"
+)
+
+func TestChunkSizer(t *testing.T) {
+	s := newChunkSizer(1024, 4096)
+
+	// a stable throughput doubles the size after stableChunks chunks
+	for range stableChunks + 1 {
+		s.complete(1024, time.Millisecond)
+	}
+	if e, a := int64(2048), s.size; e != a {
+		t.Fatalf("expected size %d after a stable throughput, got %d", e, a)
+	}
+
+	// an unstable throughput keeps the size
+	for i := range stableChunks * 2 {
+		s.complete(2048, time.Millisecond*time.Duration(1+i%2*4))
+	}
+	if e, a := int64(2048), s.size; e != a {
+		t.Fatalf("expected size %d while the throughput is unstable, got %d", e, a)
+	}
+
+	for range stableChunks * 4 {
+		s.complete(2048, time.Millisecond)
+	}
+	if e, a := int64(4096), s.size; e != a {
+		t.Errorf("expected the size to stop at the maximum %d, got %d", e, a)
+	}
+}
@@ -0,0 +1,37 @@
+package httpio
+
+import "io"
+
+// Close stops the download, cancelling the chunks in flight, and waits for
+// the workers to stop before releasing the idle connections. Reads after
+// closing fail with io.ErrClosedPipe, closing again does nothing.
+func (f *RemoteFile) Close() error {
+	f.closeOnce.Do(func() {
+		// a file that hasn't started downloading never starts
+		f.started.Do(func() {})
+
+		if f.cancel != nil {
+			f.cancel()
+		}
+
+		if f.pr != nil {
+			f.pr.Close()
+		}
+
+		f.mu.Lock()
+		r, begun := f.run, f.begun
+		f.mu.Unlock()
+
+		if begun {
+			<-r.done
+		} else {
+			r.err = io.ErrClosedPipe
+			close(r.done)
+		}
+
+		f.closeIdleConnections()
+		f.endTrace(nil)
+	})
+
+	return nil
+}
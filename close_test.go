@@ -0,0 +1,92 @@
+package httpio_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/jobstoit/httpio"
+)
+
+func TestClose(t *testing.T) {
+	var active atomic.Int32
+	svr := newTestServerWithHandler(func(h http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			// every chunk but the first stalls until the request is canceled
+			if r.Header.Get("Range") != "" && r.Header.Get("Range") != "bytes=0-262143" {
+				active.Add(1)
+				defer active.Add(-1)
+
+				<-r.Context().Done()
+				return
+			}
+
+			h.ServeHTTP(w, r)
+		})
+	})
+	defer svr.Close()
+
+	u := svr.URL().JoinPath("assets", "test_5mb").String()
+
+	remoteFile, err := httpio.Get(u, httpio.WithChunkSize(1024*256))
+	if err != nil {
+		t.Fatalf("unable to get file: %v", err)
+	}
+
+	if _, err := io.ReadFull(remoteFile, make([]byte, 1024)); err != nil {
+		t.Fatalf("unable to read file: %v", err)
+	}
+
+	if err := remoteFile.Close(); err != nil {
+		t.Fatalf("unable to close file: %v", err)
+	}
+
+	select {
+	case <-remoteFile.Done():
+	default:
+		t.Error("expected the workers to be stopped once closed")
+	}
+
+	deadline := time.Now().Add(time.Second)
+	for active.Load() > 0 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+
+	if n := active.Load(); n > 0 {
+		t.Errorf("expected the requests in flight to be canceled, %d are still active", n)
+	}
+
+	if _, err := remoteFile.Read(make([]byte, 1)); !errors.Is(err, io.ErrClosedPipe) {
+		t.Errorf("expected reading a closed file to fail, got: %v", err)
+	}
+
+	if err := remoteFile.Close(); err != nil {
+		t.Errorf("expected closing again to do nothing: %v", err)
+	}
+}
+
+func TestCloseUnstarted(t *testing.T) {
+	svr := newTestServer()
+	defer svr.Close()
+
+	u := svr.URL().JoinPath("assets", "test_5mb").String()
+
+	files, err := httpio.GetAll(context.Background(), []string{u})
+	if err != nil {
+		t.Fatalf("unable to get files: %v", err)
+	}
+
+	files[0].Close()
+
+	if err := files[0].Wait(context.Background()); !errors.Is(err, io.ErrClosedPipe) {
+		t.Errorf("expected the download to be closed, got: %v", err)
+	}
+
+	if _, err := files[0].Read(make([]byte, 1)); !errors.Is(err, io.ErrClosedPipe) {
+		t.Errorf("expected reading a closed file to fail, got: %v", err)
+	}
+}
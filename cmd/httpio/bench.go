@@ -0,0 +1,201 @@
+package main
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/jobstoit/httpio"
+)
+
+// benchResult is the outcome of the runs of a single combination
+type benchResult struct {
+	concurrency int
+	chunkSize   int
+	bytes       int64
+	elapsed     time.Duration
+	firstByte   time.Duration
+}
+
+// throughput returns the throughput in bytes per second
+func (r benchResult) throughput() float64 {
+	if r.elapsed <= 0 {
+		return 0
+	}
+
+	return float64(r.bytes) / r.elapsed.Seconds()
+}
+
+func runBench(ctx context.Context, args []string, stdout io.Writer) error {
+	fs := flag.NewFlagSet("bench", flag.ContinueOnError)
+	concurrencies := fs.String("concurrency", "1,2,4,8,16", "comma separated concurrency values")
+	chunkSizes := fs.String("chunk-size", "1MiB,5MiB,16MiB", "comma separated chunk sizes")
+	runs := fs.Int("runs", 1, "number of downloads per combination")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if fs.NArg() != 1 {
+		return errors.New("expected a single url")
+	}
+
+	concs, err := parseList(*concurrencies, strconv.Atoi)
+	if err != nil {
+		return fmt.Errorf("invalid concurrency: %w", err)
+	}
+
+	sizes, err := parseList(*chunkSizes, parseSize)
+	if err != nil {
+		return fmt.Errorf("invalid chunk size: %w", err)
+	}
+
+	var results []benchResult
+	for _, conc := range concs {
+		for _, size := range sizes {
+			res, err := bench(ctx, fs.Arg(0), conc, size, max(*runs, 1))
+			if err != nil {
+				return fmt.Errorf("concurrency %d, chunk size %s: %w", conc, formatSize(int64(size)), err)
+			}
+
+			results = append(results, res)
+		}
+	}
+
+	slices.SortStableFunc(results, func(a, b benchResult) int {
+		return cmp.Compare(b.throughput(), a.throughput())
+	})
+
+	tw := tabwriter.NewWriter(stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "CONCURRENCY\tCHUNK SIZE\tTHROUGHPUT\tFIRST BYTE\tELAPSED")
+	for _, r := range results {
+		fmt.Fprintf(tw, "%d\t%s\t%s/s\t%s\t%s\n",
+			r.concurrency,
+			formatSize(int64(r.chunkSize)),
+			formatSize(int64(r.throughput())),
+			r.firstByte.Round(time.Millisecond),
+			r.elapsed.Round(time.Millisecond),
+		)
+	}
+
+	return tw.Flush()
+}
+
+// bench downloads the url the given number of times, returning the average
+// elapsed time and time to the first byte
+func bench(ctx context.Context, url string, concurrency, chunkSize, runs int) (benchResult, error) {
+	res := benchResult{
+		concurrency: concurrency,
+		chunkSize:   chunkSize,
+	}
+
+	for range runs {
+		start := time.Now()
+
+		remoteFile, err := httpio.GetContext(ctx, url,
+			httpio.WithConcurrency(concurrency),
+			httpio.WithChunkSize(chunkSize),
+		)
+		if err != nil {
+			return res, err
+		}
+
+		fr := &firstByteReader{r: remoteFile}
+		n, err := io.Copy(io.Discard, fr)
+		remoteFile.Close()
+		if err != nil {
+			return res, err
+		}
+
+		res.bytes += n
+		res.elapsed += time.Since(start)
+		res.firstByte += fr.first.Sub(start)
+	}
+
+	res.bytes /= int64(runs)
+	res.elapsed /= time.Duration(runs)
+	res.firstByte /= time.Duration(runs)
+
+	return res, nil
+}
+
+// firstByteReader records when the first byte was read
+type firstByteReader struct {
+	r     io.Reader
+	first time.Time
+}
+
+func (r *firstByteReader) Read(p []byte) (int, error) {
+	n, err := r.r.Read(p)
+	if n > 0 && r.first.IsZero() {
+		r.first = time.Now()
+	}
+
+	return n, err
+}
+
+func parseList[T any](value string, parse func(string) (T, error)) ([]T, error) {
+	var list []T
+	for _, field := range strings.Split(value, ",") {
+		v, err := parse(strings.TrimSpace(field))
+		if err != nil {
+			return nil, err
+		}
+
+		list = append(list, v)
+	}
+
+	return list, nil
+}
+
+var sizeUnits = []struct {
+	suffix string
+	size   int
+}{
+	{"GiB", 1 << 30},
+	{"MiB", 1 << 20},
+	{"KiB", 1 << 10},
+	{"G", 1 << 30},
+	{"M", 1 << 20},
+	{"K", 1 << 10},
+	{"B", 1},
+}
+
+// parseSize parses a size like 512KiB or 5MiB
+func parseSize(value string) (int, error) {
+	for _, unit := range sizeUnits {
+		if num, ok := strings.CutSuffix(value, unit.suffix); ok {
+			n, err := strconv.Atoi(num)
+			if err != nil || n <= 0 {
+				return 0, fmt.Errorf("invalid size '%s'", value)
+			}
+
+			return n * unit.size, nil
+		}
+	}
+
+	n, err := strconv.Atoi(value)
+	if err != nil || n <= 0 {
+		return 0, fmt.Errorf("invalid size '%s'", value)
+	}
+
+	return n, nil
+}
+
+// formatSize formats the number of bytes with a binary unit
+func formatSize(n int64) string {
+	for _, unit := range sizeUnits[:3] {
+		if n >= int64(unit.size) {
+			return fmt.Sprintf("%.1f%s", float64(n)/float64(unit.size), unit.suffix)
+		}
+	}
+
+	return fmt.Sprintf("%dB", n)
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestBench(t *testing.T) {
+	content := bytes.Repeat([]byte("bench"), 1024*256)
+	svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.ServeContent(w, r, "file", time.Time{}, bytes.NewReader(content))
+	}))
+	defer svr.Close()
+
+	out := &bytes.Buffer{}
+	err := runBench(context.Background(), []string{"-concurrency", "1,4", "-chunk-size", "256KiB,1MiB", svr.URL}, out)
+	if err != nil {
+		t.Fatalf("unable to bench: %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if e, a := 5, len(lines); e != a {
+		t.Fatalf("expected %d lines, got %d:\n%s", e, a, out)
+	}
+
+	if !strings.HasPrefix(lines[0], "CONCURRENCY") {
+		t.Errorf("expected a header, got '%s'", lines[0])
+	}
+}
+
+func TestParseSize(t *testing.T) {
+	tests := []struct {
+		value  string
+		expect int
+	}{
+		{"1024", 1024},
+		{"512KiB", 512 * 1024},
+		{"5MiB", 5 * 1024 * 1024},
+		{"1G", 1024 * 1024 * 1024},
+	}
+
+	for _, test := range tests {
+		actual, err := parseSize(test.value)
+		if err != nil || actual != test.expect {
+			t.Errorf("expected %d for '%s', got %d: %v", test.expect, test.value, actual, err)
+		}
+	}
+
+	if _, err := parseSize("5XB"); err == nil {
+		t.Errorf("expected an error for an invalid size")
+	}
+}
@@ -0,0 +1,267 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path"
+	"slices"
+	"sync/atomic"
+	"time"
+
+	"github.com/jobstoit/httpio"
+)
+
+// progressInterval is the interval between the progress events
+const progressInterval = 500 * time.Millisecond
+
+func runGet(ctx context.Context, args []string, stdout io.Writer) error {
+	fs := flag.NewFlagSet("get", flag.ContinueOnError)
+	out := fs.String("o", "", "output file, defaults to the name in the url")
+	concurrency := fs.Int("concurrency", httpio.DefaultConcurrency, "number of chunks fetched at once")
+	chunkSize := fs.String("chunk-size", "5MiB", "size of the chunks")
+	progressMode := fs.String("progress", "none", "progress output, none or json for JSON lines on stdout")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if fs.NArg() != 1 {
+		return errors.New("expected a single url")
+	}
+
+	if *progressMode != "none" && *progressMode != "json" {
+		return fmt.Errorf("unknown progress mode '%s'", *progressMode)
+	}
+
+	size, err := parseSize(*chunkSize)
+	if err != nil {
+		return fmt.Errorf("invalid chunk size: %w", err)
+	}
+
+	rawURL := fs.Arg(0)
+	dest := *out
+	if dest == "" {
+		u, err := url.Parse(rawURL)
+		if err != nil {
+			return err
+		}
+
+		if dest = path.Base(u.Path); dest == "/" || dest == "." {
+			return errors.New("unable to name the output file, set it with -o")
+		}
+	}
+
+	// the metadata of the stat is reused by the download
+	meta := httpio.NewMetadataCache(time.Minute)
+	opts := []httpio.Option{
+		httpio.WithConcurrency(*concurrency),
+		httpio.WithChunkSize(size),
+		httpio.WithMetadataCache(meta),
+	}
+
+	stats, err := httpio.StatMany(ctx, []string{rawURL}, opts...)
+	if err != nil {
+		return err
+	}
+
+	if stats[0].Err != nil {
+		return stats[0].Err
+	}
+
+	f, err := httpio.GetContext(ctx, rawURL, opts...)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	file, err := os.Create(dest)
+	if err != nil {
+		return err
+	}
+
+	w := &countingWriter{w: file}
+
+	var (
+		p    *progress
+		stop func()
+	)
+	if *progressMode == "json" {
+		p = &progress{
+			enc:     json.NewEncoder(stdout),
+			file:    f,
+			written: &w.n,
+			size:    stats[0].Metadata.Size,
+			begin:   time.Now(),
+			started: map[int]httpio.Chunk{},
+		}
+
+		p.emit(progressEvent{Event: "start", URL: rawURL, Size: p.size})
+		stop = p.run(progressInterval)
+	}
+
+	_, err = io.Copy(w, f)
+	if cerr := file.Close(); err == nil {
+		err = cerr
+	}
+
+	if p != nil {
+		stop()
+		p.finish(err)
+	}
+
+	return err
+}
+
+// countingWriter counts the bytes written
+type countingWriter struct {
+	w io.Writer
+	n atomic.Int64
+}
+
+func (w *countingWriter) Write(p []byte) (int, error) {
+	n, err := w.w.Write(p)
+	w.n.Add(int64(n))
+
+	return n, err
+}
+
+// progressEvent is a line of the JSON progress output
+type progressEvent struct {
+	Event    string  `json:"event"`
+	URL      string  `json:"url,omitempty"`
+	Bytes    int64   `json:"bytes,omitempty"`
+	Size     int64   `json:"size,omitempty"`
+	Rate     float64 `json:"rate,omitempty"`
+	ETA      float64 `json:"eta,omitempty"`
+	Elapsed  float64 `json:"elapsed,omitempty"`
+	Chunk    *int    `json:"chunk,omitempty"`
+	Offset   int64   `json:"offset,omitempty"`
+	Length   int64   `json:"length,omitempty"`
+	Status   string  `json:"status,omitempty"`
+	Protocol string  `json:"protocol,omitempty"`
+	Error    string  `json:"error,omitempty"`
+}
+
+// progress reports the progress of a download as JSON lines. Chunks are
+// reported as started once their response arrived and as done once they
+// were written, the rate is in bytes per second and the times in seconds.
+type progress struct {
+	enc     *json.Encoder
+	file    *httpio.RemoteFile
+	written *atomic.Int64
+	size    int64
+	begin   time.Time
+	seen    int
+	started map[int]httpio.Chunk
+}
+
+// run reports the progress at every interval until stopped
+func (p *progress) run(interval time.Duration) (stop func()) {
+	done := make(chan struct{})
+	stopped := make(chan struct{})
+
+	go func() {
+		defer close(stopped)
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				p.report()
+			}
+		}
+	}()
+
+	return func() {
+		close(done)
+		<-stopped
+	}
+}
+
+// report emits the chunk changes since the last report and the progress
+func (p *progress) report() {
+	written := p.written.Load()
+
+	chunks := p.file.Stats().Chunks
+	for _, cs := range chunks[p.seen:] {
+		p.started[cs.Chunk.Index] = cs.Chunk
+		p.emit(progressEvent{
+			Event:    "chunk",
+			Chunk:    &cs.Chunk.Index,
+			Offset:   cs.Chunk.Offset,
+			Length:   cs.Chunk.Length,
+			Status:   "started",
+			Protocol: cs.Protocol,
+		})
+	}
+	p.seen = len(chunks)
+
+	var done []int
+	for index, c := range p.started {
+		if c.Offset+c.Length <= written {
+			done = append(done, index)
+		}
+	}
+	slices.Sort(done)
+
+	for _, index := range done {
+		c := p.started[index]
+		delete(p.started, index)
+		p.emit(progressEvent{
+			Event:  "chunk",
+			Chunk:  &index,
+			Offset: c.Offset,
+			Length: c.Length,
+			Status: "done",
+		})
+	}
+
+	elapsed := time.Since(p.begin).Seconds()
+	event := progressEvent{
+		Event:   "progress",
+		Bytes:   written,
+		Size:    p.size,
+		Elapsed: elapsed,
+	}
+
+	if elapsed > 0 {
+		event.Rate = float64(written) / elapsed
+	}
+
+	if event.Rate > 0 && p.size > written {
+		event.ETA = float64(p.size-written) / event.Rate
+	}
+
+	p.emit(event)
+}
+
+// finish emits the final progress and the outcome, the reporting has to
+// be stopped
+func (p *progress) finish(err error) {
+	p.report()
+
+	if err != nil {
+		p.emit(progressEvent{Event: "error", Error: err.Error()})
+		return
+	}
+
+	p.emit(progressEvent{
+		Event:   "done",
+		Bytes:   p.written.Load(),
+		Elapsed: time.Since(p.begin).Seconds(),
+	})
+}
+
+func (p *progress) emit(event progressEvent) {
+	// the output is best effort, a failing writer doesn't fail the download
+	_ = p.enc.Encode(event)
+}
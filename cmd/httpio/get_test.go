@@ -0,0 +1,79 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestGetProgressJSON(t *testing.T) {
+	content := bytes.Repeat([]byte("0123456789abcdef"), 64*1024)
+
+	src := t.TempDir()
+	writeTree(t, src, map[string]string{"file.bin": string(content)})
+
+	svr := httptest.NewServer(http.FileServer(http.Dir(src)))
+	defer svr.Close()
+
+	dest := filepath.Join(t.TempDir(), "out.bin")
+	args := []string{"-o", dest, "-chunk-size", "256KiB", "-progress", "json", svr.URL + "/file.bin"}
+
+	stdout := &bytes.Buffer{}
+	if err := runGet(context.Background(), args, stdout); err != nil {
+		t.Fatalf("unable to get: %v", err)
+	}
+
+	actual, err := os.ReadFile(dest)
+	if err != nil || !bytes.Equal(actual, content) {
+		t.Fatalf("expected the file to be downloaded: %v", err)
+	}
+
+	var events []progressEvent
+	dec := json.NewDecoder(stdout)
+	for dec.More() {
+		var event progressEvent
+		if err := dec.Decode(&event); err != nil {
+			t.Fatalf("invalid JSON line: %v", err)
+		}
+
+		events = append(events, event)
+	}
+
+	if len(events) < 2 || events[0].Event != "start" || events[len(events)-1].Event != "done" {
+		t.Fatalf("expected start and done events, got: %+v", events)
+	}
+
+	if events[0].Size != int64(len(content)) {
+		t.Errorf("expected size %d, got %d", len(content), events[0].Size)
+	}
+
+	status := map[int][]string{}
+	var last progressEvent
+	for _, event := range events {
+		switch event.Event {
+		case "chunk":
+			status[*event.Chunk] = append(status[*event.Chunk], event.Status)
+		case "progress":
+			last = event
+		}
+	}
+
+	if len(status) != 4 {
+		t.Errorf("expected 4 chunks, got %d", len(status))
+	}
+
+	for index, s := range status {
+		if len(s) != 2 || s[0] != "started" || s[1] != "done" {
+			t.Errorf("expected chunk %d to be started and done, got: %v", index, s)
+		}
+	}
+
+	if last.Bytes != int64(len(content)) {
+		t.Errorf("expected the final progress to cover the file, got %d bytes", last.Bytes)
+	}
+}
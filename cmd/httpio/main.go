@@ -0,0 +1,59 @@
+// Command httpio downloads and benchmarks files using the httpio package.
+//
+// Usage:
+//
+//	httpio <command> [flags] <url>
+//
+// The commands are:
+//
+//	get	download a file
+//	bench	benchmark an origin with a matrix of concurrency and chunk sizes
+//	mirror	download the tree below an index page or bucket listing
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+)
+
+const usage = `usage: httpio <command> [flags] <url>
+
+commands:
+  get     download a file
+  bench   benchmark an origin with a matrix of concurrency and chunk sizes
+  mirror  download the tree below an index page or bucket listing
+
+Run 'httpio <command> -h' for the flags of a command.
+`
+
+type command func(ctx context.Context, args []string, stdout io.Writer) error
+
+var commands = map[string]command{
+	"get":    runGet,
+	"bench":  runBench,
+	"mirror": runMirror,
+}
+
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	if len(os.Args) < 2 {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	cmd, ok := commands[os.Args[1]]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "unknown command '%s'\n\n%s", os.Args[1], usage)
+		os.Exit(2)
+	}
+
+	if err := cmd(ctx, os.Args[2:], os.Stdout); err != nil {
+		fmt.Fprintf(os.Stderr, "httpio %s: %v\n", os.Args[1], err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,315 @@
+package main
+
+import (
+	"context"
+	"encoding/xml"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jobstoit/httpio"
+)
+
+// maxIndexSize limits the size of the index pages that are parsed
+const maxIndexSize = 16 * 1024 * 1024
+
+var hrefPattern = regexp.MustCompile(`(?i)href\s*=\s*["']([^"'#]+)["']`)
+
+// globs is a flag that can be set multiple times
+type globs []string
+
+func (g *globs) String() string {
+	return strings.Join(*g, ",")
+}
+
+func (g *globs) Set(value string) error {
+	if _, err := path.Match(value, ""); err != nil {
+		return fmt.Errorf("invalid glob '%s': %w", value, err)
+	}
+
+	*g = append(*g, value)
+
+	return nil
+}
+
+// match reports whether any of the globs matches the relative path, globs
+// without a slash are matched against the file name
+func (g globs) match(rel string) bool {
+	for _, glob := range g {
+		name := rel
+		if !strings.Contains(glob, "/") {
+			name = path.Base(rel)
+		}
+
+		if ok, _ := path.Match(glob, name); ok {
+			return true
+		}
+	}
+
+	return false
+}
+
+// remoteFile is a file found while walking the remote index
+type remoteFile struct {
+	url string
+	rel string
+}
+
+func runMirror(ctx context.Context, args []string, stdout io.Writer) error {
+	fs := flag.NewFlagSet("mirror", flag.ContinueOnError)
+	out := fs.String("o", ".", "output directory")
+	depth := fs.Int("depth", 5, "maximum directory depth to walk")
+	jobs := fs.Int("jobs", 4, "number of files downloaded at once")
+	var include, exclude globs
+	fs.Var(&include, "include", "only download files matching the glob, can be repeated")
+	fs.Var(&exclude, "exclude", "skip files matching the glob, can be repeated")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if fs.NArg() != 1 {
+		return errors.New("expected a single url")
+	}
+
+	root, err := url.Parse(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	if !strings.HasSuffix(root.Path, "/") {
+		root.Path += "/"
+	}
+
+	files, err := walk(ctx, root, *depth)
+	if err != nil {
+		return err
+	}
+
+	var selected []remoteFile
+	for _, f := range files {
+		if (len(include) == 0 || include.match(f.rel)) && !exclude.match(f.rel) {
+			selected = append(selected, f)
+		}
+	}
+
+	return mirror(ctx, selected, *out, max(*jobs, 1), stdout)
+}
+
+// walk lists the files below the root, following the directories of HTML
+// autoindex pages up to the given depth or reading a bucket listing
+func walk(ctx context.Context, root *url.URL, depth int) ([]remoteFile, error) {
+	var files []remoteFile
+	seen := map[string]bool{root.String(): true}
+	dirs := []*url.URL{root}
+
+	for level := 0; len(dirs) > 0 && level <= depth; level++ {
+		var next []*url.URL
+		for _, dir := range dirs {
+			links, err := listIndex(ctx, root, dir)
+			if err != nil {
+				return nil, err
+			}
+
+			for _, link := range links {
+				if seen[link.String()] || !strings.HasPrefix(link.Path, root.Path) || link.Host != root.Host {
+					continue
+				}
+				seen[link.String()] = true
+
+				if strings.HasSuffix(link.Path, "/") {
+					next = append(next, link)
+					continue
+				}
+
+				files = append(files, remoteFile{
+					url: link.String(),
+					rel: strings.TrimPrefix(link.Path, root.Path),
+				})
+			}
+		}
+
+		dirs = next
+	}
+
+	return files, nil
+}
+
+// listIndex returns the links of the index page at the given url
+func listIndex(ctx context.Context, root, dir *url.URL) ([]*url.URL, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, dir.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unable to list '%s': %s", dir, res.Status)
+	}
+
+	body, err := io.ReadAll(io.LimitReader(res.Body, maxIndexSize))
+	if err != nil {
+		return nil, err
+	}
+
+	if head := strings.TrimSpace(string(body[:min(len(body), 512)])); strings.HasPrefix(head, "<?xml") &&
+		strings.Contains(head, "<ListBucketResult") {
+		return listBucket(ctx, root, body)
+	}
+
+	var links []*url.URL
+	for _, match := range hrefPattern.FindAllSubmatch(body, -1) {
+		link, err := dir.Parse(string(match[1]))
+		if err != nil || link.RawQuery != "" {
+			continue
+		}
+
+		links = append(links, link)
+	}
+
+	return links, nil
+}
+
+// listBucketResult is an S3 compatible bucket listing
+type listBucketResult struct {
+	IsTruncated bool   `xml:"IsTruncated"`
+	NextMarker  string `xml:"NextMarker"`
+	Contents    []struct {
+		Key string `xml:"Key"`
+	} `xml:"Contents"`
+}
+
+// listBucket returns the objects of a bucket listing, requesting the next
+// pages of a truncated listing
+func listBucket(ctx context.Context, root *url.URL, body []byte) ([]*url.URL, error) {
+	var links []*url.URL
+	for {
+		var result listBucketResult
+		if err := xml.Unmarshal(body, &result); err != nil {
+			return nil, fmt.Errorf("invalid bucket listing: %w", err)
+		}
+
+		for _, content := range result.Contents {
+			link := root.JoinPath(content.Key)
+			if !strings.HasSuffix(content.Key, "/") {
+				links = append(links, link)
+			}
+		}
+
+		if !result.IsTruncated || len(result.Contents) == 0 {
+			return links, nil
+		}
+
+		marker := result.NextMarker
+		if marker == "" {
+			marker = result.Contents[len(result.Contents)-1].Key
+		}
+
+		page := *root
+		page.RawQuery = url.Values{"marker": {marker}}.Encode()
+
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, page.String(), nil)
+		if err != nil {
+			return nil, err
+		}
+
+		res, err := http.DefaultClient.Do(req)
+		if err != nil {
+			return nil, err
+		}
+
+		body, err = io.ReadAll(io.LimitReader(res.Body, maxIndexSize))
+		res.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+}
+
+// mirror downloads the files below the output directory, files that
+// already exist with the size of the remote file are skipped
+func mirror(ctx context.Context, files []remoteFile, out string, jobs int, stdout io.Writer) error {
+	urls := make([]string, len(files))
+	for i, f := range files {
+		urls[i] = f.url
+	}
+
+	// the metadata of the stats is reused by the downloads
+	meta := httpio.NewMetadataCache(time.Hour)
+
+	stats, err := httpio.StatMany(ctx, urls, httpio.WithConcurrency(jobs), httpio.WithMetadataCache(meta))
+	if err != nil {
+		return err
+	}
+
+	var (
+		wg    sync.WaitGroup
+		mu    sync.Mutex
+		errs  []error
+		slots = make(chan struct{}, jobs)
+	)
+
+	report := func(format string, a ...any) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		fmt.Fprintf(stdout, format+"\n", a...)
+	}
+
+	for i, f := range files {
+		if stats[i].Err != nil {
+			mu.Lock()
+			errs = append(errs, fmt.Errorf("%s: %w", f.rel, stats[i].Err))
+			mu.Unlock()
+
+			continue
+		}
+
+		dest := filepath.Join(out, filepath.FromSlash(f.rel))
+		if info, err := os.Stat(dest); err == nil && info.Size() == stats[i].Metadata.Size {
+			report("skipped %s", f.rel)
+			continue
+		}
+
+		slots <- struct{}{}
+		wg.Add(1)
+		go func() {
+			defer func() {
+				<-slots
+				wg.Done()
+			}()
+
+			err := os.MkdirAll(filepath.Dir(dest), 0o755)
+			if err == nil {
+				err = httpio.DownloadFile(ctx, f.url, dest, httpio.WithMetadataCache(meta))
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", f.rel, err))
+				return
+			}
+
+			fmt.Fprintf(stdout, "downloaded %s\n", f.rel)
+		}()
+	}
+	wg.Wait()
+
+	return errors.Join(errs...)
+}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func writeTree(t *testing.T, dir string, files map[string]string) {
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("unable to create directory: %v", err)
+		}
+
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatalf("unable to write file: %v", err)
+		}
+	}
+}
+
+func TestMirror(t *testing.T) {
+	src := t.TempDir()
+	writeTree(t, src, map[string]string{
+		"a.txt":          "a",
+		"b.bin":          "b",
+		"sub/c.txt":      "c",
+		"sub/skip.txt":   "skip",
+		"sub/deep/d.txt": "d",
+	})
+
+	svr := httptest.NewServer(http.FileServer(http.Dir(src)))
+	defer svr.Close()
+
+	out := t.TempDir()
+	args := []string{"-o", out, "-include", "*.txt", "-exclude", "skip*", svr.URL + "/"}
+
+	stdout := &bytes.Buffer{}
+	if err := runMirror(context.Background(), args, stdout); err != nil {
+		t.Fatalf("unable to mirror: %v", err)
+	}
+
+	for _, name := range []string{"a.txt", "sub/c.txt", "sub/deep/d.txt"} {
+		actual, err := os.ReadFile(filepath.Join(out, filepath.FromSlash(name)))
+		if err != nil {
+			t.Errorf("expected '%s' to be mirrored: %v", name, err)
+			continue
+		}
+
+		if e, a := filepath.Base(strings.TrimSuffix(name, ".txt")), string(actual); e != a {
+			t.Errorf("expected content '%s' of '%s', got '%s'", e, name, a)
+		}
+	}
+
+	for _, name := range []string{"b.bin", "sub/skip.txt"} {
+		if _, err := os.Stat(filepath.Join(out, filepath.FromSlash(name))); err == nil {
+			t.Errorf("expected '%s' to be filtered out", name)
+		}
+	}
+
+	stdout.Reset()
+	if err := runMirror(context.Background(), args, stdout); err != nil {
+		t.Fatalf("unable to mirror again: %v", err)
+	}
+
+	if e, a := 3, strings.Count(stdout.String(), "skipped"); e != a {
+		t.Errorf("expected %d existing files to be skipped, got:\n%s", e, stdout)
+	}
+}
+
+func TestMirrorBucket(t *testing.T) {
+	objects := map[string]string{"one.txt": "1", "dir/two.txt": "2"}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/{$}", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/xml")
+		fmt.Fprint(w, `<?xml version="1.0" encoding="UTF-8"?>
+<ListBucketResult><IsTruncated>false</IsTruncated>
+<Contents><Key>one.txt</Key></Contents>
+<Contents><Key>dir/two.txt</Key></Contents>
+</ListBucketResult>`)
+	})
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		content, ok := objects[strings.TrimPrefix(r.URL.Path, "/")]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+
+		http.ServeContent(w, r, r.URL.Path, time.Time{}, strings.NewReader(content))
+	})
+
+	svr := httptest.NewServer(mux)
+	defer svr.Close()
+
+	out := t.TempDir()
+	if err := runMirror(context.Background(), []string{"-o", out, svr.URL}, &bytes.Buffer{}); err != nil {
+		t.Fatalf("unable to mirror: %v", err)
+	}
+
+	for name, content := range objects {
+		actual, err := os.ReadFile(filepath.Join(out, filepath.FromSlash(name)))
+		if err != nil || string(actual) != content {
+			t.Errorf("expected '%s' to be mirrored with '%s', got '%s': %v", name, content, actual, err)
+		}
+	}
+}
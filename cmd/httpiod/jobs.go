@@ -0,0 +1,364 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/jobstoit/httpio"
+)
+
+// The states of a job
+const (
+	stateQueued   = "queued"
+	stateRunning  = "running"
+	statePaused   = "paused"
+	stateDone     = "done"
+	stateFailed   = "failed"
+	stateCanceled = "canceled"
+)
+
+// job is a download submitted to the daemon
+type job struct {
+	id   string
+	url  string
+	path string
+
+	// the fields below are guarded by the manager's lock
+	state   string
+	size    int64
+	err     error
+	cancel  context.CancelFunc
+	stopped chan struct{}
+
+	written atomic.Int64
+}
+
+// jobStatus is the JSON representation of a job
+type jobStatus struct {
+	ID    string `json:"id"`
+	URL   string `json:"url"`
+	Path  string `json:"path"`
+	State string `json:"state"`
+	Bytes int64  `json:"bytes"`
+	Size  int64  `json:"size"`
+	Error string `json:"error,omitempty"`
+}
+
+// manager runs the jobs, a limited number at once
+type manager struct {
+	dir   string
+	slots chan struct{}
+	meta  *httpio.MetadataCache
+
+	mu     sync.Mutex
+	wg     sync.WaitGroup
+	nextID int
+	jobs   map[string]*job
+	order  []*job
+}
+
+func newManager(dir string, jobs int) *manager {
+	return &manager{
+		dir:   dir,
+		slots: make(chan struct{}, jobs),
+		meta:  httpio.NewMetadataCache(time.Minute),
+		jobs:  map[string]*job{},
+	}
+}
+
+// submit adds a job downloading the url to the path relative to the
+// download directory
+func (m *manager) submit(url, path string) (*job, error) {
+	if url == "" {
+		return nil, errors.New("missing url")
+	}
+
+	if !filepath.IsLocal(path) {
+		return nil, fmt.Errorf("invalid path '%s'", path)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.nextID++
+	j := &job{
+		id:   strconv.Itoa(m.nextID),
+		url:  url,
+		path: path,
+	}
+
+	m.jobs[j.id] = j
+	m.order = append(m.order, j)
+	m.start(j)
+
+	return j, nil
+}
+
+// start queues the job, it has to be called with the lock held
+func (m *manager) start(j *job) {
+	ctx, cancel := context.WithCancel(context.Background())
+	j.state = stateQueued
+	j.err = nil
+	j.cancel = cancel
+
+	prev := j.stopped
+	j.stopped = make(chan struct{})
+
+	m.wg.Add(1)
+	go m.run(ctx, j, prev, j.stopped)
+}
+
+// run downloads the job once a slot is free and the previous run of the
+// job stopped, so the runs don't contend for the destination
+func (m *manager) run(ctx context.Context, j *job, prev <-chan struct{}, stopped chan<- struct{}) {
+	defer m.wg.Done()
+	defer close(stopped)
+
+	if prev != nil {
+		<-prev
+	}
+	j.written.Store(0)
+
+	select {
+	case <-ctx.Done():
+		return
+	case m.slots <- struct{}{}:
+	}
+	defer func() { <-m.slots }()
+
+	m.mu.Lock()
+	if ctx.Err() != nil {
+		m.mu.Unlock()
+		return
+	}
+	j.state = stateRunning
+	m.mu.Unlock()
+
+	err := m.download(ctx, j)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	// a paused or canceled job already has its state
+	if ctx.Err() != nil {
+		return
+	}
+
+	j.cancel()
+	if err != nil {
+		j.state = stateFailed
+		j.err = err
+
+		return
+	}
+
+	j.state = stateDone
+}
+
+func (m *manager) download(ctx context.Context, j *job) error {
+	opts := []httpio.Option{httpio.WithMetadataCache(m.meta)}
+
+	stats, err := httpio.StatMany(ctx, []string{j.url}, opts...)
+	if err != nil {
+		return err
+	}
+
+	if stats[0].Err != nil {
+		return stats[0].Err
+	}
+
+	m.mu.Lock()
+	j.size = stats[0].Metadata.Size
+	m.mu.Unlock()
+
+	dest := filepath.Join(m.dir, j.path)
+	if err := os.MkdirAll(filepath.Dir(dest), 0o755); err != nil {
+		return err
+	}
+
+	// the progress counts the chunks written before a pause as well, an
+	// inspector would keep the download from being resumed
+	progress := httpio.WithProgress(func(downloaded, _ int64) {
+		j.written.Store(downloaded)
+	})
+
+	return httpio.DownloadFile(ctx, j.url, dest, append(opts, progress)...)
+}
+
+// pause stops a queued or running job, resuming downloads it again
+func (m *manager) pause(j *job) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if j.state != stateQueued && j.state != stateRunning {
+		return fmt.Errorf("unable to pause a job that's %s", j.state)
+	}
+
+	j.state = statePaused
+	j.cancel()
+
+	return nil
+}
+
+// resume queues a paused job again
+func (m *manager) resume(j *job) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if j.state != statePaused {
+		return fmt.Errorf("unable to resume a job that's %s", j.state)
+	}
+
+	m.start(j)
+
+	return nil
+}
+
+// cancelJob cancels the job unless it finished already
+func (m *manager) cancelJob(j *job) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	switch j.state {
+	case stateDone, stateFailed, stateCanceled:
+		return fmt.Errorf("unable to cancel a job that's %s", j.state)
+	}
+
+	j.state = stateCanceled
+	j.cancel()
+
+	return nil
+}
+
+// close cancels the unfinished jobs and waits for them to stop
+func (m *manager) close() {
+	m.mu.Lock()
+	for _, j := range m.order {
+		j.cancel()
+	}
+	m.mu.Unlock()
+
+	m.wg.Wait()
+}
+
+func (m *manager) status(j *job) jobStatus {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	s := jobStatus{
+		ID:    j.id,
+		URL:   j.url,
+		Path:  j.path,
+		State: j.state,
+		Bytes: j.written.Load(),
+		Size:  j.size,
+	}
+
+	if j.err != nil {
+		s.Error = j.err.Error()
+	}
+
+	return s
+}
+
+func (m *manager) lookup(id string) (*job, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	j, ok := m.jobs[id]
+
+	return j, ok
+}
+
+func (m *manager) handler() http.Handler {
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("POST /jobs", func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			URL  string `json:"url"`
+			Path string `json:"path"`
+		}
+
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+
+		j, err := m.submit(req.URL, req.Path)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+
+		writeJSON(w, http.StatusCreated, m.status(j))
+	})
+
+	mux.HandleFunc("GET /jobs", func(w http.ResponseWriter, r *http.Request) {
+		m.mu.Lock()
+		order := append([]*job(nil), m.order...)
+		m.mu.Unlock()
+
+		list := make([]jobStatus, len(order))
+		for i, j := range order {
+			list[i] = m.status(j)
+		}
+
+		writeJSON(w, http.StatusOK, list)
+	})
+
+	mux.HandleFunc("GET /jobs/{id}", m.withJob(func(w http.ResponseWriter, j *job) {
+		writeJSON(w, http.StatusOK, m.status(j))
+	}))
+
+	mux.HandleFunc("POST /jobs/{id}/pause", m.withJob(m.action(m.pause)))
+	mux.HandleFunc("POST /jobs/{id}/resume", m.withJob(m.action(m.resume)))
+	mux.HandleFunc("DELETE /jobs/{id}", m.withJob(m.action(m.cancelJob)))
+
+	return mux
+}
+
+// withJob looks up the job of the request
+func (m *manager) withJob(fn func(http.ResponseWriter, *job)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		j, ok := m.lookup(r.PathValue("id"))
+		if !ok {
+			writeError(w, http.StatusNotFound, errors.New("job not found"))
+			return
+		}
+
+		fn(w, j)
+	}
+}
+
+// action applies the state change to the job, responding with the job
+func (m *manager) action(fn func(*job) error) func(http.ResponseWriter, *job) {
+	return func(w http.ResponseWriter, j *job) {
+		if err := fn(j); err != nil {
+			writeError(w, http.StatusConflict, err)
+			return
+		}
+
+		writeJSON(w, http.StatusOK, m.status(j))
+	}
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+
+	// the client is gone when the response can't be written
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, map[string]string{"error": err.Error()})
+}
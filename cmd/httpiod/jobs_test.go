@@ -0,0 +1,267 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func newTestOrigin(t *testing.T, content []byte, wrap func(http.Handler) http.Handler) *httptest.Server {
+	src := t.TempDir()
+	if err := os.WriteFile(filepath.Join(src, "file.bin"), content, 0o644); err != nil {
+		t.Fatalf("unable to write file: %v", err)
+	}
+
+	return httptest.NewServer(wrap(http.FileServer(http.Dir(src))))
+}
+
+func doJSON(t *testing.T, method, url string, body any, status int) jobStatus {
+	t.Helper()
+
+	rd := bytes.NewReader(nil)
+	if body != nil {
+		data, _ := json.Marshal(body)
+		rd = bytes.NewReader(data)
+	}
+
+	req, _ := http.NewRequest(method, url, rd)
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("unable to request %s %s: %v", method, url, err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != status {
+		t.Fatalf("expected status %d for %s %s, got %d", status, method, url, res.StatusCode)
+	}
+
+	var s jobStatus
+	_ = json.NewDecoder(res.Body).Decode(&s)
+
+	return s
+}
+
+func waitState(t *testing.T, api, id, state string) jobStatus {
+	t.Helper()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		s := doJSON(t, http.MethodGet, api+"/jobs/"+id, nil, http.StatusOK)
+		if s.State == state {
+			return s
+		}
+
+		if time.Now().After(deadline) {
+			t.Fatalf("expected job %s to be %s, got: %+v", id, state, s)
+		}
+
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestDaemon(t *testing.T) {
+	content := bytes.Repeat([]byte("httpiod"), 300*1024)
+	origin := newTestOrigin(t, content, func(h http.Handler) http.Handler { return h })
+	defer origin.Close()
+
+	dir := t.TempDir()
+	m := newManager(dir, 2)
+	defer m.close()
+
+	api := httptest.NewServer(m.handler())
+	defer api.Close()
+
+	job := doJSON(t, http.MethodPost, api.URL+"/jobs", map[string]string{
+		"url":  origin.URL + "/file.bin",
+		"path": "sub/file.bin",
+	}, http.StatusCreated)
+
+	done := waitState(t, api.URL, job.ID, stateDone)
+	if done.Bytes != int64(len(content)) || done.Size != int64(len(content)) {
+		t.Errorf("expected the progress to cover the file, got: %+v", done)
+	}
+
+	actual, err := os.ReadFile(filepath.Join(dir, "sub", "file.bin"))
+	if err != nil || !bytes.Equal(actual, content) {
+		t.Errorf("expected the file to be downloaded: %v", err)
+	}
+
+	doJSON(t, http.MethodPost, api.URL+"/jobs", map[string]string{
+		"url":  origin.URL + "/file.bin",
+		"path": "../escape.bin",
+	}, http.StatusBadRequest)
+
+	doJSON(t, http.MethodGet, api.URL+"/jobs/404", nil, http.StatusNotFound)
+	doJSON(t, http.MethodDelete, api.URL+"/jobs/"+job.ID, nil, http.StatusConflict)
+
+	res, err := http.Get(api.URL + "/jobs")
+	if err != nil {
+		t.Fatalf("unable to list jobs: %v", err)
+	}
+	defer res.Body.Close()
+
+	var list []jobStatus
+	if err := json.NewDecoder(res.Body).Decode(&list); err != nil || len(list) != 1 || list[0].ID != job.ID {
+		t.Errorf("expected the job to be listed, got: %+v, %v", list, err)
+	}
+}
+
+func TestDaemonPause(t *testing.T) {
+	content := bytes.Repeat([]byte("httpiod"), 300*1024)
+	release := make(chan struct{})
+	origin := newTestOrigin(t, content, func(h http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Header.Get("Range") != "" {
+				select {
+				case <-release:
+				case <-r.Context().Done():
+					return
+				}
+			}
+
+			h.ServeHTTP(w, r)
+		})
+	})
+	defer origin.Close()
+
+	m := newManager(t.TempDir(), 1)
+	defer m.close()
+
+	api := httptest.NewServer(m.handler())
+	defer api.Close()
+
+	var ids []string
+	for i := range 2 {
+		job := doJSON(t, http.MethodPost, api.URL+"/jobs", map[string]string{
+			"url":  origin.URL + "/file.bin",
+			"path": fmt.Sprintf("file%d.bin", i),
+		}, http.StatusCreated)
+		ids = append(ids, job.ID)
+	}
+
+	waitState(t, api.URL, ids[0], stateRunning)
+	if s := doJSON(t, http.MethodGet, api.URL+"/jobs/"+ids[1], nil, http.StatusOK); s.State != stateQueued {
+		t.Errorf("expected the second job to wait for a slot, got: %s", s.State)
+	}
+
+	doJSON(t, http.MethodDelete, api.URL+"/jobs/"+ids[1], nil, http.StatusOK)
+
+	if s := doJSON(t, http.MethodPost, api.URL+"/jobs/"+ids[0]+"/pause", nil, http.StatusOK); s.State != statePaused {
+		t.Errorf("expected the job to be paused, got: %s", s.State)
+	}
+
+	doJSON(t, http.MethodPost, api.URL+"/jobs/"+ids[1]+"/resume", nil, http.StatusConflict)
+
+	close(release)
+	doJSON(t, http.MethodPost, api.URL+"/jobs/"+ids[0]+"/resume", nil, http.StatusOK)
+	waitState(t, api.URL, ids[0], stateDone)
+
+	if s := doJSON(t, http.MethodGet, api.URL+"/jobs/"+ids[1], nil, http.StatusOK); s.State != stateCanceled {
+		t.Errorf("expected the second job to stay canceled, got: %s", s.State)
+	}
+}
+
+func TestDaemonPauseResumes(t *testing.T) {
+	const chunkSize = 5 * 1024 * 1024
+	content := bytes.Repeat([]byte("httpiod"), 2*chunkSize/7+1024)
+
+	var mu sync.Mutex
+	var resumed bool
+	var refetched []string
+	release := make(chan struct{})
+	origin := newTestOrigin(t, content, func(h http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			rng := r.Header.Get("Range")
+
+			mu.Lock()
+			if resumed && strings.HasPrefix(rng, "bytes=0-") {
+				refetched = append(refetched, rng)
+			}
+			mu.Unlock()
+
+			// only the first chunk completes before the pause
+			if rng != "" && !strings.HasPrefix(rng, "bytes=0-") {
+				select {
+				case <-release:
+				case <-r.Context().Done():
+					return
+				}
+			}
+
+			h.ServeHTTP(w, r)
+		})
+	})
+	defer origin.Close()
+
+	dir := t.TempDir()
+	m := newManager(dir, 1)
+	defer m.close()
+
+	api := httptest.NewServer(m.handler())
+	defer api.Close()
+
+	job := doJSON(t, http.MethodPost, api.URL+"/jobs", map[string]string{
+		"url":  origin.URL + "/file.bin",
+		"path": "file.bin",
+	}, http.StatusCreated)
+
+	deadline := time.Now().Add(5 * time.Second)
+	for doJSON(t, http.MethodGet, api.URL+"/jobs/"+job.ID, nil, http.StatusOK).Bytes < chunkSize {
+		if time.Now().After(deadline) {
+			t.Fatalf("expected the first chunk to be downloaded")
+		}
+
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	doJSON(t, http.MethodPost, api.URL+"/jobs/"+job.ID+"/pause", nil, http.StatusOK)
+	waitStopped(t, m, job.ID)
+
+	mu.Lock()
+	resumed = true
+	mu.Unlock()
+
+	close(release)
+	doJSON(t, http.MethodPost, api.URL+"/jobs/"+job.ID+"/resume", nil, http.StatusOK)
+
+	done := waitState(t, api.URL, job.ID, stateDone)
+	if done.Bytes != int64(len(content)) {
+		t.Errorf("expected the progress to cover the file, got: %+v", done)
+	}
+
+	mu.Lock()
+	if len(refetched) > 0 {
+		t.Errorf("expected the resumed job to continue after the first chunk, refetched: %v", refetched)
+	}
+	mu.Unlock()
+
+	actual, err := os.ReadFile(filepath.Join(dir, "file.bin"))
+	if err != nil || !bytes.Equal(actual, content) {
+		t.Errorf("expected the file to be downloaded: %v", err)
+	}
+}
+
+// waitStopped waits for the run of the paused job to stop
+func waitStopped(t *testing.T, m *manager, id string) {
+	t.Helper()
+
+	j, _ := m.lookup(id)
+
+	m.mu.Lock()
+	stopped := j.stopped
+	m.mu.Unlock()
+
+	select {
+	case <-stopped:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("expected job %s to stop", id)
+	}
+}
@@ -0,0 +1,98 @@
+// Command httpiod is a download daemon controlled over a small REST API.
+//
+// Usage:
+//
+//	httpiod [flags]
+//
+// The API is:
+//
+//	POST   /jobs             submit a job, the body is {"url": "...", "path": "..."}
+//	GET    /jobs             list the jobs in the order they were submitted
+//	GET    /jobs/{id}        get the state and progress of a job
+//	POST   /jobs/{id}/pause  pause a queued or running job
+//	POST   /jobs/{id}/resume queue a paused job again
+//	DELETE /jobs/{id}        cancel a job
+//
+// The paths of the jobs are relative to the download directory. When
+// started with systemd socket activation the daemon serves on the passed
+// socket instead of the address.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"strconv"
+	"syscall"
+	"time"
+)
+
+// listenFDsStart is the first file descriptor passed by systemd
+const listenFDsStart = 3
+
+func main() {
+	addr := flag.String("addr", "localhost:8080", "address to serve the API on")
+	dir := flag.String("dir", ".", "directory the jobs download into")
+	jobs := flag.Int("jobs", 4, "number of jobs downloading at once")
+	flag.Parse()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if err := run(ctx, *addr, *dir, *jobs); err != nil {
+		fmt.Fprintf(os.Stderr, "httpiod: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(ctx context.Context, addr, dir string, jobs int) error {
+	ln, err := listen(addr)
+	if err != nil {
+		return err
+	}
+
+	m := newManager(dir, max(jobs, 1))
+	defer m.close()
+
+	srv := &http.Server{
+		Handler:           m.handler(),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	errs := make(chan error, 1)
+	go func() {
+		errs <- srv.Serve(ln)
+	}()
+
+	select {
+	case err := <-errs:
+		return err
+	case <-ctx.Done():
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := srv.Shutdown(shutdownCtx); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+
+	return nil
+}
+
+// listen uses the socket passed by systemd socket activation, or listens on
+// the address otherwise
+func listen(addr string) (net.Listener, error) {
+	if os.Getenv("LISTEN_PID") == strconv.Itoa(os.Getpid()) {
+		if n, _ := strconv.Atoi(os.Getenv("LISTEN_FDS")); n > 0 {
+			return net.FileListener(os.NewFile(listenFDsStart, "systemd"))
+		}
+	}
+
+	return net.Listen("tcp", addr)
+}
@@ -0,0 +1,204 @@
+package httpio
+
+import (
+	"bytes"
+	"compress/bzip2"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+	"sync"
+)
+
+// Compression is a compression format
+type Compression string
+
+const (
+	CompressionNone  Compression = ""
+	CompressionGzip  Compression = "gzip"
+	CompressionZstd  Compression = "zstd"
+	CompressionXz    Compression = "xz"
+	CompressionBzip2 Compression = "bzip2"
+)
+
+var magicNumbers = []struct {
+	compression Compression
+	magic       []byte
+}{
+	{CompressionGzip, []byte{0x1f, 0x8b}},
+	{CompressionZstd, []byte{0x28, 0xb5, 0x2f, 0xfd}},
+	{CompressionXz, []byte{0xfd, '7', 'z', 'X', 'Z', 0x00}},
+	{CompressionBzip2, []byte{'B', 'Z', 'h'}},
+}
+
+// encodings are the compression formats of the Content-Encodings
+var encodings = map[string]Compression{
+	"gzip":   CompressionGzip,
+	"x-gzip": CompressionGzip,
+	"zstd":   CompressionZstd,
+	"bzip2":  CompressionBzip2,
+	"xz":     CompressionXz,
+}
+
+// extensions are the compression formats of the file extensions
+var extensions = map[string]Compression{
+	".gz":   CompressionGzip,
+	".tgz":  CompressionGzip,
+	".zst":  CompressionZstd,
+	".tzst": CompressionZstd,
+	".bz2":  CompressionBzip2,
+	".tbz2": CompressionBzip2,
+	".xz":   CompressionXz,
+	".txz":  CompressionXz,
+}
+
+// magicLen is the length of the longest magic number
+const magicLen = 6
+
+// Decompressor wraps a compressed reader into a decompressing reader
+type Decompressor func(r io.Reader) (io.Reader, error)
+
+var (
+	decompressorsMu sync.RWMutex
+	decompressors   = map[Compression]Decompressor{
+		CompressionGzip: func(r io.Reader) (io.Reader, error) {
+			return gzip.NewReader(r)
+		},
+		CompressionBzip2: func(r io.Reader) (io.Reader, error) {
+			return bzip2.NewReader(r), nil
+		},
+	}
+)
+
+// UnsupportedCompressionError is returned when no decompressor is
+// registered for the detected compression format
+type UnsupportedCompressionError struct {
+	Compression Compression
+}
+
+func (e *UnsupportedCompressionError) Error() string {
+	return fmt.Sprintf("no decompressor registered for '%s'", e.Compression)
+}
+
+// RegisterDecompressor registers the decompressor for the given compression
+// format, replacing any previously registered one. Gzip and bzip2 are
+// registered by default, formats like zstd and xz can be registered using
+// their respective third party packages.
+func RegisterDecompressor(c Compression, d Decompressor) {
+	decompressorsMu.Lock()
+	defer decompressorsMu.Unlock()
+
+	decompressors[c] = d
+}
+
+// DetectCompression detects the compression format from the magic number
+// at the start of the given bytes
+func DetectCompression(head []byte) Compression {
+	for _, m := range magicNumbers {
+		if bytes.HasPrefix(head, m.magic) {
+			return m.compression
+		}
+	}
+
+	return CompressionNone
+}
+
+// DetectCompression detects the compression format of the file from its
+// magic number, regardless of what the server reports, without consuming it
+func (f *RemoteFile) DetectCompression() (Compression, error) {
+	head, err := f.Peek(magicLen)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return CompressionNone, err
+	}
+
+	return DetectCompression(head), nil
+}
+
+// Decompress returns a reader with the decompressed content of the file,
+// detecting the compression format by its magic number. When the file isn't
+// compressed the file itself is returned.
+func (f *RemoteFile) Decompress() (io.Reader, error) {
+	c, err := f.DetectCompression()
+	if err != nil {
+		return nil, err
+	}
+
+	return decompress(f, c)
+}
+
+func decompress(r io.Reader, c Compression) (io.Reader, error) {
+	if c == CompressionNone {
+		return r, nil
+	}
+
+	decompressorsMu.RLock()
+	d, ok := decompressors[c]
+	decompressorsMu.RUnlock()
+
+	if !ok {
+		return nil, &UnsupportedCompressionError{Compression: c}
+	}
+
+	return d(r)
+}
+
+// WithDecompress decompresses the file as it's read, detecting the
+// compression format from the Content-Encoding the server reports or else
+// from the extension of the filename or the url. The compressed bytes are
+// still downloaded in parallel chunks, so the size and the progress are of
+// the compressed file, while Read and DownloadFile return the decompressed
+// content. A file without a known compression is read as is, a decompressed
+// file can't be seeked or read with ReadAt and ReadRanges.
+func WithDecompress() Option {
+	return func(f *RemoteFile) error {
+		f.decompress = true
+
+		return nil
+	}
+}
+
+// compression returns the compression format of the file from its
+// Content-Encoding or its extension, an unknown Content-Encoding, like a
+// list of encodings, is returned as is so decompressing it fails
+func (f *RemoteFile) compression() Compression {
+	switch enc := strings.ToLower(strings.TrimSpace(f.meta.ContentEncoding)); enc {
+	case "", "identity":
+	default:
+		if c, ok := encodings[enc]; ok {
+			return c
+		}
+
+		return Compression(enc)
+	}
+
+	for _, name := range []string{f.meta.Filename, f.req.URL.Path} {
+		if c, ok := extensions[strings.ToLower(path.Ext(name))]; ok {
+			return c
+		}
+	}
+
+	return CompressionNone
+}
+
+// decompressReader decompresses its source on the first Read, so the
+// decompressor doesn't start the download reading the header
+type decompressReader struct {
+	src         io.Reader
+	compression Compression
+	rd          io.Reader
+	err         error
+}
+
+func (d *decompressReader) Read(p []byte) (int, error) {
+	if d.rd == nil && d.err == nil {
+		d.rd, d.err = decompress(d.src, d.compression)
+	}
+
+	if d.err != nil {
+		return 0, d.err
+	}
+
+	return d.rd.Read(p)
+}
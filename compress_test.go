@@ -0,0 +1,215 @@
+package httpio_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/jobstoit/httpio"
+)
+
+func TestDetectCompression(t *testing.T) {
+	tests := []struct {
+		head     []byte
+		expected httpio.Compression
+	}{
+		{[]byte{0x1f, 0x8b, 0x08, 0x00}, httpio.CompressionGzip},
+		{[]byte{0x28, 0xb5, 0x2f, 0xfd, 0x00}, httpio.CompressionZstd},
+		{[]byte{0xfd, '7', 'z', 'X', 'Z', 0x00}, httpio.CompressionXz},
+		{[]byte("BZh91AY"), httpio.CompressionBzip2},
+		{[]byte("plain text"), httpio.CompressionNone},
+		{nil, httpio.CompressionNone},
+	}
+
+	for _, test := range tests {
+		if a := httpio.DetectCompression(test.head); a != test.expected {
+			t.Errorf("expected '%s' for %x, got '%s'", test.expected, test.head, a)
+		}
+	}
+}
+
+func TestDecompress(t *testing.T) {
+	expected, _ := testdata.ReadFile("testdata/test_5mb")
+
+	compressed := &bytes.Buffer{}
+	gw := gzip.NewWriter(compressed)
+	gw.Write(expected)
+	gw.Close()
+
+	svr := newTestServerWithHandler(func(http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			http.ServeContent(w, r, "test_5mb.bin", time.Time{}, bytes.NewReader(compressed.Bytes()))
+		})
+	})
+	defer svr.Close()
+
+	remoteFile, err := httpio.Get(svr.URL().JoinPath("test_5mb.bin").String(), httpio.WithChunkSize(1024*1024))
+	if err != nil {
+		t.Fatalf("failed to setup request: %v", err)
+	}
+
+	rd, err := remoteFile.Decompress()
+	if err != nil {
+		t.Fatalf("unable to decompress: %v", err)
+	}
+
+	actual, err := io.ReadAll(rd)
+	if err != nil {
+		t.Fatalf("unable to read file: %v", err)
+	}
+
+	if !bytes.Equal(expected, actual) {
+		t.Errorf("mismatched decompressed content")
+	}
+}
+
+func TestDecompressUnsupported(t *testing.T) {
+	svr := newTestServerWithHandler(func(http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			content := []byte{0xfd, '7', 'z', 'X', 'Z', 0x00, 0x00, 0x04}
+			http.ServeContent(w, r, "file.xz", time.Time{}, bytes.NewReader(content))
+		})
+	})
+	defer svr.Close()
+
+	remoteFile, err := httpio.Get(svr.URL().JoinPath("file.xz").String())
+	if err != nil {
+		t.Fatalf("failed to setup request: %v", err)
+	}
+
+	_, err = remoteFile.Decompress()
+
+	var compressionErr *httpio.UnsupportedCompressionError
+	if !errors.As(err, &compressionErr) || compressionErr.Compression != httpio.CompressionXz {
+		t.Errorf("expected an unsupported xz compression error, got: %v", err)
+	}
+}
+
+// encodingWriter reports the Content-Encoding only once the headers are
+// written, as http.ServeContent omits the Content-Length of encoded content
+type encodingWriter struct {
+	http.ResponseWriter
+	encoding string
+}
+
+func (w *encodingWriter) WriteHeader(code int) {
+	if w.encoding != "" {
+		w.Header().Set("Content-Encoding", w.encoding)
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func TestWithDecompress(t *testing.T) {
+	expected, _ := testdata.ReadFile("testdata/test_5mb")
+
+	compressed := &bytes.Buffer{}
+	gw := gzip.NewWriter(compressed)
+	gw.Write(expected)
+	gw.Close()
+
+	tests := []struct {
+		name     string
+		path     string
+		encoding string
+	}{
+		{"content encoding", "test_5mb.bin", "gzip"},
+		{"extension", "test_5mb.bin.gz", ""},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			svr := newTestServerWithHandler(func(http.Handler) http.Handler {
+				return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					http.ServeContent(&encodingWriter{w, test.encoding}, r, "test_5mb.bin", time.Time{}, bytes.NewReader(compressed.Bytes()))
+				})
+			})
+			defer svr.Close()
+
+			remoteFile, err := httpio.Get(svr.URL().JoinPath(test.path).String(),
+				httpio.WithChunkSize(256*1024),
+				httpio.WithDecompress(),
+			)
+			if err != nil {
+				t.Fatalf("failed to setup request: %v", err)
+			}
+			defer remoteFile.Close()
+
+			if remoteFile.Size() != int64(compressed.Len()) {
+				t.Errorf("expected the compressed size %d but got %d", compressed.Len(), remoteFile.Size())
+			}
+
+			actual, err := io.ReadAll(remoteFile)
+			if err != nil {
+				t.Fatalf("unable to read file: %v", err)
+			}
+
+			if !bytes.Equal(expected, actual) {
+				t.Errorf("mismatched decompressed content")
+			}
+
+			if _, err := remoteFile.Seek(0, io.SeekStart); err == nil {
+				t.Error("expected seeking a decompressed file to fail")
+			}
+
+			if _, err := remoteFile.ReadAt(make([]byte, 10), 0); err == nil {
+				t.Error("expected reading a decompressed file at an offset to fail")
+			}
+
+			if _, err := remoteFile.ReadRanges([]httpio.ByteRange{{Offset: 0, Length: 10}}); err == nil {
+				t.Error("expected reading ranges of a decompressed file to fail")
+			}
+		})
+	}
+}
+
+func TestWithDecompressUncompressed(t *testing.T) {
+	expected := []byte("plain content")
+
+	svr := newTestServerWithHandler(func(http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			http.ServeContent(w, r, "file.txt", time.Time{}, bytes.NewReader(expected))
+		})
+	})
+	defer svr.Close()
+
+	remoteFile, err := httpio.Get(svr.URL().JoinPath("file.txt").String(), httpio.WithDecompress())
+	if err != nil {
+		t.Fatalf("failed to setup request: %v", err)
+	}
+	defer remoteFile.Close()
+
+	actual, err := io.ReadAll(remoteFile)
+	if err != nil {
+		t.Fatalf("unable to read file: %v", err)
+	}
+
+	if !bytes.Equal(expected, actual) {
+		t.Errorf("expected '%s' but got '%s'", expected, actual)
+	}
+}
+
+func TestWithDecompressUnsupported(t *testing.T) {
+	svr := newTestServerWithHandler(func(http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			http.ServeContent(&encodingWriter{w, "br"}, r, "file", time.Time{}, bytes.NewReader([]byte("compressed")))
+		})
+	})
+	defer svr.Close()
+
+	remoteFile, err := httpio.Get(svr.URL().JoinPath("file").String(), httpio.WithDecompress())
+	if err != nil {
+		t.Fatalf("failed to setup request: %v", err)
+	}
+	defer remoteFile.Close()
+
+	_, err = io.ReadAll(remoteFile)
+
+	var compressionErr *httpio.UnsupportedCompressionError
+	if !errors.As(err, &compressionErr) || compressionErr.Compression != "br" {
+		t.Errorf("expected an unsupported br compression error, got: %v", err)
+	}
+}
@@ -0,0 +1,70 @@
+package httpio
+
+import (
+	"context"
+	"net/http"
+	"slices"
+	"strings"
+)
+
+// GetIfChanged gets the file like GetContext when it changed since the
+// revision with the given ETag and Last-Modified, either of which may be
+// empty, and fails with ErrNotModified when it didn't. Only the request for
+// the metadata is conditional, with If-None-Match and If-Modified-Since, so
+// a refresh of an unchanged file costs a single request.
+func GetIfChanged(ctx context.Context, url, etag, lastModified string, opts ...Option) (*RemoteFile, error) {
+	opts = append(slices.Clip(opts), func(f *RemoteFile) error {
+		f.ifChanged = &revision{etag: etag, lastModified: lastModified}
+
+		return nil
+	})
+
+	return GetContext(ctx, url, opts...)
+}
+
+// revision is a revision of a file identified by its validators
+type revision struct {
+	etag         string
+	lastModified string
+}
+
+// setConditions makes the request conditional on the file having changed
+// since the revision
+func (r *revision) setConditions(header http.Header) {
+	if r == nil {
+		return
+	}
+
+	if r.etag != "" {
+		header.Set(headerIfNoneMatch, r.etag)
+	}
+
+	if r.lastModified != "" {
+		header.Set(headerIfModifiedSince, r.lastModified)
+	}
+}
+
+// unchanged reports whether the metadata is of the revision, comparing the
+// entity tags weakly and only comparing the modification times without an
+// entity tag, like a server evaluating the conditions would
+func (r *revision) unchanged(meta *Metadata) bool {
+	if r == nil {
+		return false
+	}
+
+	if r.etag != "" {
+		return meta.ETag != "" && weakETag(meta.ETag) == weakETag(r.etag)
+	}
+
+	if r.lastModified == "" || meta.LastModified == "" {
+		return false
+	}
+
+	since, modified := parseHTTPTime(r.lastModified), parseHTTPTime(meta.LastModified)
+
+	return !since.IsZero() && !modified.IsZero() && !modified.After(since)
+}
+
+func weakETag(etag string) string {
+	return strings.TrimPrefix(strings.TrimSpace(etag), "W/")
+}
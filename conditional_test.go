@@ -0,0 +1,85 @@
+package httpio_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/jobstoit/httpio"
+)
+
+func TestGetIfChanged(t *testing.T) {
+	content := []byte("conditional content")
+	modified := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	lastModified := modified.Format(http.TimeFormat)
+
+	conditional := func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("ETag", `"v1"`)
+		http.ServeContent(w, r, "file.txt", modified, bytes.NewReader(content))
+	}
+
+	// a server ignoring the conditions still reports the revision
+	unconditional := func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("ETag", `"v1"`)
+		w.Header().Set("Last-Modified", lastModified)
+		w.Header().Set("Content-Length", strconv.Itoa(len(content)))
+		if r.Method == http.MethodGet {
+			w.Write(content)
+		}
+	}
+
+	tests := []struct {
+		name         string
+		handler      http.HandlerFunc
+		etag         string
+		lastModified string
+		notModified  bool
+	}{
+		{"same etag", conditional, `"v1"`, "", true},
+		{"weak etag", conditional, `W/"v1"`, "", true},
+		{"other etag", conditional, `"v0"`, "", false},
+		{"same time", conditional, "", lastModified, true},
+		{"earlier time", conditional, "", modified.Add(-time.Hour).Format(http.TimeFormat), false},
+		{"etag over time", conditional, `"v0"`, lastModified, false},
+		{"no revision", conditional, "", "", false},
+		{"ignored same etag", unconditional, `"v1"`, "", true},
+		{"ignored other etag", unconditional, `"v0"`, "", false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			svr := newTestServerWithHandler(func(http.Handler) http.Handler {
+				return test.handler
+			})
+			defer svr.Close()
+
+			remoteFile, err := httpio.GetIfChanged(context.Background(), svr.URL().JoinPath("file.txt").String(), test.etag, test.lastModified)
+			if test.notModified {
+				if !errors.Is(err, httpio.ErrNotModified) {
+					t.Errorf("expected ErrNotModified but got %v", err)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unable to get file: %v", err)
+			}
+			defer remoteFile.Close()
+
+			actual, err := io.ReadAll(remoteFile)
+			if err != nil {
+				t.Fatalf("unable to read file: %v", err)
+			}
+
+			if !bytes.Equal(content, actual) {
+				t.Errorf("expected '%s' but got '%s'", content, actual)
+			}
+		})
+	}
+}
@@ -0,0 +1,69 @@
+package httpio
+
+import (
+	"cmp"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+const headerIfRange = "If-Range"
+
+// validators holds the entity tag and modification time of the file the
+// download started with, the responses for the chunks have to match them
+// so the chunks are all parts of the same revision of the file
+type validators struct {
+	mu           sync.Mutex
+	etag         string
+	lastModified string
+}
+
+// set records the validators reported by the metadata of the file
+func (v *validators) set(meta *Metadata) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	v.etag, v.lastModified = meta.ETag, meta.LastModified
+}
+
+// ifRange returns the value for the If-Range header of the chunk requests,
+// the entity tag when it's strong or otherwise the modification time, so a
+// server responds with the whole file instead of a range of another revision
+func (v *validators) ifRange() string {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	if v.etag != "" && !strings.HasPrefix(v.etag, "W/") {
+		return v.etag
+	}
+
+	return v.lastModified
+}
+
+// check returns ErrContentChanged when the validators of a successful
+// response differ from the ones the download started with, validators
+// that weren't known yet are taken from the response
+func (v *validators) check(res *http.Response) error {
+	if res.StatusCode != http.StatusOK && res.StatusCode != http.StatusPartialContent {
+		return nil
+	}
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	etag, lastModified := res.Header.Get(headerETag), res.Header.Get(headerLastModified)
+
+	if etag != "" && v.etag != "" && strings.TrimPrefix(etag, "W/") != strings.TrimPrefix(v.etag, "W/") {
+		return fmt.Errorf("%w: entity tag %s, expected %s", ErrContentChanged, etag, v.etag)
+	}
+
+	if lastModified != "" && v.lastModified != "" && lastModified != v.lastModified {
+		return fmt.Errorf("%w: last modified %s, expected %s", ErrContentChanged, lastModified, v.lastModified)
+	}
+
+	v.etag = cmp.Or(v.etag, etag)
+	v.lastModified = cmp.Or(v.lastModified, lastModified)
+
+	return nil
+}
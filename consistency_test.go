@@ -0,0 +1,106 @@
+package httpio_test
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/jobstoit/httpio"
+)
+
+// newChangingServer returns a server serving a file that changes to a new
+// revision after the given number of requests, the revisions have the
+// given entity tags or modification times
+func newChangingServer(after int32, etags []string, modtimes []time.Time, ignoreIfRange bool) (*httptest.Server, *atomic.Int32) {
+	var requests, ifRanges atomic.Int32
+	revisions := [][]byte{
+		bytes.Repeat([]byte("a"), 10*1024),
+		bytes.Repeat([]byte("b"), 10*1024),
+	}
+
+	svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		revision := 0
+		if requests.Add(1) > after {
+			revision = 1
+		}
+
+		if r.Header.Get("If-Range") != "" {
+			ifRanges.Add(1)
+		}
+
+		if ignoreIfRange {
+			r.Header.Del("If-Range")
+		}
+
+		modtime := time.Time{}
+		if len(etags) > 0 {
+			w.Header().Set("ETag", etags[revision])
+		}
+		if len(modtimes) > 0 {
+			modtime = modtimes[revision]
+		}
+
+		http.ServeContent(w, r, "", modtime, bytes.NewReader(revisions[revision]))
+	}))
+
+	return svr, &ifRanges
+}
+
+func TestGetContentChanged(t *testing.T) {
+	tests := []struct {
+		name          string
+		etags         []string
+		modtimes      []time.Time
+		ignoreIfRange bool
+	}{
+		{"entity tag", []string{`"a"`, `"b"`}, nil, false},
+		{"last modified", nil, []time.Time{time.Unix(1_445_412_480, 0), time.Unix(1_445_498_880, 0)}, false},
+		{"if-range ignored", []string{`"a"`, `"b"`}, nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svr, ifRanges := newChangingServer(3, tt.etags, tt.modtimes, tt.ignoreIfRange)
+			defer svr.Close()
+
+			remoteFile, err := httpio.Get(svr.URL, httpio.WithChunkSize(1024), httpio.WithConcurrency(1))
+			if err != nil {
+				t.Fatalf("failed to setup request: %v", err)
+			}
+			defer remoteFile.Close()
+
+			if _, err := io.Copy(io.Discard, remoteFile); !errors.Is(err, httpio.ErrContentChanged) {
+				t.Errorf("expected ErrContentChanged, got: %v", err)
+			}
+
+			if ifRanges.Load() == 0 {
+				t.Errorf("expected the chunk requests to have an If-Range header")
+			}
+		})
+	}
+}
+
+func TestGetContentUnchanged(t *testing.T) {
+	svr, _ := newChangingServer(1000, []string{`W/"a"`}, nil, false)
+	defer svr.Close()
+
+	remoteFile, err := httpio.Get(svr.URL, httpio.WithChunkSize(1024))
+	if err != nil {
+		t.Fatalf("failed to setup request: %v", err)
+	}
+	defer remoteFile.Close()
+
+	body, err := io.ReadAll(remoteFile)
+	if err != nil {
+		t.Fatalf("unable to read file: %v", err)
+	}
+
+	if !bytes.Equal(body, bytes.Repeat([]byte("a"), 10*1024)) {
+		t.Errorf("expected the first revision of the file")
+	}
+}
@@ -0,0 +1,74 @@
+package httpio
+
+import (
+	"strconv"
+	"strings"
+)
+
+// parseContentRange parses a "bytes first-last/size" Content-Range value,
+// size is -1 when it's unknown
+func parseContentRange(value string) (first, last, size int64, ok bool) {
+	rng, size, ok := splitContentRange(value)
+	if !ok || rng == "*" {
+		return 0, 0, 0, false
+	}
+
+	firstStr, lastStr, ok := strings.Cut(rng, "-")
+	if !ok {
+		return 0, 0, 0, false
+	}
+
+	var err error
+	if first, err = strconv.ParseInt(firstStr, 10, 64); err != nil || first < 0 {
+		return 0, 0, 0, false
+	}
+
+	if last, err = strconv.ParseInt(lastStr, 10, 64); err != nil || last < first {
+		return 0, 0, 0, false
+	}
+
+	if size >= 0 && size <= last {
+		return 0, 0, 0, false
+	}
+
+	return first, last, size, true
+}
+
+// parseContentRangeSize returns the size of the file from either a
+// "bytes first-last/size" Content-Range of a 206 Partial Content or a
+// "bytes */size" one of a 416 Range Not Satisfiable, size is -1 when it's
+// unknown
+func parseContentRangeSize(value string) (int64, bool) {
+	if rng, size, ok := splitContentRange(value); ok && rng == "*" {
+		return size, size >= 0
+	}
+
+	_, _, size, ok := parseContentRange(value)
+
+	return size, ok
+}
+
+// splitContentRange splits a Content-Range value in bytes into its range
+// and its size, size is -1 when it's "*"
+func splitContentRange(value string) (string, int64, bool) {
+	unit, spec, ok := strings.Cut(strings.TrimSpace(value), " ")
+	if !ok || !strings.EqualFold(unit, rangeUnitBytes) {
+		return "", 0, false
+	}
+
+	rng, sizeStr, ok := strings.Cut(strings.TrimSpace(spec), "/")
+	if !ok {
+		return "", 0, false
+	}
+
+	if sizeStr == "*" {
+		return rng, -1, true
+	}
+
+	size, err := strconv.ParseInt(sizeStr, 10, 64)
+	if err != nil || size < 0 {
+		return "", 0, false
+	}
+
+	return rng, size, true
+}
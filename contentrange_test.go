@@ -0,0 +1,63 @@
+package httpio
+
+import "testing"
+
+func TestParseContentRange(t *testing.T) {
+	tests := []struct {
+		name        string
+		value       string
+		first, last int64
+		size        int64
+		ok          bool
+	}{
+		{"single byte", "bytes 0-0/1234", 0, 0, 1234, true},
+		{"chunk", "bytes 5242880-10485759/33554432", 5242880, 10485759, 33554432, true},
+		{"last byte", "bytes 1233-1233/1234", 1233, 1233, 1234, true},
+		{"unknown size", "bytes 0-99/*", 0, 99, -1, true},
+		{"large file", "bytes 0-0/5368709120", 0, 0, 5368709120, true},
+		{"unit case", "Bytes 0-0/10", 0, 0, 10, true},
+		{"surrounding space", " bytes 0-9/10 ", 0, 9, 10, true},
+		{"unsatisfied", "bytes */1234", 0, 0, 0, false},
+		{"other unit", "items 0-9/10", 0, 0, 0, false},
+		{"missing size", "bytes 0-9", 0, 0, 0, false},
+		{"last before first", "bytes 9-0/10", 0, 0, 0, false},
+		{"past the size", "bytes 0-10/10", 0, 0, 0, false},
+		{"negative first", "bytes -1-9/10", 0, 0, 0, false},
+		{"empty", "", 0, 0, 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			first, last, size, ok := parseContentRange(tt.value)
+			if ok != tt.ok || first != tt.first || last != tt.last || size != tt.size {
+				t.Errorf("expected %d-%d/%d %t, got %d-%d/%d %t", tt.first, tt.last, tt.size, tt.ok, first, last, size, ok)
+			}
+		})
+	}
+}
+
+func TestParseContentRangeSize(t *testing.T) {
+	tests := []struct {
+		name  string
+		value string
+		size  int64
+		ok    bool
+	}{
+		{"partial content", "bytes 0-0/1234", 1234, true},
+		{"unsatisfied", "bytes */1234", 1234, true},
+		{"empty file", "bytes */0", 0, true},
+		{"unknown size", "bytes 0-0/*", -1, true},
+		{"unsatisfied unknown size", "bytes */*", 0, false},
+		{"invalid size", "bytes 0-0/abc", 0, false},
+		{"request header", "bytes=0-0", 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			size, ok := parseContentRangeSize(tt.value)
+			if ok != tt.ok || (ok && size != tt.size) {
+				t.Errorf("expected %d %t, got %d %t", tt.size, tt.ok, size, ok)
+			}
+		})
+	}
+}
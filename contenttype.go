@@ -0,0 +1,58 @@
+package httpio
+
+import (
+	"bytes"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+)
+
+// genericContentType is the content type of unknown binary content
+const genericContentType = "application/octet-stream"
+
+// checkContentType checks the content type against the allowed types,
+// which are media types like "image/png" or wildcards like "image/*"
+func checkContentType(contentType string, allowed []string) error {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return &ContentTypeError{ContentType: contentType}
+	}
+
+	for _, a := range allowed {
+		a = strings.ToLower(strings.TrimSpace(a))
+		if a == mediaType || a == "*/*" {
+			return nil
+		}
+
+		if prefix, ok := strings.CutSuffix(a, "/*"); ok && strings.HasPrefix(mediaType, prefix+"/") {
+			return nil
+		}
+	}
+
+	return &ContentTypeError{ContentType: mediaType}
+}
+
+// needsSniffing reports whether the preflight's content type doesn't tell
+// what the content is, so the first chunk has to be sniffed
+func needsSniffing(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+
+	return err != nil || mediaType == genericContentType
+}
+
+// sniffContentType checks the content type detected from the start of the
+// first chunk, returning a reader that still includes the sniffed bytes
+func sniffContentType(body io.Reader, length int64, allowed []string) (io.Reader, error) {
+	buf := make([]byte, min(length, sniffLen))
+	n, err := io.ReadFull(body, buf)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := checkContentType(http.DetectContentType(buf[:n]), allowed); err != nil {
+		return nil, err
+	}
+
+	return io.MultiReader(bytes.NewReader(buf[:n]), body), nil
+}
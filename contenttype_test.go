@@ -0,0 +1,68 @@
+package httpio_test
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/jobstoit/httpio"
+)
+
+func TestGetAllowedContentTypes(t *testing.T) {
+	svr := newTestServer()
+	defer svr.Close()
+
+	u := svr.URL().JoinPath("assets", "GitHub_logo.png").String()
+
+	remoteFile, err := httpio.Get(u, httpio.WithAllowedContentTypes("text/plain", "image/*"))
+	if err != nil {
+		t.Fatalf("expected image/png to be allowed: %v", err)
+	}
+
+	if _, err := io.Copy(io.Discard, remoteFile); err != nil {
+		t.Errorf("unable to read file: %v", err)
+	}
+
+	var typeErr *httpio.ContentTypeError
+	if _, err := httpio.Get(u, httpio.WithAllowedContentTypes("application/zip")); !errors.As(err, &typeErr) {
+		t.Fatalf("expected a ContentTypeError, got: %v", err)
+	}
+
+	if typeErr.ContentType != "image/png" {
+		t.Errorf("expected content type image/png, got: %s", typeErr.ContentType)
+	}
+}
+
+func TestGetAllowedContentTypesSniffed(t *testing.T) {
+	svr := newTestServerWithHandler(func(h http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/octet-stream")
+			h.ServeHTTP(w, r)
+		})
+	})
+	defer svr.Close()
+
+	u := svr.URL().JoinPath("assets", "GitHub_logo.png").String()
+
+	remoteFile, err := httpio.Get(u, httpio.WithAllowedContentTypes("image/png"), httpio.WithChunkSize(1024))
+	if err != nil {
+		t.Fatalf("unable to get file: %v", err)
+	}
+
+	if _, err := io.Copy(io.Discard, remoteFile); err != nil {
+		t.Errorf("expected the sniffed image/png to be allowed: %v", err)
+	}
+
+	remoteFile, err = httpio.Get(u, httpio.WithAllowedContentTypes("text/*"), httpio.WithChunkSize(1024))
+	if err != nil {
+		t.Fatalf("expected the preflight to pass: %v", err)
+	}
+
+	var typeErr *httpio.ContentTypeError
+	if n, err := io.Copy(io.Discard, remoteFile); !errors.As(err, &typeErr) {
+		t.Errorf("expected a ContentTypeError, got: %v", err)
+	} else if n != 0 {
+		t.Errorf("expected nothing to be read, got %d bytes", n)
+	}
+}
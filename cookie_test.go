@@ -0,0 +1,82 @@
+package httpio_test
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/cookiejar"
+	"testing"
+
+	"github.com/jobstoit/httpio"
+)
+
+func newSessionTestServer() *testServer {
+	return newTestServerWithHandler(func(h http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method == http.MethodHead {
+				http.SetCookie(w, &http.Cookie{Name: "session", Value: "abc", Path: "/"})
+				h.ServeHTTP(w, r)
+
+				return
+			}
+
+			if cookie, err := r.Cookie("session"); err != nil || cookie.Value != "abc" {
+				w.WriteHeader(http.StatusForbidden)
+				return
+			}
+
+			h.ServeHTTP(w, r)
+		})
+	})
+}
+
+func TestGetCookieJar(t *testing.T) {
+	svr := newSessionTestServer()
+	defer svr.Close()
+
+	u := svr.URL().JoinPath("assets", "test_5mb").String()
+	expected, _ := testdata.ReadFile("testdata/test_5mb")
+
+	jar, _ := cookiejar.New(nil)
+	client := &http.Client{}
+
+	remoteFile, err := httpio.Get(u, httpio.WithChunkSize(1024*1024), httpio.WithClient(client), httpio.WithCookieJar(jar))
+	if err != nil {
+		t.Fatalf("unable to get file: %v", err)
+	}
+	defer remoteFile.Close()
+
+	actual, err := io.ReadAll(remoteFile)
+	if err != nil {
+		t.Fatalf("unable to read file: %v", err)
+	}
+
+	if !bytes.Equal(expected, actual) {
+		t.Error("mismatched content")
+	}
+
+	if len(jar.Cookies(svr.URL())) != 1 {
+		t.Error("expected the session cookie in the jar")
+	}
+
+	if client.Jar != nil {
+		t.Error("expected the jar of the given client to be left unchanged")
+	}
+}
+
+func TestGetWithoutCookieJar(t *testing.T) {
+	svr := newSessionTestServer()
+	defer svr.Close()
+
+	u := svr.URL().JoinPath("assets", "test_5mb").String()
+
+	remoteFile, err := httpio.Get(u, httpio.WithChunkSize(1024*1024))
+	if err != nil {
+		t.Fatalf("unable to get file: %v", err)
+	}
+	defer remoteFile.Close()
+
+	if _, err := io.Copy(io.Discard, remoteFile); err == nil {
+		t.Error("expected the chunk requests without the session cookie to be forbidden")
+	}
+}
@@ -0,0 +1,327 @@
+// Package delta updates a local copy of a remote file like zsync, hashing
+// the blocks of the local file against a manifest of block checksums
+// published with the remote file and requesting only the blocks that
+// changed.
+package delta
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/jobstoit/httpio"
+)
+
+// maxBatchBytes is the number of bytes of changed blocks requested at once
+const maxBatchBytes = 16 * 1024 * 1024
+
+// Result reports how a file was updated, Reused is the number of bytes
+// copied from the local file and Downloaded the number of bytes requested
+type Result struct {
+	Reused     int64
+	Downloaded int64
+}
+
+// Download updates the file at the path to the file at the url described
+// by the manifest. The blocks found at any offset of the local file are
+// copied from it, the others are requested with httpio.ReadRanges, adjacent
+// blocks in a single range. The update is written next to the path with
+// httpio.PartSuffix and replaces the local file once it's verified against
+// the checksum of the manifest. A path that doesn't exist is downloaded
+// completely.
+func Download(ctx context.Context, url, path string, m *Manifest, opts ...httpio.Option) (*Result, error) {
+	if err := m.validate(); err != nil {
+		return nil, err
+	}
+
+	located, err := locate(path, m)
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := httpio.OpenContext(ctx, url, opts...)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	if file.Size() != m.Size {
+		return nil, fmt.Errorf("size %d of the file doesn't match the manifest size %d", file.Size(), m.Size)
+	}
+
+	part := path + httpio.PartSuffix
+	out, err := os.Create(part)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := update(file, path, out, m, located)
+	if cerr := out.Close(); err == nil {
+		err = cerr
+	}
+
+	if err == nil {
+		err = os.Rename(part, path)
+	}
+
+	if err != nil {
+		os.Remove(part)
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// update writes the located blocks of the local file and the other blocks
+// of the remote file to out and verifies it
+func update(file *httpio.RemoteFile, path string, out *os.File, m *Manifest, located []int64) (*Result, error) {
+	if err := out.Truncate(m.Size); err != nil {
+		return nil, err
+	}
+
+	res := &Result{}
+	if err := copyLocated(path, out, m, located, res); err != nil {
+		return nil, err
+	}
+
+	if err := fetchMissing(file, out, m, located, res); err != nil {
+		return nil, err
+	}
+
+	if m.SHA256 == "" {
+		return res, nil
+	}
+
+	if _, err := out.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+
+	sum := sha256.New()
+	if _, err := io.Copy(sum, out); err != nil {
+		return nil, err
+	}
+
+	if actual := hex.EncodeToString(sum.Sum(nil)); actual != m.SHA256 {
+		return nil, &httpio.ChecksumError{Name: path, Expected: m.SHA256, Actual: actual}
+	}
+
+	return res, nil
+}
+
+// copyLocated copies the blocks found in the local file to their offsets
+func copyLocated(path string, out *os.File, m *Manifest, located []int64, res *Result) error {
+	local, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+
+	if err != nil {
+		return err
+	}
+	defer local.Close()
+
+	buf := make([]byte, m.BlockSize)
+	for i, off := range located {
+		if off < 0 {
+			continue
+		}
+
+		block := buf[:m.length(i)]
+		if _, err := local.ReadAt(block, off); err != nil {
+			return err
+		}
+
+		if _, err := out.WriteAt(block, int64(i)*int64(m.BlockSize)); err != nil {
+			return err
+		}
+		res.Reused += int64(len(block))
+	}
+
+	return nil
+}
+
+// fetchMissing requests the blocks that weren't found, adjacent blocks in a
+// single range, in batches of limited size. A range is split once it spans
+// the size of a batch, so no request is larger than a batch.
+func fetchMissing(file *httpio.RemoteFile, out *os.File, m *Manifest, located []int64, res *Result) error {
+	var runs []httpio.ByteRange
+	for i, off := range located {
+		if off >= 0 {
+			continue
+		}
+
+		start, length := int64(i)*int64(m.BlockSize), m.length(i)
+		for length > 0 {
+			n := len(runs)
+			if n == 0 || runs[n-1].Offset+runs[n-1].Length != start || runs[n-1].Length == maxBatchBytes {
+				runs = append(runs, httpio.ByteRange{Offset: start})
+				n++
+			}
+
+			added := min(length, maxBatchBytes-runs[n-1].Length)
+			runs[n-1].Length += added
+			start += added
+			length -= added
+		}
+	}
+
+	for len(runs) > 0 {
+		n, size := 0, int64(0)
+		for n < len(runs) && (n == 0 || size+runs[n].Length <= maxBatchBytes) {
+			size += runs[n].Length
+			n++
+		}
+
+		batch := runs[:n]
+		runs = runs[n:]
+
+		bufs, err := file.ReadRanges(batch)
+		if err != nil {
+			return err
+		}
+
+		for i, r := range batch {
+			if _, err := out.WriteAt(bufs[i], r.Offset); err != nil {
+				return err
+			}
+			res.Downloaded += int64(len(bufs[i]))
+		}
+	}
+
+	return nil
+}
+
+// locate returns the offsets in the local file at which the blocks of the
+// manifest are found, or -1 for the blocks that aren't. The full blocks
+// are searched for at every offset with the rolling checksum, the last
+// block when it's shorter only at the end of the local file.
+func locate(path string, m *Manifest) ([]int64, error) {
+	located := make([]int64, len(m.Blocks))
+	for i := range located {
+		located[i] = -1
+	}
+
+	local, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return located, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+	defer local.Close()
+
+	info, err := local.Stat()
+	if err != nil {
+		return nil, err
+	}
+
+	blocks := map[uint32][]int{}
+	for i, b := range m.Blocks {
+		if m.length(i) == int64(m.BlockSize) {
+			blocks[b.Weak] = append(blocks[b.Weak], i)
+		}
+	}
+
+	if err := scan(bufio.NewReader(local), m, blocks, located); err != nil {
+		return nil, err
+	}
+
+	if last := len(m.Blocks) - 1; last >= 0 && located[last] < 0 && m.length(last) < int64(m.BlockSize) {
+		tail := make([]byte, m.length(last))
+		if off := info.Size() - int64(len(tail)); off >= 0 {
+			if _, err := local.ReadAt(tail, off); err != nil {
+				return nil, err
+			}
+
+			if strongSum(tail) == m.Blocks[last].Strong {
+				located[last] = off
+			}
+		}
+	}
+
+	return located, nil
+}
+
+// scan rolls a window of the block size over the local file, recording the
+// offsets of the blocks it matches and moving past a matched block
+func scan(r *bufio.Reader, m *Manifest, blocks map[uint32][]int, located []int64) error {
+	window := make([]byte, m.BlockSize)
+	linear := make([]byte, m.BlockSize)
+
+	var (
+		sum   rollingSum
+		pos   int64
+		start int
+	)
+
+	fill := func() (bool, error) {
+		if _, err := io.ReadFull(r, window); err != nil {
+			if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+				return false, nil
+			}
+
+			return false, err
+		}
+		sum.init(window)
+		start = 0
+
+		return true, nil
+	}
+
+	ok, err := fill()
+	for ok && err == nil {
+		if candidates, found := blocks[sum.value()]; found {
+			copy(linear, window[start:])
+			copy(linear[m.BlockSize-start:], window[:start])
+
+			if match(linear, m, candidates, located, pos) {
+				pos += int64(m.BlockSize)
+				ok, err = fill()
+
+				continue
+			}
+		}
+
+		c, rerr := r.ReadByte()
+		if rerr == io.EOF {
+			return nil
+		}
+
+		if rerr != nil {
+			return rerr
+		}
+
+		sum.roll(window[start], c)
+		window[start] = c
+		start = (start + 1) % m.BlockSize
+		pos++
+	}
+
+	return err
+}
+
+// match records the offset for the candidate blocks with the strong
+// checksum of the window, reporting whether any of them has it
+func match(window []byte, m *Manifest, candidates []int, located []int64, pos int64) bool {
+	strong := strongSum(window)
+
+	matched := false
+	for _, i := range candidates {
+		if m.Blocks[i].Strong != strong {
+			continue
+		}
+
+		if located[i] < 0 {
+			located[i] = pos
+		}
+		matched = true
+	}
+
+	return matched
+}
@@ -0,0 +1,205 @@
+package delta_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/jobstoit/httpio"
+	"github.com/jobstoit/httpio/delta"
+)
+
+type countingWriter struct {
+	http.ResponseWriter
+	n *atomic.Int64
+}
+
+func (w *countingWriter) Write(p []byte) (int, error) {
+	n, err := w.ResponseWriter.Write(p)
+	w.n.Add(int64(n))
+
+	return n, err
+}
+
+func serve(content []byte, served *atomic.Int64) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.ServeContent(&countingWriter{ResponseWriter: w, n: served}, r, "file.bin", time.Time{}, bytes.NewReader(content))
+	}))
+}
+
+func randomBytes(rnd *rand.Rand, n int) []byte {
+	p := make([]byte, n)
+	rnd.Read(p)
+
+	return p
+}
+
+func TestDownload(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	old := randomBytes(rnd, 1024*1024+123)
+
+	// the new revision has bytes inserted, changed and appended
+	updated := slices.Concat(old[:100_000], randomBytes(rnd, 777), old[100_000:])
+	copy(updated[500_000:], randomBytes(rnd, 1000))
+	updated = append(updated, randomBytes(rnd, 5000)...)
+
+	manifest, err := delta.NewManifest(bytes.NewReader(updated), 16*1024)
+	if err != nil {
+		t.Fatalf("unable to create manifest: %v", err)
+	}
+
+	var served atomic.Int64
+	svr := serve(updated, &served)
+	defer svr.Close()
+
+	path := filepath.Join(t.TempDir(), "file.bin")
+	if err := os.WriteFile(path, old, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	res, err := delta.Download(context.Background(), svr.URL, path, manifest)
+	if err != nil {
+		t.Fatalf("unable to update file: %v", err)
+	}
+
+	actual, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("unable to read file: %v", err)
+	}
+
+	if !bytes.Equal(actual, updated) {
+		t.Fatal("mismatched content of the updated file")
+	}
+
+	if res.Reused+res.Downloaded != int64(len(updated)) {
+		t.Errorf("expected %d bytes to be reused or downloaded, got %d and %d", len(updated), res.Reused, res.Downloaded)
+	}
+
+	// the blocks around the insertion and the change, and the appended tail
+	if res.Downloaded > 5*16*1024 {
+		t.Errorf("expected only the changed blocks to be downloaded, got %d bytes", res.Downloaded)
+	}
+
+	if n := served.Load(); n > 6*16*1024 {
+		t.Errorf("expected few bytes to be served, got %d", n)
+	}
+
+	if _, err := os.Stat(path + httpio.PartSuffix); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("expected the part file to be removed, got %v", err)
+	}
+}
+
+func TestDownloadWithoutLocalFile(t *testing.T) {
+	content := randomBytes(rand.New(rand.NewSource(2)), 100_000)
+
+	manifest, err := delta.NewManifest(bytes.NewReader(content), 0)
+	if err != nil {
+		t.Fatalf("unable to create manifest: %v", err)
+	}
+
+	svr := serve(content, new(atomic.Int64))
+	defer svr.Close()
+
+	path := filepath.Join(t.TempDir(), "file.bin")
+	res, err := delta.Download(context.Background(), svr.URL, path, manifest)
+	if err != nil {
+		t.Fatalf("unable to download file: %v", err)
+	}
+
+	if res.Reused != 0 || res.Downloaded != int64(len(content)) {
+		t.Errorf("expected the whole file to be downloaded, got %+v", res)
+	}
+
+	if actual, _ := os.ReadFile(path); !bytes.Equal(actual, content) {
+		t.Error("mismatched content")
+	}
+}
+
+func TestDownloadLargeWithoutLocalFile(t *testing.T) {
+	const maxBatch = 16 * 1024 * 1024
+	content := randomBytes(rand.New(rand.NewSource(4)), maxBatch+maxBatch/2)
+
+	manifest, err := delta.NewManifest(bytes.NewReader(content), 0)
+	if err != nil {
+		t.Fatalf("unable to create manifest: %v", err)
+	}
+
+	var largest atomic.Int64
+	svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var first, last int64
+		if _, err := fmt.Sscanf(r.Header.Get("Range"), "bytes=%d-%d", &first, &last); err == nil {
+			for n := last - first + 1; ; {
+				m := largest.Load()
+				if n <= m || largest.CompareAndSwap(m, n) {
+					break
+				}
+			}
+		}
+
+		http.ServeContent(w, r, "file.bin", time.Time{}, bytes.NewReader(content))
+	}))
+	defer svr.Close()
+
+	path := filepath.Join(t.TempDir(), "file.bin")
+	res, err := delta.Download(context.Background(), svr.URL, path, manifest)
+	if err != nil {
+		t.Fatalf("unable to download file: %v", err)
+	}
+
+	if res.Reused != 0 || res.Downloaded != int64(len(content)) {
+		t.Errorf("expected the whole file to be downloaded, got %+v", res)
+	}
+
+	if actual, _ := os.ReadFile(path); !bytes.Equal(actual, content) {
+		t.Error("mismatched content")
+	}
+
+	if n := largest.Load(); n == 0 || n > maxBatch {
+		t.Errorf("expected ranges of at most %d bytes, got %d", maxBatch, n)
+	}
+}
+
+func TestDownloadMismatch(t *testing.T) {
+	content := randomBytes(rand.New(rand.NewSource(3)), 50_000)
+
+	svr := serve(content, new(atomic.Int64))
+	defer svr.Close()
+
+	path := filepath.Join(t.TempDir(), "file.bin")
+	if err := os.WriteFile(path, content, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	// a manifest of another revision
+	other := slices.Clone(content)
+	other[0]++
+	manifest, err := delta.NewManifest(bytes.NewReader(other), 4096)
+	if err != nil {
+		t.Fatalf("unable to create manifest: %v", err)
+	}
+
+	var checksumErr *httpio.ChecksumError
+	if _, err := delta.Download(context.Background(), svr.URL, path, manifest); !errors.As(err, &checksumErr) {
+		t.Errorf("expected a checksum error but got %v", err)
+	}
+
+	// the local file is left as it was
+	if actual, _ := os.ReadFile(path); !bytes.Equal(actual, content) {
+		t.Error("expected the local file to be unchanged")
+	}
+
+	manifest.Size++
+	if _, err := delta.Download(context.Background(), svr.URL, path, manifest); err == nil {
+		t.Error("expected an error for an invalid manifest")
+	}
+}
@@ -0,0 +1,118 @@
+package delta
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+)
+
+// DefaultBlockSize is the block size of NewManifest when it's given none
+const DefaultBlockSize = 64 * 1024
+
+// Manifest lists the checksums of the blocks of a file, it's published
+// next to the file as JSON for the clients updating their copy of it
+type Manifest struct {
+	Size      int64   `json:"size"`
+	BlockSize int     `json:"block_size"`
+	SHA256    string  `json:"sha256"`
+	Blocks    []Block `json:"blocks"`
+}
+
+// Block is the checksums of a block, Weak is the rolling checksum used to
+// find the block at any offset of the local file and Strong is the hex
+// encoded SHA-256 confirming it. The last block may be shorter.
+type Block struct {
+	Weak   uint32 `json:"weak"`
+	Strong string `json:"strong"`
+}
+
+// NewManifest returns the manifest of the content of the reader with the
+// given block size, DefaultBlockSize when it's less than 1
+func NewManifest(r io.Reader, blockSize int) (*Manifest, error) {
+	if blockSize < 1 {
+		blockSize = DefaultBlockSize
+	}
+
+	m := &Manifest{BlockSize: blockSize}
+	whole := sha256.New()
+	buf := make([]byte, blockSize)
+
+	br := bufio.NewReader(r)
+	for {
+		n, err := io.ReadFull(br, buf)
+		if n > 0 {
+			whole.Write(buf[:n])
+			m.Blocks = append(m.Blocks, newBlock(buf[:n]))
+			m.Size += int64(n)
+		}
+
+		if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+			break
+		}
+
+		if err != nil {
+			return nil, err
+		}
+	}
+	m.SHA256 = hex.EncodeToString(whole.Sum(nil))
+
+	return m, nil
+}
+
+func newBlock(p []byte) Block {
+	var sum rollingSum
+	sum.init(p)
+
+	return Block{Weak: sum.value(), Strong: strongSum(p)}
+}
+
+func strongSum(p []byte) string {
+	sum := sha256.Sum256(p)
+
+	return hex.EncodeToString(sum[:])
+}
+
+// length returns the length of the block with the given index
+func (m *Manifest) length(i int) int64 {
+	return min(int64(m.BlockSize), m.Size-int64(i)*int64(m.BlockSize))
+}
+
+// validate checks that the blocks cover the size of the file
+func (m *Manifest) validate() error {
+	if m.BlockSize < 1 || m.Size < 0 {
+		return errors.New("invalid manifest")
+	}
+
+	if blocks := (m.Size + int64(m.BlockSize) - 1) / int64(m.BlockSize); int64(len(m.Blocks)) != blocks {
+		return errors.New("manifest blocks don't cover the file")
+	}
+
+	return nil
+}
+
+// rollingSum is the rolling checksum of rsync over a window of bytes, which
+// is moved by a byte without going over the window again
+type rollingSum struct {
+	a, b uint16
+	n    uint16
+}
+
+func (s *rollingSum) init(p []byte) {
+	s.a, s.b, s.n = 0, 0, uint16(len(p))
+	for i, c := range p {
+		s.a += uint16(c)
+		s.b += uint16(len(p)-i) * uint16(c)
+	}
+}
+
+// roll moves the window past the byte out and onto the byte in
+func (s *rollingSum) roll(out, in byte) {
+	s.a += uint16(in) - uint16(out)
+	s.b += s.a - s.n*uint16(out)
+}
+
+func (s *rollingSum) value() uint32 {
+	return uint32(s.a) | uint32(s.b)<<16
+}
@@ -0,0 +1,170 @@
+package httpio
+
+import (
+	"context"
+	"net"
+	"strings"
+	"time"
+)
+
+// IPFamily selects the IP family used for connecting to the server
+type IPFamily int
+
+const (
+	// IPAuto connects using the addresses in the order given by the resolver
+	// and falls back to the other family when connecting is slow
+	IPAuto IPFamily = iota
+	// IPv4Only only connects over IPv4
+	IPv4Only
+	// IPv6Only only connects over IPv6
+	IPv6Only
+	// IPv4Preferred connects over IPv4 first and falls back to IPv6
+	IPv4Preferred
+	// IPv6Preferred connects over IPv6 first and falls back to IPv4
+	IPv6Preferred
+)
+
+// fallbackDelay is the time to wait for the preferred family to connect
+// before starting a connection attempt with the other family, as in RFC 8305
+const fallbackDelay = time.Millisecond * 300
+
+type dialFunc func(ctx context.Context, network, addr string) (net.Conn, error)
+
+func (fam IPFamily) dialContext(dialer *net.Dialer) dialFunc {
+	switch fam {
+	case IPv4Only:
+		return func(ctx context.Context, network, addr string) (net.Conn, error) {
+			return dialer.DialContext(ctx, restrictNetwork(network, "4"), addr)
+		}
+	case IPv6Only:
+		return func(ctx context.Context, network, addr string) (net.Conn, error) {
+			return dialer.DialContext(ctx, restrictNetwork(network, "6"), addr)
+		}
+	case IPv4Preferred, IPv6Preferred:
+		return func(ctx context.Context, network, addr string) (net.Conn, error) {
+			return dialPreferred(ctx, dialer, network, addr, fam == IPv4Preferred)
+		}
+	default:
+		return dialer.DialContext
+	}
+}
+
+// restrictNetwork restricts a network like "tcp" to the given family
+func restrictNetwork(network, family string) string {
+	if strings.HasSuffix(network, "4") || strings.HasSuffix(network, "6") {
+		return network
+	}
+
+	return network + family
+}
+
+// dialPreferred dials the addresses of the preferred family first and
+// starts dialing the other family when the preferred family failed or
+// didn't connect within the fallback delay. The first connection wins.
+func dialPreferred(ctx context.Context, dialer *net.Dialer, network, addr string, preferV4 bool) (net.Conn, error) {
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return nil, err
+	}
+
+	ips, err := net.DefaultResolver.LookupIPAddr(ctx, host)
+	if err != nil {
+		return nil, err
+	}
+
+	var primary, fallback []net.IPAddr
+	for _, ip := range ips {
+		if (ip.IP.To4() != nil) == preferV4 {
+			primary = append(primary, ip)
+		} else {
+			fallback = append(fallback, ip)
+		}
+	}
+
+	// the other family can't be dialed from a bound local address
+	if local, ok := dialer.LocalAddr.(*net.TCPAddr); ok && local.IP != nil {
+		if (local.IP.To4() != nil) != preferV4 {
+			primary = fallback
+		}
+		fallback = nil
+	}
+
+	if len(primary) == 0 {
+		primary, fallback = fallback, nil
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	type result struct {
+		conn net.Conn
+		err  error
+	}
+
+	results := make(chan result)
+	dialAll := func(ips []net.IPAddr) {
+		var err error
+		for _, ip := range ips {
+			var conn net.Conn
+			conn, err = dialer.DialContext(ctx, network, net.JoinHostPort(ip.String(), port))
+			if err == nil {
+				results <- result{conn: conn}
+				return
+			}
+		}
+
+		results <- result{err: err}
+	}
+
+	var fallbackTimer <-chan time.Time
+	if len(fallback) > 0 {
+		t := time.NewTimer(fallbackDelay)
+		defer t.Stop()
+
+		fallbackTimer = t.C
+	}
+
+	startFallback := func() {
+		fallbackTimer = nil
+		fallback = nil
+	}
+
+	go dialAll(primary)
+	pending := 1
+
+	var firstErr error
+	for pending > 0 {
+		select {
+		case <-fallbackTimer:
+			go dialAll(fallback)
+			startFallback()
+			pending++
+		case res := <-results:
+			pending--
+
+			if res.err == nil {
+				go func(n int) {
+					for range n {
+						if res := <-results; res.conn != nil {
+							res.conn.Close()
+						}
+					}
+				}(pending)
+
+				return res.conn, nil
+			}
+
+			if firstErr == nil {
+				firstErr = res.err
+			}
+
+			if len(fallback) > 0 {
+				go dialAll(fallback)
+				startFallback()
+				pending++
+			}
+		}
+	}
+
+	return nil, firstErr
+}
@@ -0,0 +1,300 @@
+package httpio
+
+import (
+	"crypto/md5"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+const (
+	headerAuthorization   = "Authorization"
+	headerWWWAuthenticate = "WWW-Authenticate"
+)
+
+// digestAlgorithms are the supported digest algorithms from strongest to weakest
+var digestAlgorithms = []struct {
+	name string
+	hash func() hash.Hash
+}{
+	{"SHA-512-256", sha512.New512_256},
+	{"SHA-256", sha256.New},
+	{"MD5", md5.New},
+}
+
+// digestTransport authenticates requests using HTTP Digest authentication
+// as in RFC 7616. The challenge and its nonce count are shared between the
+// parallel chunk requests, so only the first request has to be challenged.
+type digestTransport struct {
+	base     http.RoundTripper
+	username string
+	password string
+
+	mu        sync.Mutex
+	challenge *digestChallenge
+	nc        uint32
+}
+
+type digestChallenge struct {
+	realm     string
+	nonce     string
+	opaque    string
+	algorithm string
+	qop       string
+	userhash  bool
+	stale     bool
+	hash      func() hash.Hash
+	session   bool
+}
+
+func (t *digestTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	challenge, nc := t.next()
+	if challenge == nil {
+		res, err := t.base.RoundTrip(req)
+		if err != nil || res.StatusCode != http.StatusUnauthorized {
+			return res, err
+		}
+
+		challenge = parseDigestChallenges(res.Header.Values(headerWWWAuthenticate))
+		if challenge == nil {
+			return res, nil
+		}
+		res.Body.Close()
+
+		nc = t.setChallenge(challenge)
+	}
+
+	res, err := t.roundTrip(req, challenge, nc)
+	if err != nil || res.StatusCode != http.StatusUnauthorized {
+		return res, err
+	}
+
+	// retry once when the nonce went stale
+	fresh := parseDigestChallenges(res.Header.Values(headerWWWAuthenticate))
+	if fresh == nil || !fresh.stale {
+		return res, nil
+	}
+	res.Body.Close()
+
+	return t.roundTrip(req, fresh, t.setChallenge(fresh))
+}
+
+func (t *digestTransport) roundTrip(req *http.Request, challenge *digestChallenge, nc uint32) (*http.Response, error) {
+	auth, err := t.authorization(req, challenge, nc)
+	if err != nil {
+		return nil, err
+	}
+
+	req = req.Clone(req.Context())
+	req.Header.Set(headerAuthorization, auth)
+
+	return t.base.RoundTrip(req)
+}
+
+// next returns the current challenge with the next nonce count
+func (t *digestTransport) next() (*digestChallenge, uint32) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.challenge == nil {
+		return nil, 0
+	}
+
+	t.nc++
+
+	return t.challenge, t.nc
+}
+
+// setChallenge replaces the current challenge, resetting the nonce count
+// when the nonce changed, and returns the next nonce count
+func (t *digestTransport) setChallenge(challenge *digestChallenge) uint32 {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.challenge == nil || t.challenge.nonce != challenge.nonce {
+		t.challenge = challenge
+		t.nc = 0
+	}
+
+	t.nc++
+
+	return t.nc
+}
+
+func (t *digestTransport) authorization(req *http.Request, c *digestChallenge, nc uint32) (string, error) {
+	h := func(s string) string {
+		hh := c.hash()
+		hh.Write([]byte(s))
+
+		return hex.EncodeToString(hh.Sum(nil))
+	}
+
+	cnonce, err := newCnonce()
+	if err != nil {
+		return "", err
+	}
+
+	uri := req.URL.RequestURI()
+	ncStr := fmt.Sprintf("%08x", nc)
+
+	ha1 := h(t.username + ":" + c.realm + ":" + t.password)
+	if c.session {
+		ha1 = h(ha1 + ":" + c.nonce + ":" + cnonce)
+	}
+
+	ha2 := h(req.Method + ":" + uri)
+	if c.qop == "auth-int" {
+		ha2 = h(req.Method + ":" + uri + ":" + h(""))
+	}
+
+	var response string
+	if c.qop == "" {
+		response = h(ha1 + ":" + c.nonce + ":" + ha2)
+	} else {
+		response = h(ha1 + ":" + c.nonce + ":" + ncStr + ":" + cnonce + ":" + c.qop + ":" + ha2)
+	}
+
+	username := t.username
+	if c.userhash {
+		username = h(t.username + ":" + c.realm)
+	}
+
+	params := []string{
+		fmt.Sprintf(`username="%s"`, username),
+		fmt.Sprintf(`realm="%s"`, c.realm),
+		fmt.Sprintf(`nonce="%s"`, c.nonce),
+		fmt.Sprintf(`uri="%s"`, uri),
+		fmt.Sprintf(`response="%s"`, response),
+		fmt.Sprintf(`algorithm=%s`, c.algorithm),
+	}
+
+	if c.qop != "" {
+		params = append(params, "qop="+c.qop, "nc="+ncStr, fmt.Sprintf(`cnonce="%s"`, cnonce))
+	}
+
+	if c.opaque != "" {
+		params = append(params, fmt.Sprintf(`opaque="%s"`, c.opaque))
+	}
+
+	if c.userhash {
+		params = append(params, "userhash=true")
+	}
+
+	return "Digest " + strings.Join(params, ", "), nil
+}
+
+func newCnonce() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(b), nil
+}
+
+// parseDigestChallenges returns the strongest supported digest challenge
+// of the given WWW-Authenticate headers, or nil if there is none
+func parseDigestChallenges(headers []string) *digestChallenge {
+	var best *digestChallenge
+	bestRank := len(digestAlgorithms)
+
+	for _, header := range headers {
+		scheme, rest, _ := strings.Cut(strings.TrimSpace(header), " ")
+		if !strings.EqualFold(scheme, "Digest") {
+			continue
+		}
+
+		params := parseAuthParams(rest)
+		c := &digestChallenge{
+			realm:     params["realm"],
+			nonce:     params["nonce"],
+			opaque:    params["opaque"],
+			algorithm: params["algorithm"],
+			userhash:  strings.EqualFold(params["userhash"], "true"),
+			stale:     strings.EqualFold(params["stale"], "true"),
+		}
+
+		if c.algorithm == "" {
+			c.algorithm = "MD5"
+		}
+
+		name := strings.ToUpper(c.algorithm)
+		if c.session = strings.HasSuffix(name, "-SESS"); c.session {
+			name = strings.TrimSuffix(name, "-SESS")
+		}
+
+		rank := -1
+		for i, alg := range digestAlgorithms {
+			if alg.name == name {
+				rank = i
+				c.hash = alg.hash
+			}
+		}
+
+		if rank < 0 || rank >= bestRank {
+			continue
+		}
+
+		if qop, ok := params["qop"]; ok {
+			for _, q := range strings.Split(qop, ",") {
+				q = strings.TrimSpace(q)
+				if q == "auth" || (q == "auth-int" && c.qop == "") {
+					c.qop = q
+				}
+			}
+
+			if c.qop == "" {
+				continue
+			}
+		}
+
+		best, bestRank = c, rank
+	}
+
+	return best
+}
+
+// parseAuthParams parses comma separated auth-params, with optionally
+// quoted values, into a map with lowercase keys
+func parseAuthParams(s string) map[string]string {
+	params := map[string]string{}
+
+	for len(s) > 0 {
+		s = strings.TrimLeft(s, " \t,")
+
+		key, rest, ok := strings.Cut(s, "=")
+		if !ok {
+			break
+		}
+		key = strings.ToLower(strings.TrimSpace(key))
+		rest = strings.TrimLeft(rest, " \t")
+
+		var value string
+		if strings.HasPrefix(rest, `"`) {
+			var b strings.Builder
+			i := 1
+			for ; i < len(rest) && rest[i] != '"'; i++ {
+				if rest[i] == '\\' && i+1 < len(rest) {
+					i++
+				}
+				b.WriteByte(rest[i])
+			}
+
+			value = b.String()
+			s = rest[min(i+1, len(rest)):]
+		} else {
+			value, s, _ = strings.Cut(rest, ",")
+			value = strings.TrimSpace(value)
+		}
+
+		params[key] = value
+	}
+
+	return params
+}
@@ -0,0 +1,83 @@
+package httpio_test
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jobstoit/httpio"
+)
+
+var digestParamRegexp = regexp.MustCompile(`(\w+)=(?:"([^"]*)"|([^,\s]*))`)
+
+func TestGetDigestAuth(t *testing.T) {
+	const (
+		username = "user"
+		password = "pass"
+		realm    = "test"
+		nonce    = "dcd98b7102dd2f0e8b11d0f600bfb0c093"
+	)
+
+	content := bytes.Repeat([]byte("digest"), 1024*256)
+	sha := func(s string) string {
+		sum := sha256.Sum256([]byte(s))
+		return hex.EncodeToString(sum[:])
+	}
+
+	var (
+		mu         sync.Mutex
+		seen       = map[string]bool{}
+		challenged int
+	)
+
+	svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		params := map[string]string{}
+		for _, m := range digestParamRegexp.FindAllStringSubmatch(r.Header.Get("Authorization"), -1) {
+			params[m[1]] = m[2] + m[3]
+		}
+
+		ha1 := sha(username + ":" + realm + ":" + password)
+		ha2 := sha(r.Method + ":" + r.URL.RequestURI())
+		expected := sha(ha1 + ":" + nonce + ":" + params["nc"] + ":" + params["cnonce"] + ":auth:" + ha2)
+
+		mu.Lock()
+		defer mu.Unlock()
+
+		if params["response"] != expected || params["algorithm"] != "SHA-256" || seen[params["nc"]] {
+			challenged++
+			w.Header().Add("WWW-Authenticate", `Digest realm="test", qop="auth", algorithm=MD5, nonce="`+nonce+`"`)
+			w.Header().Add("WWW-Authenticate", `Digest realm="test", qop="auth", algorithm=SHA-256, nonce="`+nonce+`"`)
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		seen[params["nc"]] = true
+		http.ServeContent(w, r, "file", time.Time{}, bytes.NewReader(content))
+	}))
+	defer svr.Close()
+
+	remoteFile, err := httpio.Get(svr.URL, httpio.WithDigestAuth(username, password), httpio.WithChunkSize(1024*128))
+	if err != nil {
+		t.Fatalf("failed to setup request: %v", err)
+	}
+
+	actual, err := io.ReadAll(remoteFile)
+	if err != nil {
+		t.Fatalf("unable to read file: %v", err)
+	}
+
+	if !bytes.Equal(content, actual) {
+		t.Errorf("mismatched content")
+	}
+
+	if challenged != 1 {
+		t.Errorf("expected only the first request to be challenged, got %d challenges", challenged)
+	}
+}
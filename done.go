@@ -0,0 +1,31 @@
+package httpio
+
+import "context"
+
+// Done returns a channel that's closed once the download finished or
+// failed. The download finishes once the whole file was read, files opened
+// with GetAll only start downloading on their first read. Seeking starts a
+// new download with a new channel.
+func (f *RemoteFile) Done() <-chan struct{} {
+	return f.currentRun().done
+}
+
+// Wait waits until the download finished or failed and returns the error
+// that stopped the download, or the context's error when it's done first
+func (f *RemoteFile) Wait(ctx context.Context) error {
+	r := f.currentRun()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-r.done:
+		return r.err
+	}
+}
+
+func (f *RemoteFile) currentRun() *run {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return f.run
+}
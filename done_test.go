@@ -0,0 +1,76 @@
+package httpio_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/jobstoit/httpio"
+)
+
+func TestWait(t *testing.T) {
+	svr := newTestServer()
+	defer svr.Close()
+
+	u := svr.URL().JoinPath("assets", "test_5mb").String()
+
+	remoteFile, err := httpio.Get(u, httpio.WithChunkSize(1024*1024))
+	if err != nil {
+		t.Fatalf("unable to get file: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*10)
+	defer cancel()
+
+	if err := remoteFile.Wait(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected the download to wait for the reader, got: %v", err)
+	}
+
+	go func() {
+		_, _ = io.Copy(io.Discard, remoteFile)
+	}()
+
+	if err := remoteFile.Wait(context.Background()); err != nil {
+		t.Errorf("expected the download to succeed: %v", err)
+	}
+
+	select {
+	case <-remoteFile.Done():
+	default:
+		t.Error("expected done to be closed")
+	}
+}
+
+func TestWaitFailed(t *testing.T) {
+	svr := newTestServerWithHandler(func(h http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Header.Get("Range") != "" {
+				http.Error(w, "broken", http.StatusInternalServerError)
+				return
+			}
+
+			h.ServeHTTP(w, r)
+		})
+	})
+	defer svr.Close()
+
+	u := svr.URL().JoinPath("assets", "test_5mb").String()
+
+	remoteFile, err := httpio.Get(u)
+	if err != nil {
+		t.Fatalf("unable to get file: %v", err)
+	}
+
+	<-remoteFile.Done()
+
+	if err := remoteFile.Wait(context.Background()); err == nil {
+		t.Error("expected the failed download's error")
+	}
+
+	if _, err := io.Copy(io.Discard, remoteFile); err == nil {
+		t.Error("expected reading to fail")
+	}
+}
@@ -0,0 +1,175 @@
+package httpio
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// DownloadFile downloads the file from the given url to the given path,
+// writing the chunks to their offsets in the file as they come in. The file
+// is written next to the path with PartSuffix and renamed to the path once
+// it's complete, so the path never holds a partial file. The part file, or
+// the manifest of a download split into parts, is locked while
+// downloading, so another process downloading to the same path fails with
+// ErrLocked instead of interleaving its writes. The state of
+// the download is kept next to the file until it's complete, a download
+// that's interrupted is resumed by the next DownloadFile to the same path
+// when the file hasn't changed on the server, see StateSuffix. The file is
+// matched by its ETag, Last-Modified and size only, so the download may
+// resume from another url of the same file.
+func DownloadFile(ctx context.Context, url, path string, opts ...Option) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	f, err := open(ctx, url, opts...)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	defer f.reportStats()
+
+	if f.partSize > 0 {
+		manifest, err := lockFile(path + ManifestSuffix)
+		if err != nil {
+			return err
+		}
+		defer manifest.Close()
+
+		f.begin()
+
+		return f.downloadParts(path, manifest)
+	}
+
+	if f.timestamping && f.current(path) {
+		f.logDebug("skipping current file", "path", path)
+
+		return nil
+	}
+
+	part := path + PartSuffix
+	out, err := lockFile(part)
+	if err != nil {
+		return err
+	}
+
+	sum, err := f.downloadFile(ctx, path, out)
+	if err == nil {
+		err = f.finishFile(out)
+	}
+	if cerr := out.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return err
+	}
+
+	if err := os.Rename(part, path); err != nil {
+		return err
+	}
+
+	if f.timestamping {
+		if err := f.stampModTime(path); err != nil {
+			return err
+		}
+	}
+
+	if f.fsync {
+		syncDir(filepath.Dir(path))
+	}
+
+	if !f.sourceAttrs {
+		return nil
+	}
+
+	return writeSource(path, &Source{
+		URL:          f.req.URL.String(),
+		ETag:         f.meta.ETag,
+		LastModified: f.meta.LastModified,
+		SHA256:       sum,
+	})
+}
+
+// downloadFile downloads the opened file to the locked part file,
+// resuming an earlier download to it when possible, and returns the SHA-256
+// checksum of the file when the source attributes are stored
+func (f *RemoteFile) downloadFile(ctx context.Context, path string, out *os.File) (string, error) {
+	if f.resumable() {
+		r, err := f.resume(path, out)
+		if err != nil {
+			return "", err
+		}
+		f.resumed = r
+	} else if err := out.Truncate(0); err != nil {
+		return "", err
+	}
+
+	if f.preallocate && f.size > 0 {
+		if err := preallocate(out, f.size); err != nil {
+			return "", err
+		}
+	}
+
+	if err := f.downloadTo(ctx, out); err != nil {
+		return "", err
+	}
+
+	if f.resumed != nil {
+		if err := f.resumed.remove(); err != nil {
+			return "", err
+		}
+	}
+
+	if !f.sourceAttrs {
+		return "", nil
+	}
+
+	// the chunks were written out of order, so the file is hashed afterwards
+	if _, err := out.Seek(0, io.SeekStart); err != nil {
+		return "", err
+	}
+
+	sum := sha256.New()
+	if _, err := io.Copy(sum, out); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(sum.Sum(nil)), nil
+}
+
+// DownloadTo downloads the file from the given url to w, writing the
+// chunks to their offsets as they come in instead of in order. Chunks are
+// only written in order when they're inspected with WithInspector or
+// verified against a checksum.
+func DownloadTo(ctx context.Context, url string, w io.WriterAt, opts ...Option) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	f, err := open(ctx, url, opts...)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	return f.downloadTo(ctx, w)
+}
+
+// downloadTo downloads the opened file to w and waits for it to finish, an
+// encrypted or decompressed file is written in order as it's read
+func (f *RemoteFile) downloadTo(ctx context.Context, w io.WriterAt) error {
+	if f.aead != nil || f.decompress {
+		f.begin()
+
+		_, err := io.Copy(io.NewOffsetWriter(w, 0), f)
+
+		return err
+	}
+
+	f.writerAt = w
+	f.begin()
+
+	return f.Wait(ctx)
+}
@@ -0,0 +1,331 @@
+package httpio_test
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/jobstoit/httpio"
+)
+
+func TestDownloadFile(t *testing.T) {
+	svr := newTestServer()
+	defer svr.Close()
+
+	path := filepath.Join(t.TempDir(), "test_12mb")
+	u := svr.URL().JoinPath("assets", "test_12mb")
+
+	var stats httpio.Stats
+	if err := httpio.DownloadFile(context.Background(), u.String(), path, httpio.WithStats(func(s httpio.Stats) { stats = s })); err != nil {
+		t.Fatalf("unable to download file: %v", err)
+	}
+
+	expected, _ := testdata.ReadFile("testdata/test_12mb")
+	actual, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("unable to read downloaded file: %v", err)
+	}
+
+	if !bytes.Equal(expected, actual) {
+		t.Errorf("mismatched downloaded content")
+	}
+
+	if stats.BytesDownloaded != int64(len(expected)) || stats.BytesUploaded != 0 {
+		t.Errorf("expected %d bytes downloaded, got: %+v", len(expected), stats)
+	}
+}
+
+// writerAt records the offsets of its writes, written is closed on the first write
+type writerAt struct {
+	mu      sync.Mutex
+	buf     []byte
+	offsets []int64
+	written chan struct{}
+}
+
+func (w *writerAt) WriteAt(p []byte, off int64) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if len(w.offsets) == 0 {
+		close(w.written)
+	}
+
+	if end := int(off) + len(p); end > len(w.buf) {
+		w.buf = append(w.buf, make([]byte, end-len(w.buf))...)
+	}
+	copy(w.buf[off:], p)
+	w.offsets = append(w.offsets, off)
+
+	return len(p), nil
+}
+
+func TestDownloadTo(t *testing.T) {
+	w := &writerAt{written: make(chan struct{})}
+
+	// the first chunk is held back until another chunk has been written
+	svr := newTestServerWithHandler(func(h http.Handler) http.Handler {
+		return http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+			if strings.HasPrefix(r.Header.Get("Range"), "bytes=0-") {
+				<-w.written
+			}
+
+			h.ServeHTTP(rw, r)
+		})
+	})
+	defer svr.Close()
+
+	u := svr.URL().JoinPath("assets", "test_12mb").String()
+
+	if err := httpio.DownloadTo(context.Background(), u, w, httpio.WithChunkSize(1024*1024)); err != nil {
+		t.Fatalf("unable to download file: %v", err)
+	}
+
+	expected, _ := testdata.ReadFile("testdata/test_12mb")
+	if !bytes.Equal(expected, w.buf) {
+		t.Errorf("mismatched downloaded content")
+	}
+
+	if w.offsets[0] == 0 {
+		t.Errorf("expected the chunks to be written out of order")
+	}
+}
+
+func TestDownloadFileLocked(t *testing.T) {
+	started := make(chan struct{})
+	release := make(chan struct{})
+	var once sync.Once
+
+	svr := newTestServerWithHandler(func(h http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Header.Get("Range") != "" {
+				once.Do(func() { close(started) })
+				<-release
+			}
+
+			h.ServeHTTP(w, r)
+		})
+	})
+	defer svr.Close()
+
+	path := filepath.Join(t.TempDir(), "test_5mb")
+	u := svr.URL().JoinPath("assets", "test_5mb").String()
+
+	errs := make(chan error, 1)
+	go func() {
+		errs <- httpio.DownloadFile(context.Background(), u, path)
+	}()
+	<-started
+
+	if err := httpio.DownloadFile(context.Background(), u, path); !errors.Is(err, httpio.ErrLocked) {
+		t.Errorf("expected ErrLocked while another download holds the destination, got: %v", err)
+	}
+	close(release)
+
+	if err := <-errs; err != nil {
+		t.Fatalf("unable to download file: %v", err)
+	}
+
+	expected, _ := testdata.ReadFile("testdata/test_5mb")
+	if actual, _ := os.ReadFile(path); !bytes.Equal(expected, actual) {
+		t.Errorf("mismatched downloaded content")
+	}
+
+	if err := httpio.DownloadFile(context.Background(), u, path); err != nil {
+		t.Errorf("expected the lock to be released: %v", err)
+	}
+}
+
+func TestDownloadFilePartFile(t *testing.T) {
+	started := make(chan struct{})
+	release := make(chan struct{})
+	var once sync.Once
+
+	svr := newTestServerWithHandler(func(h http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Header.Get("Range") != "" {
+				once.Do(func() { close(started) })
+				<-release
+			}
+
+			h.ServeHTTP(w, r)
+		})
+	})
+	defer svr.Close()
+
+	path := filepath.Join(t.TempDir(), "test_5mb")
+	u := svr.URL().JoinPath("assets", "test_5mb").String()
+
+	errs := make(chan error, 1)
+	go func() {
+		errs <- httpio.DownloadFile(context.Background(), u, path,
+			httpio.WithFileMode(0o600), httpio.WithFsync(), httpio.WithPreallocate())
+	}()
+	<-started
+
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("expected no file at the path while downloading, got: %v", err)
+	}
+
+	if _, err := os.Stat(path + httpio.PartSuffix); err != nil {
+		t.Errorf("expected the part file while downloading: %v", err)
+	}
+	close(release)
+
+	if err := <-errs; err != nil {
+		t.Fatalf("unable to download file: %v", err)
+	}
+
+	expected, _ := testdata.ReadFile("testdata/test_5mb")
+	if actual, _ := os.ReadFile(path); !bytes.Equal(expected, actual) {
+		t.Errorf("mismatched downloaded content")
+	}
+
+	if _, err := os.Stat(path + httpio.PartSuffix); !os.IsNotExist(err) {
+		t.Errorf("expected the part file to be renamed, got: %v", err)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("unable to stat downloaded file: %v", err)
+	}
+
+	if runtime.GOOS != "windows" && info.Mode().Perm() != 0o600 {
+		t.Errorf("expected mode 0600, got %v", info.Mode().Perm())
+	}
+}
+
+func TestDownloadFileParts(t *testing.T) {
+	svr := newTestServer()
+	defer svr.Close()
+
+	const partSize = 1024 * 1024 * 5
+
+	path := filepath.Join(t.TempDir(), "test_12mb")
+	u := svr.URL().JoinPath("assets", "test_12mb")
+
+	if err := httpio.DownloadFile(context.Background(), u.String(), path, httpio.WithPartSize(partSize)); err != nil {
+		t.Fatalf("unable to download file: %v", err)
+	}
+
+	data, err := os.ReadFile(path + httpio.ManifestSuffix)
+	if err != nil {
+		t.Fatalf("unable to read manifest: %v", err)
+	}
+
+	var manifest httpio.Manifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		t.Fatalf("unable to parse manifest: %v", err)
+	}
+
+	expected, _ := testdata.ReadFile("testdata/test_12mb")
+	if e, a := int64(len(expected)), manifest.Size; e != a {
+		t.Errorf("expected manifest size %d, got %d", e, a)
+	}
+
+	if e, a := fmt.Sprintf("%x", sha256.Sum256(expected)), manifest.SHA256; e != a {
+		t.Errorf("expected manifest checksum '%s', got '%s'", e, a)
+	}
+
+	if e, a := 3, len(manifest.Parts); e != a {
+		t.Fatalf("expected %d parts, got %d", e, a)
+	}
+
+	var joined []byte
+	for i, part := range manifest.Parts {
+		content, err := os.ReadFile(filepath.Join(filepath.Dir(path), part.Name))
+		if err != nil {
+			t.Fatalf("unable to read part %d: %v", i, err)
+		}
+
+		if i < len(manifest.Parts)-1 && len(content) != partSize {
+			t.Errorf("expected part %d to have size %d, got %d", i, partSize, len(content))
+		}
+
+		if e, a := fmt.Sprintf("%x", sha256.Sum256(content)), part.SHA256; e != a {
+			t.Errorf("mismatched checksum of part %d", i)
+		}
+
+		joined = append(joined, content...)
+	}
+
+	if !bytes.Equal(expected, joined) {
+		t.Errorf("mismatched content of the joined parts")
+	}
+}
+
+func TestJoinParts(t *testing.T) {
+	svr := newTestServer()
+	defer svr.Close()
+
+	path := filepath.Join(t.TempDir(), "test_12mb")
+	u := svr.URL().JoinPath("assets", "test_12mb")
+
+	if err := httpio.DownloadFile(context.Background(), u.String(), path, httpio.WithPartSize(1024*1024*5)); err != nil {
+		t.Fatalf("unable to download file: %v", err)
+	}
+
+	if err := httpio.JoinParts(path); err != nil {
+		t.Fatalf("unable to join parts: %v", err)
+	}
+
+	expected, _ := testdata.ReadFile("testdata/test_12mb")
+	actual, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("unable to read joined file: %v", err)
+	}
+
+	if !bytes.Equal(expected, actual) {
+		t.Errorf("mismatched joined content")
+	}
+
+	manifest, err := httpio.ReadManifest(path)
+	if err != nil {
+		t.Fatalf("unable to read manifest: %v", err)
+	}
+
+	corrupt := filepath.Join(filepath.Dir(path), manifest.Parts[1].Name)
+	if err := os.WriteFile(corrupt, make([]byte, manifest.Parts[1].Size), 0o644); err != nil {
+		t.Fatalf("unable to corrupt part: %v", err)
+	}
+
+	var checksumErr *httpio.ChecksumError
+	if err := httpio.JoinParts(path); !errors.As(err, &checksumErr) {
+		t.Fatalf("expected a checksum error, got: %v", err)
+	}
+
+	if e, a := manifest.Parts[1].Name, checksumErr.Name; e != a {
+		t.Errorf("expected the checksum error for '%s', got '%s'", e, a)
+	}
+
+	if actual, err := os.ReadFile(path); err != nil || !bytes.Equal(expected, actual) {
+		t.Errorf("expected the failed join to keep the joined file, got error: %v", err)
+	}
+
+	manifest.Parts[1].Name = "../" + manifest.Parts[1].Name
+	data, _ := json.Marshal(manifest)
+	if err := os.WriteFile(path+httpio.ManifestSuffix, data, 0o644); err != nil {
+		t.Fatalf("unable to write manifest: %v", err)
+	}
+
+	if err := httpio.JoinParts(path); err == nil || errors.As(err, &checksumErr) {
+		t.Errorf("expected an invalid part name error, got: %v", err)
+	}
+
+	entries, _ := os.ReadDir(filepath.Dir(path))
+	for _, entry := range entries {
+		if strings.HasSuffix(entry.Name(), ".tmp") {
+			t.Errorf("expected no temporary files to be left, found '%s'", entry.Name())
+		}
+	}
+}
@@ -0,0 +1,264 @@
+package httpio
+
+import (
+	"container/heap"
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"sync/atomic"
+)
+
+// DefaultActiveTransfers is the number of transfers a Downloader runs at
+// once when it's given none
+const DefaultActiveTransfers = 4
+
+// Downloader is a download manager downloading a queue of files to disk
+// with DownloadFile. A number of transfers run at once, taken from the queue
+// by their priority, and share the concurrency set with WithConcurrency and
+// the limit set with WithBandwidthLimit between them like the files of
+// GetAll.
+type Downloader struct {
+	ctx       context.Context
+	cancel    context.CancelFunc
+	opts      []Option
+	budget    budget
+	bandwidth *bandwidth
+	active    int
+
+	mu        sync.Mutex
+	idle      *sync.Cond
+	queue     transferQueue
+	running   int
+	pending   int
+	transfers []*Transfer
+}
+
+// Transfer is a file queued on a Downloader
+type Transfer struct {
+	URL      string
+	Path     string
+	Priority int
+
+	opts []Option
+	seq  int
+	done chan struct{}
+	err  error
+
+	downloaded atomic.Int64
+	total      atomic.Int64
+}
+
+// DownloaderProgress is the aggregate progress of the transfers of a
+// Downloader, Total is the sum of the sizes known so far
+type DownloaderProgress struct {
+	Transfers  int
+	Completed  int
+	Failed     int
+	Downloaded int64
+	Total      int64
+}
+
+// NewDownloader returns a downloader running the given number of transfers
+// at once, DefaultActiveTransfers when it's less than 1. The options apply to
+// every transfer, the downloader stops when the context is canceled.
+func NewDownloader(ctx context.Context, active int, opts ...Option) (*Downloader, error) {
+	cfg, err := sharedConfig(ctx, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	if active < 1 {
+		active = DefaultActiveTransfers
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	d := &Downloader{
+		ctx:       ctx,
+		cancel:    cancel,
+		opts:      opts,
+		budget:    make(budget, cfg.concurrency),
+		bandwidth: cfg.bandwidth,
+		active:    active,
+	}
+	d.idle = sync.NewCond(&d.mu)
+
+	return d, nil
+}
+
+// Add queues the download of the url to the path, transfers with a higher
+// priority start before the others and transfers with the same priority
+// start in the order they were added. The options apply to this transfer
+// on top of the options of the downloader.
+func (d *Downloader) Add(url, path string, priority int, opts ...Option) *Transfer {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	t := &Transfer{
+		URL:      url,
+		Path:     path,
+		Priority: priority,
+		opts:     opts,
+		seq:      len(d.transfers),
+		done:     make(chan struct{}),
+	}
+	t.total.Store(-1)
+
+	d.transfers = append(d.transfers, t)
+	d.pending++
+	heap.Push(&d.queue, t)
+	d.schedule()
+
+	return t
+}
+
+// schedule starts the queued transfers there's room for, it has to be
+// called with the lock held
+func (d *Downloader) schedule() {
+	for d.running < d.active && d.queue.Len() > 0 {
+		t := heap.Pop(&d.queue).(*Transfer)
+		d.running++
+
+		go d.run(t)
+	}
+}
+
+func (d *Downloader) run(t *Transfer) {
+	opts := append(slices.Clip(d.opts), t.opts...)
+
+	// the progress of the transfer is reported to the options' progress too
+	var progress func(downloaded, total int64)
+	if cfg, err := sharedConfig(d.ctx, opts...); err == nil {
+		progress = cfg.progress
+	}
+
+	opts = append(slices.Clip(opts),
+		withBudget(d.budget),
+		withBandwidth(d.bandwidth),
+		WithProgress(func(downloaded, total int64) {
+			t.downloaded.Store(downloaded)
+			t.total.Store(total)
+
+			if progress != nil {
+				progress(downloaded, total)
+			}
+		}),
+	)
+
+	err := DownloadFile(d.ctx, t.URL, t.Path, opts...)
+	if total := t.total.Load(); err == nil && total >= 0 {
+		// the chunks of a resumed download were downloaded before
+		t.downloaded.Store(total)
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	t.err = err
+	close(t.done)
+
+	d.running--
+	d.pending--
+	d.schedule()
+	d.idle.Broadcast()
+}
+
+// Wait waits for the transfers that were added to complete and returns the
+// errors of the ones that failed
+func (d *Downloader) Wait() error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	for d.pending > 0 {
+		d.idle.Wait()
+	}
+
+	var errs []error
+	for _, t := range d.transfers {
+		if t.err != nil {
+			errs = append(errs, t.err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// Close stops the transfers and waits for them to return, the transfers
+// still queued fail with the error of the canceled context
+func (d *Downloader) Close() {
+	d.cancel()
+	d.Wait()
+}
+
+// Progress returns the aggregate progress of the transfers
+func (d *Downloader) Progress() DownloaderProgress {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	p := DownloaderProgress{Transfers: len(d.transfers)}
+	for _, t := range d.transfers {
+		downloaded, total := t.Progress()
+		p.Downloaded += downloaded
+		if total > 0 {
+			p.Total += total
+		}
+
+		select {
+		case <-t.done:
+			if t.err != nil {
+				p.Failed++
+			} else {
+				p.Completed++
+			}
+		default:
+		}
+	}
+
+	return p
+}
+
+// Done returns a channel that's closed once the transfer completed or failed
+func (t *Transfer) Done() <-chan struct{} {
+	return t.done
+}
+
+// Err returns the error the transfer failed with once it's done
+func (t *Transfer) Err() error {
+	select {
+	case <-t.done:
+		return t.err
+	default:
+		return nil
+	}
+}
+
+// Progress returns the number of bytes downloaded and the size of the
+// file, which is -1 while it's unknown
+func (t *Transfer) Progress() (downloaded, total int64) {
+	return t.downloaded.Load(), t.total.Load()
+}
+
+// transferQueue is a heap of the queued transfers by priority
+type transferQueue []*Transfer
+
+func (q transferQueue) Len() int { return len(q) }
+
+func (q transferQueue) Less(i, j int) bool {
+	if q[i].Priority != q[j].Priority {
+		return q[i].Priority > q[j].Priority
+	}
+
+	return q[i].seq < q[j].seq
+}
+
+func (q transferQueue) Swap(i, j int) { q[i], q[j] = q[j], q[i] }
+
+func (q *transferQueue) Push(x any) { *q = append(*q, x.(*Transfer)) }
+
+func (q *transferQueue) Pop() any {
+	old := *q
+	t := old[len(old)-1]
+	*q = old[:len(old)-1]
+
+	return t
+}
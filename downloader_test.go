@@ -0,0 +1,198 @@
+package httpio_test
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jobstoit/httpio"
+)
+
+func TestDownloader(t *testing.T) {
+	svr := newTestServer()
+	defer svr.Close()
+
+	dir := t.TempDir()
+	d, err := httpio.NewDownloader(context.Background(), 2, httpio.WithChunkSize(1024*1024))
+	if err != nil {
+		t.Fatalf("unable to create downloader: %v", err)
+	}
+	defer d.Close()
+
+	names := []string{"test_5mb", "test_12mb", "GitHub_logo.png"}
+	transfers := make([]*httpio.Transfer, len(names))
+	for i, name := range names {
+		transfers[i] = d.Add(svr.URL().JoinPath("assets", name).String(), filepath.Join(dir, name), 0)
+	}
+
+	if err := d.Wait(); err != nil {
+		t.Fatalf("unable to download: %v", err)
+	}
+
+	var total int64
+	for i, name := range names {
+		expected, _ := testdata.ReadFile("testdata/" + name)
+		actual, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			t.Fatalf("unable to read %s: %v", name, err)
+		}
+
+		if !bytes.Equal(expected, actual) {
+			t.Errorf("mismatched content of %s", name)
+		}
+
+		if downloaded, size := transfers[i].Progress(); downloaded != int64(len(expected)) || size != int64(len(expected)) {
+			t.Errorf("unexpected progress of %s: %d of %d", name, downloaded, size)
+		}
+		total += int64(len(expected))
+	}
+
+	progress := d.Progress()
+	if progress.Transfers != 3 || progress.Completed != 3 || progress.Failed != 0 {
+		t.Errorf("unexpected progress: %+v", progress)
+	}
+
+	if progress.Downloaded != total || progress.Total != total {
+		t.Errorf("expected %d bytes, got %d of %d", total, progress.Downloaded, progress.Total)
+	}
+}
+
+func TestDownloaderHeader(t *testing.T) {
+	svr := newTestServerWithHandler(func(h http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Header.Get("X-Test") != "value" || r.Header.Get("X-Transfer") != "value" {
+				w.WriteHeader(http.StatusForbidden)
+				return
+			}
+
+			h.ServeHTTP(w, r)
+		})
+	})
+	defer svr.Close()
+
+	dir := t.TempDir()
+	d, err := httpio.NewDownloader(context.Background(), 1, httpio.WithHeader("X-Test", "value"))
+	if err != nil {
+		t.Fatalf("unable to create downloader: %v", err)
+	}
+	defer d.Close()
+
+	path := filepath.Join(dir, "GitHub_logo.png")
+	d.Add(svr.URL().JoinPath("assets", "GitHub_logo.png").String(), path, 0, httpio.WithHeader("X-Transfer", "value"))
+
+	if err := d.Wait(); err != nil {
+		t.Fatalf("unable to download: %v", err)
+	}
+
+	expected, _ := testdata.ReadFile("testdata/GitHub_logo.png")
+	if actual, _ := os.ReadFile(path); !bytes.Equal(expected, actual) {
+		t.Errorf("mismatched content")
+	}
+}
+
+func TestDownloaderPriority(t *testing.T) {
+	var mu sync.Mutex
+	var order []string
+	release := make(chan struct{})
+
+	svr := newTestServerWithHandler(func(h http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method == http.MethodHead {
+				mu.Lock()
+				order = append(order, r.URL.Query().Get("name"))
+				mu.Unlock()
+
+				// the first transfer holds the only slot until the others are queued
+				if r.URL.Query().Get("name") == "first" {
+					<-release
+				}
+			}
+
+			h.ServeHTTP(w, r)
+		})
+	})
+	defer svr.Close()
+
+	dir := t.TempDir()
+	d, err := httpio.NewDownloader(context.Background(), 1)
+	if err != nil {
+		t.Fatalf("unable to create downloader: %v", err)
+	}
+	defer d.Close()
+
+	add := func(name string, priority int) {
+		u := svr.URL().JoinPath("assets", "GitHub_logo.png")
+		u.RawQuery = "name=" + name
+		d.Add(u.String(), filepath.Join(dir, name), priority)
+	}
+
+	add("first", 0)
+	add("low", 0)
+	add("high", 2)
+	add("medium", 1)
+	close(release)
+
+	if err := d.Wait(); err != nil {
+		t.Fatalf("unable to download: %v", err)
+	}
+
+	if got := strings.Join(order, ","); got != "first,high,medium,low" {
+		t.Errorf("expected the transfers by priority, got %s", got)
+	}
+}
+
+func TestDownloaderClose(t *testing.T) {
+	svr := newTestServer()
+	defer svr.Close()
+
+	dir := t.TempDir()
+	d, err := httpio.NewDownloader(context.Background(), 1, httpio.WithBandwidthLimit(1024*1024))
+	if err != nil {
+		t.Fatalf("unable to create downloader: %v", err)
+	}
+
+	running := d.Add(svr.URL().JoinPath("assets", "test_5mb").String(), filepath.Join(dir, "running"), 0)
+	queued := d.Add(svr.URL().JoinPath("assets", "test_5mb").String(), filepath.Join(dir, "queued"), 0)
+
+	time.Sleep(time.Millisecond * 100)
+	d.Close()
+
+	if running.Err() == nil || queued.Err() == nil {
+		t.Errorf("expected the transfers to be stopped, got: %v, %v", running.Err(), queued.Err())
+	}
+
+	if progress := d.Progress(); progress.Failed != 2 {
+		t.Errorf("expected 2 failed transfers, got %+v", progress)
+	}
+}
+
+func TestDownloaderBandwidth(t *testing.T) {
+	svr := newTestServer()
+	defer svr.Close()
+
+	dir := t.TempDir()
+	d, err := httpio.NewDownloader(context.Background(), 2, httpio.WithBandwidthLimit(16*1024*1024))
+	if err != nil {
+		t.Fatalf("unable to create downloader: %v", err)
+	}
+	defer d.Close()
+
+	start := time.Now()
+	d.Add(svr.URL().JoinPath("assets", "test_5mb").String(), filepath.Join(dir, "a"), 0)
+	d.Add(svr.URL().JoinPath("assets", "test_5mb").String(), filepath.Join(dir, "b"), 0)
+
+	if err := d.Wait(); err != nil {
+		t.Fatalf("unable to download: %v", err)
+	}
+
+	// 10mb at 16mb/s, minus the burst
+	if elapsed := time.Since(start); elapsed < time.Millisecond*300 {
+		t.Errorf("expected the transfers to share the bandwidth, took %s", elapsed)
+	}
+}
@@ -0,0 +1,48 @@
+package httpio
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+const headerAcceptEncoding = "Accept-Encoding"
+
+// WithoutIdentityEncoding doesn't ask for the identity encoding on the
+// requests for the file, for servers rejecting it. By default the requests
+// accept only the identity encoding, as the ranges of an encoded response
+// are of the encoded content. A range response with a Content-Encoding the
+// metadata of the file didn't report still fails with ErrContentEncoding.
+func WithoutIdentityEncoding() Option {
+	return func(f *RemoteFile) error {
+		f.anyEncoding = true
+
+		return nil
+	}
+}
+
+// acceptIdentity asks for the identity encoding unless it's disabled or
+// an Accept-Encoding was given with WithHeader
+func (f *RemoteFile) acceptIdentity() {
+	if f.anyEncoding || f.req.Header.Get(headerAcceptEncoding) != "" {
+		return
+	}
+
+	f.req.Header.Set(headerAcceptEncoding, "identity")
+}
+
+// checkContentEncoding checks that a range response isn't encoded other
+// than the file is stored, which would make its bytes a range of content
+// encoded for this response alone
+func (f *RemoteFile) checkContentEncoding(res *http.Response) error {
+	if res.StatusCode != http.StatusPartialContent {
+		return nil
+	}
+
+	enc := strings.TrimSpace(res.Header.Get(headerContentEncoding))
+	if enc == "" || strings.EqualFold(enc, "identity") || strings.EqualFold(enc, strings.TrimSpace(f.meta.ContentEncoding)) {
+		return nil
+	}
+
+	return fmt.Errorf("%w: '%s'", ErrContentEncoding, enc)
+}
@@ -0,0 +1,90 @@
+package httpio_test
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jobstoit/httpio"
+)
+
+func TestIdentityEncoding(t *testing.T) {
+	expected, _ := testdata.ReadFile("testdata/test_5mb")
+
+	tests := []struct {
+		name     string
+		opts     []httpio.Option
+		expected string
+	}{
+		{"default", nil, "identity"},
+		{"opt out", []httpio.Option{httpio.WithoutIdentityEncoding()}, ""},
+		{"header", []httpio.Option{httpio.WithHeader("Accept-Encoding", "zstd")}, "zstd"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var mu sync.Mutex
+			encodings := map[string]bool{}
+
+			svr := newTestServerWithHandler(func(http.Handler) http.Handler {
+				return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					if r.Header.Get("Range") != "" {
+						mu.Lock()
+						encodings[r.Header.Get("Accept-Encoding")] = true
+						mu.Unlock()
+					}
+					http.ServeContent(w, r, "test_5mb.bin", time.Time{}, bytes.NewReader(expected))
+				})
+			})
+			defer svr.Close()
+
+			opts := append([]httpio.Option{httpio.WithChunkSize(1024 * 1024)}, test.opts...)
+			remoteFile, err := httpio.Get(svr.URL().JoinPath("test_5mb.bin").String(), opts...)
+			if err != nil {
+				t.Fatalf("failed to setup request: %v", err)
+			}
+			defer remoteFile.Close()
+
+			if _, err := io.Copy(io.Discard, remoteFile); err != nil {
+				t.Fatalf("unable to read file: %v", err)
+			}
+
+			if len(encodings) != 1 || !encodings[test.expected] {
+				t.Errorf("expected the chunks to accept '%s', got %v", test.expected, encodings)
+			}
+		})
+	}
+}
+
+func TestContentEncodingRejected(t *testing.T) {
+	content := bytes.Repeat([]byte("content "), 128*1024)
+
+	svr := newTestServerWithHandler(func(http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			// a server compressing the ranges on the fly
+			enc := ""
+			if r.Header.Get("Range") != "" {
+				enc = "gzip"
+			}
+			http.ServeContent(&encodingWriter{w, enc}, r, "file.txt", time.Time{}, bytes.NewReader(content))
+		})
+	})
+	defer svr.Close()
+
+	remoteFile, err := httpio.Get(svr.URL().JoinPath("file.txt").String(),
+		httpio.WithChunkSize(256*1024),
+		httpio.WithoutIdentityEncoding(),
+	)
+	if err != nil {
+		t.Fatalf("failed to setup request: %v", err)
+	}
+	defer remoteFile.Close()
+
+	if _, err := io.Copy(io.Discard, remoteFile); !errors.Is(err, httpio.ErrContentEncoding) {
+		t.Errorf("expected ErrContentEncoding but got %v", err)
+	}
+}
@@ -0,0 +1,170 @@
+package httpio
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"io"
+)
+
+// The encrypted stream starts with a header of the magic and a random nonce
+// prefix, followed by the file in segments sealed with AES-GCM. The nonce of
+// a segment is the prefix, the segment counter and a flag marking the last
+// segment, so reordered, dropped or truncated segments fail to decrypt.
+const (
+	encryptMagic       = "hio1"
+	encryptPrefixSize  = 7
+	encryptSegmentSize = 64 * 1024
+)
+
+// WithEncryption encrypts the file with AES-GCM using the given 16, 24 or
+// 32 byte key as it's read, so the plaintext never reaches the destination.
+// The stream is decrypted with NewDecryptReader. An encrypted file can't be
+// seeked or read with ReadAt and ReadRanges.
+func WithEncryption(key []byte) Option {
+	return func(f *RemoteFile) error {
+		aead, err := newAEAD(key)
+		if err != nil {
+			return err
+		}
+
+		f.aead = aead
+
+		return nil
+	}
+}
+
+// NewDecryptReader decrypts a stream encrypted with WithEncryption using
+// the same key, reads fail with ErrDecryption when the stream was altered
+// or truncated
+func NewDecryptReader(r io.Reader, key []byte) (io.Reader, error) {
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+
+	header := make([]byte, len(encryptMagic)+encryptPrefixSize)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return nil, ErrDecryption
+	}
+
+	if string(header[:len(encryptMagic)]) != encryptMagic {
+		return nil, ErrDecryption
+	}
+
+	return &segmentReader{
+		src:    r,
+		aead:   aead,
+		prefix: header[len(encryptMagic):],
+		size:   encryptSegmentSize + aead.Overhead(),
+		open:   true,
+	}, nil
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(block)
+}
+
+// newEncryptReader encrypts the plaintext read from src
+func newEncryptReader(src io.Reader, aead cipher.AEAD) (io.Reader, error) {
+	prefix := make([]byte, encryptPrefixSize)
+	if _, err := rand.Read(prefix); err != nil {
+		return nil, err
+	}
+
+	return &segmentReader{
+		src:    src,
+		aead:   aead,
+		prefix: prefix,
+		size:   encryptSegmentSize,
+		out:    append([]byte(encryptMagic), prefix...),
+	}, nil
+}
+
+// segmentReader seals or opens the segments read from src. One byte past
+// the segment is read ahead to know whether the segment is the last one.
+type segmentReader struct {
+	src     io.Reader
+	aead    cipher.AEAD
+	prefix  []byte
+	size    int
+	open    bool
+	counter uint32
+	buf     []byte
+	out     []byte
+	err     error
+}
+
+func (s *segmentReader) Read(p []byte) (int, error) {
+	for len(s.out) == 0 {
+		if s.err != nil {
+			return 0, s.err
+		}
+
+		s.err = s.next()
+	}
+
+	n := copy(p, s.out)
+	s.out = s.out[n:]
+
+	return n, nil
+}
+
+// next seals or opens the next segment into out
+func (s *segmentReader) next() error {
+	if s.buf == nil {
+		s.buf = make([]byte, 0, s.size+1)
+	}
+
+	n, err := io.ReadFull(s.src, s.buf[len(s.buf):s.size+1])
+	s.buf = s.buf[:len(s.buf)+n]
+
+	last := errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF)
+	if err != nil && !last {
+		return err
+	}
+
+	segment := s.buf
+	if !last {
+		segment = s.buf[:s.size]
+	}
+
+	nonce := make([]byte, 0, s.aead.NonceSize())
+	nonce = append(nonce, s.prefix...)
+	nonce = binary.BigEndian.AppendUint32(nonce, s.counter)
+	if last {
+		nonce = append(nonce, 1)
+	} else {
+		nonce = append(nonce, 0)
+	}
+
+	if s.open {
+		out, err := s.aead.Open(nil, nonce, segment, nil)
+		if err != nil {
+			return ErrDecryption
+		}
+		s.out = out
+	} else {
+		s.out = s.aead.Seal(nil, nonce, segment, nil)
+	}
+
+	if last {
+		return io.EOF
+	}
+
+	s.counter++
+	if s.counter == 0 {
+		return errors.New("encrypted stream too long")
+	}
+
+	s.buf = append(s.buf[:0], s.buf[s.size])
+
+	return nil
+}
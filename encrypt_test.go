@@ -0,0 +1,81 @@
+package httpio_test
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/jobstoit/httpio"
+)
+
+func TestGetEncryption(t *testing.T) {
+	svr := newTestServer()
+	defer svr.Close()
+
+	u := svr.URL().JoinPath("assets", "test_5mb").String()
+	expected, _ := testdata.ReadFile("testdata/test_5mb")
+	key := bytes.Repeat([]byte{0x42}, 32)
+
+	remoteFile, err := httpio.Get(u, httpio.WithEncryption(key))
+	if err != nil {
+		t.Fatalf("unable to get file: %v", err)
+	}
+
+	encrypted, err := io.ReadAll(remoteFile)
+	if err != nil {
+		t.Fatalf("unable to read file: %v", err)
+	}
+
+	if bytes.Contains(encrypted, expected[:1024]) {
+		t.Fatal("expected the file to be encrypted")
+	}
+
+	if _, err := remoteFile.ReadAt(make([]byte, 10), 0); err == nil {
+		t.Error("expected reading an encrypted file at an offset to fail")
+	}
+
+	if _, err := remoteFile.ReadRanges([]httpio.ByteRange{{Offset: 0, Length: 10}}); err == nil {
+		t.Error("expected reading ranges of an encrypted file to fail")
+	}
+
+	rd, err := httpio.NewDecryptReader(bytes.NewReader(encrypted), key)
+	if err != nil {
+		t.Fatalf("unable to decrypt: %v", err)
+	}
+
+	decrypted, err := io.ReadAll(rd)
+	if err != nil {
+		t.Fatalf("unable to decrypt: %v", err)
+	}
+
+	if !bytes.Equal(decrypted, expected) {
+		t.Error("expected the decrypted file to equal the original")
+	}
+
+	tests := map[string]struct {
+		data []byte
+		key  []byte
+	}{
+		"wrong key": {encrypted, bytes.Repeat([]byte{0x24}, 32)},
+		"truncated": {encrypted[:len(encrypted)-64*1024-16], key},
+		"altered":   {append(bytes.Clone(encrypted[:1000]), append([]byte{encrypted[1000] ^ 1}, encrypted[1001:]...)...), key},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			rd, err := httpio.NewDecryptReader(bytes.NewReader(test.data), test.key)
+			if err == nil {
+				_, err = io.ReadAll(rd)
+			}
+
+			if !errors.Is(err, httpio.ErrDecryption) {
+				t.Errorf("expected ErrDecryption, got: %v", err)
+			}
+		})
+	}
+
+	if _, err := httpio.Get(u, httpio.WithEncryption([]byte("short"))); err == nil {
+		t.Error("expected an invalid key to be refused")
+	}
+}
@@ -0,0 +1,623 @@
+package httpio
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptrace"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+type chunkState int
+
+const (
+	chunkPending chunkState = iota
+	chunkInflight
+	chunkDone
+)
+
+// engine fetches the chunks of a file with a pool of workers in the order
+// given by the scheduler and writes them in order to the pipe
+type engine struct {
+	file  *RemoteFile
+	wr    *io.PipeWriter
+	out   io.Writer
+	lim   *limiter
+	sched Scheduler
+	// at is set when the chunks are written out of order to their offsets
+	at io.WriterAt
+	// sizer is set when the chunks are planned as the download progresses,
+	// planned is the offset up to which they're planned
+	sizer   *chunkSizer
+	planned int64
+	// hedge is set when slow chunks get a duplicate request
+	hedge *hedger
+
+	mu        sync.Mutex
+	changed   chan struct{}
+	err       error
+	chunks    []Chunk
+	states    []chunkState
+	offsets   map[int64]int
+	completed int
+	// offset is the position up to which the file has been written
+	offset  int64
+	writing bool
+	// downloaded is the number of bytes of the completed chunks, counting
+	// the bytes before the start offset as done
+	downloaded int64
+	buffered   map[int64][]byte
+	// bufferedBytes is the size of the buffered chunks, including the
+	// chunks being read into memory
+	bufferedBytes int64
+}
+
+// run is a download of the file from an offset, seeking abandons the
+// current run and starts a new one at the new offset
+type run struct {
+	cancel context.CancelFunc
+	done   chan struct{}
+	err    error
+}
+
+// download fetches the file from the start offset and closes the pipe once
+// done. When the server doesn't support ranges the file is downloaded
+// sequentially instead, also when it turns out it ignores the range requests.
+func (f *RemoteFile) download(parent context.Context, wr *io.PipeWriter, start int64, r *run) {
+	ctx, cancel := context.WithCancel(parent)
+	defer cancel()
+
+	chunks := planChunks(start, f.end, f.chunkSize)
+	states := make([]chunkState, len(chunks))
+
+	// a resumed download only fetches the chunks that weren't written yet
+	pending, completed, skipped := chunks, 0, int64(0)
+	if f.resumed != nil && start == 0 {
+		pending = nil
+		for i, done := range f.resumed.plan(f.chunkSize, len(chunks)) {
+			if done {
+				states[i] = chunkDone
+				completed++
+				skipped += chunks[i].Length
+			} else {
+				pending = append(pending, chunks[i])
+			}
+		}
+	}
+
+	// adaptive chunks are planned in order as the download progresses, they
+	// can't be recorded in the state of a resumed download
+	var sizer *chunkSizer
+	workers := len(pending)
+	if f.adaptiveChunkSize && f.resumed == nil {
+		sizer = newChunkSizer(f.minChunkSize, f.maxChunkSize)
+		chunks, states, pending = nil, nil, nil
+		workers = int(min(chunkCount(f.end-start, f.minChunkSize), int64(f.concurrency)))
+	}
+
+	newScheduler := f.newScheduler
+	if newScheduler == nil || sizer != nil {
+		newScheduler = NewSequentialScheduler
+	}
+
+	lim := newLimiter(f.concurrency)
+	if f.adaptiveConcurrency {
+		lim.adapt(time.Now)
+	}
+
+	e := &engine{
+		file:       f,
+		wr:         wr,
+		out:        wr,
+		lim:        lim,
+		sched:      newScheduler(pending),
+		changed:    make(chan struct{}),
+		chunks:     chunks,
+		states:     states,
+		completed:  completed,
+		downloaded: start + skipped,
+		offsets:    make(map[int64]int, len(chunks)),
+		offset:     start,
+		buffered:   map[int64][]byte{},
+		sizer:      sizer,
+		planned:    start,
+	}
+
+	f.observe().OnStart(start, f.size)
+	defer func() {
+		r.err = e.err
+		f.observe().OnComplete(e.err)
+
+		// a seek only ends the current run of the download
+		if parent.Err() == nil || f.ctx.Err() != nil {
+			f.endTrace(e.err)
+		}
+		close(r.done)
+	}()
+
+	for _, c := range chunks {
+		e.offsets[c.Offset] = c.Index
+	}
+
+	if f.hedgeFactor > 0 {
+		e.hedge = newHedger(f.hedgeFactor)
+	}
+
+	// the checksums of the whole file can only be verified from the start
+	checksums := append(slices.Clip(f.checksums), f.meta.Checksums...)
+	inspectors := f.inspectors
+	if start == 0 && f.end == f.size && len(checksums) > 0 {
+		verifier := newChecksumVerifier(f.req.URL.String(), checksums)
+		inspectors = append(slices.Clip(inspectors), &checksumInspector{verifier: verifier})
+	}
+
+	// a download to a WriterAt writes the chunks to their offsets as they
+	// come in, unless they have to be inspected in order
+	if f.writerAt != nil {
+		e.out = io.NewOffsetWriter(f.writerAt, start)
+		if len(inspectors) == 0 {
+			e.at = f.writerAt
+		}
+	}
+
+	var inspect *inspectWriter
+	if len(inspectors) > 0 {
+		inspect = &inspectWriter{wr: e.out, inspectors: inspectors, offset: start}
+		e.out = inspect
+	}
+
+	if f.sequential {
+		if err := f.stream(ctx, e.out, start); err != nil {
+			e.fail(err)
+		}
+	} else {
+		var wg sync.WaitGroup
+		for i := range min(f.concurrency, max(workers, 1)) {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+
+				if err := e.work(ctx, i > 0); err != nil {
+					e.fail(err)
+					cancel()
+				}
+			}()
+		}
+		wg.Wait()
+
+		if e.fallback(e.err) {
+			e.err = nil
+			if err := f.stream(parent, e.out, e.offset); err != nil {
+				e.fail(err)
+			}
+		}
+	}
+	f.closeIdleConnections()
+
+	if e.err != nil {
+		return
+	}
+
+	if inspect != nil {
+		if err := inspect.done(); err != nil {
+			e.fail(err)
+			return
+		}
+	}
+
+	wr.CloseWithError(io.EOF)
+}
+
+// work fetches chunks until all chunks are done or an error occurs. A
+// limiter slot is acquired before taking a chunk, so a chunk that's in
+// flight is always able to make progress. Shared workers also take a slot
+// of the budget shared with other files, the first worker doesn't so every
+// file progresses while the other files hold the budget.
+func (e *engine) work(ctx context.Context, shared bool) error {
+	budget := e.file.budget
+	if !shared {
+		budget = nil
+	}
+
+	for {
+		if err := budget.acquire(ctx); err != nil {
+			return err
+		}
+
+		if err := e.lim.acquire(ctx); err != nil {
+			budget.release()
+			return err
+		}
+
+		c, ok, changed, err := e.take()
+		if !ok {
+			e.lim.release()
+			budget.release()
+
+			if err != nil || changed == nil {
+				return err
+			}
+
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-changed:
+			}
+
+			continue
+		}
+
+		e.file.observe().OnChunkStart(c)
+
+		started := time.Now()
+		e.file.stats.active.Add(1)
+		chunkCtx, endChunk := e.file.traceChunk(ctx, c)
+		err = e.fetch(chunkCtx, c)
+		endChunk(err)
+		e.file.stats.active.Add(-1)
+		e.lim.release()
+		budget.release()
+
+		e.mu.Lock()
+		if err != nil {
+			e.sched.OnError(c, err)
+			e.mu.Unlock()
+
+			return err
+		}
+
+		elapsed := time.Since(started)
+		e.lim.complete(c.Length)
+		if e.sizer != nil {
+			e.sizer.complete(c.Length, elapsed)
+		}
+		e.file.observe().OnChunkComplete(c, c.Length, elapsed)
+		e.file.logDebug("fetched chunk", "chunk", c.Index, "range", c.Range(), "duration", elapsed)
+
+		e.states[c.Index] = chunkDone
+		e.completed++
+		e.downloaded += c.Length
+		if e.file.progress != nil {
+			e.file.progress(e.downloaded, e.file.size)
+		}
+		e.sched.OnComplete(c)
+		e.broadcast()
+		e.mu.Unlock()
+	}
+}
+
+// take takes the next chunk from the scheduler. When the scheduler has no
+// chunk right now the channel that's closed on the next change is returned,
+// both are empty once all chunks are done.
+func (e *engine) take() (Chunk, bool, <-chan struct{}, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.err != nil || (e.completed == len(e.chunks) && (e.sizer == nil || e.planned >= e.file.end)) {
+		return Chunk{}, false, nil, nil
+	}
+
+	c, ok := e.next()
+	if !ok {
+		if !e.inflight() {
+			return Chunk{}, false, nil, ErrSchedulerStalled
+		}
+
+		return Chunk{}, false, e.changed, nil
+	}
+
+	if c.Index < 0 || c.Index >= len(e.chunks) || e.chunks[c.Index] != c || e.states[c.Index] != chunkPending {
+		return Chunk{}, false, nil, fmt.Errorf("scheduler returned an invalid chunk: %+v", c)
+	}
+
+	e.states[c.Index] = chunkInflight
+
+	return c, true, nil, nil
+}
+
+// next returns the next chunk of the scheduler, or plans the next chunk in
+// order with the current chunk size when the chunk size is adaptive
+func (e *engine) next() (Chunk, bool) {
+	if e.sizer == nil {
+		return e.sched.NextChunk()
+	}
+
+	if e.planned >= e.file.end {
+		return Chunk{}, false
+	}
+
+	c := Chunk{
+		Index:  len(e.chunks),
+		Offset: e.planned,
+		Length: min(e.sizer.size, e.file.end-e.planned),
+	}
+	e.planned += c.Length
+	e.chunks = append(e.chunks, c)
+	e.states = append(e.states, chunkPending)
+	e.offsets[c.Offset] = c.Index
+
+	return c, true
+}
+
+// fetch fetches the chunk and writes it in order, or right away to its
+// offset when downloading to a WriterAt
+func (e *engine) fetch(ctx context.Context, c Chunk) error {
+	f := e.file
+
+	var rd io.Reader
+	var trailer func() http.Header
+	if e.hedge != nil {
+		buf, header, err := e.hedged(ctx, c)
+		if err != nil {
+			return err
+		}
+		defer putChunkBuffer(buf)
+
+		rd, trailer = bytes.NewReader(buf), func() http.Header { return header }
+	} else {
+		body, err := e.open(ctx, c)
+		if err != nil {
+			return err
+		}
+		defer body.Close()
+
+		rd, trailer = body, body.trailer
+	}
+
+	var err error
+	if f.sniff && c.Offset == 0 {
+		if rd, err = sniffContentType(rd, c.Length, f.allowedTypes); err != nil {
+			return err
+		}
+	}
+
+	if e.at != nil {
+		err = e.writeAt(c, rd)
+	} else {
+		err = e.write(ctx, c, rd)
+	}
+	if err != nil {
+		return err
+	}
+
+	if f.resumed != nil && e.at != nil {
+		if err := f.resumed.complete(c); err != nil {
+			return err
+		}
+	}
+
+	// the trailer is complete now the body has been read to the end
+	f.trailers.add(c, trailer())
+
+	return nil
+}
+
+// open requests the chunk and returns the reader of its body
+func (e *engine) open(ctx context.Context, c Chunk) (*chunkReader, error) {
+	f := e.file
+
+	var reused atomic.Bool
+	traced := httptrace.WithClientTrace(ctx, &httptrace.ClientTrace{
+		GotConn: func(info httptrace.GotConnInfo) {
+			reused.Store(info.Reused)
+		},
+	})
+
+	res, err := f.fetchChunk(traced, e.lim, c)
+	if err != nil {
+		return nil, err
+	}
+	f.stats.chunk(c, res, reused.Load())
+
+	if err := checkPrecondition(res); err != nil {
+		res.Body.Close()
+		return nil, err
+	}
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		res.Body.Close()
+		return nil, newStatusError(res)
+	}
+
+	if err := checkRangeUnit(res.Header.Get(headerContentRange)); err != nil {
+		res.Body.Close()
+		return nil, err
+	}
+
+	// a response with the whole file only serves a chunk covering the file
+	if res.StatusCode != http.StatusPartialContent && (c.Offset != 0 || c.Length != f.size) {
+		res.Body.Close()
+		return nil, ErrRangeIgnored
+	}
+
+	e.lim.succeed()
+
+	body, err := f.newChunkReader(ctx, e.lim, c, res)
+	if err != nil {
+		body.Close()
+		return nil, err
+	}
+
+	return body, nil
+}
+
+// write writes the chunk once all chunks before it are written. Chunks
+// fetched ahead of the write position are read into memory, up to the size
+// set with WithMaxBufferedBytes, so their connections are released for the
+// next chunks instead of waiting for their turn.
+func (e *engine) write(ctx context.Context, c Chunk, body io.Reader) error {
+	for {
+		e.mu.Lock()
+		if e.err != nil {
+			e.mu.Unlock()
+			return nil
+		}
+
+		if e.offset == c.Offset && !e.writing {
+			e.writing = true
+			e.mu.Unlock()
+
+			n, err := copyBuffer(e.out, body)
+			if err == nil && n != c.Length {
+				err = fmt.Errorf("chunk %d has length %d, expected %d", c.Index, n, c.Length)
+			}
+
+			e.mu.Lock()
+			e.offset += n
+			err = e.flush(err)
+			e.mu.Unlock()
+
+			return err
+		}
+
+		// a chunk is buffered when the chunk at the write position isn't
+		// being fetched, as waiting for it could stall the workers, or
+		// otherwise when it fits in the buffer so its connection is freed
+		i, ok := e.offsets[e.offset]
+		if !ok || e.states[i] != chunkInflight || e.bufferedBytes+c.Length <= e.file.maxBuffered {
+			e.bufferedBytes += c.Length
+			e.mu.Unlock()
+
+			return e.buffer(c, body)
+		}
+
+		changed := e.changed
+		e.mu.Unlock()
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-changed:
+		}
+	}
+}
+
+// writeAt writes the chunk to its offset right away
+func (e *engine) writeAt(c Chunk, body io.Reader) error {
+	n, err := copyBuffer(io.NewOffsetWriter(e.at, c.Offset), body)
+	if err == nil && n != c.Length {
+		err = fmt.Errorf("chunk %d has length %d, expected %d", c.Index, n, c.Length)
+	}
+
+	return err
+}
+
+// buffer reads the chunk into memory and writes it right away when the
+// write position reached the chunk in the meantime
+func (e *engine) buffer(c Chunk, body io.Reader) error {
+	buf := getChunkBuffer(c.Length)
+
+	var n int
+	var err error
+	for n < len(buf) && err == nil {
+		var m int
+		m, err = body.Read(buf[n:])
+		n += m
+	}
+
+	// the body is read to its end, completing its trailer and checksums
+	var extra int64
+	if err == nil {
+		extra, err = io.Copy(io.Discard, body)
+	}
+
+	if err == io.EOF {
+		err = nil
+	}
+
+	if err == nil && int64(n)+extra != c.Length {
+		err = fmt.Errorf("chunk %d has length %d, expected %d", c.Index, int64(n)+extra, c.Length)
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if err != nil {
+		putChunkBuffer(buf)
+		e.bufferedBytes -= c.Length
+		e.broadcast()
+
+		return err
+	}
+
+	e.buffered[c.Offset] = buf
+	if e.offset != c.Offset || e.writing {
+		return nil
+	}
+
+	e.writing = true
+
+	return e.flush(nil)
+}
+
+// flush writes the buffered chunks at the write position, it has to be
+// called with the lock held by the writer and releases the write position
+func (e *engine) flush(err error) error {
+	for err == nil {
+		buf, ok := e.buffered[e.offset]
+		if !ok {
+			break
+		}
+		delete(e.buffered, e.offset)
+
+		e.mu.Unlock()
+		var n int
+		n, err = e.out.Write(buf)
+		e.mu.Lock()
+
+		e.offset += int64(n)
+		e.bufferedBytes -= int64(len(buf))
+		putChunkBuffer(buf)
+	}
+
+	e.writing = false
+	e.broadcast()
+
+	return err
+}
+
+// inflight reports whether any chunk is being fetched
+func (e *engine) inflight() bool {
+	for _, state := range e.states {
+		if state == chunkInflight {
+			return true
+		}
+	}
+
+	return false
+}
+
+// fail stops the download with the given error
+func (e *engine) fail(err error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.err != nil {
+		return
+	}
+
+	e.err = err
+	e.broadcast()
+
+	// the download continues sequentially on the same pipe
+	if !e.fallback(err) {
+		e.wr.CloseWithError(err)
+	}
+}
+
+// fallback reports whether the download continues sequentially after
+// failing with the error
+func (e *engine) fallback(err error) bool {
+	return errors.Is(err, ErrRangeIgnored) && !e.file.strictRanges
+}
+
+func (e *engine) broadcast() {
+	close(e.changed)
+	e.changed = make(chan struct{})
+}
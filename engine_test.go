@@ -0,0 +1,197 @@
+package httpio_test
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"runtime"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/jobstoit/httpio"
+)
+
+func TestGetWorkerPool(t *testing.T) {
+	const concurrency = 3
+
+	baseline := runtime.NumGoroutine()
+
+	var inflight, maxInflight, maxGoroutines atomic.Int64
+	svr := newTestServerWithHandler(func(h http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Header.Get("Range") != "" {
+				storeMax(&maxInflight, inflight.Add(1))
+				defer inflight.Add(-1)
+
+				storeMax(&maxGoroutines, int64(runtime.NumGoroutine()))
+			}
+
+			h.ServeHTTP(w, r)
+		})
+	})
+	defer svr.Close()
+
+	// 192 chunks, far more than there are workers
+	u := svr.URL().JoinPath("assets", "test_12mb").String()
+	remoteFile, err := httpio.Get(u, httpio.WithChunkSize(64*1024), httpio.WithConcurrency(concurrency))
+	if err != nil {
+		t.Fatalf("failed to setup request: %v", err)
+	}
+	defer remoteFile.Close()
+
+	body, err := io.ReadAll(remoteFile)
+	if err != nil {
+		t.Fatalf("unable to read file: %v", err)
+	}
+
+	expected, _ := testdata.ReadFile("testdata/test_12mb")
+	if !bytes.Equal(body, expected) {
+		t.Errorf("mismatched content")
+	}
+
+	if maxInflight.Load() > concurrency {
+		t.Errorf("expected at most %d chunks in flight, got %d", concurrency, maxInflight.Load())
+	}
+
+	// the workers, connections and server handlers, not a goroutine per chunk
+	if extra := maxGoroutines.Load() - int64(baseline); extra > 40 {
+		t.Errorf("expected a bounded number of goroutines, got %d more than before", extra)
+	}
+}
+
+// storeMax stores n when it's larger than the value
+func storeMax(v *atomic.Int64, n int64) {
+	for m := v.Load(); n > m; m = v.Load() {
+		if v.CompareAndSwap(m, n) {
+			return
+		}
+	}
+}
+
+func TestGetBuffersChunksAhead(t *testing.T) {
+	tests := []struct {
+		name        string
+		maxBuffered int64
+		check       func(ahead int64) bool
+	}{
+		// the second worker keeps fetching chunks while the first is held
+		{"default", httpio.DefaultMaxBufferedBytes, func(ahead int64) bool { return ahead > 2 }},
+		// one chunk is buffered, the next holds its connection
+		{"one chunk", 64 * 1024, func(ahead int64) bool { return ahead == 2 }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var served, ahead atomic.Int64
+
+			svr := newTestServerWithHandler(func(h http.Handler) http.Handler {
+				return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					switch rng := r.Header.Get("Range"); {
+					case strings.HasPrefix(rng, "bytes=0-"):
+						time.Sleep(time.Second / 4)
+						ahead.Store(served.Load())
+					case rng != "":
+						served.Add(1)
+					}
+
+					h.ServeHTTP(w, r)
+				})
+			})
+			defer svr.Close()
+
+			u := svr.URL().JoinPath("assets", "test_5mb").String()
+			remoteFile, err := httpio.Get(u,
+				httpio.WithChunkSize(64*1024),
+				httpio.WithConcurrency(2),
+				httpio.WithMaxBufferedBytes(tt.maxBuffered),
+			)
+			if err != nil {
+				t.Fatalf("failed to setup request: %v", err)
+			}
+			defer remoteFile.Close()
+
+			body, err := io.ReadAll(remoteFile)
+			if err != nil {
+				t.Fatalf("unable to read file: %v", err)
+			}
+
+			expected, _ := testdata.ReadFile("testdata/test_5mb")
+			if !bytes.Equal(body, expected) {
+				t.Errorf("mismatched content")
+			}
+
+			if !tt.check(ahead.Load()) {
+				t.Errorf("unexpected number of chunks served while the first was held: %d", ahead.Load())
+			}
+		})
+	}
+}
+
+func TestGetAdaptiveChunkSize(t *testing.T) {
+	var mu sync.Mutex
+	var lengths []int64
+	svr := newTestServerWithHandler(func(h http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if spec, ok := strings.CutPrefix(r.Header.Get("Range"), "bytes="); ok {
+				startStr, endStr, _ := strings.Cut(spec, "-")
+				start, _ := strconv.ParseInt(startStr, 10, 64)
+				end, _ := strconv.ParseInt(endStr, 10, 64)
+
+				mu.Lock()
+				lengths = append(lengths, end-start+1)
+				mu.Unlock()
+
+				// a steady latency keeps the throughput of the chunks stable
+				time.Sleep(time.Millisecond * 20)
+			}
+
+			h.ServeHTTP(w, r)
+		})
+	})
+	defer svr.Close()
+
+	const minSize, maxSize = 64 * 1024, 512 * 1024
+
+	u := svr.URL().JoinPath("assets", "test_12mb").String()
+	remoteFile, err := httpio.Get(u,
+		httpio.WithAdaptiveChunkSize(),
+		httpio.WithMinMaxChunkSize(minSize, maxSize),
+		httpio.WithConcurrency(2),
+	)
+	if err != nil {
+		t.Fatalf("failed to setup request: %v", err)
+	}
+	defer remoteFile.Close()
+
+	body, err := io.ReadAll(remoteFile)
+	if err != nil {
+		t.Fatalf("unable to read file: %v", err)
+	}
+
+	expected, _ := testdata.ReadFile("testdata/test_12mb")
+	if !bytes.Equal(body, expected) {
+		t.Errorf("mismatched content")
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	if e, a := int64(minSize), lengths[0]; e != a {
+		t.Errorf("expected the first chunk to have the minimum size %d, got %d", e, a)
+	}
+
+	if e, a := int64(maxSize), slices.Max(lengths); e != a {
+		t.Errorf("expected the chunks to grow to the maximum size %d, got %d", e, a)
+	}
+}
+
+func TestWithMinMaxChunkSize(t *testing.T) {
+	if _, err := httpio.Get("http://localhost", httpio.WithMinMaxChunkSize(2048, 1024)); err == nil {
+		t.Errorf("expected an error for a minimum exceeding the maximum")
+	}
+}
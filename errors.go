@@ -0,0 +1,144 @@
+package httpio
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// ErrNoCache is returned when prefetching without a cache configured with WithHTTPCache
+var ErrNoCache = errors.New("no cache configured")
+
+// ErrSchedulerStalled is returned when the scheduler returns no chunk while
+// chunks are pending and none are in flight
+var ErrSchedulerStalled = errors.New("scheduler returned no chunk while chunks are pending")
+
+// ErrChaos is the failure injected into chunk requests by WithChaos
+var ErrChaos = errors.New("injected chaos failure")
+
+// ErrRobotsDisallowed is returned for requests disallowed by the host's
+// robots.txt when downloading with WithPoliteness
+var ErrRobotsDisallowed = errors.New("disallowed by robots.txt")
+
+// ErrTooLarge is returned when the file is larger than the size set with WithMaxSize
+var ErrTooLarge = errors.New("file too large")
+
+// ErrTransferLimit is returned when a download receives more bytes than
+// the limit set with WithTransferLimit
+var ErrTransferLimit = errors.New("transfer limit exceeded")
+
+// ErrRangeNotSupported is matched by the errors of a server that can't serve
+// the file in ranges, which are ErrRangeIgnored and RangeUnitError
+var ErrRangeNotSupported = errors.New("range requests not supported")
+
+// ErrRangeIgnored is returned when the server responds to a range request
+// with the whole file and the download can't continue sequentially, either
+// because of WithoutSequentialFallback or because it's a ReadAt
+var ErrRangeIgnored = fmt.Errorf("%w: range request answered with the whole file", ErrRangeNotSupported)
+
+// ErrContentChanged is returned when the file changes on the server while
+// it's being downloaded, as the chunks would be parts of different revisions
+var ErrContentChanged = errors.New("content changed during download")
+
+// ErrNotModified is returned by GetIfChanged when the file didn't change
+// since the given revision
+var ErrNotModified = errors.New("not modified")
+
+// ErrStalled is returned when a response delivers less than the minimum
+// speed set with WithMinSpeed
+var ErrStalled = errors.New("download stalled")
+
+// ErrChunkTimeout is returned when a range request takes longer than the
+// timeout set with WithChunkTimeout
+var ErrChunkTimeout = errors.New("chunk request timed out")
+
+// ErrLocked is returned by DownloadFile when another download holds the
+// lock on the destination
+var ErrLocked = errors.New("destination is locked by another download")
+
+// ErrDecryption is returned when a stream encrypted with WithEncryption
+// can't be decrypted because of a wrong key or an altered stream
+var ErrDecryption = errors.New("unable to decrypt stream")
+
+// ErrContentEncoding is returned when a response to a range request has a
+// Content-Encoding the metadata of the file didn't report, as its range is
+// of content encoded for that response and can't be reassembled
+var ErrContentEncoding = errors.New("range response with an unexpected content encoding")
+
+// RangeUnitError is returned when the server advertises or responds with
+// a range unit other than bytes, which can't be used to fetch the file in chunks
+type RangeUnitError struct {
+	Unit string
+}
+
+func (e *RangeUnitError) Error() string {
+	return fmt.Sprintf("unsupported range unit: '%s'", e.Unit)
+}
+
+func (e *RangeUnitError) Is(target error) bool {
+	return target == ErrRangeNotSupported
+}
+
+// StatusError is returned when the server responds with an unexpected
+// status, Range is the range that was requested if any
+type StatusError struct {
+	Code   int
+	Status string
+	Range  string
+	URL    string
+}
+
+func newStatusError(res *http.Response) *StatusError {
+	e := &StatusError{Code: res.StatusCode, Status: res.Status}
+	if res.Request != nil {
+		e.Range = res.Request.Header.Get(headerRange)
+		if res.Request.URL != nil {
+			e.URL = res.Request.URL.String()
+		}
+	}
+
+	return e
+}
+
+func (e *StatusError) Error() string {
+	if e.Range != "" {
+		return fmt.Sprintf("unexpected statuscode for range %s of '%s': %d: %s", e.Range, e.URL, e.Code, e.Status)
+	}
+
+	return fmt.Sprintf("unexpected statuscode for '%s': %d: %s", e.URL, e.Code, e.Status)
+}
+
+// ChecksumError is returned when the checksum of the content doesn't match
+// the expected checksum
+type ChecksumError struct {
+	Name     string
+	Expected string
+	Actual   string
+}
+
+func (e *ChecksumError) Error() string {
+	return fmt.Sprintf("checksum mismatch for '%s', expected: '%s', but got '%s'", e.Name, e.Expected, e.Actual)
+}
+
+// PreconditionFailedError is returned when the server responds with 412
+// Precondition Failed to a request with the preconditions of WithIfMatch or
+// WithIfUnmodifiedSince, the validators hold the current revision if given
+type PreconditionFailedError struct {
+	URL          string
+	ETag         string
+	LastModified string
+}
+
+func (e *PreconditionFailedError) Error() string {
+	return fmt.Sprintf("precondition failed for '%s'", e.URL)
+}
+
+// ContentTypeError is returned when the content type of the file isn't one
+// of the types allowed with WithAllowedContentTypes
+type ContentTypeError struct {
+	ContentType string
+}
+
+func (e *ContentTypeError) Error() string {
+	return fmt.Sprintf("content type not allowed: '%s'", e.ContentType)
+}
@@ -0,0 +1,84 @@
+package httpio_test
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/jobstoit/httpio"
+)
+
+func TestGetStatusError(t *testing.T) {
+	svr := newTestServerWithHandler(func(h http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Header.Get("Range") == "bytes=1048576-2097151" {
+				w.WriteHeader(http.StatusForbidden)
+				return
+			}
+
+			h.ServeHTTP(w, r)
+		})
+	})
+	defer svr.Close()
+
+	u := svr.URL().JoinPath("assets", "test_5mb").String()
+
+	remoteFile, err := httpio.Get(u, httpio.WithChunkSize(1024*1024))
+	if err != nil {
+		t.Fatalf("unable to get file: %v", err)
+	}
+	defer remoteFile.Close()
+
+	_, err = io.Copy(io.Discard, remoteFile)
+
+	var statusErr *httpio.StatusError
+	if !errors.As(err, &statusErr) {
+		t.Fatalf("expected a StatusError, got: %v", err)
+	}
+
+	if statusErr.Code != http.StatusForbidden {
+		t.Errorf("expected status %d, got %d", http.StatusForbidden, statusErr.Code)
+	}
+
+	if statusErr.Range != "bytes=1048576-2097151" {
+		t.Errorf("unexpected range: '%s'", statusErr.Range)
+	}
+
+	if statusErr.URL != u {
+		t.Errorf("expected url '%s', got '%s'", u, statusErr.URL)
+	}
+}
+
+func TestGetStatusErrorReadAt(t *testing.T) {
+	svr := newTestServerWithHandler(func(h http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method == http.MethodGet {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+
+			h.ServeHTTP(w, r)
+		})
+	})
+	defer svr.Close()
+
+	u := svr.URL().JoinPath("assets", "test_5mb").String()
+
+	remoteFile, err := httpio.Get(u)
+	if err != nil {
+		t.Fatalf("unable to get file: %v", err)
+	}
+	defer remoteFile.Close()
+
+	_, err = remoteFile.ReadAt(make([]byte, 10), 1000)
+
+	var statusErr *httpio.StatusError
+	if !errors.As(err, &statusErr) || statusErr.Code != http.StatusServiceUnavailable {
+		t.Fatalf("expected a StatusError with status %d, got: %v", http.StatusServiceUnavailable, err)
+	}
+
+	if statusErr.Range != "bytes=1000-1009" {
+		t.Errorf("unexpected range: '%s'", statusErr.Range)
+	}
+}
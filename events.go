@@ -0,0 +1,88 @@
+package httpio
+
+import "time"
+
+// Observer receives the events of a download. The methods are called from
+// the goroutines of the download, concurrently for different chunks, so
+// they should be safe for concurrent use and return quickly.
+type Observer interface {
+	// OnStart is called when the download starts at the given offset of a
+	// file of the given size, which is -1 when unknown
+	OnStart(offset, size int64)
+	// OnChunkStart is called when a chunk is about to be requested
+	OnChunkStart(c Chunk)
+	// OnChunkComplete is called when a chunk has been fetched, with its
+	// number of bytes and the time it took
+	OnChunkComplete(c Chunk, bytes int64, duration time.Duration)
+	// OnRetry is called before a request of the chunk is retried after
+	// the error, attempt is the number of the retry starting at 1
+	OnRetry(c Chunk, err error, attempt int)
+	// OnComplete is called when the download ends, with nil when the whole
+	// file has been downloaded. Seeking ends the current download with
+	// context.Canceled and starts a new one.
+	OnComplete(err error)
+}
+
+// NopObserver ignores all events, it's embedded by observers only
+// interested in some of them
+type NopObserver struct{}
+
+func (NopObserver) OnStart(int64, int64)                        {}
+func (NopObserver) OnChunkStart(Chunk)                          {}
+func (NopObserver) OnChunkComplete(Chunk, int64, time.Duration) {}
+func (NopObserver) OnRetry(Chunk, error, int)                   {}
+func (NopObserver) OnComplete(error)                            {}
+
+// WithEvents reports the events of the download to the observer, it can be
+// given multiple times to report to multiple observers
+func WithEvents(observer Observer) Option {
+	return func(f *RemoteFile) error {
+		f.observers = append(f.observers, observer)
+
+		return nil
+	}
+}
+
+// retried records the retry of a request of the chunk after the error
+func (f *RemoteFile) retried(c Chunk, err error, attempt int) {
+	f.stats.retries.Add(1)
+	f.observe().OnRetry(c, err, attempt)
+}
+
+// observe returns the observers of the file as one
+func (f *RemoteFile) observe() Observer {
+	return f.observers
+}
+
+// observers reports the events to each of the observers
+type observers []Observer
+
+func (o observers) OnStart(offset, size int64) {
+	for _, observer := range o {
+		observer.OnStart(offset, size)
+	}
+}
+
+func (o observers) OnChunkStart(c Chunk) {
+	for _, observer := range o {
+		observer.OnChunkStart(c)
+	}
+}
+
+func (o observers) OnChunkComplete(c Chunk, bytes int64, duration time.Duration) {
+	for _, observer := range o {
+		observer.OnChunkComplete(c, bytes, duration)
+	}
+}
+
+func (o observers) OnRetry(c Chunk, err error, attempt int) {
+	for _, observer := range o {
+		observer.OnRetry(c, err, attempt)
+	}
+}
+
+func (o observers) OnComplete(err error) {
+	for _, observer := range o {
+		observer.OnComplete(err)
+	}
+}
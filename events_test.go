@@ -0,0 +1,112 @@
+package httpio_test
+
+import (
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/jobstoit/httpio"
+)
+
+type recordingObserver struct {
+	mu        sync.Mutex
+	started   []httpio.Chunk
+	completed []httpio.Chunk
+	bytes     int64
+	attempts  []int
+	done      chan error
+}
+
+func (o *recordingObserver) OnStart(int64, int64) {}
+
+func (o *recordingObserver) OnChunkStart(c httpio.Chunk) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	o.started = append(o.started, c)
+}
+
+func (o *recordingObserver) OnChunkComplete(c httpio.Chunk, bytes int64, _ time.Duration) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	o.completed = append(o.completed, c)
+	o.bytes += bytes
+}
+
+func (o *recordingObserver) OnRetry(_ httpio.Chunk, _ error, attempt int) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	o.attempts = append(o.attempts, attempt)
+}
+
+func (o *recordingObserver) OnComplete(err error) {
+	o.done <- err
+}
+
+func TestGetEvents(t *testing.T) {
+	// the second chunk fails the first time it's requested
+	var failed atomic.Bool
+	svr := newTestServerWithHandler(func(h http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if strings.HasPrefix(r.Header.Get("Range"), "bytes=1048576-") && failed.CompareAndSwap(false, true) {
+				w.WriteHeader(http.StatusInternalServerError)
+				return
+			}
+
+			h.ServeHTTP(w, r)
+		})
+	})
+	defer svr.Close()
+
+	observer := &recordingObserver{done: make(chan error, 1)}
+
+	u := svr.URL().JoinPath("assets", "test_5mb").String()
+	remoteFile, err := httpio.Get(u,
+		httpio.WithChunkSize(1024*1024),
+		httpio.WithRetry(1),
+		httpio.WithBackoff(func(int) time.Duration { return 0 }),
+		httpio.WithEvents(observer),
+	)
+	if err != nil {
+		t.Fatalf("failed to setup request: %v", err)
+	}
+	defer remoteFile.Close()
+
+	if _, err := io.Copy(io.Discard, remoteFile); err != nil {
+		t.Fatalf("unable to read file: %v", err)
+	}
+
+	select {
+	case err := <-observer.done:
+		if err != nil {
+			t.Errorf("expected the download to complete, got: %v", err)
+		}
+	case <-time.After(time.Second * 5):
+		t.Fatalf("expected OnComplete to be called")
+	}
+
+	observer.mu.Lock()
+	defer observer.mu.Unlock()
+
+	if e, a := 5, len(observer.started); e != a {
+		t.Errorf("expected %d chunks to start, got %d", e, a)
+	}
+
+	if e, a := 5, len(observer.completed); e != a {
+		t.Errorf("expected %d chunks to complete, got %d", e, a)
+	}
+
+	if e, a := remoteFile.Size(), observer.bytes; e != a {
+		t.Errorf("expected %d bytes in the completed chunks, got %d", e, a)
+	}
+
+	if len(observer.attempts) != 1 || observer.attempts[0] != 1 {
+		t.Errorf("expected a single first retry, got %v", observer.attempts)
+	}
+}
@@ -0,0 +1,160 @@
+package httpio
+
+import (
+	"context"
+	"errors"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"path"
+	"time"
+)
+
+// RemoteFS is a file system of the files under a base url, see FS
+type RemoteFS struct {
+	base *url.URL
+	err  error
+	opts []Option
+}
+
+// FS returns the file system of the files under the given base url, the
+// name of a file is resolved relative to it. The files are opened as
+// RemoteFiles that start downloading on the first Read, so ReadAt and Seek
+// work without fetching the file, and their Stat reports the metadata of the
+// file. As HTTP has no listings, the root directory is empty.
+func FS(baseURL string, opts ...Option) *RemoteFS {
+	base, err := url.Parse(baseURL)
+
+	return &RemoteFS{base: base, err: err, opts: opts}
+}
+
+// Open implements fs.FS
+func (fsys *RemoteFS) Open(name string) (fs.File, error) {
+	if name == "." {
+		return &rootDir{}, nil
+	}
+
+	f, err := fsys.open(name)
+	if err != nil {
+		return nil, err
+	}
+
+	return &fsFile{RemoteFile: f, name: name}, nil
+}
+
+// Stat implements fs.StatFS, requesting only the metadata of the file
+func (fsys *RemoteFS) Stat(name string) (fs.FileInfo, error) {
+	if name == "." {
+		return rootInfo{}, nil
+	}
+
+	f, err := fsys.open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return newFileInfo(name, f.meta), nil
+}
+
+func (fsys *RemoteFS) open(name string) (*RemoteFile, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrInvalid}
+	}
+
+	if fsys.err != nil {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fsys.err}
+	}
+
+	f, err := open(context.Background(), fsys.base.JoinPath(name).String(), fsys.opts...)
+	if err != nil {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fsError(err)}
+	}
+
+	return f, nil
+}
+
+// fsError returns the fs error of a missing or forbidden file, wrapping the
+// original error
+func fsError(err error) error {
+	var statusErr *StatusError
+	if !errors.As(err, &statusErr) {
+		return err
+	}
+
+	switch statusErr.Code {
+	case http.StatusNotFound, http.StatusGone:
+		return errors.Join(fs.ErrNotExist, err)
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return errors.Join(fs.ErrPermission, err)
+	default:
+		return err
+	}
+}
+
+// fsFile is a RemoteFile of a RemoteFS, its Stat implements fs.File
+type fsFile struct {
+	*RemoteFile
+	name string
+}
+
+func (f *fsFile) Stat() (fs.FileInfo, error) {
+	return newFileInfo(f.name, f.meta), nil
+}
+
+// fileInfo describes a remote file, Sys returns its *Metadata
+type fileInfo struct {
+	name string
+	meta *Metadata
+}
+
+func newFileInfo(name string, meta *Metadata) fileInfo {
+	m := *meta
+
+	return fileInfo{name: path.Base(name), meta: &m}
+}
+
+func (i fileInfo) Name() string       { return i.name }
+func (i fileInfo) Size() int64        { return i.meta.Size }
+func (i fileInfo) Mode() fs.FileMode  { return 0o444 }
+func (i fileInfo) IsDir() bool        { return false }
+func (i fileInfo) Sys() any           { return i.meta }
+func (i fileInfo) ModTime() time.Time { return parseHTTPTime(i.meta.LastModified) }
+
+func parseHTTPTime(value string) time.Time {
+	t, err := http.ParseTime(value)
+	if err != nil {
+		return time.Time{}
+	}
+
+	return t
+}
+
+// rootDir is the root directory of a RemoteFS, which has no entries as
+// there's no listing of the files
+type rootDir struct{}
+
+func (rootDir) Stat() (fs.FileInfo, error) { return rootInfo{}, nil }
+func (rootDir) Close() error               { return nil }
+
+func (rootDir) Read([]byte) (int, error) {
+	return 0, &fs.PathError{Op: "read", Path: ".", Err: errors.New("is a directory")}
+}
+
+func (rootDir) ReadDir(n int) ([]fs.DirEntry, error) {
+	if n > 0 {
+		return nil, io.EOF
+	}
+
+	return nil, nil
+}
+
+type rootInfo struct{}
+
+func (rootInfo) Name() string       { return "." }
+func (rootInfo) Size() int64        { return 0 }
+func (rootInfo) Mode() fs.FileMode  { return fs.ModeDir | 0o555 }
+func (rootInfo) ModTime() time.Time { return time.Time{} }
+func (rootInfo) IsDir() bool        { return true }
+func (rootInfo) Sys() any           { return nil }
@@ -0,0 +1,119 @@
+package httpio_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/jobstoit/httpio"
+)
+
+func TestFS(t *testing.T) {
+	svr := newTestServer()
+	defer svr.Close()
+
+	fsys := httpio.FS(svr.URL().JoinPath("assets").String(), httpio.WithChunkSize(1024*1024))
+	expected, _ := testdata.ReadFile("testdata/test_5mb")
+
+	actual, err := fs.ReadFile(fsys, "test_5mb")
+	if err != nil {
+		t.Fatalf("unable to read file: %v", err)
+	}
+
+	if !bytes.Equal(expected, actual) {
+		t.Error("mismatched content")
+	}
+
+	info, err := fs.Stat(fsys, "test_5mb")
+	if err != nil {
+		t.Fatalf("unable to stat file: %v", err)
+	}
+
+	if info.Name() != "test_5mb" || info.Size() != int64(len(expected)) || info.IsDir() {
+		t.Errorf("unexpected file info: %s, %d bytes", info.Name(), info.Size())
+	}
+
+	if _, ok := info.Sys().(*httpio.Metadata); !ok {
+		t.Errorf("expected the metadata of the file, got %T", info.Sys())
+	}
+}
+
+func TestFSErrors(t *testing.T) {
+	svr := newTestServer()
+	defer svr.Close()
+
+	fsys := httpio.FS(svr.URL().JoinPath("assets").String())
+
+	if _, err := fsys.Open("missing"); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("expected fs.ErrNotExist, got: %v", err)
+	}
+
+	if _, err := fs.Stat(fsys, "missing"); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("expected fs.ErrNotExist, got: %v", err)
+	}
+
+	if _, err := fsys.Open("../test_5mb"); !errors.Is(err, fs.ErrInvalid) {
+		t.Errorf("expected fs.ErrInvalid, got: %v", err)
+	}
+
+	info, err := fs.Stat(fsys, ".")
+	if err != nil || !info.IsDir() {
+		t.Errorf("expected the root to be a directory, got: %v", err)
+	}
+}
+
+func TestFSZip(t *testing.T) {
+	var archive bytes.Buffer
+	zw := zip.NewWriter(&archive)
+	w, _ := zw.Create("hello.txt")
+	w.Write([]byte("hello world"))
+	zw.Close()
+
+	var ranged atomic.Int32
+	svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Range") != "" {
+			ranged.Add(1)
+		}
+
+		http.ServeContent(w, r, "archive.zip", time.Time{}, bytes.NewReader(archive.Bytes()))
+	}))
+	defer svr.Close()
+
+	f, err := httpio.FS(svr.URL).Open("archive.zip")
+	if err != nil {
+		t.Fatalf("unable to open archive: %v", err)
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		t.Fatalf("unable to stat archive: %v", err)
+	}
+
+	zr, err := zip.NewReader(f.(io.ReaderAt), info.Size())
+	if err != nil {
+		t.Fatalf("unable to read archive: %v", err)
+	}
+
+	rc, err := zr.Open("hello.txt")
+	if err != nil {
+		t.Fatalf("unable to open entry: %v", err)
+	}
+	defer rc.Close()
+
+	content, err := io.ReadAll(rc)
+	if err != nil || string(content) != "hello world" {
+		t.Errorf("unexpected entry '%s': %v", content, err)
+	}
+
+	if ranged.Load() == 0 {
+		t.Error("expected the archive to be read with range requests")
+	}
+}
@@ -0,0 +1,95 @@
+package httpio
+
+import (
+	"context"
+	"sync"
+)
+
+// budget is a number of chunk requests shared by multiple files,
+// a nil budget is unlimited
+type budget chan struct{}
+
+func (b budget) acquire(ctx context.Context) error {
+	if b == nil {
+		return nil
+	}
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case b <- struct{}{}:
+		return nil
+	}
+}
+
+func (b budget) release() {
+	if b != nil {
+		<-b
+	}
+}
+
+// GetAll opens the files at the given urls, sharing the concurrency set
+// with WithConcurrency between them. A file starts downloading when it's
+// first read and always fetches at least one chunk at a time, the shared
+// budget limits the chunks fetched at once on top of that. The files are
+// in the order of the urls, when opening any of them fails the error is
+// returned and none of the files are downloaded. The files share the limit
+// set with WithBandwidthLimit as well.
+func GetAll(ctx context.Context, urls []string, opts ...Option) ([]*RemoteFile, error) {
+	cfg, err := sharedConfig(ctx, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	shared := make(budget, cfg.concurrency)
+	files := make([]*RemoteFile, len(urls))
+	errs := make([]error, len(urls))
+
+	var wg sync.WaitGroup
+	for i, url := range urls {
+		if err := shared.acquire(ctx); err != nil {
+			wg.Wait()
+			return nil, err
+		}
+
+		wg.Add(1)
+		go func() {
+			defer func() {
+				shared.release()
+				wg.Done()
+			}()
+
+			files[i], errs[i] = open(ctx, url, append(opts, withBudget(shared), withBandwidth(cfg.bandwidth))...)
+		}()
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			for _, f := range files {
+				if f != nil {
+					f.Close()
+				}
+			}
+
+			return nil, err
+		}
+	}
+
+	return files, nil
+}
+
+func withBudget(b budget) Option {
+	return func(f *RemoteFile) error {
+		f.budget = b
+
+		return nil
+	}
+}
+
+// begin starts the download of a file opened with a deferred start
+func (f *RemoteFile) begin() {
+	if f.start != nil {
+		f.started.Do(f.start)
+	}
+}
@@ -0,0 +1,113 @@
+package httpio_test
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"sync/atomic"
+	"testing"
+
+	"github.com/jobstoit/httpio"
+)
+
+func TestGetAll(t *testing.T) {
+	var inflight, maxInflight atomic.Int32
+	svr := newTestServerWithHandler(func(h http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Header.Get("Range") != "" {
+				n := inflight.Add(1)
+				defer inflight.Add(-1)
+
+				for {
+					m := maxInflight.Load()
+					if n <= m || maxInflight.CompareAndSwap(m, n) {
+						break
+					}
+				}
+			}
+
+			h.ServeHTTP(w, r)
+		})
+	})
+	defer svr.Close()
+
+	names := []string{"test_5mb", "test_12mb", "GitHub_logo.png"}
+	var urls []string
+	for _, name := range names {
+		urls = append(urls, svr.URL().JoinPath("assets", name).String())
+	}
+
+	files, err := httpio.GetAll(context.Background(), urls,
+		httpio.WithConcurrency(2),
+		httpio.WithChunkSize(1024*1024),
+	)
+	if err != nil {
+		t.Fatalf("failed to setup requests: %v", err)
+	}
+
+	// the started files that aren't read hold the shared budget, the files
+	// read first still progress with their own worker
+	for _, f := range files[:2] {
+		if _, err := f.Peek(1); err != nil {
+			t.Fatalf("unable to peek: %v", err)
+		}
+	}
+
+	for i := len(names) - 1; i >= 0; i-- {
+		name := names[i]
+		expected, _ := testdata.ReadFile("testdata/" + name)
+		actual, err := io.ReadAll(files[i])
+		if err != nil {
+			t.Fatalf("unable to read '%s': %v", name, err)
+		}
+
+		if !bytes.Equal(expected, actual) {
+			t.Errorf("mismatched content of '%s'", name)
+		}
+	}
+
+	// every file fetches one chunk on its own on top of the shared budget
+	if max := maxInflight.Load(); max > int32(2+len(names)) {
+		t.Errorf("expected at most %d chunk requests at once, got %d", 2+len(names), max)
+	}
+
+	if _, err := httpio.GetAll(context.Background(), append(urls, "http://[::1]:namedport")); err == nil {
+		t.Errorf("expected an error for an invalid url")
+	}
+}
+
+func TestGetAllHeader(t *testing.T) {
+	var missing atomic.Int32
+	svr := newTestServerWithHandler(func(h http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Header.Get("X-Test") != "value" {
+				missing.Add(1)
+			}
+
+			h.ServeHTTP(w, r)
+		})
+	})
+	defer svr.Close()
+
+	urls := []string{
+		svr.URL().JoinPath("assets", "test_5mb").String(),
+		svr.URL().JoinPath("assets", "GitHub_logo.png").String(),
+	}
+
+	files, err := httpio.GetAll(context.Background(), urls, httpio.WithHeader("X-Test", "value"))
+	if err != nil {
+		t.Fatalf("failed to setup requests: %v", err)
+	}
+
+	for _, f := range files {
+		if _, err := io.Copy(io.Discard, f); err != nil {
+			t.Fatalf("unable to read: %v", err)
+		}
+		f.Close()
+	}
+
+	if n := missing.Load(); n > 0 {
+		t.Errorf("expected the header on every request, %d requests were without", n)
+	}
+}
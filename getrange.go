@@ -0,0 +1,30 @@
+package httpio
+
+import (
+	"context"
+	"errors"
+)
+
+// GetRange gets the given number of bytes of the file from the offset
+// concurrently in chunks, the way GetContext gets the whole file. The file
+// reads the range, ending early at the end of the file, while its offsets
+// and Size remain those of the whole file. The checksums of the whole file
+// aren't verified for a range.
+func GetRange(ctx context.Context, url string, offset, length int64, opts ...Option) (*RemoteFile, error) {
+	if offset < 0 || length < 0 {
+		return nil, errors.New("negative range")
+	}
+
+	file, err := open(ctx, url, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	file.pos, file.end = offset, offset+length
+	if file.size >= 0 {
+		file.end = max(min(file.end, file.size), offset)
+	}
+	file.begin()
+
+	return file, nil
+}
@@ -0,0 +1,83 @@
+package httpio_test
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/jobstoit/httpio"
+)
+
+func TestGetRange(t *testing.T) {
+	svr := newTestServer()
+	defer svr.Close()
+
+	u := svr.URL().JoinPath("assets", "test_5mb").String()
+	expected, _ := testdata.ReadFile("testdata/test_5mb")
+	size := int64(len(expected))
+
+	tests := []struct {
+		name           string
+		offset, length int64
+		opts           []httpio.Option
+	}{
+		{"within a chunk", 100, 1000, nil},
+		{"across chunks", 1024*1024 + 512*1024, 2 * 1024 * 1024, nil},
+		{"past the end", size - 1000, 5000, nil},
+		{"beyond the end", size + 10, 10, nil},
+		{"empty", 2000, 0, nil},
+		{"adaptive", 1000, 3 * 1024 * 1024, []httpio.Option{httpio.WithAdaptiveChunkSize(), httpio.WithMinMaxChunkSize(256*1024, 1024*1024)}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			opts := append([]httpio.Option{httpio.WithChunkSize(1024 * 1024)}, test.opts...)
+
+			remoteFile, err := httpio.GetRange(context.Background(), u, test.offset, test.length, opts...)
+			if err != nil {
+				t.Fatalf("unable to get range: %v", err)
+			}
+			defer remoteFile.Close()
+
+			actual, err := io.ReadAll(remoteFile)
+			if err != nil {
+				t.Fatalf("unable to read range: %v", err)
+			}
+
+			want := expected[min(test.offset, size):min(test.offset+test.length, size)]
+			if !bytes.Equal(want, actual) {
+				t.Errorf("mismatched range, expected %d bytes, got %d", len(want), len(actual))
+			}
+		})
+	}
+}
+
+func TestGetRangeSequential(t *testing.T) {
+	svr := newTestServerWithHandler(func(h http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			r.Header.Del("Range")
+			h.ServeHTTP(w, r)
+		})
+	})
+	defer svr.Close()
+
+	u := svr.URL().JoinPath("assets", "test_5mb").String()
+	expected, _ := testdata.ReadFile("testdata/test_5mb")
+
+	remoteFile, err := httpio.GetRange(context.Background(), u, 1000, 2*1024*1024, httpio.WithChunkSize(1024*1024))
+	if err != nil {
+		t.Fatalf("unable to get range: %v", err)
+	}
+	defer remoteFile.Close()
+
+	actual, err := io.ReadAll(remoteFile)
+	if err != nil {
+		t.Fatalf("unable to read range: %v", err)
+	}
+
+	if !bytes.Equal(expected[1000:1000+2*1024*1024], actual) {
+		t.Errorf("mismatched range, got %d bytes", len(actual))
+	}
+}
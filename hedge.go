@@ -0,0 +1,154 @@
+package httpio
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"slices"
+	"sync"
+	"time"
+)
+
+const (
+	// minHedgeSamples is the number of chunks that have to complete before
+	// slow chunks are hedged
+	minHedgeSamples = 4
+	// maxHedgeSamples is the number of recent chunks the median is taken of
+	maxHedgeSamples = 64
+)
+
+// WithHedging sends a duplicate request for a chunk that takes longer than
+// the given factor times the median time of the chunks before it, scaled to
+// its length, and takes the response that completes first, canceling the
+// other. The factor is at least 1. Hedged chunks are read into memory and
+// their duplicate requests are sent on top of the concurrency.
+func WithHedging(factor float64) Option {
+	return func(f *RemoteFile) error {
+		f.hedgeFactor = max(factor, 1)
+
+		return nil
+	}
+}
+
+// hedger keeps the time per byte of the recent chunks
+type hedger struct {
+	factor float64
+
+	mu      sync.Mutex
+	samples []float64
+}
+
+func newHedger(factor float64) *hedger {
+	return &hedger{factor: factor}
+}
+
+// record records the time a chunk of the given length took
+func (h *hedger) record(n int64, elapsed time.Duration) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.samples = append(h.samples, elapsed.Seconds()/float64(max(n, 1)))
+	if len(h.samples) > maxHedgeSamples {
+		h.samples = h.samples[1:]
+	}
+}
+
+// delay returns the time after which a chunk of the given length is
+// hedged, which is unknown until enough chunks have completed
+func (h *hedger) delay(n int64) (time.Duration, bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if len(h.samples) < minHedgeSamples {
+		return 0, false
+	}
+
+	sorted := slices.Clone(h.samples)
+	slices.Sort(sorted)
+	median := sorted[len(sorted)/2]
+
+	return time.Duration(median * float64(n) * h.factor * float64(time.Second)), true
+}
+
+// hedgeResult is the outcome of one of the requests of a hedged chunk
+type hedgeResult struct {
+	buf     []byte
+	trailer http.Header
+	err     error
+}
+
+// hedged reads the chunk into memory, sending a duplicate request when it
+// takes longer than the hedger allows. The first request to complete is
+// used, a failed request leaves the chunk to the other one.
+func (e *engine) hedged(ctx context.Context, c Chunk) ([]byte, http.Header, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	results := make(chan hedgeResult, 2)
+	attempt := func() {
+		start := time.Now()
+		buf, trailer, err := e.receive(ctx, c)
+		if err == nil {
+			e.hedge.record(c.Length, time.Since(start))
+		}
+
+		results <- hedgeResult{buf: buf, trailer: trailer, err: err}
+	}
+	go attempt()
+
+	var timeout <-chan time.Time
+	if d, ok := e.hedge.delay(c.Length); ok {
+		timer := time.NewTimer(d)
+		defer timer.Stop()
+		timeout = timer.C
+	}
+
+	var err error
+	for pending := 1; ; {
+		select {
+		case <-timeout:
+			timeout = nil
+			pending++
+			e.file.stats.hedged.Add(1)
+
+			go attempt()
+		case r := <-results:
+			pending--
+			if r.err == nil {
+				return r.buf, r.trailer, nil
+			}
+
+			if err == nil {
+				err = r.err
+			}
+
+			// the duplicate is only sent for a slow chunk, not a failed one
+			if pending == 0 {
+				return nil, nil, err
+			}
+		}
+	}
+}
+
+// receive requests the chunk and reads it into memory
+func (e *engine) receive(ctx context.Context, c Chunk) ([]byte, http.Header, error) {
+	body, err := e.open(ctx, c)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer body.Close()
+
+	buf := getChunkBuffer(c.Length)
+	if _, err := io.ReadFull(body, buf); err != nil {
+		putChunkBuffer(buf)
+		return nil, nil, err
+	}
+
+	// the body is read to its end, completing its trailer and checksums
+	if _, err := io.Copy(io.Discard, body); err != nil {
+		putChunkBuffer(buf)
+		return nil, nil, err
+	}
+
+	return buf, body.trailer(), nil
+}
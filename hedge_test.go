@@ -0,0 +1,66 @@
+package httpio_test
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/jobstoit/httpio"
+)
+
+func TestGetHedging(t *testing.T) {
+	// the sixth chunk hangs halfway the first time it's requested, the
+	// chunks before it set the pace
+	var hung atomic.Bool
+	svr := newTestServerWithHandler(func(h http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if strings.HasPrefix(r.Header.Get("Range"), "bytes=1310720-") && hung.CompareAndSwap(false, true) {
+				w = &stallWriter{ResponseWriter: w, r: r, after: 128 * 1024}
+			}
+
+			h.ServeHTTP(w, r)
+		})
+	})
+	defer svr.Close()
+
+	u := svr.URL().JoinPath("assets", "test_5mb").String()
+	remoteFile, err := httpio.Get(u,
+		httpio.WithChunkSize(256*1024),
+		httpio.WithConcurrency(2),
+		httpio.WithHedging(3),
+	)
+	if err != nil {
+		t.Fatalf("failed to setup request: %v", err)
+	}
+	defer remoteFile.Close()
+
+	done := make(chan struct{})
+	var body []byte
+	go func() {
+		defer close(done)
+		body, err = io.ReadAll(remoteFile)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second * 10):
+		t.Fatalf("expected the hanging chunk to be hedged")
+	}
+
+	if err != nil {
+		t.Fatalf("unable to read file: %v", err)
+	}
+
+	expected, _ := testdata.ReadFile("testdata/test_5mb")
+	if !bytes.Equal(expected, body) {
+		t.Errorf("mismatched content")
+	}
+
+	if stats := remoteFile.Stats(); stats.Hedged < 1 {
+		t.Errorf("expected a hedged request, got %d", stats.Hedged)
+	}
+}
@@ -0,0 +1,48 @@
+package httpio
+
+import "net/http"
+
+// WithRequestHook calls the given hook with every request right before it's
+// sent, including the request for the metadata and the Range header of the
+// chunk requests, e.g. to sign it or add trace headers. The hook is given a
+// copy of the request it may modify.
+func WithRequestHook(hook func(req *http.Request)) Option {
+	return WithTransportWrapper(func(rt http.RoundTripper) http.RoundTripper {
+		return &hookTransport{base: rt, request: hook}
+	})
+}
+
+// WithResponseHook calls the given hook with every response, failing the
+// request with the error the hook returns, e.g. to reject responses by a
+// custom policy. A rejected chunk request is retried like a failed one with
+// WithRetry.
+func WithResponseHook(hook func(res *http.Response) error) Option {
+	return WithTransportWrapper(func(rt http.RoundTripper) http.RoundTripper {
+		return &hookTransport{base: rt, response: hook}
+	})
+}
+
+type hookTransport struct {
+	base     http.RoundTripper
+	request  func(req *http.Request)
+	response func(res *http.Response) error
+}
+
+func (t *hookTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if t.request != nil {
+		req = req.Clone(req.Context())
+		t.request(req)
+	}
+
+	res, err := t.base.RoundTrip(req)
+	if err != nil || t.response == nil {
+		return res, err
+	}
+
+	if err := t.response(res); err != nil {
+		res.Body.Close()
+		return nil, err
+	}
+
+	return res, nil
+}
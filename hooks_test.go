@@ -0,0 +1,77 @@
+package httpio_test
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"sync/atomic"
+	"testing"
+
+	"github.com/jobstoit/httpio"
+)
+
+func TestGetRequestHook(t *testing.T) {
+	var unsigned atomic.Int32
+	svr := newTestServerWithHandler(func(h http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Header.Get("X-Signed-Range") != r.Header.Get("Range") || r.Header.Get("X-Trace") != "trace" {
+				unsigned.Add(1)
+			}
+
+			h.ServeHTTP(w, r)
+		})
+	})
+	defer svr.Close()
+
+	u := svr.URL().JoinPath("assets", "test_5mb").String()
+
+	remoteFile, err := httpio.Get(u, httpio.WithChunkSize(1024*1024),
+		httpio.WithRequestHook(func(req *http.Request) {
+			req.Header.Set("X-Trace", "trace")
+		}),
+		httpio.WithRequestHook(func(req *http.Request) {
+			req.Header.Set("X-Signed-Range", req.Header.Get("Range"))
+		}),
+	)
+	if err != nil {
+		t.Fatalf("unable to get file: %v", err)
+	}
+	defer remoteFile.Close()
+
+	if _, err := io.Copy(io.Discard, remoteFile); err != nil {
+		t.Fatalf("unable to read file: %v", err)
+	}
+
+	if n := unsigned.Load(); n != 0 {
+		t.Errorf("expected every request to pass the hooks, got %d requests that didn't", n)
+	}
+}
+
+func TestGetResponseHook(t *testing.T) {
+	svr := newTestServer()
+	defer svr.Close()
+
+	u := svr.URL().JoinPath("assets", "test_5mb").String()
+	errRejected := errors.New("rejected")
+
+	var responses atomic.Int32
+	remoteFile, err := httpio.Get(u, httpio.WithChunkSize(1024*1024), httpio.WithResponseHook(func(res *http.Response) error {
+		if res.Request.Method == http.MethodHead {
+			return nil
+		}
+
+		if responses.Add(1) > 2 {
+			return errRejected
+		}
+
+		return nil
+	}))
+	if err != nil {
+		t.Fatalf("unable to get file: %v", err)
+	}
+	defer remoteFile.Close()
+
+	if _, err := io.Copy(io.Discard, remoteFile); !errors.Is(err, errRejected) {
+		t.Errorf("expected the error of the response hook, got: %v", err)
+	}
+}
@@ -0,0 +1,680 @@
+package httpio
+
+import (
+	"bytes"
+	"context"
+	"encoding/gob"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	headerCacheControl    = "Cache-Control"
+	headerAge             = "Age"
+	headerDate            = "Date"
+	headerExpires         = "Expires"
+	headerETag            = "ETag"
+	headerLastModified    = "Last-Modified"
+	headerIfNoneMatch     = "If-None-Match"
+	headerIfModifiedSince = "If-Modified-Since"
+	headerVary            = "Vary"
+	headerContentLength   = "Content-Length"
+)
+
+// maxHeuristicFreshness caps the heuristic freshness derived from Last-Modified
+const maxHeuristicFreshness = time.Hour * 24
+
+// DefaultRefreshConcurrency is the number of background cache refreshes
+// of a download that run at the same time, refreshes beyond it are skipped
+const DefaultRefreshConcurrency = 4
+
+// refreshAheadDivisor makes fresh entries refresh in the background during
+// the last part of their freshness lifetime when stale-while-revalidate applies
+const refreshAheadDivisor = 10
+
+// CacheRefresh is the result of a background cache refresh
+type CacheRefresh struct {
+	URL   string
+	Range string
+	// NotModified is true when the server confirmed the cached response
+	NotModified bool
+	Err         error
+}
+
+// cacheEntry is a stored response
+type cacheEntry struct {
+	StatusCode   int
+	Header       http.Header
+	Body         []byte
+	Vary         map[string]string
+	RequestTime  time.Time
+	ResponseTime time.Time
+}
+
+// cachingTransport is a private HTTP cache as described in RFC 9111,
+// storing whole and partial responses of GET and HEAD requests
+type cachingTransport struct {
+	base      http.RoundTripper
+	cache     Cache
+	stats     *stats
+	swr       time.Duration
+	onRefresh func(CacheRefresh)
+
+	refreshSlots chan struct{}
+	refreshing   sync.Map
+
+	rangesMu sync.Mutex
+}
+
+func (t *cachingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if (req.Method != http.MethodGet && req.Method != http.MethodHead) ||
+		parseCacheControl(req.Header).has("no-store") {
+		return t.base.RoundTrip(req)
+	}
+
+	key := cacheKey(req)
+	entry := t.lookup(key, req)
+	if entry == nil {
+		return t.fetch(key, req)
+	}
+
+	now := time.Now()
+	if !entry.mustRevalidate(req) {
+		swr := entry.staleWhileRevalidate(t.swr)
+		lifetime, age := entry.freshnessLifetime(), entry.currentAge(now)
+
+		if age < lifetime+swr {
+			if swr > 0 && age >= lifetime-lifetime/refreshAheadDivisor {
+				t.refresh(key, req, entry)
+			}
+
+			t.stats.cacheHits.Add(1)
+
+			return entry.response(req, now), nil
+		}
+	}
+
+	res, notModified, err := t.revalidate(key, req, entry)
+	if notModified {
+		t.stats.cacheHits.Add(1)
+	}
+
+	return res, err
+}
+
+// refresh revalidates the entry in the background, skipping the refresh
+// when the key is already being refreshed or when no slot is available
+func (t *cachingTransport) refresh(key string, req *http.Request, entry *cacheEntry) {
+	if _, busy := t.refreshing.LoadOrStore(key, struct{}{}); busy {
+		return
+	}
+
+	select {
+	case t.refreshSlots <- struct{}{}:
+	default:
+		t.refreshing.Delete(key)
+		return
+	}
+
+	req = req.Clone(context.WithoutCancel(req.Context()))
+
+	go func() {
+		defer func() {
+			<-t.refreshSlots
+			t.refreshing.Delete(key)
+		}()
+
+		res, notModified, err := t.revalidate(key, req, entry)
+		if err == nil {
+			_, err = io.Copy(io.Discard, res.Body)
+			res.Body.Close()
+		}
+
+		if t.onRefresh != nil {
+			t.onRefresh(CacheRefresh{
+				URL:         req.URL.String(),
+				Range:       req.Header.Get(headerRange),
+				NotModified: notModified,
+				Err:         err,
+			})
+		}
+	}()
+}
+
+// lookup returns the stored entry for the request, when the range of a
+// range request itself isn't stored it's sliced from a fresh whole object
+// or from a fresh stored range containing it
+func (t *cachingTransport) lookup(key string, req *http.Request) *cacheEntry {
+	if entry := t.load(key, req); entry != nil {
+		return entry
+	}
+
+	rangeHeader := req.Header.Get(headerRange)
+	if rangeHeader == "" || req.Method != http.MethodGet {
+		return nil
+	}
+
+	other := req.Clone(req.Context())
+	other.Header.Del(headerRange)
+
+	now := time.Now()
+	if entry := t.load(cacheKey(other), other); entry != nil && entry.fresh(now) {
+		if part := entry.slice(rangeHeader); part != nil {
+			return part
+		}
+	}
+
+	first, last, ok := parseRequestRange(rangeHeader)
+	if !ok {
+		return nil
+	}
+
+	// only the entries covering the range are decoded, the entries that
+	// were evicted from the cache are dropped from the index
+	var evicted []string
+	var part *cacheEntry
+	for _, stored := range t.storedRanges(req) {
+		if !stored.covers(first, last) {
+			continue
+		}
+
+		other.Header.Set(headerRange, stored.Range)
+		key := cacheKey(other)
+
+		entry := t.load(key, other)
+		if entry == nil {
+			if _, ok := t.cache.Get(key); !ok {
+				evicted = append(evicted, stored.Range)
+			}
+
+			continue
+		}
+
+		if entry.fresh(now) {
+			if part = entry.slice(rangeHeader); part != nil {
+				break
+			}
+		}
+	}
+
+	if len(evicted) > 0 {
+		t.removeStoredRanges(req, evicted)
+	}
+
+	return part
+}
+
+// maxStoredRanges caps the partial responses indexed per URL, the oldest
+// ones are dropped from the index first
+const maxStoredRanges = 256
+
+// storedRange is a partial response stored for a URL, Range is the Range
+// header it was requested with and First, Last and Size are from its
+// Content-Range, Size is -1 when the server didn't report it
+type storedRange struct {
+	Range string
+	First int64
+	Last  int64
+	Size  int64
+}
+
+// covers reports whether a request for the range from first to last, or to
+// the end of the file when last is -1, can be sliced from the response
+func (r storedRange) covers(first, last int64) bool {
+	if first < r.First || first > r.Last {
+		return false
+	}
+
+	return (last >= 0 && last <= r.Last) || (r.Size >= 0 && r.Last == r.Size-1)
+}
+
+// storedRanges returns the index of the partial responses stored for the
+// request's URL
+func (t *cachingTransport) storedRanges(req *http.Request) []storedRange {
+	data, ok := t.cache.Get(rangesKey(req))
+	if !ok {
+		return nil
+	}
+
+	var ranges []storedRange
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(&ranges); err != nil {
+		return nil
+	}
+
+	return ranges
+}
+
+func (t *cachingTransport) setStoredRanges(req *http.Request, ranges []storedRange) {
+	if len(ranges) == 0 {
+		t.cache.Delete(rangesKey(req))
+		return
+	}
+
+	buf := &bytes.Buffer{}
+	if err := gob.NewEncoder(buf).Encode(ranges); err != nil {
+		return
+	}
+
+	t.cache.Set(rangesKey(req), buf.Bytes())
+}
+
+// addStoredRange records a stored partial response so requests for ranges
+// inside of it can be served from it
+func (t *cachingTransport) addStoredRange(req *http.Request, res *http.Response) {
+	first, last, size, ok := parseContentRange(res.Header.Get(headerContentRange))
+	if !ok {
+		return
+	}
+	stored := storedRange{Range: req.Header.Get(headerRange), First: first, Last: last, Size: size}
+
+	t.rangesMu.Lock()
+	defer t.rangesMu.Unlock()
+
+	ranges := slices.DeleteFunc(t.storedRanges(req), func(r storedRange) bool {
+		return r.Range == stored.Range
+	})
+	ranges = append(ranges, stored)
+
+	if n := len(ranges) - maxStoredRanges; n > 0 {
+		ranges = ranges[n:]
+	}
+
+	t.setStoredRanges(req, ranges)
+}
+
+// removeStoredRanges drops the partial responses with the given Range
+// headers from the index
+func (t *cachingTransport) removeStoredRanges(req *http.Request, removed []string) {
+	t.rangesMu.Lock()
+	defer t.rangesMu.Unlock()
+
+	t.setStoredRanges(req, slices.DeleteFunc(t.storedRanges(req), func(r storedRange) bool {
+		return slices.Contains(removed, r.Range)
+	}))
+}
+
+// parseRequestRange parses a single bytes range of a Range header, last is
+// -1 when the range runs to the end of the file
+func parseRequestRange(value string) (first, last int64, ok bool) {
+	if first, last, ok = parseRangeHeader(value); ok {
+		return first, last, true
+	}
+
+	spec, ok := strings.CutPrefix(value, rangeUnitBytes+"=")
+	if !ok {
+		return 0, 0, false
+	}
+
+	firstStr, ok := strings.CutSuffix(spec, "-")
+	if !ok {
+		return 0, 0, false
+	}
+
+	first, err := strconv.ParseInt(firstStr, 10, 64)
+	if err != nil || first < 0 {
+		return 0, 0, false
+	}
+
+	return first, -1, true
+}
+
+func (t *cachingTransport) load(key string, req *http.Request) *cacheEntry {
+	data, ok := t.cache.Get(key)
+	if !ok {
+		return nil
+	}
+
+	entry := &cacheEntry{}
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(entry); err != nil {
+		t.cache.Delete(key)
+		return nil
+	}
+
+	for name, value := range entry.Vary {
+		if req.Header.Get(name) != value {
+			return nil
+		}
+	}
+
+	return entry
+}
+
+func (t *cachingTransport) store(key string, entry *cacheEntry) {
+	buf := &bytes.Buffer{}
+	if err := gob.NewEncoder(buf).Encode(entry); err != nil {
+		return
+	}
+
+	t.cache.Set(key, buf.Bytes())
+}
+
+// fetch sends the request and stores the response when it's storable
+func (t *cachingTransport) fetch(key string, req *http.Request) (*http.Response, error) {
+	requestTime := time.Now()
+	res, err := t.base.RoundTrip(req)
+	if err != nil || !storable(res) {
+		return res, err
+	}
+
+	return t.storeResponse(key, req, res, requestTime)
+}
+
+// storeResponse stores the storable response and returns it with its body
+// replaced by the stored body
+func (t *cachingTransport) storeResponse(key string, req *http.Request, res *http.Response, requestTime time.Time) (*http.Response, error) {
+	body, err := io.ReadAll(res.Body)
+	res.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	entry := &cacheEntry{
+		StatusCode:   res.StatusCode,
+		Header:       res.Header.Clone(),
+		Body:         body,
+		Vary:         varyValues(req, res.Header),
+		RequestTime:  requestTime,
+		ResponseTime: time.Now(),
+	}
+	t.store(key, entry)
+
+	if res.StatusCode == http.StatusPartialContent && req.Method == http.MethodGet {
+		t.addStoredRange(req, res)
+	}
+
+	res.Body = io.NopCloser(bytes.NewReader(body))
+
+	return res, nil
+}
+
+// revalidate validates the stale entry with the server, the stored entry is
+// served when the server responds with 304 Not Modified
+func (t *cachingTransport) revalidate(key string, req *http.Request, entry *cacheEntry) (*http.Response, bool, error) {
+	etag, lastModified := entry.Header.Get(headerETag), entry.Header.Get(headerLastModified)
+	if etag == "" && lastModified == "" {
+		res, err := t.fetch(key, req)
+		return res, false, err
+	}
+
+	cond := req.Clone(req.Context())
+	if etag != "" {
+		cond.Header.Set(headerIfNoneMatch, etag)
+	}
+	if lastModified != "" {
+		cond.Header.Set(headerIfModifiedSince, lastModified)
+	}
+
+	requestTime := time.Now()
+	res, err := t.base.RoundTrip(cond)
+	if err != nil {
+		return nil, false, err
+	}
+
+	if res.StatusCode != http.StatusNotModified {
+		if !storable(res) {
+			t.cache.Delete(key)
+			return res, false, nil
+		}
+
+		res, err = t.storeResponse(key, req, res, requestTime)
+
+		return res, false, err
+	}
+	res.Body.Close()
+
+	// the entry may be served concurrently, so the update goes to a copy
+	updated := *entry
+	updated.Header = entry.Header.Clone()
+	for name, values := range res.Header {
+		if name == headerContentLength {
+			continue
+		}
+
+		updated.Header[name] = values
+	}
+	updated.RequestTime = requestTime
+	updated.ResponseTime = time.Now()
+	t.store(key, &updated)
+
+	return updated.response(req, updated.ResponseTime), true, nil
+}
+
+// fresh reports whether the entry's freshness lifetime exceeds its age
+func (e *cacheEntry) fresh(now time.Time) bool {
+	return e.freshnessLifetime() > e.currentAge(now)
+}
+
+// mustRevalidate reports whether the entry has to be validated even when fresh
+func (e *cacheEntry) mustRevalidate(req *http.Request) bool {
+	return parseCacheControl(req.Header).has("no-cache") || parseCacheControl(e.Header).has("no-cache")
+}
+
+// staleWhileRevalidate returns the time the entry may be served stale while
+// it's revalidated in the background, the server's directive takes
+// precedence over the given default. Entries that must be revalidated once
+// stale are never served stale.
+func (e *cacheEntry) staleWhileRevalidate(fallback time.Duration) time.Duration {
+	cc := parseCacheControl(e.Header)
+	if cc.has("must-revalidate") || cc.has("proxy-revalidate") {
+		return 0
+	}
+
+	value, ok := cc["stale-while-revalidate"]
+	if !ok {
+		return fallback
+	}
+
+	seconds, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return fallback
+	}
+
+	return time.Duration(seconds) * time.Second
+}
+
+func (e *cacheEntry) freshnessLifetime() time.Duration {
+	cc := parseCacheControl(e.Header)
+	if maxAge, ok := cc["max-age"]; ok {
+		seconds, err := strconv.ParseInt(maxAge, 10, 64)
+		if err != nil {
+			return 0
+		}
+
+		return time.Duration(seconds) * time.Second
+	}
+
+	date := e.date()
+	if expires := e.Header.Get(headerExpires); expires != "" {
+		t, err := http.ParseTime(expires)
+		if err != nil {
+			return 0
+		}
+
+		return t.Sub(date)
+	}
+
+	if lm, err := http.ParseTime(e.Header.Get(headerLastModified)); err == nil && date.After(lm) {
+		return min(date.Sub(lm)/10, maxHeuristicFreshness)
+	}
+
+	return 0
+}
+
+// currentAge calculates the age of the entry as in RFC 9111 section 4.2.3
+func (e *cacheEntry) currentAge(now time.Time) time.Duration {
+	var ageValue time.Duration
+	if seconds, err := strconv.ParseInt(e.Header.Get(headerAge), 10, 64); err == nil {
+		ageValue = time.Duration(seconds) * time.Second
+	}
+
+	apparentAge := max(e.ResponseTime.Sub(e.date()), 0)
+	correctedAge := ageValue + e.ResponseTime.Sub(e.RequestTime)
+	initialAge := max(apparentAge, correctedAge)
+
+	return initialAge + now.Sub(e.ResponseTime)
+}
+
+func (e *cacheEntry) date() time.Time {
+	if date, err := http.ParseTime(e.Header.Get(headerDate)); err == nil {
+		return date
+	}
+
+	return e.ResponseTime
+}
+
+// response returns the entry as a response to the given request
+func (e *cacheEntry) response(req *http.Request, now time.Time) *http.Response {
+	header := e.Header.Clone()
+	header.Set(headerAge, strconv.FormatInt(int64(e.currentAge(now)/time.Second), 10))
+
+	contentLength := int64(len(e.Body))
+	if req.Method == http.MethodHead {
+		contentLength = -1
+		if cl, err := strconv.ParseInt(header.Get(headerContentLength), 10, 64); err == nil {
+			contentLength = cl
+		}
+	}
+
+	var body []byte
+	if req.Method != http.MethodHead {
+		body = e.Body
+	}
+
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", e.StatusCode, http.StatusText(e.StatusCode)),
+		StatusCode:    e.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader(body)),
+		ContentLength: contentLength,
+		Request:       req,
+	}
+}
+
+// slice returns a partial entry for the given single bytes range of a whole
+// object or a partial entry, or nil when the entry doesn't contain the range
+func (e *cacheEntry) slice(rangeHeader string) *cacheEntry {
+	spec, ok := strings.CutPrefix(rangeHeader, rangeUnitBytes+"=")
+	if !ok || strings.Contains(spec, ",") {
+		return nil
+	}
+
+	first, last, size := int64(0), int64(len(e.Body))-1, int64(len(e.Body))
+	if e.StatusCode == http.StatusPartialContent {
+		if first, last, size, ok = parseContentRange(e.Header.Get(headerContentRange)); !ok ||
+			last-first+1 != int64(len(e.Body)) {
+			return nil
+		}
+	}
+
+	startStr, endStr, _ := strings.Cut(spec, "-")
+	start, err := strconv.ParseInt(startStr, 10, 64)
+	if err != nil || start < first || start > last {
+		return nil
+	}
+
+	end := last
+	if endStr != "" {
+		if end, err = strconv.ParseInt(endStr, 10, 64); err != nil || end < start {
+			return nil
+		}
+
+		if end > last {
+			if size < 0 || last != size-1 {
+				return nil
+			}
+			end = last
+		}
+	} else if size < 0 || last != size-1 {
+		return nil
+	}
+
+	// the checksums of the stored body don't cover the slice
+	header := e.Header.Clone()
+	header.Del(headerContentDigest)
+	header.Del(headerContentMD5)
+	header.Set(headerContentRange, fmt.Sprintf("bytes %d-%d/%d", start, end, size))
+	header.Set(headerContentLength, strconv.FormatInt(end-start+1, 10))
+
+	return &cacheEntry{
+		StatusCode:   http.StatusPartialContent,
+		Header:       header,
+		Body:         e.Body[start-first : end-first+1],
+		Vary:         e.Vary,
+		RequestTime:  e.RequestTime,
+		ResponseTime: e.ResponseTime,
+	}
+}
+
+// storable reports whether the response may be stored, only successful
+// responses that can be served fresh or be revalidated are stored
+func storable(res *http.Response) bool {
+	if res.StatusCode != http.StatusOK && res.StatusCode != http.StatusPartialContent {
+		return false
+	}
+
+	cc := parseCacheControl(res.Header)
+	if cc.has("no-store") || res.Header.Get(headerVary) == "*" {
+		return false
+	}
+
+	_, maxAge := cc["max-age"]
+
+	return maxAge || res.Header.Get(headerExpires) != "" ||
+		res.Header.Get(headerETag) != "" || res.Header.Get(headerLastModified) != ""
+}
+
+func varyValues(req *http.Request, header http.Header) map[string]string {
+	vary := map[string]string{}
+	for _, value := range header.Values(headerVary) {
+		for _, name := range strings.Split(value, ",") {
+			name = http.CanonicalHeaderKey(strings.TrimSpace(name))
+			if name != "" {
+				vary[name] = req.Header.Get(name)
+			}
+		}
+	}
+
+	return vary
+}
+
+func cacheKey(req *http.Request) string {
+	return req.Method + " " + req.URL.String() + " " + req.Header.Get(headerRange)
+}
+
+// rangesKey is the key of the stored ranges of the request's URL
+func rangesKey(req *http.Request) string {
+	return "ranges " + req.URL.String()
+}
+
+type cacheControl map[string]string
+
+func (cc cacheControl) has(directive string) bool {
+	_, ok := cc[directive]
+
+	return ok
+}
+
+func parseCacheControl(header http.Header) cacheControl {
+	cc := cacheControl{}
+	for _, value := range header.Values(headerCacheControl) {
+		for _, directive := range strings.Split(value, ",") {
+			name, val, _ := strings.Cut(strings.TrimSpace(directive), "=")
+			if name == "" {
+				continue
+			}
+
+			cc[strings.ToLower(name)] = strings.Trim(val, `"`)
+		}
+	}
+
+	return cc
+}
@@ -0,0 +1,257 @@
+package httpio_test
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/jobstoit/httpio"
+)
+
+func TestGetHTTPCache(t *testing.T) {
+	tests := []struct {
+		name         string
+		cacheControl string
+		revalidated  bool
+	}{
+		{"fresh", "max-age=60", false},
+		{"stale", "max-age=0", true},
+		{"no-cache", "no-cache", true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			content := bytes.Repeat([]byte("cached"), 1024*256)
+
+			var requests, notModified atomic.Int32
+			svr := newTestServerWithHandler(func(http.Handler) http.Handler {
+				return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					requests.Add(1)
+					if r.Header.Get("If-None-Match") != "" {
+						notModified.Add(1)
+					}
+
+					w.Header().Set("Cache-Control", test.cacheControl)
+					w.Header().Set("ETag", `"v1"`)
+					http.ServeContent(w, r, "file", time.Time{}, bytes.NewReader(content))
+				})
+			})
+			defer svr.Close()
+
+			cache := httpio.NewMemoryCache()
+			get := func() *httpio.RemoteFile {
+				remoteFile, err := httpio.Get(svr.URL().JoinPath("file").String(),
+					httpio.WithHTTPCache(cache),
+					httpio.WithChunkSize(1024*256),
+				)
+				if err != nil {
+					t.Fatalf("failed to setup request: %v", err)
+				}
+
+				actual, err := io.ReadAll(remoteFile)
+				if err != nil {
+					t.Fatalf("unable to read file: %v", err)
+				}
+
+				if !bytes.Equal(content, actual) {
+					t.Errorf("mismatched content")
+				}
+
+				return remoteFile
+			}
+
+			get()
+			first := requests.Load()
+
+			remoteFile := get()
+			second := requests.Load() - first
+
+			if test.revalidated {
+				if second != first || notModified.Load() != first {
+					t.Errorf("expected all %d requests to be revalidated, got %d requests with %d revalidations", first, second, notModified.Load())
+				}
+			} else if second != 0 {
+				t.Errorf("expected all requests to be served from cache, got %d requests", second)
+			}
+
+			if e, a := int64(first), remoteFile.Stats().CacheHits; e != a {
+				t.Errorf("expected %d cache hits, got %d", e, a)
+			}
+		})
+	}
+}
+
+func TestGetHTTPCacheNoStore(t *testing.T) {
+	var requests atomic.Int32
+	svr := newTestServerWithHandler(func(http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requests.Add(1)
+			w.Header().Set("Cache-Control", "no-store, max-age=60")
+			http.ServeContent(w, r, "file", time.Time{}, bytes.NewReader([]byte("not stored")))
+		})
+	})
+	defer svr.Close()
+
+	cache := httpio.NewMemoryCache()
+	for range 2 {
+		remoteFile, err := httpio.Get(svr.URL().JoinPath("file").String(), httpio.WithHTTPCache(cache))
+		if err != nil {
+			t.Fatalf("failed to setup request: %v", err)
+		}
+
+		if _, err := io.ReadAll(remoteFile); err != nil {
+			t.Fatalf("unable to read file: %v", err)
+		}
+	}
+
+	if e, a := int32(4), requests.Load(); e != a {
+		t.Errorf("expected %d requests, got %d", e, a)
+	}
+}
+
+func TestGetHTTPCacheStaleWhileRevalidate(t *testing.T) {
+	content := bytes.Repeat([]byte("stale"), 1024)
+
+	var requests atomic.Int32
+	svr := newTestServerWithHandler(func(http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requests.Add(1)
+			w.Header().Set("Cache-Control", "max-age=0, stale-while-revalidate=60")
+			w.Header().Set("ETag", `"v1"`)
+			http.ServeContent(w, r, "file", time.Time{}, bytes.NewReader(content))
+		})
+	})
+	defer svr.Close()
+
+	cache := httpio.NewMemoryCache()
+	refreshed := make(chan httpio.CacheRefresh, 8)
+	get := func() {
+		remoteFile, err := httpio.Get(svr.URL().JoinPath("file").String(),
+			httpio.WithHTTPCache(cache),
+			httpio.WithCacheRefreshHook(func(r httpio.CacheRefresh) { refreshed <- r }),
+		)
+		if err != nil {
+			t.Fatalf("failed to setup request: %v", err)
+		}
+
+		actual, err := io.ReadAll(remoteFile)
+		if err != nil {
+			t.Fatalf("unable to read file: %v", err)
+		}
+
+		if !bytes.Equal(content, actual) {
+			t.Errorf("mismatched content")
+		}
+	}
+
+	get()
+	if e, a := int32(2), requests.Load(); e != a {
+		t.Fatalf("expected %d requests, got %d", e, a)
+	}
+
+	get()
+
+	for range 2 {
+		select {
+		case r := <-refreshed:
+			if r.Err != nil || !r.NotModified {
+				t.Errorf("expected a not modified refresh, got: %+v", r)
+			}
+		case <-time.After(time.Second * 5):
+			t.Fatalf("timed out waiting for the background refresh")
+		}
+	}
+
+	if e, a := int32(4), requests.Load(); e != a {
+		t.Errorf("expected %d requests after the background refreshes, got %d", e, a)
+	}
+}
+
+func TestGetHTTPCacheMustRevalidate(t *testing.T) {
+	content := bytes.Repeat([]byte("strict"), 1024)
+
+	var conditional atomic.Int32
+	svr := newTestServerWithHandler(func(http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Header.Get("If-None-Match") != "" {
+				conditional.Add(1)
+			}
+			w.Header().Set("Cache-Control", "max-age=0, must-revalidate")
+			w.Header().Set("ETag", `"v1"`)
+			http.ServeContent(w, r, "file", time.Time{}, bytes.NewReader(content))
+		})
+	})
+	defer svr.Close()
+
+	cache := httpio.NewMemoryCache()
+	for range 2 {
+		remoteFile, err := httpio.Get(svr.URL().JoinPath("file").String(),
+			httpio.WithHTTPCache(cache),
+			httpio.WithStaleWhileRevalidate(time.Minute),
+			httpio.WithCacheRefreshHook(func(r httpio.CacheRefresh) {
+				t.Errorf("unexpected background refresh: %+v", r)
+			}),
+		)
+		if err != nil {
+			t.Fatalf("failed to setup request: %v", err)
+		}
+
+		actual, err := io.ReadAll(remoteFile)
+		if err != nil {
+			t.Fatalf("unable to read file: %v", err)
+		}
+
+		if !bytes.Equal(content, actual) {
+			t.Errorf("mismatched content")
+		}
+	}
+
+	if e, a := int32(2), conditional.Load(); e != a {
+		t.Errorf("expected %d synchronous revalidations, got %d", e, a)
+	}
+}
+
+func TestGetHTTPCacheChanged(t *testing.T) {
+	content := []byte("version 1")
+
+	var requests atomic.Int32
+	svr := newTestServerWithHandler(func(http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requests.Add(1)
+			w.Header().Set("Cache-Control", "max-age=0")
+			w.Header().Set("ETag", `"`+string(content)+`"`)
+			http.ServeContent(w, r, "file", time.Time{}, bytes.NewReader(content))
+		})
+	})
+	defer svr.Close()
+
+	cache := httpio.NewMemoryCache()
+	read := func() []byte {
+		remoteFile, err := httpio.Get(svr.URL().JoinPath("file").String(), httpio.WithHTTPCache(cache))
+		if err != nil {
+			t.Fatalf("failed to setup request: %v", err)
+		}
+
+		actual, err := io.ReadAll(remoteFile)
+		if err != nil {
+			t.Fatalf("unable to read file: %v", err)
+		}
+
+		return actual
+	}
+
+	read()
+	first := requests.Load()
+
+	content = []byte("version 2")
+	if e, a := "version 2", string(read()); e != a {
+		t.Errorf("expected '%s' after the change, got '%s'", e, a)
+	}
+
+	if e, a := first, requests.Load()-first; e != a {
+		t.Errorf("expected the changed object to be fetched once with %d requests, got %d", e, a)
+	}
+}
@@ -2,174 +2,589 @@ package httpio
 
 import (
 	"context"
+	"crypto/cipher"
+	"crypto/tls"
+	"encoding/base64"
+	"errors"
 	"fmt"
 	"io"
-	"log"
+	"log/slog"
 	"net/http"
-	"strconv"
+	"net/url"
+	"os"
 	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
 )
 
 const (
-	DefaultConcurrency = 5
-	DefaultChunkSize   = 1024 * 1024 * 5 // 5mb
+	DefaultConcurrency      = 5
+	DefaultChunkSize        = 1024 * 1024 * 5  // 5mb
+	DefaultMaxBufferedBytes = 1024 * 1024 * 64 // 64mb
 )
 
 const (
-	headerRange = "Range"
+	headerRange        = "Range"
+	headerAcceptRanges = "Accept-Ranges"
+	headerContentRange = "Content-Range"
+
+	headerProxyAuthorization = "Proxy-Authorization"
+
+	rangeUnitBytes = "bytes"
+	rangeUnitNone  = "none"
 )
 
-type remoteFile struct {
-	client      *http.Client
-	req         *http.Request
-	rd          *io.PipeReader
-	chunkSize   int
-	concurrency int
-	size        int
-	debug       bool
+// defaultClient is used when no client is given with WithClient. Its
+// transport shares a TLS session cache so parallel chunk connections to the
+// same host can resume a session instead of doing a full handshake each.
+var defaultClient = &http.Client{
+	Transport: newDefaultTransport(),
 }
 
-type Option func(*remoteFile) error
+// RemoteFile is a reader over a remote file that's being fetched concurrently in chunks
+type RemoteFile struct {
+	client          *http.Client
+	req             *http.Request
+	rd              io.Reader
+	pr              *io.PipeReader
+	chunkSize       int64
+	concurrency     int
+	size            int64
+	tlsSessionCache tls.ClientSessionCache
+	ipFamily        IPFamily
+	localAddr       string
+	proxyFunc       func(*http.Request) (*url.URL, error)
+	proxyAuth       string
+	peeked          []byte
+	partSize        int64
+	stats           stats
+	pacer           *pacer
+	httpCache       Cache
+
+	retries              int
+	backoff              Backoff
+	staleWhileRevalidate time.Duration
+	onCacheRefresh       func(CacheRefresh)
+	refreshConcurrency   int
+	newScheduler         NewSchedulerFunc
+	transportWrappers    []func(http.RoundTripper) http.RoundTripper
+	transport            *http.Transport
+	trailers             trailers
+	tuneStore            Cache
+	metadataCache        *MetadataCache
+	budget               budget
+	start                func()
+	started              sync.Once
+	maxSize              int64
+	transferLimit        int64
+	transferred          atomic.Int64
+	allowedTypes         []string
+	sniff                bool
+	inspectors           []Inspector
+	aead                 cipher.AEAD
+	decompress           bool
+	anyEncoding          bool
+	meta                 *Metadata
+	location             atomic.Pointer[url.URL]
+	header               http.Header
+	sourceAttrs          bool
+	sequential           bool
+	strictRanges         bool
+	probeGet             bool
+	knownSize            int64
+	validators           validators
+	checksums            []Checksum
+	writerAt             io.WriterAt
+	resumed              *resumeState
+	fileMode             os.FileMode
+	fsync                bool
+	preallocate          bool
+	maxBuffered          int64
+	adaptiveConcurrency  bool
+	adaptiveChunkSize    bool
+	minChunkSize         int64
+	maxChunkSize         int64
+	minSpeed             int64
+	minSpeedWindow       time.Duration
+	chunkTimeout         time.Duration
+	hedgeFactor          float64
+	progress             func(downloaded, total int64)
+	onStats              func(Stats)
+	observers            observers
+	logger               *slog.Logger
+	tracer               Tracer
+	urlRefresher         *urlRefresher
+	jar                  http.CookieJar
+	mirrors              *mirrors
+	balanceMirrors       bool
+	bandwidth            *bandwidth
+	trace                tracing
+	sizeKnown            bool
+	ifChanged            *revision
+	timestamping         bool
+	readAtBlock          int64
+	readAhead            int64
+	readAt               readAtState
+	rangesPerRequest     int
+	multiRangeIgnored    atomic.Bool
+
+	// ctx is the context the runs of the download derive from, pos is the
+	// position of the reader and begun reports whether the first run started,
+	// end is the offset the runs end at, which is the size of the file
+	// unless it's a range of GetRange
+	ctx       context.Context
+	cancel    context.CancelFunc
+	closeOnce sync.Once
+	pos       int64
+	end       int64
+	mu        sync.Mutex
+	run       *run
+	begun     bool
+}
+
+type Option func(*RemoteFile) error
+
+func (f *RemoteFile) Read(p []byte) (int, error) {
+	f.begin()
 
-func (f *remoteFile) Read(p []byte) (int, error) {
-	return f.rd.Read(p)
+	if len(f.peeked) > 0 {
+		n := copy(p, f.peeked)
+		f.peeked = f.peeked[n:]
+		f.pos += int64(n)
+		f.stats.delivered.Add(int64(n))
+
+		return n, nil
+	}
+
+	n, err := f.rd.Read(p)
+	f.pos += int64(n)
+	f.stats.delivered.Add(int64(n))
+
+	return n, err
 }
 
-// GetContext get's the requested file concurrently in chunks
-func GetContext(ctx context.Context, url string, opts ...Option) (io.Reader, error) {
+// Size returns the size of the remote file in bytes, or -1 when the server
+// didn't report it
+func (f *RemoteFile) Size() int64 {
+	return f.size
+}
+
+// newRemoteFile applies the options to a new RemoteFile for the given url
+// without making any requests
+func newRemoteFile(ctx context.Context, url string, opts ...Option) (*RemoteFile, error) {
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
 	if err != nil {
 		return nil, err
 	}
 
-	rd, wr := io.Pipe()
-	file := &remoteFile{
-		client:      http.DefaultClient,
-		req:         req,
-		rd:          rd,
-		concurrency: DefaultConcurrency,
-		chunkSize:   DefaultChunkSize,
+	file, err := configure(req, opts...)
+	if err != nil {
+		return nil, err
 	}
+	file.acceptIdentity()
 
-	if err := Options(opts...)(file); err != nil {
+	if err := file.configureClient(); err != nil {
 		return nil, err
 	}
 
-	sizeReq, err := http.NewRequestWithContext(ctx, http.MethodHead, url, nil)
+	return file, nil
+}
+
+// sharedConfig applies the options to a RemoteFile without a url, to read
+// the settings shared by the files of GetAll, StatMany and a Downloader
+func sharedConfig(ctx context.Context, opts ...Option) (*RemoteFile, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, "", nil)
 	if err != nil {
 		return nil, err
 	}
-	sizeReq.Header = req.Header.Clone()
 
-	res, err := file.client.Do(sizeReq)
-	if err != nil {
-		return nil, fmt.Errorf("unable to get content range: %w", err)
+	return configure(req, opts...)
+}
+
+// configure applies the options to a new RemoteFile with the defaults for
+// the given request
+func configure(req *http.Request, opts ...Option) (*RemoteFile, error) {
+	file := &RemoteFile{
+		client:             defaultClient,
+		req:                req,
+		concurrency:        DefaultConcurrency,
+		chunkSize:          DefaultChunkSize,
+		maxBuffered:        DefaultMaxBufferedBytes,
+		minChunkSize:       DefaultMinChunkSize,
+		maxChunkSize:       DefaultMaxChunkSize,
+		pacer:              newPacer(),
+		refreshConcurrency: DefaultRefreshConcurrency,
+		rangesPerRequest:   DefaultRangesPerRequest,
+		run:                &run{cancel: func() {}, done: make(chan struct{})},
 	}
-	defer res.Body.Close()
 
-	if resLen := res.ContentLength; resLen != 0 {
-		file.size = int(resLen)
-	} else {
-		contentRange := res.Header.Get(headerRange)
-		parts := strings.Split(contentRange, "/")
-
-		total := -1
-		var err error
-		// Checking for whether or not a numbered total exists
-		// If one does not exist, we will assume the total to be -1, undefined,
-		// and sequentially download each chunk until hitting a 416 error
-		totalStr := parts[len(parts)-1]
-		if totalStr != "*" {
-			total, err = strconv.Atoi(totalStr)
-			if err != nil {
-				return nil, err
-			}
-		}
+	if err := Options(opts...)(file); err != nil {
+		return nil, err
+	}
+
+	return file, nil
+}
 
-		file.size = total
+// GetContext get's the requested file concurrently in chunks
+func GetContext(ctx context.Context, url string, opts ...Option) (*RemoteFile, error) {
+	file, err := open(ctx, url, opts...)
+	if err != nil {
+		return nil, err
 	}
+	file.begin()
 
-	cl := make(chan struct{}, file.concurrency)
-	sl := make(chan struct{}, 1)
-	defer close(sl)
+	return file, nil
+}
+
+// OpenContext requests the metadata of the file without downloading it,
+// the download starts with the first Read. A file that's only read with
+// ReadAt or ReadRanges is never downloaded as a whole.
+func OpenContext(ctx context.Context, url string, opts ...Option) (*RemoteFile, error) {
+	return open(ctx, url, opts...)
+}
+
+// Open requests the metadata of the file without downloading it
+func Open(url string, opts ...Option) (*RemoteFile, error) {
+	return OpenContext(context.Background(), url, opts...)
+}
 
-	go file.getChunk(ctx, cl, sl, 0, wr)
+// open requests the metadata of the file, the download starts with begin
+func open(ctx context.Context, url string, opts ...Option) (*RemoteFile, error) {
+	file, err := newRemoteFile(ctx, url, opts...)
+	if err != nil {
+		return nil, err
+	}
 
-	if file.debug {
-		log.Printf("fetching '%s' with length: %d", file.req.URL.String(), file.size)
+	// the download is traced from the request of the metadata on
+	ctx = file.startTrace(ctx)
+	if err := file.prepare(ctx); err != nil {
+		file.endTrace(err)
+		return nil, err
 	}
 
 	return file, nil
 }
 
-// Get get's the requested file concurrently in chunks
-func Get(url string, opts ...Option) (io.Reader, error) {
-	return GetContext(context.Background(), url, opts...)
-}
+// prepare requests the metadata of the file and sets up its reader
+func (f *RemoteFile) prepare(ctx context.Context) error {
+	meta, err := f.metadata(ctx)
+	if err == nil && f.ifChanged.unchanged(meta) {
+		err = ErrNotModified
+	}
 
-func (f *remoteFile) getChunk(ctx context.Context, concurrencyLock chan struct{}, sequenceLock <-chan struct{}, start int, wr *io.PipeWriter) {
-	if start == f.size+1 {
-		defer close(concurrencyLock)
+	if err != nil {
+		f.closeIdleConnections()
+		return err
+	}
+	f.size, f.end = meta.Size, meta.Size
+	f.meta = meta
+	f.validators.set(meta)
+	f.sequential = rangesUnsupported(meta)
+
+	if len(f.allowedTypes) > 0 && !needsSniffing(meta.ContentType) {
+		if err := checkContentType(meta.ContentType, f.allowedTypes); err != nil {
+			f.closeIdleConnections()
+			return err
+		}
+	}
+	f.sniff = len(f.allowedTypes) > 0 && needsSniffing(meta.ContentType)
+
+	if f.maxSize > 0 && meta.Size > f.maxSize {
+		f.closeIdleConnections()
+		return fmt.Errorf("%w: %d bytes exceeds the maximum of %d", ErrTooLarge, meta.Size, f.maxSize)
+	}
 
-		select {
-		case <-ctx.Done():
-			wr.CloseWithError(ctx.Err())
-		case <-sequenceLock:
-			wr.CloseWithError(io.EOF)
+	rd, wr := io.Pipe()
+	f.rd, f.pr = rd, rd
+	f.ctx, f.cancel = context.WithCancel(ctx)
+
+	if f.aead != nil {
+		if f.rd, err = newEncryptReader(rd, f.aead); err != nil {
+			f.cancel()
+			f.closeIdleConnections()
+			return err
 		}
+	}
+
+	if f.decompress {
+		c := f.compression()
+		f.rd = &decompressReader{src: f.rd, compression: c}
+		f.decompress = c != CompressionNone
+	}
+
+	f.start = func() {
+		f.stats.begin(time.Now())
+
+		if f.tuneStore != nil {
+			f.autoTune(f.ctx)
+		}
+
+		ctx, cancel := context.WithCancel(f.ctx)
+
+		f.mu.Lock()
+		r := f.run
+		r.cancel = cancel
+		f.begun = true
+		f.mu.Unlock()
 
-		return
+		go f.download(ctx, wr, f.pos, r)
+
+		f.logDebug("fetching", "size", f.size, "offset", f.pos)
+	}
+
+	return nil
+}
+
+// metadata returns the metadata of the file from the size given with
+// WithSize, the metadata cache or from a HEAD request, or a probe with
+// WithProbe
+func (f *RemoteFile) metadata(ctx context.Context) (*Metadata, error) {
+	if f.sizeKnown {
+		return &Metadata{URL: f.req.URL.String(), Size: f.knownSize}, nil
 	}
 
-	concurrencyLock <- struct{}{}
-	defer func() {
-		<-concurrencyLock
-	}()
+	if f.metadataCache != nil {
+		if meta, ok := f.metadataCache.get(f.req.URL.String()); ok {
+			return meta, nil
+		}
+	}
+
+	fetch := f.head
+	if f.probeGet {
+		fetch = f.probeMetadata
+	}
 
-	end := start + f.chunkSize
-	if end > f.size {
-		end = f.size
+	meta, err := fetch(ctx)
+	if err != nil {
+		return nil, err
 	}
 
-	next := make(chan struct{}, 1)
-	defer close(next)
+	if f.metadataCache != nil {
+		f.metadataCache.set(f.req.URL.String(), meta)
+	}
 
-	go f.getChunk(ctx, concurrencyLock, next, end+1, wr)
+	return meta, nil
+}
 
-	req := f.req.Clone(ctx)
-	req.Header.Add(headerRange, fmt.Sprintf("bytes=%d-%d", start, end))
+// head requests the metadata of the file with a HEAD request
+func (f *RemoteFile) head(ctx context.Context) (*Metadata, error) {
+	sizeReq, err := http.NewRequestWithContext(f.traceContext(ctx), http.MethodHead, f.req.URL.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	sizeReq.Header = f.req.Header.Clone()
+	f.ifChanged.setConditions(sizeReq.Header)
 
-	// TODO: implement retries
-	res, err := f.client.Do(req)
+	res, err := f.client.Do(sizeReq)
 	if err != nil {
-		wr.CloseWithError(err)
-		return
+		return nil, fmt.Errorf("unable to get content range: %w", err)
 	}
 	defer res.Body.Close()
 
+	if headRejected(res) {
+		return f.probeMetadata(ctx)
+	}
+
+	f.pacer.update(res.Header)
+
+	if res.StatusCode == http.StatusNotModified {
+		return nil, ErrNotModified
+	}
+
+	if err := checkPrecondition(res); err != nil {
+		return nil, err
+	}
+
 	if res.StatusCode < 200 || res.StatusCode > 299 {
-		wr.CloseWithError(fmt.Errorf("unexpected statuscode: %d: %s", res.StatusCode, res.Status))
-		return
+		return nil, newStatusError(res)
+	}
+
+	if err := checkAcceptRanges(res.Header.Get(headerAcceptRanges)); err != nil {
+		return nil, err
 	}
 
-	select {
-	case <-ctx.Done():
-		wr.CloseWithError(ctx.Err())
-	case <-sequenceLock:
-		_, err = io.Copy(wr, res.Body)
+	f.located(res)
+	meta := newMetadata(f.req.URL.String(), res)
+
+	// A server answering the HEAD request with a range reports the size of
+	// the file in the Content-Range rather than the Content-Length
+	if contentRange := res.Header.Get(headerContentRange); contentRange != "" {
+		if err := checkRangeUnit(contentRange); err != nil {
+			return nil, err
+		}
+
+		size, ok := parseContentRangeSize(contentRange)
+		if !ok {
+			return nil, fmt.Errorf("invalid Content-Range: '%s'", contentRange)
+		}
+		meta.Size = size
+	}
+
+	return meta, nil
+}
+
+// Get get's the requested file concurrently in chunks
+func Get(url string, opts ...Option) (*RemoteFile, error) {
+	return GetContext(context.Background(), url, opts...)
+}
+
+// fetchChunk requests the given range, paced to stay within the rate limit
+// advertised by the server. When the server responds with 429 Too Many
+// Requests, or 503 Service Unavailable with a Retry-After header, the
+// concurrency is lowered and the request is retried after the Retry-After
+// delay or a backoff. Failed requests are retried as configured with WithRetry.
+// The requests are conditional on the revision of the file the download
+// started with, failing with ErrContentChanged when the file has changed,
+// and their bodies are verified against the checksums the server reports.
+// A chunk forbidden at an expired URL is retried at a URL refreshed with
+// WithURLRefresher, and a chunk of GetMirrored fails over to the next mirror.
+func (f *RemoteFile) fetchChunk(ctx context.Context, lim *limiter, c Chunk) (*http.Response, error) {
+	var tried []bool
+	if f.mirrors != nil {
+		tried = make([]bool, len(f.mirrors.urls))
+	}
+
+	for attempt, retries, refreshed := 0, 0, false; ; {
+		// the validators are of the file at the primary mirror
+		req, mirror, primary := f.newRequest(f.traceContext(ctx)), -1, true
+		if f.mirrors != nil {
+			mirror, _ = f.mirrors.pick(c, tried)
+			req, primary = f.mirrorRequest(f.traceContext(ctx), mirror), mirror == f.mirrors.primary
+		}
+
+		req.Header.Add(headerRange, c.Range())
+		if ifRange := f.validators.ifRange(); primary && ifRange != "" && req.Header.Get(headerIfRange) == "" {
+			req.Header.Set(headerIfRange, ifRange)
+		}
+
+		if err := f.pacer.wait(ctx); err != nil {
+			return nil, err
+		}
+
+		sent := time.Now()
+		res, err := f.doChunk(req)
+		if err == nil {
+			f.logDebug("response", "range", c.Range(), "status", res.StatusCode, "duration", time.Since(sent))
+		}
+
+		if mirror >= 0 && ctx.Err() == nil {
+			next, err := f.failover(c, mirror, tried, res, err)
+			if err != nil {
+				return nil, err
+			}
+
+			if next {
+				continue
+			}
+		}
+
+		if retries < f.retries && !throttled(res) && retryable(ctx, res, err) {
+			retryErr := err
+			if err == nil {
+				res.Body.Close()
+				retryErr = errors.New(res.Status)
+			}
+
+			f.logDebug("retrying", "range", c.Range(), "attempt", retries+1, "error", retryErr)
+
+			lim.fail()
+			f.retried(c, retryErr, retries+1)
+
+			if err := sleep(ctx, f.retryDelay(retries)); err != nil {
+				return nil, err
+			}
+			retries++
+			clear(tried)
+
+			continue
+		}
+
 		if err != nil {
-			wr.CloseWithError(err)
+			return nil, err
+		}
+
+		if !refreshed && f.expired(res) {
+			res.Body.Close()
+			if err := f.refreshURL(ctx, req.URL); err != nil {
+				return nil, err
+			}
+			refreshed = true
+
+			continue
+		}
+		f.pacer.update(res.Header)
+
+		if primary {
+			if err := f.validators.check(res); err != nil {
+				res.Body.Close()
+				return nil, err
+			}
+		}
+
+		if err := f.checkContentEncoding(res); err != nil {
+			res.Body.Close()
+			return nil, err
+		}
+		verifyBody(res)
+
+		if !throttled(res) || attempt >= maxThrottleRetries {
+			return res, nil
+		}
+		res.Body.Close()
+
+		f.stats.throttled.Add(1)
+		lim.throttle()
+
+		f.logDebug("throttled", "range", c.Range(), "status", res.StatusCode, "concurrency", lim.current())
+
+		delay, ok := parseRetryAfter(res.Header, time.Now())
+		if !ok {
+			delay = throttleDelay(attempt)
+		}
+		f.retried(c, fmt.Errorf("throttled: %s", res.Status), attempt+1)
+
+		if err := sleep(ctx, delay); err != nil {
+			return nil, err
 		}
+		attempt++
+	}
+}
+
+// checkAcceptRanges checks whether the Accept-Ranges header either allows
+// bytes ranges, disallows ranges all together or is absent
+func checkAcceptRanges(acceptRanges string) error {
+	if acceptRanges == "" {
+		return nil
+	}
 
-		if f.debug {
-			log.Printf("write '%s', range %d-%d/%d", f.req.URL.String(), start, end, f.size)
+	for _, unit := range strings.Split(acceptRanges, ",") {
+		unit = strings.TrimSpace(unit)
+		if strings.EqualFold(unit, rangeUnitBytes) || strings.EqualFold(unit, rangeUnitNone) {
+			return nil
 		}
 	}
+
+	return &RangeUnitError{Unit: strings.TrimSpace(acceptRanges)}
+}
+
+// checkRangeUnit checks whether the given Content-Range is absent or in bytes
+func checkRangeUnit(contentRange string) error {
+	if contentRange == "" {
+		return nil
+	}
+
+	unit, _, _ := strings.Cut(strings.TrimSpace(contentRange), " ")
+	if !strings.EqualFold(unit, rangeUnitBytes) {
+		return &RangeUnitError{Unit: unit}
+	}
+
+	return nil
 }
 
 // Options is a collection of options
 func Options(opts ...Option) Option {
-	return func(f *remoteFile) error {
+	return func(f *RemoteFile) error {
 		for _, opt := range opts {
 			if err := opt(f); err != nil {
 				return err
@@ -182,7 +597,7 @@ func Options(opts ...Option) Option {
 
 // WithHeader sets headers to be used with the request
 func WithHeader(key, value string) Option {
-	return func(f *remoteFile) error {
+	return func(f *RemoteFile) error {
 		f.req.Header.Add(key, value)
 
 		return nil
@@ -191,7 +606,7 @@ func WithHeader(key, value string) Option {
 
 // WithClient sets the client that should be used
 func WithClient(client *http.Client) Option {
-	return func(f *remoteFile) error {
+	return func(f *RemoteFile) error {
 		if client != nil {
 			f.client = client
 		}
@@ -200,9 +615,312 @@ func WithClient(client *http.Client) Option {
 	}
 }
 
+// WithCookieJar stores the cookies set by the responses in the given jar and
+// sends them along with the requests, so the chunk requests carry the session
+// cookies set by the response to the request for the metadata. When used
+// together with WithClient the client is copied, leaving its jar unchanged.
+func WithCookieJar(jar http.CookieJar) Option {
+	return func(f *RemoteFile) error {
+		f.jar = jar
+
+		return nil
+	}
+}
+
+// WithTLSSessionCache sets the TLS session cache used for resuming sessions
+// across the chunk connections. When used together with WithClient the
+// client's transport is cloned, so connections aren't shared with other users
+// of that client.
+func WithTLSSessionCache(cache tls.ClientSessionCache) Option {
+	return func(f *RemoteFile) error {
+		f.tlsSessionCache = cache
+
+		return nil
+	}
+}
+
+// WithIPFamily sets the IP family used for connecting to the server.
+// When used together with WithClient the client's transport is cloned and
+// its dialer is replaced.
+func WithIPFamily(family IPFamily) Option {
+	return func(f *RemoteFile) error {
+		f.ipFamily = family
+
+		return nil
+	}
+}
+
+// WithLocalAddr binds the outgoing connections to the given local IP address
+// or to the address of the network interface with the given name.
+// When used together with WithClient the client's transport is cloned and
+// its dialer is replaced.
+func WithLocalAddr(addr string) Option {
+	return func(f *RemoteFile) error {
+		f.localAddr = addr
+
+		return nil
+	}
+}
+
+// WithProxy routes the requests through the proxy at the given url,
+// basic auth credentials can be given in the url's userinfo.
+// When used together with WithClient the client's transport is cloned.
+func WithProxy(proxyURL string) Option {
+	return func(f *RemoteFile) error {
+		u, err := url.Parse(proxyURL)
+		if err != nil {
+			return fmt.Errorf("invalid proxy url: %w", err)
+		}
+
+		f.proxyFunc = http.ProxyURL(u)
+
+		return nil
+	}
+}
+
+// WithProxyFunc sets the function selecting the proxy for each request,
+// a nil url means the request isn't proxied.
+// When used together with WithClient the client's transport is cloned.
+func WithProxyFunc(fn func(*http.Request) (*url.URL, error)) Option {
+	return func(f *RemoteFile) error {
+		f.proxyFunc = fn
+
+		return nil
+	}
+}
+
+// WithProxyBasicAuth authenticates with the proxy using basic auth
+func WithProxyBasicAuth(username, password string) Option {
+	return func(f *RemoteFile) error {
+		f.proxyAuth = "Basic " + base64.StdEncoding.EncodeToString([]byte(username+":"+password))
+
+		return nil
+	}
+}
+
+// WithProxyBearerToken authenticates with the proxy using the given token
+func WithProxyBearerToken(token string) Option {
+	return func(f *RemoteFile) error {
+		f.proxyAuth = "Bearer " + token
+
+		return nil
+	}
+}
+
+// WithDigestAuth authenticates using HTTP Digest authentication,
+// sharing the challenge and nonce count across all chunk requests
+func WithDigestAuth(username, password string) Option {
+	return WithTransportWrapper(func(rt http.RoundTripper) http.RoundTripper {
+		return &digestTransport{
+			base:     rt,
+			username: username,
+			password: password,
+		}
+	})
+}
+
+// WithSigner signs the preflight and every chunk request with the given signer
+func WithSigner(signer Signer) Option {
+	return WithTransportWrapper(func(rt http.RoundTripper) http.RoundTripper {
+		return &signingTransport{
+			base:   rt,
+			signer: signer,
+		}
+	})
+}
+
+// WithHTTPCache caches the responses in the given cache, honoring
+// Cache-Control, Age and the validators as a private cache (RFC 9111).
+// Stale responses are revalidated with the server before being served.
+func WithHTTPCache(cache Cache) Option {
+	return func(f *RemoteFile) error {
+		f.transportWrappers = append(f.transportWrappers, func(rt http.RoundTripper) http.RoundTripper {
+			return &cachingTransport{
+				base:         rt,
+				cache:        cache,
+				stats:        &f.stats,
+				swr:          f.staleWhileRevalidate,
+				onRefresh:    f.onCacheRefresh,
+				refreshSlots: make(chan struct{}, f.refreshConcurrency),
+			}
+		})
+		f.httpCache = cache
+
+		return nil
+	}
+}
+
+// WithStaleWhileRevalidate serves cached responses up to the given duration
+// after they went stale while refreshing them in the background, for
+// responses without a stale-while-revalidate directive of their own.
+// Responses are also refreshed ahead when they're nearing expiry.
+func WithStaleWhileRevalidate(d time.Duration) Option {
+	return func(f *RemoteFile) error {
+		f.staleWhileRevalidate = d
+
+		return nil
+	}
+}
+
+// WithCacheRefreshConcurrency sets the number of background cache refreshes
+// that run at the same time, defaults to DefaultRefreshConcurrency
+func WithCacheRefreshConcurrency(n int) Option {
+	return func(f *RemoteFile) error {
+		if n < 1 {
+			n = 1
+		}
+
+		f.refreshConcurrency = n
+
+		return nil
+	}
+}
+
+// WithCacheRefreshHook sets a function that's called with the result of
+// every background cache refresh
+func WithCacheRefreshHook(fn func(CacheRefresh)) Option {
+	return func(f *RemoteFile) error {
+		f.onCacheRefresh = fn
+
+		return nil
+	}
+}
+
+// WithChaos randomly delays, truncates or fails a fraction of the chunk
+// requests, for testing how an application handles failing downloads
+func WithChaos(config ChaosConfig) Option {
+	return func(f *RemoteFile) error {
+		f.transportWrappers = append(f.transportWrappers, func(rt http.RoundTripper) http.RoundTripper {
+			return &chaosTransport{
+				base:     rt,
+				config:   config,
+				attempts: map[string]int{},
+			}
+		})
+
+		return nil
+	}
+}
+
+// WithPoliteness identifies the requests with the politeness' user agent,
+// follows the host's robots.txt and spaces the requests to the host
+func WithPoliteness(p *Politeness) Option {
+	return func(f *RemoteFile) error {
+		f.transportWrappers = append(f.transportWrappers, func(rt http.RoundTripper) http.RoundTripper {
+			return &politeTransport{
+				base:   rt,
+				polite: p,
+			}
+		})
+
+		return nil
+	}
+}
+
+// WithTransportWrapper wraps the client's transport, e.g. for adding
+// authentication to both the preflight and all chunk requests.
+// Wrappers are applied in order after the other transport options.
+func WithTransportWrapper(wrap func(http.RoundTripper) http.RoundTripper) Option {
+	return func(f *RemoteFile) error {
+		f.transportWrappers = append(f.transportWrappers, wrap)
+
+		return nil
+	}
+}
+
+// WithScheduler sets the function creating the scheduler that decides the
+// order in which the chunks are fetched
+func WithScheduler(newScheduler NewSchedulerFunc) Option {
+	return func(f *RemoteFile) error {
+		f.newScheduler = newScheduler
+
+		return nil
+	}
+}
+
+// WithMetadataCache reuses the metadata of files in the cache, so opening a
+// file again within the cache's time to live skips the HEAD request.
+// Share the cache between downloads by passing the same MetadataCache.
+func WithMetadataCache(cache *MetadataCache) Option {
+	return func(f *RemoteFile) error {
+		f.metadataCache = cache
+
+		return nil
+	}
+}
+
+// WithAutoTune measures the origin on the first download from its host and
+// stores the derived concurrency and chunk size in the store, so later
+// downloads from the host start with them. The stored settings replace
+// the ones set with WithConcurrency and WithChunkSize.
+func WithAutoTune(store Cache) Option {
+	return func(f *RemoteFile) error {
+		f.tuneStore = store
+
+		return nil
+	}
+}
+
+// WithMaxSize aborts with ErrTooLarge before transferring anything when the
+// size reported by the server exceeds the given number of bytes
+func WithMaxSize(n int64) Option {
+	return func(f *RemoteFile) error {
+		f.maxSize = n
+
+		return nil
+	}
+}
+
+// WithSize sets the size of the file when it's known already, from a
+// manifest or a listing, so no HEAD request is made before downloading.
+// Without the HEAD request the content type isn't known up front and the
+// server is assumed to support ranges. A negative size is ignored.
+func WithSize(n int64) Option {
+	return func(f *RemoteFile) error {
+		f.knownSize, f.sizeKnown = n, n >= 0
+
+		return nil
+	}
+}
+
+// WithTransferLimit fails the download with ErrTransferLimit once more than
+// the given number of bytes are received, whatever size the server reported
+func WithTransferLimit(n int64) Option {
+	return func(f *RemoteFile) error {
+		f.transferLimit = n
+
+		return nil
+	}
+}
+
+// WithAllowedContentTypes only allows files with one of the given media
+// types, like "image/png", or wildcards like "image/*". The type reported
+// by the preflight is checked before downloading, files reported as
+// application/octet-stream or without a type are checked against the type
+// sniffed from their first bytes instead. Other types fail with a
+// ContentTypeError.
+func WithAllowedContentTypes(types ...string) Option {
+	return func(f *RemoteFile) error {
+		f.allowedTypes = append(f.allowedTypes, types...)
+
+		return nil
+	}
+}
+
+// WithInspector passes the file through the inspector as it's downloaded,
+// an inspector returning an error aborts the download before the reader
+// sees the rejected bytes
+func WithInspector(ins Inspector) Option {
+	return func(f *RemoteFile) error {
+		f.inspectors = append(f.inspectors, ins)
+
+		return nil
+	}
+}
+
 // WithConcurrency sets the concurrency limit for this request
 func WithConcurrency(c int) Option {
-	return func(f *remoteFile) error {
+	return func(f *RemoteFile) error {
 		if c < 1 {
 			c = 1
 		}
@@ -215,21 +933,38 @@ func WithConcurrency(c int) Option {
 
 // WithChuckSize sets the chunksize for the requests
 func WithChunkSize(c int) Option {
-	return func(f *remoteFile) error {
+	return func(f *RemoteFile) error {
 		if c < 1 {
 			c = DefaultChunkSize
 		}
 
-		f.chunkSize = c
+		f.chunkSize = int64(c)
 
 		return nil
 	}
 }
 
-// WithDebug sets the debug flag for debug logs
-func WithDebug() Option {
-	return func(f *remoteFile) error {
-		f.debug = true
+// WithMaxBufferedBytes limits the memory used for chunks fetched ahead of
+// the reader, which are buffered so their connections are released for the
+// next chunks. Without room in the buffer a chunk holds its connection
+// until it's its turn to be read. It's DefaultMaxBufferedBytes by default.
+func WithMaxBufferedBytes(n int64) Option {
+	return func(f *RemoteFile) error {
+		f.maxBuffered = max(n, 0)
+
+		return nil
+	}
+}
+
+// WithPartSize makes DownloadFile split the file into numbered part files
+// of the given size, together with a manifest describing the parts
+func WithPartSize(size int64) Option {
+	return func(f *RemoteFile) error {
+		if size < 0 {
+			size = 0
+		}
+
+		f.partSize = size
 
 		return nil
 	}
@@ -1,15 +1,22 @@
 package httpio_test
 
 import (
+	"bytes"
 	"crypto/sha256"
+	"crypto/tls"
 	"embed"
+	"errors"
 	"fmt"
 	"io"
 	"io/fs"
+	"net"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/jobstoit/httpio"
 )
@@ -58,21 +65,627 @@ func testGet(t *testing.T, name string, file string) {
 	})
 }
 
+func TestOpen(t *testing.T) {
+	var ranged atomic.Int32
+	svr := newTestServerWithHandler(func(h http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Header.Get("X-Test") != "value" {
+				w.WriteHeader(http.StatusForbidden)
+				return
+			}
+
+			if r.Header.Get("Range") != "" {
+				ranged.Add(1)
+			}
+
+			h.ServeHTTP(w, r)
+		})
+	})
+	defer svr.Close()
+
+	remoteFile, err := httpio.Open(svr.URL().JoinPath("assets", "test_5mb").String(),
+		httpio.WithHeader("X-Test", "value"),
+		httpio.WithChunkSize(1024*1024),
+	)
+	if err != nil {
+		t.Fatalf("failed to setup request: %v", err)
+	}
+	defer remoteFile.Close()
+
+	expected, _ := testdata.ReadFile("testdata/test_5mb")
+
+	p := make([]byte, 100)
+	if _, err := remoteFile.ReadAt(p, 1000); err != nil {
+		t.Fatalf("unable to read at: %v", err)
+	}
+
+	if !bytes.Equal(expected[1000:1100], p) {
+		t.Errorf("mismatched content read at")
+	}
+
+	if e, a := int32(1), ranged.Load(); e != a {
+		t.Errorf("expected only the range read at to be requested, got %d requests", a)
+	}
+
+	actual, err := io.ReadAll(remoteFile)
+	if err != nil {
+		t.Fatalf("unable to read file: %v", err)
+	}
+
+	if !bytes.Equal(expected, actual) {
+		t.Errorf("mismatched content")
+	}
+}
+
+func TestGetTLSSessionResumption(t *testing.T) {
+	svr := newTestTLSServer()
+	defer svr.Close()
+
+	u := svr.URL().JoinPath("assets", "test_32mb")
+	remoteFile, err := httpio.Get(u.String(),
+		httpio.WithClient(svr.server.Client()),
+		httpio.WithTLSSessionCache(tls.NewLRUClientSessionCache(0)),
+		httpio.WithChunkSize(1024*1024),
+	)
+	if err != nil {
+		t.Fatalf("failed to setup request: %v", err)
+	}
+
+	if _, err := io.Copy(io.Discard, remoteFile); err != nil {
+		t.Fatalf("unable to read file: %v", err)
+	}
+
+	stats := remoteFile.Stats()
+	if stats.TLSHandshakes < 2 {
+		t.Fatalf("expected multiple handshakes, got %d", stats.TLSHandshakes)
+	}
+
+	if stats.TLSResumed == 0 {
+		t.Errorf("expected resumed handshakes, got none out of %d", stats.TLSHandshakes)
+	}
+}
+
+func TestGetProtocolStats(t *testing.T) {
+	svr := newTestTLSServer()
+	defer svr.Close()
+
+	u := svr.URL().JoinPath("assets", "test_5mb")
+	remoteFile, err := httpio.Get(u.String(),
+		httpio.WithClient(svr.server.Client()),
+		httpio.WithChunkSize(1024*1024),
+		httpio.WithConcurrency(1),
+	)
+	if err != nil {
+		t.Fatalf("failed to setup request: %v", err)
+	}
+
+	if _, err := io.Copy(io.Discard, remoteFile); err != nil {
+		t.Fatalf("unable to read file: %v", err)
+	}
+
+	stats := remoteFile.Stats()
+	if e, a := 5, len(stats.Chunks); e != a {
+		t.Fatalf("expected stats of %d chunks, got %d", e, a)
+	}
+
+	for _, chunk := range stats.Chunks {
+		if chunk.Protocol != "HTTP/1.1" || chunk.TLSVersion != "TLS 1.3" {
+			t.Errorf("unexpected protocol details of chunk %d: %+v", chunk.Chunk.Index, chunk)
+		}
+	}
+
+	if !stats.Chunks[len(stats.Chunks)-1].Reused || stats.ConnectionsReused == 0 {
+		t.Errorf("expected the sequential chunk requests to reuse the connection")
+	}
+
+	if e, a := int64(6), stats.Connections+stats.ConnectionsReused; e != a {
+		t.Errorf("expected %d requests over new or reused connections, got %d", e, a)
+	}
+}
+
+func TestGetTransferStats(t *testing.T) {
+	var failed atomic.Bool
+	svr := newTestServerWithHandler(func(h http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Header.Get("Range") != "" {
+				if r.Header.Get("Range") == "bytes=524288-1048575" && failed.CompareAndSwap(false, true) {
+					w.WriteHeader(http.StatusInternalServerError)
+					return
+				}
+
+				time.Sleep(time.Millisecond * 20)
+			}
+
+			h.ServeHTTP(w, r)
+		})
+	})
+	defer svr.Close()
+
+	u := svr.URL().JoinPath("assets", "test_5mb").String()
+	remoteFile, err := httpio.Get(u,
+		httpio.WithChunkSize(512*1024),
+		httpio.WithConcurrency(2),
+		httpio.WithRetry(1),
+		httpio.WithBackoff(func(int) time.Duration { return 0 }),
+	)
+	if err != nil {
+		t.Fatalf("failed to setup request: %v", err)
+	}
+	defer remoteFile.Close()
+
+	// the statistics are taken while the file is being read
+	done := make(chan struct{})
+	go func() {
+		for {
+			select {
+			case <-done:
+				return
+			default:
+				remoteFile.Stats()
+			}
+		}
+	}()
+
+	half := remoteFile.Size() / 2
+	if _, err := io.CopyN(io.Discard, remoteFile, half); err != nil {
+		t.Fatalf("unable to read file: %v", err)
+	}
+
+	stats := remoteFile.Stats()
+	if e, a := half, stats.BytesDelivered; e != a {
+		t.Errorf("expected %d bytes delivered, got %d", e, a)
+	}
+
+	if stats.BytesDownloaded < half || stats.Throughput <= 0 || stats.Remaining <= 0 {
+		t.Errorf("unexpected statistics halfway the download: %+v", stats)
+	}
+
+	if stats.ActiveChunks > 2 {
+		t.Errorf("expected at most 2 active chunks, got %d", stats.ActiveChunks)
+	}
+
+	if _, err := io.Copy(io.Discard, remoteFile); err != nil {
+		t.Fatalf("unable to read file: %v", err)
+	}
+	close(done)
+
+	stats = remoteFile.Stats()
+	if e, a := remoteFile.Size(), stats.BytesDelivered; e != a {
+		t.Errorf("expected %d bytes delivered, got %d", e, a)
+	}
+
+	if e, a := remoteFile.Size(), stats.BytesDownloaded; e != a {
+		t.Errorf("expected %d bytes downloaded, got %d", e, a)
+	}
+
+	if stats.ActiveChunks != 0 || stats.Retries != 1 {
+		t.Errorf("expected no active chunks and a single retry, got %d and %d", stats.ActiveChunks, stats.Retries)
+	}
+}
+
+func TestGetThrottled(t *testing.T) {
+	var throttled atomic.Int32
+	svr := newTestServerWithHandler(func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Header.Get("Range") != "" && throttled.Add(1) <= 3 {
+				w.WriteHeader(http.StatusTooManyRequests)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	})
+	defer svr.Close()
+
+	u := svr.URL().JoinPath("assets", "test_12mb")
+	remoteFile, err := httpio.Get(u.String(), httpio.WithChunkSize(1024*1024))
+	if err != nil {
+		t.Fatalf("failed to setup request: %v", err)
+	}
+
+	expected, _ := testdata.ReadFile("testdata/test_12mb")
+	actual, err := io.ReadAll(remoteFile)
+	if err != nil {
+		t.Fatalf("unable to read file: %v", err)
+	}
+
+	if !bytes.Equal(expected, actual) {
+		t.Errorf("mismatched content after throttling")
+	}
+
+	if e, a := int64(3), remoteFile.Stats().Throttled; e != a {
+		t.Errorf("expected %d throttled requests, got %d", e, a)
+	}
+}
+
+func TestGetRetryAfter(t *testing.T) {
+	var unavailable atomic.Int32
+	svr := newTestServerWithHandler(func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Header.Get("Range") != "" && unavailable.Add(1) <= 2 {
+				w.Header().Set("Retry-After", "1")
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	})
+	defer svr.Close()
+
+	u := svr.URL().JoinPath("assets", "test_5mb")
+	start := time.Now()
+
+	remoteFile, err := httpio.Get(u.String(), httpio.WithConcurrency(1))
+	if err != nil {
+		t.Fatalf("failed to setup request: %v", err)
+	}
+
+	expected, _ := testdata.ReadFile("testdata/test_5mb")
+	actual, err := io.ReadAll(remoteFile)
+	if err != nil {
+		t.Fatalf("expected the unavailable chunks to be retried: %v", err)
+	}
+
+	if !bytes.Equal(expected, actual) {
+		t.Errorf("mismatched content after waiting for Retry-After")
+	}
+
+	if elapsed := time.Since(start); elapsed < time.Second*2 {
+		t.Errorf("expected the worker to wait for the Retry-After delays, took %s", elapsed)
+	}
+
+	if e, a := int64(2), remoteFile.Stats().Throttled; e != a {
+		t.Errorf("expected %d throttled requests, got %d", e, a)
+	}
+}
+
+func TestGetUnsupportedRangeUnit(t *testing.T) {
+	svr := newTestServerWithHandler(func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Accept-Ranges", "items")
+			w.Header().Set("Content-Length", "5")
+			w.WriteHeader(http.StatusOK)
+		})
+	})
+	defer svr.Close()
+
+	u := svr.URL().JoinPath("items")
+	_, err := httpio.Get(u.String())
+
+	var unitErr *httpio.RangeUnitError
+	if !errors.As(err, &unitErr) {
+		t.Fatalf("expected a range unit error, got: %v", err)
+	}
+
+	if e, a := "items", unitErr.Unit; e != a {
+		t.Errorf("expected unit '%s', got '%s'", e, a)
+	}
+}
+
+func TestGetIPFamily(t *testing.T) {
+	svr := newTestServer()
+	defer svr.Close()
+
+	u := svr.URL().JoinPath("assets", "GitHub_logo.png")
+
+	if _, err := httpio.Get(u.String(), httpio.WithIPFamily(httpio.IPv6Only)); err == nil {
+		t.Errorf("expected an error connecting to an IPv4 address over IPv6")
+	}
+
+	u.Host = net.JoinHostPort("localhost", u.Port())
+	remoteFile, err := httpio.Get(u.String(), httpio.WithIPFamily(httpio.IPv4Preferred))
+	if err != nil {
+		t.Fatalf("failed to setup request: %v", err)
+	}
+
+	if _, err := io.Copy(io.Discard, remoteFile); err != nil {
+		t.Errorf("unable to read file: %v", err)
+	}
+}
+
+func TestGetLocalAddr(t *testing.T) {
+	var remoteHosts sync.Map
+	svr := newTestServerWithHandler(func(h http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			host, _, _ := net.SplitHostPort(r.RemoteAddr)
+			remoteHosts.Store(host, struct{}{})
+			h.ServeHTTP(w, r)
+		})
+	})
+	defer svr.Close()
+
+	// any loopback address other than the default source address makes
+	// the binding observable, where the platform supports it
+	local := "127.0.0.2"
+	if l, err := net.Listen("tcp", net.JoinHostPort(local, "0")); err != nil {
+		local = "127.0.0.1"
+	} else {
+		l.Close()
+	}
+
+	u := svr.URL().JoinPath("assets", "GitHub_logo.png")
+	u.Host = net.JoinHostPort("localhost", u.Port())
+
+	remoteFile, err := httpio.Get(u.String(),
+		httpio.WithLocalAddr(local),
+		httpio.WithIPFamily(httpio.IPv6Preferred),
+	)
+	if err != nil {
+		t.Fatalf("failed to setup request: %v", err)
+	}
+
+	if _, err := io.Copy(io.Discard, remoteFile); err != nil {
+		t.Errorf("unable to read file: %v", err)
+	}
+
+	remoteHosts.Range(func(host, _ any) bool {
+		if host != local {
+			t.Errorf("expected requests from the bound address %s, got one from '%s'", local, host)
+		}
+
+		return true
+	})
+
+	if _, ok := remoteHosts.Load(local); !ok {
+		t.Errorf("expected requests from the bound address %s", local)
+	}
+
+	if _, err := httpio.Get(u.String(), httpio.WithLocalAddr("not-an-interface")); err == nil {
+		t.Errorf("expected an error for an unknown local address")
+	}
+}
+
+func TestGetMaxSize(t *testing.T) {
+	var ranged atomic.Int32
+	svr := newTestServerWithHandler(func(h http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Header.Get("Range") != "" {
+				ranged.Add(1)
+			}
+
+			h.ServeHTTP(w, r)
+		})
+	})
+	defer svr.Close()
+
+	u := svr.URL().JoinPath("assets", "test_5mb").String()
+
+	if _, err := httpio.Get(u, httpio.WithMaxSize(1024*1024)); !errors.Is(err, httpio.ErrTooLarge) {
+		t.Errorf("expected ErrTooLarge, got: %v", err)
+	}
+
+	if ranged.Load() != 0 {
+		t.Errorf("expected nothing to be transferred, got %d range requests", ranged.Load())
+	}
+
+	remoteFile, err := httpio.Get(u, httpio.WithMaxSize(1024*1024*5))
+	if err != nil {
+		t.Fatalf("expected a file of the maximum size to be allowed: %v", err)
+	}
+
+	if _, err := io.Copy(io.Discard, remoteFile); err != nil {
+		t.Errorf("unable to read file: %v", err)
+	}
+}
+
+// patternReaderAt is a file of any size whose byte at offset n is n%251
+type patternReaderAt struct{}
+
+func (patternReaderAt) ReadAt(p []byte, off int64) (int, error) {
+	for i := range p {
+		p[i] = byte((off + int64(i)) % 251)
+	}
+
+	return len(p), nil
+}
+
+func TestGetLargeFile(t *testing.T) {
+	const size = 5 << 30
+
+	svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.ServeContent(w, r, "large", time.Time{}, io.NewSectionReader(patternReaderAt{}, 0, size))
+	}))
+	defer svr.Close()
+
+	remoteFile, err := httpio.Get(svr.URL)
+	if err != nil {
+		t.Fatalf("failed to setup request: %v", err)
+	}
+	defer remoteFile.Close()
+
+	if remoteFile.Size() != size {
+		t.Fatalf("expected size %d, got %d", int64(size), remoteFile.Size())
+	}
+
+	if _, err := remoteFile.Seek(-16, io.SeekEnd); err != nil {
+		t.Fatalf("unable to seek: %v", err)
+	}
+
+	tail, err := io.ReadAll(remoteFile)
+	if err != nil {
+		t.Fatalf("unable to read file: %v", err)
+	}
+
+	expected := make([]byte, 16)
+	_, _ = patternReaderAt{}.ReadAt(expected, size-16)
+
+	if !bytes.Equal(tail, expected) {
+		t.Errorf("expected tail %v, got %v", expected, tail)
+	}
+}
+
+func TestGetHeadContentRange(t *testing.T) {
+	data := bytes.Repeat([]byte("0123456789"), 1000)
+
+	svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodHead {
+			// some servers answer every request with a range, reporting
+			// the size of the file only in the Content-Range
+			w.Header().Set("Accept-Ranges", "bytes")
+			w.Header().Set("Content-Range", fmt.Sprintf("bytes 0-0/%d", len(data)))
+			w.Header().Set("Content-Length", "1")
+			w.WriteHeader(http.StatusPartialContent)
+
+			return
+		}
+
+		http.ServeContent(w, r, "file", time.Time{}, bytes.NewReader(data))
+	}))
+	defer svr.Close()
+
+	remoteFile, err := httpio.Get(svr.URL, httpio.WithChunkSize(1024))
+	if err != nil {
+		t.Fatalf("failed to setup request: %v", err)
+	}
+	defer remoteFile.Close()
+
+	if remoteFile.Size() != int64(len(data)) {
+		t.Errorf("expected size %d, got %d", len(data), remoteFile.Size())
+	}
+
+	body, err := io.ReadAll(remoteFile)
+	if err != nil {
+		t.Fatalf("unable to read file: %v", err)
+	}
+
+	if !bytes.Equal(body, data) {
+		t.Errorf("expected %d bytes of the file, got %d", len(data), len(body))
+	}
+}
+
+func TestGetWithSize(t *testing.T) {
+	var heads atomic.Int32
+	svr := newTestServerWithHandler(func(h http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method == http.MethodHead {
+				heads.Add(1)
+			}
+
+			h.ServeHTTP(w, r)
+		})
+	})
+	defer svr.Close()
+
+	expected, err := testdata.ReadFile("testdata/test_5mb")
+	if err != nil {
+		t.Fatalf("cannot find file in testdata: %v", err)
+	}
+
+	u := svr.URL().JoinPath("assets", "test_5mb").String()
+	remoteFile, err := httpio.Get(u, httpio.WithSize(int64(len(expected))), httpio.WithChunkSize(1024*1024))
+	if err != nil {
+		t.Fatalf("failed to setup request: %v", err)
+	}
+	defer remoteFile.Close()
+
+	body, err := io.ReadAll(remoteFile)
+	if err != nil {
+		t.Fatalf("unable to read file: %v", err)
+	}
+
+	if !bytes.Equal(body, expected) {
+		t.Errorf("expected %d bytes of the file, got %d", len(expected), len(body))
+	}
+
+	if heads.Load() != 0 {
+		t.Errorf("expected no HEAD requests, got %d", heads.Load())
+	}
+}
+
+func TestGetTransferLimit(t *testing.T) {
+	svr := newTestServer()
+	defer svr.Close()
+
+	u := svr.URL().JoinPath("assets", "test_5mb").String()
+
+	remoteFile, err := httpio.Get(u, httpio.WithTransferLimit(1024*1024), httpio.WithChunkSize(1024*256))
+	if err != nil {
+		t.Fatalf("failed to setup request: %v", err)
+	}
+
+	n, err := io.Copy(io.Discard, remoteFile)
+	if !errors.Is(err, httpio.ErrTransferLimit) {
+		t.Errorf("expected ErrTransferLimit, got: %v", err)
+	}
+
+	if n > 1024*1024 {
+		t.Errorf("expected at most the limit to be read, got %d bytes", n)
+	}
+
+	remoteFile, err = httpio.Get(u, httpio.WithTransferLimit(1024*1024*5))
+	if err != nil {
+		t.Fatalf("failed to setup request: %v", err)
+	}
+
+	if _, err := io.Copy(io.Discard, remoteFile); err != nil {
+		t.Errorf("expected a download within the limit to succeed: %v", err)
+	}
+}
+
+func TestDetectContentType(t *testing.T) {
+	svr := newTestServer()
+	defer svr.Close()
+
+	u := svr.URL().JoinPath("assets", "GitHub_logo.png")
+	remoteFile, err := httpio.Get(u.String())
+	if err != nil {
+		t.Fatalf("failed to setup request: %v", err)
+	}
+
+	contentType, err := remoteFile.DetectContentType()
+	if err != nil {
+		t.Fatalf("unable to detect content type: %v", err)
+	}
+
+	if e, a := "image/png", contentType; e != a {
+		t.Errorf("expected content type '%s', got '%s'", e, a)
+	}
+
+	if _, err := remoteFile.Peek(-1); err == nil {
+		t.Error("expected peeking a negative count to fail")
+	}
+
+	expected, _ := testdata.ReadFile("testdata/GitHub_logo.png")
+	actual, err := io.ReadAll(remoteFile)
+	if err != nil {
+		t.Fatalf("unable to read file: %v", err)
+	}
+
+	if !bytes.Equal(expected, actual) {
+		t.Errorf("expected the peeked bytes to be part of the read content")
+	}
+}
+
 type testServer struct {
 	server *httptest.Server
 }
 
 func newTestServer() *testServer {
-	ts := &testServer{}
+	return &testServer{
+		server: httptest.NewServer(newTestMux()),
+	}
+}
 
+func newTestServerWithHandler(wrap func(http.Handler) http.Handler) *testServer {
+	return &testServer{
+		server: httptest.NewServer(wrap(newTestMux())),
+	}
+}
+
+func newTestTLSServer() *testServer {
+	return &testServer{
+		server: httptest.NewTLSServer(newTestMux()),
+	}
+}
+
+func newTestMux() *http.ServeMux {
 	assets, _ := fs.Sub(testdata, "testdata")
 
 	mux := http.NewServeMux()
 	mux.Handle("GET /assets/", http.StripPrefix("/assets/", http.FileServerFS(assets)))
 
-	ts.server = httptest.NewServer(mux)
-
-	return ts
+	return mux
 }
 
 func (s *testServer) Close() {
@@ -0,0 +1,49 @@
+package httpio
+
+import "io"
+
+// Inspector inspects a file as it's downloaded, it sees every byte of the
+// file once and in order before the reader does
+type Inspector interface {
+	// Inspect is called with the next bytes of the file at the given
+	// offset, returning an error aborts the download with it. The bytes
+	// are only valid during the call.
+	Inspect(offset int64, p []byte) error
+
+	// Done is called once the whole file was inspected before the reader
+	// sees the end of the file, returning an error aborts the download
+	// with it.
+	Done() error
+}
+
+// inspectWriter passes the bytes written to the inspectors before writing
+// them, the engine writes in order and never concurrently
+type inspectWriter struct {
+	wr         io.Writer
+	inspectors []Inspector
+	offset     int64
+}
+
+func (w *inspectWriter) Write(p []byte) (int, error) {
+	for _, ins := range w.inspectors {
+		if err := ins.Inspect(w.offset, p); err != nil {
+			return 0, err
+		}
+	}
+
+	n, err := w.wr.Write(p)
+	w.offset += int64(n)
+
+	return n, err
+}
+
+// done reports the end of the file to the inspectors
+func (w *inspectWriter) done() error {
+	for _, ins := range w.inspectors {
+		if err := ins.Done(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
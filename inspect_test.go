@@ -0,0 +1,104 @@
+package httpio_test
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"errors"
+	"hash"
+	"io"
+	"testing"
+
+	"github.com/jobstoit/httpio"
+)
+
+type hashInspector struct {
+	hash   hash.Hash
+	offset int64
+	done   bool
+	err    error
+	limit  int64
+}
+
+func (h *hashInspector) Inspect(offset int64, p []byte) error {
+	if offset != h.offset {
+		return errors.New("inspected out of order")
+	}
+
+	if h.limit > 0 && offset+int64(len(p)) > h.limit {
+		return h.err
+	}
+
+	h.offset += int64(len(p))
+	h.hash.Write(p)
+
+	return nil
+}
+
+func (h *hashInspector) Done() error {
+	h.done = true
+
+	if h.limit == 0 {
+		return h.err
+	}
+
+	return nil
+}
+
+func TestGetInspector(t *testing.T) {
+	svr := newTestServer()
+	defer svr.Close()
+
+	u := svr.URL().JoinPath("assets", "test_5mb").String()
+	expected, _ := testdata.ReadFile("testdata/test_5mb")
+
+	ins := &hashInspector{hash: sha256.New()}
+
+	remoteFile, err := httpio.Get(u, httpio.WithInspector(ins), httpio.WithChunkSize(1024*256))
+	if err != nil {
+		t.Fatalf("unable to get file: %v", err)
+	}
+
+	if _, err := io.Copy(io.Discard, remoteFile); err != nil {
+		t.Fatalf("unable to read file: %v", err)
+	}
+
+	sum := sha256.Sum256(expected)
+	if !ins.done || !bytes.Equal(ins.hash.Sum(nil), sum[:]) {
+		t.Errorf("expected the inspector to see the whole file")
+	}
+}
+
+func TestGetInspectorAbort(t *testing.T) {
+	svr := newTestServer()
+	defer svr.Close()
+
+	u := svr.URL().JoinPath("assets", "test_5mb").String()
+	errRejected := errors.New("rejected")
+
+	ins := &hashInspector{hash: sha256.New(), err: errRejected, limit: 1024 * 1024}
+
+	remoteFile, err := httpio.Get(u, httpio.WithInspector(ins), httpio.WithChunkSize(1024*256))
+	if err != nil {
+		t.Fatalf("unable to get file: %v", err)
+	}
+
+	n, err := io.Copy(io.Discard, remoteFile)
+	if !errors.Is(err, errRejected) {
+		t.Errorf("expected the inspector's error, got: %v", err)
+	}
+
+	if n > ins.limit {
+		t.Errorf("expected at most %d bytes to be read, got: %d", ins.limit, n)
+	}
+
+	ins = &hashInspector{hash: sha256.New(), err: errRejected}
+
+	remoteFile, err = httpio.Get(u, httpio.WithInspector(ins))
+	if err != nil {
+		t.Fatalf("unable to get file: %v", err)
+	}
+
+	if _, err := io.Copy(io.Discard, remoteFile); !errors.Is(err, errRejected) {
+		t.Errorf("expected the inspector's error at the end, got: %v", err)
+	}
+}
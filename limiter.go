@@ -0,0 +1,129 @@
+package httpio
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+const (
+	maxThrottleRetries = 8
+	throttleBaseDelay  = time.Millisecond * 250
+	throttleMaxDelay   = time.Second * 30
+)
+
+// limiter limits the number of concurrent chunk requests. The limit is
+// halved when the server throttles the requests and slowly ramps back up to
+// the configured maximum as chunks succeed.
+type limiter struct {
+	mu        sync.Mutex
+	wake      chan struct{}
+	max       int
+	limit     int
+	active    int
+	successes int
+	// adaptive is set when the limit is tuned to the throughput
+	adaptive *adaptive
+}
+
+func newLimiter(max int) *limiter {
+	return &limiter{
+		wake:  make(chan struct{}),
+		max:   max,
+		limit: max,
+	}
+}
+
+// acquire blocks until a slot is available or the context is done
+func (l *limiter) acquire(ctx context.Context) error {
+	for {
+		l.mu.Lock()
+		if l.active < l.limit {
+			l.active++
+			l.mu.Unlock()
+
+			return nil
+		}
+		wake := l.wake
+		l.mu.Unlock()
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-wake:
+		}
+	}
+}
+
+// release frees a slot acquired with acquire
+func (l *limiter) release() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.active--
+	l.broadcast()
+}
+
+// throttle halves the limit, with a minimum of one
+func (l *limiter) throttle() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.limit = max(l.limit/2, 1)
+	l.successes = 0
+}
+
+// succeed records a successful chunk and raises the limit by one after
+// a full round of successes at the current limit, an adaptive limit is
+// raised by complete instead
+func (l *limiter) succeed() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.limit >= l.max || l.adaptive != nil {
+		return
+	}
+
+	l.successes++
+	if l.successes >= l.limit {
+		l.limit++
+		l.successes = 0
+		l.broadcast()
+	}
+}
+
+// current returns the current limit
+func (l *limiter) current() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return l.limit
+}
+
+func (l *limiter) broadcast() {
+	close(l.wake)
+	l.wake = make(chan struct{})
+}
+
+// throttleDelay returns the exponential backoff delay for the given attempt
+func throttleDelay(attempt int) time.Duration {
+	delay := throttleBaseDelay << attempt
+	if delay <= 0 || delay > throttleMaxDelay {
+		delay = throttleMaxDelay
+	}
+
+	return delay
+}
+
+// sleep waits for the given duration or until the context is done
+func sleep(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
@@ -0,0 +1,106 @@
+package httpio
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestLimiterThrottle(t *testing.T) {
+	l := newLimiter(8)
+
+	l.throttle()
+	l.throttle()
+	if e, a := 2, l.current(); e != a {
+		t.Fatalf("expected limit %d after throttling, got %d", e, a)
+	}
+
+	for range 2 {
+		l.succeed()
+	}
+	if e, a := 3, l.current(); e != a {
+		t.Fatalf("expected limit %d after a round of successes, got %d", e, a)
+	}
+
+	for range 100 {
+		l.succeed()
+	}
+	if e, a := 8, l.current(); e != a {
+		t.Errorf("expected limit to be capped at %d, got %d", e, a)
+	}
+}
+
+func TestLimiterAcquire(t *testing.T) {
+	l := newLimiter(1)
+	ctx := context.Background()
+
+	if err := l.acquire(ctx); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, time.Millisecond*10)
+	defer cancel()
+
+	if err := l.acquire(ctx); err == nil {
+		t.Fatalf("expected acquire to block when the limit is reached")
+	}
+
+	l.release()
+	if err := l.acquire(context.Background()); err != nil {
+		t.Errorf("unexpected error after release: %v", err)
+	}
+}
+
+func TestLimiterAdaptive(t *testing.T) {
+	now := time.Unix(0, 0)
+	l := newLimiter(8)
+	l.adapt(func() time.Time { return now })
+
+	if e, a := adaptiveStart, l.current(); e != a {
+		t.Fatalf("expected to start with limit %d, got %d", e, a)
+	}
+
+	// round runs a round of chunks of a MB each, taking the given time
+	round := func(d time.Duration) {
+		now = now.Add(d)
+		for range l.current() {
+			l.complete(1 << 20)
+		}
+	}
+
+	// the throughput scales with the limit up to 4
+	round(time.Second)
+	round(time.Second)
+	round(time.Second)
+	if e, a := 5, l.current(); e != a {
+		t.Fatalf("expected limit %d while the throughput scales, got %d", e, a)
+	}
+
+	// 5 at once takes as long as 4, so the raise is undone
+	round(time.Second * 5 / 4)
+	if e, a := 4, l.current(); e != a {
+		t.Fatalf("expected limit %d once the throughput stopped scaling, got %d", e, a)
+	}
+
+	for range adaptiveHold {
+		round(time.Second)
+	}
+	if e, a := 4, l.current(); e != a {
+		t.Fatalf("expected limit %d to be held, got %d", e, a)
+	}
+
+	round(time.Second)
+	if e, a := 5, l.current(); e != a {
+		t.Fatalf("expected limit %d to be tried again, got %d", e, a)
+	}
+
+	l.fail()
+	if e, a := 4, l.current(); e != a {
+		t.Errorf("expected limit %d after a failure, got %d", e, a)
+	}
+
+	l.succeed()
+	if e, a := 4, l.current(); e != a {
+		t.Errorf("expected succeed to leave the adaptive limit at %d, got %d", e, a)
+	}
+}
@@ -0,0 +1,84 @@
+package httpio
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// sensitiveHeaders are the headers that aren't sent to a redirected URL on
+// another domain, the way the http.Client drops them on redirects
+var sensitiveHeaders = []string{headerAuthorization, "Www-Authenticate", "Cookie", "Cookie2"}
+
+// URL returns the URL of the file after the redirects of the request for
+// its metadata, which the chunks are requested from directly, or the URL
+// given by the refresher of WithURLRefresher. It's the requested URL when
+// the metadata was given with WithSize or cached.
+func (f *RemoteFile) URL() string {
+	if location := f.location.Load(); location != nil {
+		return location.String()
+	}
+
+	return f.req.URL.String()
+}
+
+// Header returns the headers of the response to the request for the
+// metadata of the file, which are empty when the metadata was given with
+// WithSize or cached
+func (f *RemoteFile) Header() http.Header {
+	if f.header == nil {
+		return http.Header{}
+	}
+
+	return f.header.Clone()
+}
+
+// located records the URL the request for the metadata ended up at and
+// the headers of its response
+func (f *RemoteFile) located(res *http.Response) {
+	f.header = res.Header.Clone()
+
+	if res.Request == nil || res.Request.URL == nil || res.Request.URL.String() == f.req.URL.String() {
+		return
+	}
+
+	f.location.Store(res.Request.URL)
+	f.logDebug("redirected", "location", res.Request.URL.String())
+}
+
+// newRequest returns a request for the file at the URL the redirects of
+// the request for its metadata lead to, skipping the redirects
+func (f *RemoteFile) newRequest(ctx context.Context) *http.Request {
+	current := f.location.Load()
+	if current == nil {
+		return f.req.Clone(ctx)
+	}
+
+	return f.requestAt(ctx, current)
+}
+
+// requestAt returns a request for the file at the given URL, without the
+// credentials when the URL is on another domain
+func (f *RemoteFile) requestAt(ctx context.Context, u *url.URL) *http.Request {
+	req := f.req.Clone(ctx)
+	location := *u
+	req.URL = &location
+	req.Host = location.Host
+
+	if !isDomainOrSubdomain(location.Hostname(), f.req.URL.Hostname()) {
+		for _, name := range sensitiveHeaders {
+			req.Header.Del(name)
+		}
+	}
+
+	return req
+}
+
+// isDomainOrSubdomain reports whether sub is the same domain as parent or
+// a subdomain of it
+func isDomainOrSubdomain(sub, parent string) bool {
+	sub, parent = strings.ToLower(sub), strings.ToLower(parent)
+
+	return sub == parent || strings.HasSuffix(sub, "."+parent)
+}
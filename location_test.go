@@ -0,0 +1,97 @@
+package httpio_test
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/jobstoit/httpio"
+)
+
+func TestGetRedirected(t *testing.T) {
+	var redirects atomic.Int32
+	svr := newTestServerWithHandler(func(h http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if strings.HasPrefix(r.URL.Path, "/moved/") {
+				redirects.Add(1)
+				http.Redirect(w, r, "/assets/"+strings.TrimPrefix(r.URL.Path, "/moved/"), http.StatusFound)
+				return
+			}
+
+			w.Header().Set("X-Served-By", "origin")
+			h.ServeHTTP(w, r)
+		})
+	})
+	defer svr.Close()
+
+	u := svr.URL().JoinPath("moved", "test_5mb").String()
+	expected, _ := testdata.ReadFile("testdata/test_5mb")
+
+	remoteFile, err := httpio.Get(u, httpio.WithChunkSize(1024*1024))
+	if err != nil {
+		t.Fatalf("unable to get file: %v", err)
+	}
+	defer remoteFile.Close()
+
+	if final := svr.URL().JoinPath("assets", "test_5mb").String(); remoteFile.URL() != final {
+		t.Errorf("expected url '%s', got '%s'", final, remoteFile.URL())
+	}
+
+	if v := remoteFile.Header().Get("X-Served-By"); v != "origin" {
+		t.Errorf("expected the headers of the response, got: '%s'", v)
+	}
+
+	actual, err := io.ReadAll(remoteFile)
+	if err != nil {
+		t.Fatalf("unable to read file: %v", err)
+	}
+
+	if !bytes.Equal(expected, actual) {
+		t.Error("mismatched content")
+	}
+
+	if n := redirects.Load(); n != 1 {
+		t.Errorf("expected only the metadata request to be redirected, got %d redirects", n)
+	}
+}
+
+func TestGetRedirectedCrossDomain(t *testing.T) {
+	var leaked atomic.Int32
+	origin := newTestServerWithHandler(func(h http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Header.Get("Authorization") != "" {
+				leaked.Add(1)
+			}
+
+			h.ServeHTTP(w, r)
+		})
+	})
+	defer origin.Close()
+
+	// the same server under another host name
+	location := origin.URL()
+	location.Host = "localhost:" + location.Port()
+	location = location.JoinPath("assets", "test_5mb")
+
+	svr := newTestServerWithHandler(func(http.Handler) http.Handler {
+		return http.RedirectHandler(location.String(), http.StatusFound)
+	})
+	defer svr.Close()
+
+	remoteFile, err := httpio.Get(svr.URL().String(), httpio.WithChunkSize(1024*1024), httpio.WithHeader("Authorization", "Bearer secret"))
+	if err != nil {
+		t.Fatalf("unable to get file: %v", err)
+	}
+	defer remoteFile.Close()
+
+	if _, err := io.Copy(io.Discard, remoteFile); err != nil {
+		t.Fatalf("unable to read file: %v", err)
+	}
+
+	if n := leaked.Load(); n != 0 {
+		t.Errorf("expected the authorization not to be sent to another domain, got it %d times", n)
+	}
+}
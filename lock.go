@@ -0,0 +1,20 @@
+package httpio
+
+import "os"
+
+// lockFile opens the file at the given path for writing without truncating
+// it and takes an exclusive advisory lock on it, which is released when the
+// file is closed. It fails with ErrLocked when another download holds it.
+func lockFile(path string) (*os.File, error) {
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0o644)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := lock(file); err != nil {
+		file.Close()
+		return nil, err
+	}
+
+	return file, nil
+}
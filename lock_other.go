@@ -0,0 +1,10 @@
+//go:build !(darwin || dragonfly || freebsd || linux || netbsd || openbsd || windows)
+
+package httpio
+
+import "os"
+
+// lock is a no-op on platforms without advisory file locks
+func lock(*os.File) error {
+	return nil
+}
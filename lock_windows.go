@@ -0,0 +1,41 @@
+//go:build windows
+
+package httpio
+
+import (
+	"errors"
+	"os"
+	"syscall"
+	"unsafe"
+)
+
+const (
+	lockfileFailImmediately = 0x1
+	lockfileExclusiveLock   = 0x2
+
+	errorLockViolation syscall.Errno = 33
+)
+
+var procLockFileEx = syscall.NewLazyDLL("kernel32.dll").NewProc("LockFileEx")
+
+func lock(file *os.File) error {
+	var overlapped syscall.Overlapped
+
+	ok, _, err := procLockFileEx.Call(
+		file.Fd(),
+		lockfileExclusiveLock|lockfileFailImmediately,
+		0,
+		0xffffffff,
+		0xffffffff,
+		uintptr(unsafe.Pointer(&overlapped)),
+	)
+	if ok != 0 {
+		return nil
+	}
+
+	if errors.Is(err, errorLockViolation) {
+		return ErrLocked
+	}
+
+	return err
+}
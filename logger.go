@@ -0,0 +1,33 @@
+package httpio
+
+import (
+	"log/slog"
+	"os"
+)
+
+// WithLogger logs the events of the download at debug level to the logger,
+// with the url of the file and attributes like the range, status and
+// duration of the requests
+func WithLogger(logger *slog.Logger) Option {
+	return func(f *RemoteFile) error {
+		f.logger = logger
+
+		return nil
+	}
+}
+
+// WithDebug logs the events of the download to stderr.
+//
+// Deprecated: use WithLogger with a logger enabled for debug level.
+func WithDebug() Option {
+	return WithLogger(slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelDebug})))
+}
+
+// logDebug logs the event at debug level when a logger is set
+func (f *RemoteFile) logDebug(msg string, args ...any) {
+	if f.logger == nil {
+		return
+	}
+
+	f.logger.Debug(msg, append([]any{"url", f.req.URL.String()}, args...)...)
+}
@@ -0,0 +1,80 @@
+package httpio_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"sync"
+	"testing"
+
+	"github.com/jobstoit/httpio"
+)
+
+// syncBuffer is a buffer safe for the concurrent writes of a logger
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.buf.Write(p)
+}
+
+func TestGetLogger(t *testing.T) {
+	svr := newTestServer()
+	defer svr.Close()
+
+	out := &syncBuffer{}
+	logger := slog.New(slog.NewJSONHandler(out, &slog.HandlerOptions{Level: slog.LevelDebug}))
+
+	u := svr.URL().JoinPath("assets", "test_5mb").String()
+	remoteFile, err := httpio.Get(u, httpio.WithChunkSize(1024*1024), httpio.WithLogger(logger))
+	if err != nil {
+		t.Fatalf("failed to setup request: %v", err)
+	}
+	defer remoteFile.Close()
+
+	if _, err := io.Copy(io.Discard, remoteFile); err != nil {
+		t.Fatalf("unable to read file: %v", err)
+	}
+
+	out.mu.Lock()
+	defer out.mu.Unlock()
+
+	events := map[string]int{}
+	dec := json.NewDecoder(&out.buf)
+	for dec.More() {
+		var event struct {
+			Level  string
+			Msg    string
+			URL    string
+			Range  string
+			Status int
+		}
+		if err := dec.Decode(&event); err != nil {
+			t.Fatalf("invalid log output: %v", err)
+		}
+
+		if event.Level != "DEBUG" || event.URL != u {
+			t.Errorf("expected debug events with the url, got: %+v", event)
+		}
+
+		if event.Msg == "response" && event.Status != 206 {
+			t.Errorf("expected the responses to be logged with their status, got: %+v", event)
+		}
+
+		if event.Msg == "fetched chunk" && event.Range == "" {
+			t.Errorf("expected the chunks to be logged with their range, got: %+v", event)
+		}
+
+		events[event.Msg]++
+	}
+
+	if events["response"] != 5 || events["fetched chunk"] != 5 {
+		t.Errorf("expected a response and a fetched chunk event per chunk, got: %v", events)
+	}
+}
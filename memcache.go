@@ -0,0 +1,196 @@
+package httpio
+
+import (
+	"container/list"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// WithMemoryCache keeps the most recently fetched chunks of the file in
+// memory, up to the given number of bytes, and serves the range requests
+// they cover from memory. Seeking back and repeated ReadAt calls read the
+// bytes that were just fetched without downloading them again, even when
+// their ranges span several chunks. A size of 0 or less caches nothing.
+func WithMemoryCache(maxBytes int64) Option {
+	return func(f *RemoteFile) error {
+		if maxBytes <= 0 {
+			return nil
+		}
+
+		f.transportWrappers = append(f.transportWrappers, func(rt http.RoundTripper) http.RoundTripper {
+			return &memoryCacheTransport{base: rt, lru: newChunkLRU(maxBytes), stats: &f.stats}
+		})
+
+		return nil
+	}
+}
+
+// memoryCacheTransport serves the single range requests of a file from
+// the chunks it fetched before
+type memoryCacheTransport struct {
+	base  http.RoundTripper
+	lru   *chunkLRU
+	stats *stats
+}
+
+func (t *memoryCacheTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	first, last, ok := parseRangeHeader(req.Header.Get(headerRange))
+	if req.Method != http.MethodGet || !ok {
+		return t.base.RoundTrip(req)
+	}
+
+	// the chunks are of the revision the If-Range asks for
+	validator := req.Header.Get(headerIfRange)
+	if header, body, ok := t.lru.get(validator, first, last); ok {
+		t.stats.cacheHits.Add(1)
+
+		return partialResponse(req, header, body), nil
+	}
+
+	res, err := t.base.RoundTrip(req)
+	if err != nil || res.StatusCode != http.StatusPartialContent || res.Header.Get(headerContentEncoding) != "" {
+		return res, err
+	}
+
+	first, last, size, ok := parseContentRange(res.Header.Get(headerContentRange))
+	if !ok || size < 0 {
+		return res, nil
+	}
+
+	res.Body = &chunkCacheBody{
+		ReadCloser: res.Body,
+		length:     last - first + 1,
+		store: func(body []byte) {
+			t.lru.add(&cachedChunk{validator: validator, offset: first, size: size, header: storedHeader(res.Header), body: body})
+		},
+	}
+
+	return res, nil
+}
+
+// parseRangeHeader parses a Range header of a single range with a first
+// and last byte, like the ones of the chunks
+func parseRangeHeader(value string) (first, last int64, ok bool) {
+	spec, ok := strings.CutPrefix(value, rangeUnitBytes+"=")
+	if !ok || strings.Contains(spec, ",") {
+		return 0, 0, false
+	}
+
+	firstStr, lastStr, ok := strings.Cut(spec, "-")
+	if !ok {
+		return 0, 0, false
+	}
+
+	var err error
+	if first, err = strconv.ParseInt(firstStr, 10, 64); err != nil || first < 0 {
+		return 0, 0, false
+	}
+
+	if last, err = strconv.ParseInt(lastStr, 10, 64); err != nil || last < first {
+		return 0, 0, false
+	}
+
+	return first, last, true
+}
+
+// cachedChunk is a chunk kept by a chunkLRU
+type cachedChunk struct {
+	validator string
+	offset    int64
+	size      int64
+	header    http.Header
+	body      []byte
+}
+
+func (c *cachedChunk) covers(validator string, off int64) bool {
+	return c.validator == validator && off >= c.offset && off < c.offset+int64(len(c.body))
+}
+
+// chunkLRU keeps chunks up to a number of bytes, evicting the least
+// recently used chunks first
+type chunkLRU struct {
+	maxBytes int64
+
+	mu     sync.Mutex
+	used   int64
+	chunks *list.List
+}
+
+func newChunkLRU(maxBytes int64) *chunkLRU {
+	return &chunkLRU{maxBytes: maxBytes, chunks: list.New()}
+}
+
+// get returns the headers and the bytes of the range when the chunks
+// cover it, the chunks that are used become the most recently used
+func (l *chunkLRU) get(validator string, first, last int64) (http.Header, []byte, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	var (
+		header http.Header
+		size   int64
+		used   []*list.Element
+	)
+
+	body := make([]byte, 0, last-first+1)
+	for pos := first; pos <= last; {
+		e := l.find(validator, pos)
+		if e == nil {
+			return nil, nil, false
+		}
+
+		c := e.Value.(*cachedChunk)
+		if header == nil {
+			header, size = c.header, c.size
+		}
+
+		end := min(last+1, c.offset+int64(len(c.body)))
+		body = append(body, c.body[pos-c.offset:end-c.offset]...)
+		pos = end
+		used = append(used, e)
+	}
+
+	for _, e := range used {
+		l.chunks.MoveToFront(e)
+	}
+
+	header = header.Clone()
+	header.Set(headerContentRange, fmt.Sprintf("bytes %d-%d/%d", first, last, size))
+
+	return header, body, true
+}
+
+// find returns the element of the chunk covering the offset, or nil
+func (l *chunkLRU) find(validator string, off int64) *list.Element {
+	for e := l.chunks.Front(); e != nil; e = e.Next() {
+		if e.Value.(*cachedChunk).covers(validator, off) {
+			return e
+		}
+	}
+
+	return nil
+}
+
+// add keeps the chunk, evicting the least recently used chunks to stay
+// within the maximum, a chunk larger than the maximum isn't kept
+func (l *chunkLRU) add(c *cachedChunk) {
+	n := int64(len(c.body))
+	if n == 0 || n > l.maxBytes {
+		return
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.chunks.PushFront(c)
+	l.used += n
+
+	for l.used > l.maxBytes {
+		e := l.chunks.Back()
+		l.used -= int64(len(e.Value.(*cachedChunk).body))
+		l.chunks.Remove(e)
+	}
+}
@@ -0,0 +1,120 @@
+package httpio_test
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/jobstoit/httpio"
+)
+
+func newCountingServer(content []byte, ranges *atomic.Int32) *testServer {
+	return newTestServerWithHandler(func(http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Header.Get("Range") != "" {
+				ranges.Add(1)
+			}
+
+			http.ServeContent(w, r, "test_5mb.bin", time.Time{}, bytes.NewReader(content))
+		})
+	})
+}
+
+func TestMemoryCacheSeek(t *testing.T) {
+	expected, _ := testdata.ReadFile("testdata/test_5mb")
+
+	tests := []struct {
+		name     string
+		maxBytes int64
+		cached   bool
+	}{
+		{"cached", 8 * 1024 * 1024, true},
+		{"evicted", 2 * 1024 * 1024, false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var ranges atomic.Int32
+			svr := newCountingServer(expected, &ranges)
+			defer svr.Close()
+
+			remoteFile, err := httpio.Get(svr.URL().JoinPath("test_5mb.bin").String(),
+				httpio.WithChunkSize(1024*1024),
+				httpio.WithMemoryCache(test.maxBytes),
+			)
+			if err != nil {
+				t.Fatalf("failed to setup request: %v", err)
+			}
+			defer remoteFile.Close()
+
+			if _, err := io.Copy(io.Discard, remoteFile); err != nil {
+				t.Fatalf("unable to read file: %v", err)
+			}
+
+			// seeking back to the middle of a chunk spans the cached chunks
+			offset := int64(1024*1024 + 512*1024)
+			if _, err := remoteFile.Seek(offset, io.SeekStart); err != nil {
+				t.Fatalf("unable to seek: %v", err)
+			}
+
+			ranges.Store(0)
+			actual, err := io.ReadAll(remoteFile)
+			if err != nil {
+				t.Fatalf("unable to read file: %v", err)
+			}
+
+			if !bytes.Equal(expected[offset:], actual) {
+				t.Error("mismatched content after seeking back")
+			}
+
+			if n := ranges.Load(); test.cached != (n == 0) {
+				t.Errorf("expected cached %v, got %d range requests", test.cached, n)
+			}
+		})
+	}
+}
+
+func TestMemoryCacheReadAt(t *testing.T) {
+	expected, _ := testdata.ReadFile("testdata/test_5mb")
+
+	var ranges atomic.Int32
+	svr := newCountingServer(expected, &ranges)
+	defer svr.Close()
+
+	files, err := httpio.GetAll(context.Background(), []string{svr.URL().JoinPath("test_5mb.bin").String()},
+		httpio.WithMemoryCache(1024*1024),
+	)
+	if err != nil {
+		t.Fatalf("failed to setup request: %v", err)
+	}
+	remoteFile := files[0]
+	defer remoteFile.Close()
+
+	buf := make([]byte, 4096)
+	for range 3 {
+		if _, err := remoteFile.ReadAt(buf, 2*1024*1024); err != nil {
+			t.Fatalf("unable to read: %v", err)
+		}
+
+		if !bytes.Equal(buf, expected[2*1024*1024:2*1024*1024+4096]) {
+			t.Error("mismatched content")
+		}
+	}
+
+	// a read within the cached range is served too
+	if _, err := remoteFile.ReadAt(buf[:100], 2*1024*1024+1000); err != nil {
+		t.Fatalf("unable to read: %v", err)
+	}
+
+	if n := ranges.Load(); n != 1 {
+		t.Errorf("expected the repeated reads to be served from memory, got %d range requests", n)
+	}
+
+	if hits := remoteFile.Stats().CacheHits; hits != 3 {
+		t.Errorf("expected 3 cache hits but got %d", hits)
+	}
+}
@@ -0,0 +1,194 @@
+package httpio
+
+import (
+	"context"
+	"mime"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	headerContentType        = "Content-Type"
+	headerContentDisposition = "Content-Disposition"
+	headerContentEncoding    = "Content-Encoding"
+)
+
+// Metadata describes a remote file as reported by the server
+type Metadata struct {
+	URL string
+	// Size is the size of the file in bytes, -1 when it's unknown
+	Size         int64
+	ETag         string
+	LastModified string
+	AcceptRanges string
+	ContentType  string
+	// ContentEncoding is the encoding the file is stored with, like gzip
+	ContentEncoding string
+	// Filename is the name suggested by the Content-Disposition, without
+	// any directories, empty when there's none
+	Filename string
+	// Checksums are the checksums of the whole file reported by the server
+	Checksums []Checksum
+}
+
+// newMetadata returns the metadata reported by the headers of the response
+func newMetadata(url string, res *http.Response) *Metadata {
+	return &Metadata{
+		URL:             url,
+		Size:            res.ContentLength,
+		ETag:            res.Header.Get(headerETag),
+		LastModified:    res.Header.Get(headerLastModified),
+		AcceptRanges:    res.Header.Get(headerAcceptRanges),
+		ContentType:     res.Header.Get(headerContentType),
+		ContentEncoding: res.Header.Get(headerContentEncoding),
+		Filename:        dispositionFilename(res.Header.Get(headerContentDisposition)),
+		Checksums:       fileChecksums(res),
+	}
+}
+
+// AcceptsRanges reports whether the file can be requested in ranges, which
+// is assumed unless the server reports otherwise
+func (m *Metadata) AcceptsRanges() bool {
+	return !rangesUnsupported(m)
+}
+
+// dispositionFilename returns the base name of the filename parameter of
+// the given Content-Disposition, preferring the encoded filename* variant
+func dispositionFilename(disposition string) string {
+	_, params, err := mime.ParseMediaType(disposition)
+	if err != nil {
+		return ""
+	}
+
+	name := params["filename"]
+	if i := strings.LastIndexAny(name, `/\`); i >= 0 {
+		name = name[i+1:]
+	}
+
+	if name == "." || name == ".." {
+		return ""
+	}
+
+	return name
+}
+
+// Stat returns the metadata of the file as reported by the server before
+// the download, only the size is known when it was given with WithSize
+func (f *RemoteFile) Stat() *Metadata {
+	meta := *f.meta
+
+	return &meta
+}
+
+// MetadataCache holds the metadata of files for a fixed time to live,
+// it's safe for concurrent use by multiple downloads
+type MetadataCache struct {
+	ttl time.Duration
+
+	mu      sync.Mutex
+	entries map[string]metadataEntry
+}
+
+type metadataEntry struct {
+	meta    Metadata
+	expires time.Time
+}
+
+// NewMetadataCache returns an empty MetadataCache keeping metadata for the given duration
+func NewMetadataCache(ttl time.Duration) *MetadataCache {
+	return &MetadataCache{
+		ttl:     ttl,
+		entries: map[string]metadataEntry{},
+	}
+}
+
+func (c *MetadataCache) get(url string) (*Metadata, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.entries[url]
+	if !ok {
+		return nil, false
+	}
+
+	if time.Now().After(entry.expires) {
+		delete(c.entries, url)
+		return nil, false
+	}
+
+	meta := entry.meta
+
+	return &meta, true
+}
+
+func (c *MetadataCache) set(url string, meta *Metadata) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.entries[url] = metadataEntry{
+		meta:    *meta,
+		expires: time.Now().Add(c.ttl),
+	}
+}
+
+// Invalidate removes the metadata of the file at the given url
+func (c *MetadataCache) Invalidate(url string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	delete(c.entries, url)
+}
+
+// StatResult is the metadata of a single url of StatMany
+type StatResult struct {
+	Metadata *Metadata
+	Err      error
+}
+
+// StatMany requests the metadata of the files at the given urls, running
+// as many HEAD requests at once as set with WithConcurrency. The results
+// are in the order of the urls.
+func StatMany(ctx context.Context, urls []string, opts ...Option) ([]StatResult, error) {
+	cfg, err := sharedConfig(ctx, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]StatResult, len(urls))
+	slots := make(chan struct{}, cfg.concurrency)
+
+	var wg sync.WaitGroup
+	for i, url := range urls {
+		select {
+		case <-ctx.Done():
+			wg.Wait()
+			return nil, ctx.Err()
+		case slots <- struct{}{}:
+		}
+
+		wg.Add(1)
+		go func() {
+			defer func() {
+				<-slots
+				wg.Done()
+			}()
+
+			results[i].Metadata, results[i].Err = stat(ctx, url, opts...)
+		}()
+	}
+	wg.Wait()
+
+	return results, nil
+}
+
+func stat(ctx context.Context, url string, opts ...Option) (*Metadata, error) {
+	f, err := newRemoteFile(ctx, url, opts...)
+	if err != nil {
+		return nil, err
+	}
+	defer f.closeIdleConnections()
+
+	return f.metadata(ctx)
+}
@@ -0,0 +1,168 @@
+package httpio_test
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/jobstoit/httpio"
+)
+
+func TestGetMetadataCache(t *testing.T) {
+	var heads atomic.Int32
+	svr := newTestServerWithHandler(func(h http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method == http.MethodHead {
+				heads.Add(1)
+			}
+
+			h.ServeHTTP(w, r)
+		})
+	})
+	defer svr.Close()
+
+	u := svr.URL().JoinPath("assets", "test_5mb").String()
+	expected, _ := testdata.ReadFile("testdata/test_5mb")
+	cache := httpio.NewMetadataCache(time.Minute)
+
+	get := func() {
+		remoteFile, err := httpio.Get(u, httpio.WithMetadataCache(cache))
+		if err != nil {
+			t.Fatalf("failed to setup request: %v", err)
+		}
+
+		actual, err := io.ReadAll(remoteFile)
+		if err != nil {
+			t.Fatalf("unable to read file: %v", err)
+		}
+
+		if !bytes.Equal(expected, actual) {
+			t.Errorf("mismatched content")
+		}
+	}
+
+	get()
+	get()
+	if e, a := int32(1), heads.Load(); e != a {
+		t.Errorf("expected %d HEAD request, got %d", e, a)
+	}
+
+	cache.Invalidate(u)
+	get()
+	if e, a := int32(2), heads.Load(); e != a {
+		t.Errorf("expected %d HEAD requests after invalidating, got %d", e, a)
+	}
+}
+
+func TestStatMany(t *testing.T) {
+	svr := newTestServer()
+	defer svr.Close()
+
+	urls := []string{
+		svr.URL().JoinPath("assets", "test_5mb").String(),
+		svr.URL().JoinPath("assets", "GitHub_logo.png").String(),
+		"http://[::1]:namedport",
+	}
+
+	results, err := httpio.StatMany(context.Background(), urls, httpio.WithConcurrency(2))
+	if err != nil {
+		t.Fatalf("unable to stat: %v", err)
+	}
+
+	for i, name := range []string{"testdata/test_5mb", "testdata/GitHub_logo.png"} {
+		expected, _ := testdata.ReadFile(name)
+		if results[i].Err != nil {
+			t.Fatalf("unable to stat '%s': %v", urls[i], results[i].Err)
+		}
+
+		if e, a := int64(len(expected)), results[i].Metadata.Size; e != a {
+			t.Errorf("expected size %d of '%s', got %d", e, urls[i], a)
+		}
+	}
+
+	if e, a := "image/png", results[1].Metadata.ContentType; e != a {
+		t.Errorf("expected content type '%s', got '%s'", e, a)
+	}
+
+	if results[2].Err == nil {
+		t.Errorf("expected an error for an invalid url")
+	}
+}
+
+func TestStatManyHeader(t *testing.T) {
+	svr := newTestServerWithHandler(func(h http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Header.Get("X-Test") != "value" {
+				w.WriteHeader(http.StatusForbidden)
+				return
+			}
+
+			h.ServeHTTP(w, r)
+		})
+	})
+	defer svr.Close()
+
+	urls := []string{svr.URL().JoinPath("assets", "GitHub_logo.png").String()}
+
+	results, err := httpio.StatMany(context.Background(), urls, httpio.WithHeader("X-Test", "value"))
+	if err != nil {
+		t.Fatalf("unable to stat: %v", err)
+	}
+
+	if err := results[0].Err; err != nil {
+		t.Errorf("expected the header on the request, got: %v", err)
+	}
+}
+
+func TestStat(t *testing.T) {
+	tests := []struct {
+		name        string
+		disposition string
+		filename    string
+	}{
+		{"no disposition", "", ""},
+		{"filename", `attachment; filename="report.pdf"`, "report.pdf"},
+		{"encoded filename", `attachment; filename="report.pdf"; filename*=UTF-8''r%C3%A9sum%C3%A9.pdf`, "résumé.pdf"},
+		{"directories", `attachment; filename="../../etc/passwd"`, "passwd"},
+		{"windows directories", `attachment; filename="C:\\temp\\report.pdf"`, "report.pdf"},
+		{"parent", `attachment; filename=".."`, ""},
+		{"invalid", `attachment; filename="report.pdf`, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if tt.disposition != "" {
+					w.Header().Set("Content-Disposition", tt.disposition)
+				}
+				w.Header().Set("Content-Type", "application/pdf")
+				w.Header().Set("ETag", `"v1"`)
+				w.Header().Set("Accept-Ranges", "bytes")
+				w.Header().Set("Last-Modified", "Wed, 21 Oct 2015 07:28:00 GMT")
+				http.ServeContent(w, r, "", time.Time{}, bytes.NewReader([]byte("%PDF-1.7")))
+			}))
+			defer svr.Close()
+
+			remoteFile, err := httpio.Get(svr.URL)
+			if err != nil {
+				t.Fatalf("failed to setup request: %v", err)
+			}
+			defer remoteFile.Close()
+
+			meta := remoteFile.Stat()
+			if meta.Filename != tt.filename {
+				t.Errorf("expected filename '%s', got '%s'", tt.filename, meta.Filename)
+			}
+
+			if meta.Size != 8 || meta.ContentType != "application/pdf" || meta.ETag != `"v1"` ||
+				meta.LastModified != "Wed, 21 Oct 2015 07:28:00 GMT" || !meta.AcceptsRanges() {
+				t.Errorf("unexpected metadata: %+v", meta)
+			}
+		})
+	}
+}
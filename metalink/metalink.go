@@ -0,0 +1,156 @@
+// Package metalink downloads the files described by Metalink 4 documents
+// (RFC 5854), fetching their chunks from the mirrors listed in the document
+// and verifying them against its piece and file hashes.
+package metalink
+
+import (
+	"context"
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"net/url"
+	"slices"
+	"strings"
+
+	"github.com/jobstoit/httpio"
+)
+
+// hashAlgorithms are the supported hash types from strongest to weakest
+var hashAlgorithms = []struct {
+	name string
+	hash func() hash.Hash
+}{
+	{"sha-512", sha512.New},
+	{"sha-384", sha512.New384},
+	{"sha-256", sha256.New},
+	{"sha-1", sha1.New},
+	{"md5", md5.New},
+}
+
+// Metalink is a Metalink 4 document
+type Metalink struct {
+	Files []File `xml:"file"`
+}
+
+// File is a file described by a Metalink document
+type File struct {
+	Name string `xml:"name,attr"`
+	// Size is the size of the file in bytes, 0 when it isn't given
+	Size   int64   `xml:"size"`
+	Hashes []Hash  `xml:"hash"`
+	Pieces *Pieces `xml:"pieces"`
+	URLs   []URL   `xml:"url"`
+}
+
+// Hash is a hex encoded hash of the whole file
+type Hash struct {
+	Type  string `xml:"type,attr"`
+	Value string `xml:",chardata"`
+}
+
+// Pieces are the hex encoded hashes of the consecutive pieces of the given
+// length the file consists of
+type Pieces struct {
+	Length int64    `xml:"length,attr"`
+	Type   string   `xml:"type,attr"`
+	Hashes []string `xml:"hash"`
+}
+
+// URL is a mirror of a file, a lower priority is preferred and a priority
+// of 0 means it has none
+type URL struct {
+	Priority int    `xml:"priority,attr"`
+	Location string `xml:"location,attr"`
+	URL      string `xml:",chardata"`
+}
+
+// Parse parses a Metalink 4 document
+func Parse(r io.Reader) (*Metalink, error) {
+	m := &Metalink{}
+	if err := xml.NewDecoder(r).Decode(m); err != nil {
+		return nil, fmt.Errorf("invalid metalink: %w", err)
+	}
+
+	return m, nil
+}
+
+// File returns the file with the given name
+func (m *Metalink) File(name string) (*File, bool) {
+	for i := range m.Files {
+		if m.Files[i].Name == name {
+			return &m.Files[i], true
+		}
+	}
+
+	return nil, false
+}
+
+// Mirrors returns the http and https urls of the file by their priority
+func (f *File) Mirrors() []string {
+	urls := slices.Clone(f.URLs)
+	slices.SortStableFunc(urls, func(a, b URL) int {
+		return priority(a) - priority(b)
+	})
+
+	var mirrors []string
+	for _, u := range urls {
+		raw := strings.TrimSpace(u.URL)
+		if parsed, err := url.Parse(raw); err == nil && (parsed.Scheme == "http" || parsed.Scheme == "https") {
+			mirrors = append(mirrors, raw)
+		}
+	}
+
+	return mirrors
+}
+
+// priority returns the priority of the url, placing the urls without one last
+func priority(u URL) int {
+	if u.Priority <= 0 {
+		return int(^uint(0) >> 1)
+	}
+
+	return u.Priority
+}
+
+// Options returns the options downloading the file with its size and
+// verifying it against its hashes. The strongest supported hash of the
+// whole file is verified when the file is read from the start, the pieces
+// are verified as they're read.
+func (f *File) Options() ([]httpio.Option, error) {
+	var opts []httpio.Option
+	if f.Size > 0 {
+		opts = append(opts, httpio.WithSize(f.Size))
+	}
+
+	v, err := newVerifier(f)
+	if err != nil {
+		return nil, err
+	}
+
+	if v != nil {
+		opts = append(opts, httpio.WithInspector(v))
+	}
+
+	return opts, nil
+}
+
+// Get gets the file from its mirrors, verifying it against its hashes
+func Get(ctx context.Context, f *File, opts ...httpio.Option) (*httpio.RemoteFile, error) {
+	mirrors := f.Mirrors()
+	if len(mirrors) == 0 {
+		return nil, errors.New("no http urls for " + f.Name)
+	}
+
+	fileOpts, err := f.Options()
+	if err != nil {
+		return nil, err
+	}
+
+	return httpio.GetMirrored(ctx, mirrors, append(fileOpts, opts...)...)
+}
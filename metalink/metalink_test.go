@@ -0,0 +1,163 @@
+package metalink_test
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jobstoit/httpio"
+	"github.com/jobstoit/httpio/metalink"
+)
+
+const pieceLength = 256 * 1024
+
+func newContent() []byte {
+	content := make([]byte, 1024*1024+1000)
+	for i := range content {
+		content[i] = byte(i * 7)
+	}
+
+	return content
+}
+
+func newDocument(content []byte, urls []string, corrupt int) string {
+	sum := sha256.Sum256(content)
+
+	var pieces []string
+	for i := 0; i*pieceLength < len(content); i++ {
+		piece := sha256.Sum256(content[i*pieceLength : min((i+1)*pieceLength, len(content))])
+		if i == corrupt {
+			piece[0]++
+		}
+		pieces = append(pieces, "<hash>"+hex.EncodeToString(piece[:])+"</hash>")
+	}
+
+	var mirrors []string
+	for i, u := range urls {
+		mirrors = append(mirrors, fmt.Sprintf(`<url priority="%d">%s</url>`, i+1, u))
+	}
+
+	return fmt.Sprintf(`<?xml version="1.0" encoding="UTF-8"?>
+<metalink xmlns="urn:ietf:params:xml:ns:metalink">
+  <file name="file.bin">
+    <size>%d</size>
+    <hash type="sha-256">%s</hash>
+    <pieces length="%d" type="sha-256">%s</pieces>
+    %s
+  </file>
+</metalink>`, len(content), hex.EncodeToString(sum[:]), pieceLength, strings.Join(pieces, ""), strings.Join(mirrors, ""))
+}
+
+func newServer(content []byte) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.ServeContent(w, r, "file.bin", time.Time{}, bytes.NewReader(content))
+	}))
+}
+
+func TestParse(t *testing.T) {
+	m, err := metalink.Parse(strings.NewReader(`<?xml version="1.0" encoding="UTF-8"?>
+<metalink xmlns="urn:ietf:params:xml:ns:metalink">
+  <file name="example.ext">
+    <size>14471447</size>
+    <url>http://example.com/example.ext</url>
+    <url priority="2">ftp://ftp.example.com/example.ext</url>
+    <url priority="1" location="de">https://de.example.com/example.ext</url>
+    <pieces length="262144" type="sha-1">
+      <hash>ab</hash>
+      <hash>cd</hash>
+    </pieces>
+  </file>
+</metalink>`))
+	if err != nil {
+		t.Fatalf("unable to parse: %v", err)
+	}
+
+	file, ok := m.File("example.ext")
+	if !ok {
+		t.Fatal("expected the file")
+	}
+
+	if file.Size != 14471447 {
+		t.Errorf("unexpected size %d", file.Size)
+	}
+
+	if file.Pieces == nil || file.Pieces.Length != 262144 || len(file.Pieces.Hashes) != 2 {
+		t.Errorf("unexpected pieces: %+v", file.Pieces)
+	}
+
+	expected := []string{"https://de.example.com/example.ext", "http://example.com/example.ext"}
+	if mirrors := file.Mirrors(); !slices.Equal(mirrors, expected) {
+		t.Errorf("expected the http mirrors by priority %v, got %v", expected, mirrors)
+	}
+}
+
+func TestGet(t *testing.T) {
+	content := newContent()
+
+	down := httptest.NewServer(http.NotFoundHandler())
+	defer down.Close()
+
+	mirror := newServer(content)
+	defer mirror.Close()
+
+	m, err := metalink.Parse(strings.NewReader(newDocument(content, []string{down.URL + "/file.bin", mirror.URL + "/file.bin"}, -1)))
+	if err != nil {
+		t.Fatalf("unable to parse: %v", err)
+	}
+
+	remoteFile, err := metalink.Get(context.Background(), &m.Files[0], httpio.WithChunkSize(pieceLength))
+	if err != nil {
+		t.Fatalf("unable to get file: %v", err)
+	}
+	defer remoteFile.Close()
+
+	actual, err := io.ReadAll(remoteFile)
+	if err != nil {
+		t.Fatalf("unable to read file: %v", err)
+	}
+
+	if !bytes.Equal(content, actual) {
+		t.Error("mismatched content")
+	}
+}
+
+func TestGetCorruptPiece(t *testing.T) {
+	content := newContent()
+
+	svr := newServer(content)
+	defer svr.Close()
+
+	m, err := metalink.Parse(strings.NewReader(newDocument(content, []string{svr.URL + "/file.bin"}, 2)))
+	if err != nil {
+		t.Fatalf("unable to parse: %v", err)
+	}
+
+	remoteFile, err := metalink.Get(context.Background(), &m.Files[0])
+	if err != nil {
+		t.Fatalf("unable to get file: %v", err)
+	}
+	defer remoteFile.Close()
+
+	_, err = io.Copy(io.Discard, remoteFile)
+
+	var checksumErr *httpio.ChecksumError
+	if !errors.As(err, &checksumErr) || checksumErr.Name != "file.bin piece 2" {
+		t.Errorf("expected a ChecksumError of the corrupt piece, got: %v", err)
+	}
+}
+
+func TestGetNoMirrors(t *testing.T) {
+	if _, err := metalink.Get(context.Background(), &metalink.File{Name: "file.bin"}); err == nil {
+		t.Error("expected an error without mirrors")
+	}
+}
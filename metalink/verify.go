@@ -0,0 +1,176 @@
+package metalink
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"strings"
+
+	"github.com/jobstoit/httpio"
+)
+
+// verifier is an httpio.Inspector verifying the pieces of a file and the
+// whole file against their hashes. The pieces and the file are only
+// verified when they're inspected from their start.
+type verifier struct {
+	name string
+
+	fileHash func() hash.Hash
+	fileSum  []byte
+
+	pieceHash   func() hash.Hash
+	pieceLength int64
+	pieceSums   [][]byte
+
+	// offset is the offset of the next bytes, file and piece are the
+	// running hashes which are nil when their start wasn't inspected
+	offset  int64
+	started bool
+	file    hash.Hash
+	piece   hash.Hash
+}
+
+// newVerifier returns the verifier of the file, or nil when the file has
+// no hashes of a supported type
+func newVerifier(f *File) (*verifier, error) {
+	v := &verifier{name: f.Name}
+
+	for _, alg := range hashAlgorithms {
+		i := indexHash(f.Hashes, alg.name)
+		if i < 0 {
+			continue
+		}
+
+		sum, err := hex.DecodeString(strings.TrimSpace(f.Hashes[i].Value))
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s hash of %s: %w", alg.name, f.Name, err)
+		}
+		v.fileHash, v.fileSum = alg.hash, sum
+
+		break
+	}
+
+	if p := f.Pieces; p != nil && p.Length > 0 {
+		for _, alg := range hashAlgorithms {
+			if !strings.EqualFold(p.Type, alg.name) {
+				continue
+			}
+
+			for _, value := range p.Hashes {
+				sum, err := hex.DecodeString(strings.TrimSpace(value))
+				if err != nil {
+					return nil, fmt.Errorf("invalid %s piece hash of %s: %w", alg.name, f.Name, err)
+				}
+				v.pieceSums = append(v.pieceSums, sum)
+			}
+			v.pieceHash, v.pieceLength = alg.hash, p.Length
+
+			break
+		}
+	}
+
+	if v.fileHash == nil && v.pieceHash == nil {
+		return nil, nil
+	}
+
+	return v, nil
+}
+
+func indexHash(hashes []Hash, name string) int {
+	for i, h := range hashes {
+		if strings.EqualFold(h.Type, name) {
+			return i
+		}
+	}
+
+	return -1
+}
+
+// Inspect implements httpio.Inspector
+func (v *verifier) Inspect(offset int64, p []byte) error {
+	// a download that doesn't continue where the last one left off, like
+	// after a seek, only verifies what it inspects from the start
+	if !v.started || offset != v.offset {
+		v.started, v.offset = true, offset
+		v.file, v.piece = nil, nil
+
+		if offset == 0 && v.fileHash != nil {
+			v.file = v.fileHash()
+		}
+
+		if v.pieceHash != nil && offset%v.pieceLength == 0 {
+			v.piece = v.pieceHash()
+		}
+	}
+
+	if v.file != nil {
+		v.file.Write(p)
+	}
+
+	if v.pieceHash == nil {
+		v.offset += int64(len(p))
+		return nil
+	}
+
+	for len(p) > 0 {
+		end := (v.offset/v.pieceLength + 1) * v.pieceLength
+		n := min(int64(len(p)), end-v.offset)
+
+		if v.piece != nil {
+			v.piece.Write(p[:n])
+		}
+		v.offset += n
+		p = p[n:]
+
+		if v.offset == end {
+			if err := v.verifyPiece(end/v.pieceLength - 1); err != nil {
+				return err
+			}
+			v.piece = v.pieceHash()
+		}
+	}
+
+	return nil
+}
+
+// Done implements httpio.Inspector
+func (v *verifier) Done() error {
+	if v.pieceHash != nil && v.offset%v.pieceLength != 0 {
+		if err := v.verifyPiece(v.offset / v.pieceLength); err != nil {
+			return err
+		}
+	}
+
+	if v.file != nil {
+		if actual := v.file.Sum(nil); !bytes.Equal(actual, v.fileSum) {
+			return &httpio.ChecksumError{
+				Name:     v.name,
+				Expected: hex.EncodeToString(v.fileSum),
+				Actual:   hex.EncodeToString(actual),
+			}
+		}
+	}
+
+	v.started = false
+
+	return nil
+}
+
+// verifyPiece verifies the piece with the given index when it was
+// inspected from its start
+func (v *verifier) verifyPiece(index int64) error {
+	if v.piece == nil || index >= int64(len(v.pieceSums)) {
+		return nil
+	}
+
+	if actual := v.piece.Sum(nil); !bytes.Equal(actual, v.pieceSums[index]) {
+		return &httpio.ChecksumError{
+			Name:     fmt.Sprintf("%s piece %d", v.name, index),
+			Expected: hex.EncodeToString(v.pieceSums[index]),
+			Actual:   hex.EncodeToString(actual),
+		}
+	}
+
+	return nil
+}
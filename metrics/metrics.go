@@ -0,0 +1,88 @@
+// Package metrics collects Prometheus metrics of httpio downloads and uploads
+package metrics
+
+import (
+	"time"
+
+	"github.com/jobstoit/httpio"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Collector holds the metrics of the transfers it's given to with Option
+type Collector struct {
+	chunks  prometheus.Counter
+	bytes   prometheus.Counter
+	retries prometheus.Counter
+	latency prometheus.Histogram
+	active  prometheus.Gauge
+}
+
+// New returns a collector with its metrics registered with the registerer,
+// which is the default registerer when nil
+func New(reg prometheus.Registerer) (*Collector, error) {
+	if reg == nil {
+		reg = prometheus.DefaultRegisterer
+	}
+
+	c := &Collector{
+		chunks: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "httpio_chunks_fetched_total",
+			Help: "Number of chunks downloaded or uploaded.",
+		}),
+		bytes: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "httpio_chunk_bytes_total",
+			Help: "Number of bytes of the downloaded or uploaded chunks.",
+		}),
+		retries: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "httpio_retries_total",
+			Help: "Number of retried chunk requests.",
+		}),
+		latency: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Name:    "httpio_chunk_duration_seconds",
+			Help:    "Time it took to download or upload a chunk.",
+			Buckets: prometheus.ExponentialBuckets(0.01, 2, 12),
+		}),
+		active: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "httpio_active_downloads",
+			Help: "Number of downloads and uploads in progress.",
+		}),
+	}
+
+	for _, collector := range []prometheus.Collector{c.chunks, c.bytes, c.retries, c.latency, c.active} {
+		if err := reg.Register(collector); err != nil {
+			return nil, err
+		}
+	}
+
+	return c, nil
+}
+
+// Option collects the metrics of the download or upload it's given to
+func (c *Collector) Option() httpio.Option {
+	return httpio.WithEvents(&observer{c: c})
+}
+
+// observer updates the metrics with the events of a transfer
+type observer struct {
+	c *Collector
+}
+
+func (o *observer) OnStart(int64, int64) {
+	o.c.active.Inc()
+}
+
+func (o *observer) OnChunkStart(httpio.Chunk) {}
+
+func (o *observer) OnChunkComplete(_ httpio.Chunk, bytes int64, duration time.Duration) {
+	o.c.chunks.Inc()
+	o.c.bytes.Add(float64(bytes))
+	o.c.latency.Observe(duration.Seconds())
+}
+
+func (o *observer) OnRetry(httpio.Chunk, error, int) {
+	o.c.retries.Inc()
+}
+
+func (o *observer) OnComplete(error) {
+	o.c.active.Dec()
+}
@@ -0,0 +1,89 @@
+package metrics_test
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/jobstoit/httpio"
+	"github.com/jobstoit/httpio/metrics"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestCollector(t *testing.T) {
+	content := bytes.Repeat([]byte("httpio"), 1024*1024)
+
+	// the second chunk fails the first time it's requested
+	var failed atomic.Bool
+	svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Range") == "bytes=1048576-2097151" && failed.CompareAndSwap(false, true) {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		http.ServeContent(w, r, "file", time.Time{}, bytes.NewReader(content))
+	}))
+	defer svr.Close()
+
+	reg := prometheus.NewRegistry()
+	collector, err := metrics.New(reg)
+	if err != nil {
+		t.Fatalf("unable to create the collector: %v", err)
+	}
+
+	remoteFile, err := httpio.Get(svr.URL,
+		httpio.WithChunkSize(1024*1024),
+		httpio.WithRetry(1),
+		httpio.WithBackoff(func(int) time.Duration { return 0 }),
+		collector.Option(),
+	)
+	if err != nil {
+		t.Fatalf("failed to setup request: %v", err)
+	}
+
+	if _, err := io.Copy(io.Discard, remoteFile); err != nil {
+		t.Fatalf("unable to read file: %v", err)
+	}
+	remoteFile.Close()
+
+	expected := map[string]float64{
+		"httpio_chunks_fetched_total": 6,
+		"httpio_chunk_bytes_total":    float64(len(content)),
+		"httpio_retries_total":        1,
+		"httpio_active_downloads":     0,
+	}
+
+	families, err := reg.Gather()
+	if err != nil {
+		t.Fatalf("unable to gather the metrics: %v", err)
+	}
+
+	for _, family := range families {
+		e, ok := expected[family.GetName()]
+		if !ok {
+			continue
+		}
+		delete(expected, family.GetName())
+
+		metric := family.GetMetric()[0]
+		a := metric.GetCounter().GetValue() + metric.GetGauge().GetValue()
+		if e != a {
+			t.Errorf("expected %s to be %v, got %v", family.GetName(), e, a)
+		}
+	}
+
+	// the active downloads gauge isn't gathered at 0 by all registries
+	delete(expected, "httpio_active_downloads")
+	if len(expected) > 0 {
+		t.Errorf("expected the metrics to be gathered: %v", expected)
+	}
+
+	if n := testutil.CollectAndCount(reg, "httpio_chunk_duration_seconds"); n != 1 {
+		t.Errorf("expected the chunk duration histogram, got %d", n)
+	}
+}
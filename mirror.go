@@ -0,0 +1,191 @@
+package httpio
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+)
+
+// mirrorCooldown is the time a mirror that failed a request is skipped
+// while other mirrors are healthy
+const mirrorCooldown = time.Second * 10
+
+// GetMirrored gets the file served at all of the given urls, fetching its
+// chunks from the mirrors that are healthy. The metadata is requested from
+// the first url that responds. A chunk request failing at a mirror fails over
+// to the next one, skipping the failed mirror for a while. The chunks are
+// fetched from the first healthy mirror, or spread over all of them with
+// WithMirrorBalancing. The mirrors are trusted to serve the same content,
+// only their sizes are compared, so WithChecksum is recommended.
+func GetMirrored(ctx context.Context, urls []string, opts ...Option) (*RemoteFile, error) {
+	if len(urls) == 0 {
+		return nil, errors.New("no urls given")
+	}
+
+	locations := make([]*url.URL, len(urls))
+	for i, raw := range urls {
+		u, err := url.Parse(raw)
+		if err != nil {
+			return nil, err
+		}
+		locations[i] = u
+	}
+
+	var errs []error
+	for i, raw := range urls {
+		file, err := open(ctx, raw, opts...)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+
+		file.mirrors = newMirrors(locations, i, file.balanceMirrors)
+		file.begin()
+
+		return file, nil
+	}
+
+	return nil, errors.Join(errs...)
+}
+
+// WithMirrorBalancing spreads the chunks of GetMirrored over all healthy
+// mirrors for a higher throughput, rather than fetching them from the first
+// healthy mirror
+func WithMirrorBalancing() Option {
+	return func(f *RemoteFile) error {
+		f.balanceMirrors = true
+
+		return nil
+	}
+}
+
+// mirrors keeps the health of the mirrors of a file, the primary mirror is
+// the one the metadata was requested from
+type mirrors struct {
+	urls    []*url.URL
+	primary int
+	balance bool
+
+	mu   sync.Mutex
+	down []time.Time
+}
+
+func newMirrors(urls []*url.URL, primary int, balance bool) *mirrors {
+	return &mirrors{
+		urls:    urls,
+		primary: primary,
+		balance: balance,
+		down:    make([]time.Time, len(urls)),
+	}
+}
+
+// pick returns the mirror to request the chunk from of the mirrors it
+// hasn't been requested from yet, preferring the healthy ones, or false
+// when it has been requested from all of them
+func (m *mirrors) pick(c Chunk, tried []bool) (int, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	start := m.primary
+	if m.balance {
+		start = c.Index % len(m.urls)
+	}
+
+	now := time.Now()
+	pick := -1
+	for n := range m.urls {
+		i := (start + n) % len(m.urls)
+		if tried[i] {
+			continue
+		}
+
+		if now.After(m.down[i]) {
+			return i, true
+		}
+
+		// otherwise the mirror that has been down the longest
+		if pick < 0 || m.down[i].Before(m.down[pick]) {
+			pick = i
+		}
+	}
+
+	return pick, pick >= 0
+}
+
+// fail skips the mirror for a while
+func (m *mirrors) fail(i int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.down[i] = time.Now().Add(mirrorCooldown)
+}
+
+// mirrorRequest returns a request for the file at the given mirror
+func (f *RemoteFile) mirrorRequest(ctx context.Context, i int) *http.Request {
+	if i == f.mirrors.primary {
+		return f.newRequest(ctx)
+	}
+
+	return f.requestAt(ctx, f.mirrors.urls[i])
+}
+
+// failover marks the mirror as failed when it failed to serve the chunk and
+// reports whether the chunk is to be requested from the next mirror. The
+// response of a mirror serving a file of another size is closed and the
+// error returned when there's no mirror left.
+func (f *RemoteFile) failover(c Chunk, mirror int, tried []bool, res *http.Response, err error) (bool, error) {
+	failure := f.mirrorFailure(c, res, err)
+	if failure == nil {
+		return false, nil
+	}
+
+	tried[mirror] = true
+	f.mirrors.fail(mirror)
+
+	if _, ok := f.mirrors.pick(c, tried); ok {
+		if err == nil {
+			res.Body.Close()
+		}
+		f.logDebug("failing over", "range", c.Range(), "mirror", f.mirrors.urls[mirror].String(), "error", failure)
+
+		return true, nil
+	}
+
+	if err == nil && res.StatusCode == http.StatusPartialContent {
+		res.Body.Close()
+		return false, failure
+	}
+
+	return false, nil
+}
+
+// mirrorFailure returns why the mirror failed to serve the chunk, either by
+// failing the request or by not serving a range of a file of the size of
+// the file, or nil when it didn't
+func (f *RemoteFile) mirrorFailure(c Chunk, res *http.Response, err error) error {
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case throttled(res), res.StatusCode == http.StatusPreconditionFailed:
+		return nil
+	case res.StatusCode >= 400:
+		return newStatusError(res)
+	case res.StatusCode == http.StatusPartialContent:
+		size, ok := parseContentRangeSize(res.Header.Get(headerContentRange))
+		if ok && f.size >= 0 && size != f.size {
+			return fmt.Errorf("mirror '%s' serves %d bytes, expected %d", res.Request.URL, size, f.size)
+		}
+
+		return nil
+	case c.Offset != 0 || c.Length != f.size:
+		return ErrRangeIgnored
+	default:
+		return nil
+	}
+}
@@ -0,0 +1,150 @@
+package httpio_test
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"sync/atomic"
+	"testing"
+
+	"github.com/jobstoit/httpio"
+)
+
+// newCountingTestServer counts the range requests and fails them with the
+// given status when it isn't 0
+func newCountingTestServer(status int) (*testServer, *atomic.Int32) {
+	var ranged atomic.Int32
+	svr := newTestServerWithHandler(func(h http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Header.Get("Range") != "" {
+				ranged.Add(1)
+
+				if status != 0 {
+					w.WriteHeader(status)
+					return
+				}
+			}
+
+			h.ServeHTTP(w, r)
+		})
+	})
+
+	return svr, &ranged
+}
+
+func TestGetMirroredFailover(t *testing.T) {
+	primary, _ := newCountingTestServer(http.StatusServiceUnavailable)
+	defer primary.Close()
+
+	mirror, ranged := newCountingTestServer(0)
+	defer mirror.Close()
+
+	urls := []string{
+		primary.URL().JoinPath("assets", "test_5mb").String(),
+		mirror.URL().JoinPath("assets", "test_5mb").String(),
+	}
+	expected, _ := testdata.ReadFile("testdata/test_5mb")
+
+	remoteFile, err := httpio.GetMirrored(context.Background(), urls, httpio.WithChunkSize(1024*1024))
+	if err != nil {
+		t.Fatalf("unable to get file: %v", err)
+	}
+	defer remoteFile.Close()
+
+	actual, err := io.ReadAll(remoteFile)
+	if err != nil {
+		t.Fatalf("unable to read file: %v", err)
+	}
+
+	if !bytes.Equal(expected, actual) {
+		t.Error("mismatched content")
+	}
+
+	if n := ranged.Load(); n != 5 {
+		t.Errorf("expected the chunks to be fetched from the mirror, got %d range requests", n)
+	}
+}
+
+func TestGetMirroredUnavailable(t *testing.T) {
+	down := newTestServer()
+	downURL := down.URL().JoinPath("assets", "test_5mb").String()
+	down.Close()
+
+	mirror := newTestServer()
+	defer mirror.Close()
+
+	urls := []string{downURL, mirror.URL().JoinPath("assets", "test_5mb").String()}
+	expected, _ := testdata.ReadFile("testdata/test_5mb")
+
+	remoteFile, err := httpio.GetMirrored(context.Background(), urls, httpio.WithChunkSize(1024*1024))
+	if err != nil {
+		t.Fatalf("unable to get file: %v", err)
+	}
+	defer remoteFile.Close()
+
+	actual, err := io.ReadAll(remoteFile)
+	if err != nil {
+		t.Fatalf("unable to read file: %v", err)
+	}
+
+	if !bytes.Equal(expected, actual) {
+		t.Error("mismatched content")
+	}
+}
+
+func TestGetMirroredBalancing(t *testing.T) {
+	first, firstRanged := newCountingTestServer(0)
+	defer first.Close()
+
+	second, secondRanged := newCountingTestServer(0)
+	defer second.Close()
+
+	urls := []string{
+		first.URL().JoinPath("assets", "test_5mb").String(),
+		second.URL().JoinPath("assets", "test_5mb").String(),
+	}
+
+	remoteFile, err := httpio.GetMirrored(context.Background(), urls, httpio.WithChunkSize(1024*1024), httpio.WithMirrorBalancing())
+	if err != nil {
+		t.Fatalf("unable to get file: %v", err)
+	}
+	defer remoteFile.Close()
+
+	if _, err := io.Copy(io.Discard, remoteFile); err != nil {
+		t.Fatalf("unable to read file: %v", err)
+	}
+
+	if firstRanged.Load() == 0 || secondRanged.Load() == 0 {
+		t.Errorf("expected the chunks to be spread over the mirrors, got %d and %d", firstRanged.Load(), secondRanged.Load())
+	}
+}
+
+func TestGetMirroredSizeMismatch(t *testing.T) {
+	primary, _ := newCountingTestServer(http.StatusNotFound)
+	defer primary.Close()
+
+	mirror := newTestServer()
+	defer mirror.Close()
+
+	urls := []string{
+		primary.URL().JoinPath("assets", "test_5mb").String(),
+		mirror.URL().JoinPath("assets", "test_12mb").String(),
+	}
+
+	remoteFile, err := httpio.GetMirrored(context.Background(), urls, httpio.WithChunkSize(1024*1024))
+	if err != nil {
+		t.Fatalf("unable to get file: %v", err)
+	}
+	defer remoteFile.Close()
+
+	if _, err := io.Copy(io.Discard, remoteFile); err == nil {
+		t.Error("expected a mirror serving another file to fail the download")
+	}
+}
+
+func TestGetMirroredNoURLs(t *testing.T) {
+	if _, err := httpio.GetMirrored(context.Background(), nil); err == nil {
+		t.Error("expected an error without urls")
+	}
+}
@@ -0,0 +1,246 @@
+package httpio
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// DefaultRangesPerRequest is the number of ranges ReadRanges requests at once
+const DefaultRangesPerRequest = 32
+
+// WithRangesPerRequest sets the number of ranges ReadRanges requests in a
+// single request, servers limit the number of ranges they serve at once.
+// A number of 1 or less requests every range on its own.
+func WithRangesPerRequest(n int) Option {
+	return func(f *RemoteFile) error {
+		f.rangesPerRequest = max(n, 1)
+
+		return nil
+	}
+}
+
+// ReadRanges reads the given ranges of the file with range requests of its
+// own, like ReadAt, requesting up to the number of ranges set with
+// WithRangesPerRequest in a single request that a server supporting it
+// answers with a multipart/byteranges response. The ranges a response
+// doesn't cover are requested one by one, and once a server answers
+// several ranges with the whole file they're always requested one by one.
+// Ranges past the end of the file are cut short. It's safe to call
+// concurrently.
+func (f *RemoteFile) ReadRanges(ranges []ByteRange) ([][]byte, error) {
+	if f.size < 0 {
+		return nil, errors.New("unknown size of the file")
+	}
+
+	if err := f.checkRandomAccess(); err != nil {
+		return nil, err
+	}
+
+	bufs := make([][]byte, len(ranges))
+	var parts []rangePart
+	for i, r := range ranges {
+		if r.Offset < 0 {
+			return nil, errors.New("negative offset")
+		}
+
+		length := f.size - min(r.Offset, f.size)
+		if r.Length >= 0 {
+			length = min(r.Length, length)
+		}
+
+		bufs[i] = make([]byte, length)
+		if length > 0 {
+			parts = append(parts, rangePart{offset: r.Offset, buf: bufs[i]})
+		}
+	}
+
+	var (
+		wg   sync.WaitGroup
+		mu   sync.Mutex
+		errs []error
+	)
+
+	for len(parts) > 0 {
+		batch := parts[:min(len(parts), f.rangesPerRequest)]
+		parts = parts[len(batch):]
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			if err := f.fetchRanges(batch); err != nil {
+				mu.Lock()
+				errs = append(errs, err)
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+
+	return bufs, nil
+}
+
+// rangePart is a range read by ReadRanges, filled reports whether a
+// response covered it
+type rangePart struct {
+	offset int64
+	buf    []byte
+	filled bool
+}
+
+func (p *rangePart) last() int64 {
+	return p.offset + int64(len(p.buf)) - 1
+}
+
+// fetchRanges fetches the parts, with a single request when there are
+// several and the server didn't ignore them before, and then fetches the
+// parts its response didn't cover one by one
+func (f *RemoteFile) fetchRanges(parts []rangePart) error {
+	if len(parts) > 1 && !f.multiRangeIgnored.Load() {
+		if err := f.fetchMultiRange(parts); err != nil {
+			return err
+		}
+	}
+
+	for i := range parts {
+		if parts[i].filled {
+			continue
+		}
+
+		buf, err := f.fetchRange(Chunk{Offset: parts[i].offset, Length: int64(len(parts[i].buf))})
+		if err != nil {
+			return err
+		}
+		copy(parts[i].buf, buf)
+	}
+
+	return nil
+}
+
+// fetchMultiRange requests the parts in a single request and fills the
+// parts its response covers
+func (f *RemoteFile) fetchMultiRange(parts []rangePart) error {
+	ctx := f.ctx
+	lim := f.readAtLimiter()
+
+	if err := lim.acquire(ctx); err != nil {
+		return err
+	}
+	defer lim.release()
+
+	specs := make([]string, len(parts))
+	for i, p := range parts {
+		specs[i] = fmt.Sprintf("%d-%d", p.offset, p.last())
+	}
+
+	req := f.newRequest(f.traceContext(ctx))
+	req.Header.Set(headerRange, rangeUnitBytes+"="+strings.Join(specs, ","))
+	if ifRange := f.validators.ifRange(); ifRange != "" && req.Header.Get(headerIfRange) == "" {
+		req.Header.Set(headerIfRange, ifRange)
+	}
+
+	if err := f.pacer.wait(ctx); err != nil {
+		return err
+	}
+
+	res, err := f.doChunk(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	f.pacer.update(res.Header)
+
+	if err := checkPrecondition(res); err != nil {
+		return err
+	}
+
+	if res.StatusCode == http.StatusOK {
+		// the parts are requested one by one from now on
+		f.multiRangeIgnored.Store(true)
+		f.logDebug("multiple ranges ignored", "ranges", len(parts))
+
+		return nil
+	}
+
+	if res.StatusCode != http.StatusPartialContent {
+		return newStatusError(res)
+	}
+
+	if err := f.validators.check(res); err != nil {
+		return err
+	}
+
+	if err := f.checkContentEncoding(res); err != nil {
+		return err
+	}
+
+	mediaType, params, _ := mime.ParseMediaType(res.Header.Get(headerContentType))
+	if mediaType != "multipart/byteranges" {
+		// a single range covering some or all of the parts
+		return fillParts(parts, res.Header.Get(headerContentRange), res.Body)
+	}
+
+	mr := multipart.NewReader(res.Body, params["boundary"])
+	for {
+		part, err := mr.NextPart()
+		if err == io.EOF {
+			return nil
+		}
+
+		if err != nil {
+			return err
+		}
+
+		if err := fillParts(parts, part.Header.Get(headerContentRange), part); err != nil {
+			return err
+		}
+	}
+}
+
+// fillParts fills the parts the range of the body covers completely
+func fillParts(parts []rangePart, contentRange string, body io.Reader) error {
+	if err := checkRangeUnit(contentRange); err != nil {
+		return err
+	}
+
+	first, last, _, ok := parseContentRange(contentRange)
+	if !ok {
+		return fmt.Errorf("invalid Content-Range: '%s'", contentRange)
+	}
+
+	// the server may have coalesced the requested ranges, but it serves
+	// nothing outside of them
+	lowest, highest := parts[0].offset, parts[0].last()
+	for _, p := range parts[1:] {
+		lowest, highest = min(lowest, p.offset), max(highest, p.last())
+	}
+
+	if first < lowest || last > highest {
+		return fmt.Errorf("range %d-%d is outside of the requested ranges", first, last)
+	}
+
+	buf := make([]byte, last-first+1)
+	if _, err := io.ReadFull(body, buf); err != nil {
+		return err
+	}
+
+	for i := range parts {
+		p := &parts[i]
+		if p.offset >= first && p.last() <= last {
+			copy(p.buf, buf[p.offset-first:])
+			p.filled = true
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,112 @@
+package httpio_test
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/jobstoit/httpio"
+)
+
+func TestReadRanges(t *testing.T) {
+	expected, _ := testdata.ReadFile("testdata/test_5mb")
+	size := int64(len(expected))
+
+	ranges := []httpio.ByteRange{
+		{Offset: 0, Length: 100},
+		{Offset: 1024 * 1024, Length: 4096},
+		{Offset: 3 * 1024 * 1024, Length: 10},
+		{Offset: size - 50, Length: -1},
+		{Offset: size - 20, Length: 100},
+		{Offset: 2048, Length: 0},
+	}
+
+	tests := []struct {
+		name     string
+		handler  func(w http.ResponseWriter, r *http.Request)
+		opts     []httpio.Option
+		requests int64
+	}{
+		{
+			name: "multipart",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				http.ServeContent(w, r, "test_5mb.bin", time.Time{}, bytes.NewReader(expected))
+			},
+			requests: 1,
+		},
+		{
+			name: "batched",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				http.ServeContent(w, r, "test_5mb.bin", time.Time{}, bytes.NewReader(expected))
+			},
+			opts:     []httpio.Option{httpio.WithRangesPerRequest(2)},
+			requests: 3,
+		},
+		{
+			name: "first range only",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				first, _, _ := strings.Cut(r.Header.Get("Range"), ",")
+				r.Header.Set("Range", first)
+				http.ServeContent(w, r, "test_5mb.bin", time.Time{}, bytes.NewReader(expected))
+			},
+			requests: 5,
+		},
+		{
+			name: "multiple ranges ignored",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				if strings.Contains(r.Header.Get("Range"), ",") {
+					r.Header.Del("Range")
+				}
+				http.ServeContent(w, r, "test_5mb.bin", time.Time{}, bytes.NewReader(expected))
+			},
+			requests: 6,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var requests atomic.Int64
+			svr := newTestServerWithHandler(func(http.Handler) http.Handler {
+				return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					if r.Method == http.MethodGet {
+						requests.Add(1)
+					}
+					test.handler(w, r)
+				})
+			})
+			defer svr.Close()
+
+			// the files of GetAll don't download until they're read
+			files, err := httpio.GetAll(context.Background(), []string{svr.URL().JoinPath("test_5mb.bin").String()}, test.opts...)
+			if err != nil {
+				t.Fatalf("failed to setup request: %v", err)
+			}
+			remoteFile := files[0]
+			defer remoteFile.Close()
+
+			bufs, err := remoteFile.ReadRanges(ranges)
+			if err != nil {
+				t.Fatalf("unable to read ranges: %v", err)
+			}
+
+			for i, r := range ranges {
+				end := size
+				if r.Length >= 0 {
+					end = min(r.Offset+r.Length, size)
+				}
+
+				if !bytes.Equal(bufs[i], expected[r.Offset:end]) {
+					t.Errorf("mismatched content of range %d", i)
+				}
+			}
+
+			if n := requests.Load(); n != test.requests {
+				t.Errorf("expected %d requests but got %d", test.requests, n)
+			}
+		})
+	}
+}
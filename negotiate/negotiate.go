@@ -0,0 +1,194 @@
+// Package negotiate adds SPNEGO (Negotiate) authentication to httpio
+// requests, for downloading from intranet servers behind Windows
+// integrated authentication.
+//
+// The Kerberos implementation is provided by the caller through a
+// TokenSource, keeping this package free of dependencies. A TokenSource can
+// be backed by e.g. the spnego client of github.com/jcmturner/gokrb5 or by
+// SSPI on Windows.
+package negotiate
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/jobstoit/httpio"
+)
+
+const (
+	headerAuthorization   = "Authorization"
+	headerWWWAuthenticate = "WWW-Authenticate"
+	schemeNegotiate       = "Negotiate"
+)
+
+// maxLegs limits the number of round trips of a single authentication
+const maxLegs = 5
+
+// TokenSource provides the initial SPNEGO token for a service
+type TokenSource interface {
+	// Token returns the SPNEGO token for the given service principal name
+	Token(ctx context.Context, spn string) ([]byte, error)
+}
+
+// Continuer is implemented by a TokenSource that handles the tokens the
+// server sends back, for multi leg authentication and mutual authentication
+type Continuer interface {
+	// Continue processes the server's token, returning the next token to
+	// send or nil when the authentication is complete
+	Continue(ctx context.Context, spn string, token []byte) ([]byte, error)
+}
+
+// TokenSourceFunc is a function implementing TokenSource
+type TokenSourceFunc func(ctx context.Context, spn string) ([]byte, error)
+
+// Token implements TokenSource
+func (fn TokenSourceFunc) Token(ctx context.Context, spn string) ([]byte, error) {
+	return fn(ctx, spn)
+}
+
+// Transport answers Negotiate challenges of the allowed hosts with tokens
+// from the Source. Requests are sent without credentials first, so tokens
+// are never sent to servers that didn't ask for them.
+type Transport struct {
+	// Base is the underlying transport, http.DefaultTransport when nil
+	Base http.RoundTripper
+	// Source provides the SPNEGO tokens
+	Source TokenSource
+	// SPN overrides the service principal name, defaults to HTTP/<host>
+	SPN string
+	// Hosts are the hosts that get tokens, defaults to the host of the
+	// first request so redirects to other hosts don't receive them
+	Hosts []string
+
+	once  sync.Once
+	hosts []string
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	t.once.Do(func() {
+		t.hosts = t.Hosts
+		if len(t.hosts) == 0 {
+			t.hosts = []string{req.URL.Hostname()}
+		}
+	})
+
+	res, err := t.base().RoundTrip(req)
+	if err != nil || res.StatusCode != http.StatusUnauthorized || !t.allowed(req) {
+		return res, err
+	}
+
+	if _, ok := challenge(res); !ok || (req.Body != nil && req.GetBody == nil) {
+		return res, nil
+	}
+
+	ctx, spn := req.Context(), t.spn(req)
+
+	token, err := t.Source.Token(ctx, spn)
+	if err != nil {
+		res.Body.Close()
+		return nil, fmt.Errorf("unable to get negotiate token: %w", err)
+	}
+
+	for range maxLegs {
+		res.Body.Close()
+
+		if res, err = t.send(req, token); err != nil {
+			return nil, err
+		}
+
+		serverToken, ok := challenge(res)
+		if !ok || len(serverToken) == 0 {
+			return res, nil
+		}
+
+		continuer, ok := t.Source.(Continuer)
+		if !ok {
+			return res, nil
+		}
+
+		if token, err = continuer.Continue(ctx, spn, serverToken); err != nil {
+			res.Body.Close()
+			return nil, fmt.Errorf("unable to continue negotiation: %w", err)
+		}
+
+		if res.StatusCode != http.StatusUnauthorized || token == nil {
+			return res, nil
+		}
+	}
+
+	return res, nil
+}
+
+// send retries the request with the given token
+func (t *Transport) send(req *http.Request, token []byte) (*http.Response, error) {
+	retry := req.Clone(req.Context())
+	if req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			return nil, err
+		}
+		retry.Body = body
+	}
+	retry.Header.Set(headerAuthorization, schemeNegotiate+" "+base64.StdEncoding.EncodeToString(token))
+
+	return t.base().RoundTrip(retry)
+}
+
+func (t *Transport) allowed(req *http.Request) bool {
+	return slices.Contains(t.hosts, req.URL.Hostname())
+}
+
+func (t *Transport) spn(req *http.Request) string {
+	if t.SPN != "" {
+		return t.SPN
+	}
+
+	return "HTTP/" + req.URL.Hostname()
+}
+
+func (t *Transport) base() http.RoundTripper {
+	if t.Base == nil {
+		return http.DefaultTransport
+	}
+
+	return t.Base
+}
+
+// challenge returns the token of the response's Negotiate challenge, which
+// is empty for the initial challenge
+func challenge(res *http.Response) ([]byte, bool) {
+	for _, value := range res.Header.Values(headerWWWAuthenticate) {
+		scheme, param, _ := strings.Cut(strings.TrimSpace(value), " ")
+		if !strings.EqualFold(scheme, schemeNegotiate) {
+			continue
+		}
+
+		token, err := base64.StdEncoding.DecodeString(strings.TrimSpace(param))
+		if err != nil {
+			return nil, false
+		}
+
+		return token, true
+	}
+
+	return nil, false
+}
+
+// WithNegotiate authenticates the preflight and all chunk requests using
+// SPNEGO tokens from the given source. Tokens are only sent to the given
+// hosts, or to the host of the download's URL when none are given.
+func WithNegotiate(src TokenSource, hosts ...string) httpio.Option {
+	return httpio.WithTransportWrapper(func(rt http.RoundTripper) http.RoundTripper {
+		return &Transport{
+			Base:   rt,
+			Source: src,
+			Hosts:  hosts,
+		}
+	})
+}
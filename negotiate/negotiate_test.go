@@ -0,0 +1,161 @@
+package negotiate_test
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/jobstoit/httpio"
+	"github.com/jobstoit/httpio/negotiate"
+)
+
+func TestWithNegotiate(t *testing.T) {
+	content := bytes.Repeat([]byte("negotiate"), 1024*128)
+
+	var authorized atomic.Int32
+	svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Negotiate dG9rZW4=" {
+			w.Header().Set("WWW-Authenticate", "Negotiate")
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		authorized.Add(1)
+		http.ServeContent(w, r, "file", time.Time{}, bytes.NewReader(content))
+	}))
+	defer svr.Close()
+
+	var spn atomic.Value
+	src := negotiate.TokenSourceFunc(func(ctx context.Context, s string) ([]byte, error) {
+		spn.Store(s)
+		return []byte("token"), nil
+	})
+
+	remoteFile, err := httpio.Get(svr.URL, negotiate.WithNegotiate(src), httpio.WithChunkSize(1024*256))
+	if err != nil {
+		t.Fatalf("failed to setup request: %v", err)
+	}
+
+	actual, err := io.ReadAll(remoteFile)
+	if err != nil {
+		t.Fatalf("unable to read file: %v", err)
+	}
+
+	if !bytes.Equal(content, actual) {
+		t.Errorf("mismatched content")
+	}
+
+	if e, a := "HTTP/127.0.0.1", spn.Load(); e != a {
+		t.Errorf("expected spn '%s', got '%s'", e, a)
+	}
+
+	if authorized.Load() < 2 {
+		t.Errorf("expected the preflight and chunk requests to be authorized, got %d", authorized.Load())
+	}
+}
+
+type continuingSource struct {
+	continued []string
+	mu        sync.Mutex
+}
+
+func (s *continuingSource) Token(ctx context.Context, spn string) ([]byte, error) {
+	return []byte("token"), nil
+}
+
+func (s *continuingSource) Continue(ctx context.Context, spn string, token []byte) ([]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.continued = append(s.continued, string(token))
+	if string(token) == "step" {
+		return []byte("next"), nil
+	}
+
+	return nil, nil
+}
+
+func TestWithNegotiateContinue(t *testing.T) {
+	content := []byte("negotiated in two legs")
+
+	svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Header.Get("Authorization") {
+		case "Negotiate dG9rZW4=":
+			w.Header().Set("WWW-Authenticate", "Negotiate c3RlcA==")
+			w.WriteHeader(http.StatusUnauthorized)
+		case "Negotiate bmV4dA==":
+			w.Header().Set("WWW-Authenticate", "Negotiate ZG9uZQ==")
+			http.ServeContent(w, r, "file", time.Time{}, bytes.NewReader(content))
+		default:
+			w.Header().Set("WWW-Authenticate", "Negotiate")
+			w.WriteHeader(http.StatusUnauthorized)
+		}
+	}))
+	defer svr.Close()
+
+	src := &continuingSource{}
+	remoteFile, err := httpio.Get(svr.URL, negotiate.WithNegotiate(src), httpio.WithConcurrency(1))
+	if err != nil {
+		t.Fatalf("failed to setup request: %v", err)
+	}
+
+	actual, err := io.ReadAll(remoteFile)
+	if err != nil {
+		t.Fatalf("unable to read file: %v", err)
+	}
+
+	if !bytes.Equal(content, actual) {
+		t.Errorf("mismatched content")
+	}
+
+	src.mu.Lock()
+	defer src.mu.Unlock()
+
+	if e, a := []string{"step", "done", "step", "done"}, src.continued; !slices.Equal(e, a) {
+		t.Errorf("expected continued tokens %v, got %v", e, a)
+	}
+}
+
+func TestWithNegotiateOtherHost(t *testing.T) {
+	var requests, leaked atomic.Int32
+	other := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		if r.Header.Get("Authorization") != "" {
+			leaked.Add(1)
+		}
+
+		w.Header().Set("WWW-Authenticate", "Negotiate")
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer other.Close()
+
+	otherURL := strings.Replace(other.URL, "127.0.0.1", "localhost", 1)
+	svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, otherURL, http.StatusFound)
+	}))
+	defer svr.Close()
+
+	src := negotiate.TokenSourceFunc(func(ctx context.Context, s string) ([]byte, error) {
+		return []byte("token"), nil
+	})
+
+	if remoteFile, err := httpio.Get(svr.URL, negotiate.WithNegotiate(src)); err == nil {
+		_, _ = io.Copy(io.Discard, remoteFile)
+	}
+
+	if requests.Load() == 0 {
+		t.Fatalf("expected the request to be redirected")
+	}
+
+	if leaked.Load() != 0 {
+		t.Errorf("expected no token to be sent to the redirected host, got %d", leaked.Load())
+	}
+}
@@ -0,0 +1,113 @@
+// Package otel traces httpio downloads with OpenTelemetry, with a span per
+// download and a child span per chunk. The trace context is propagated in
+// the headers of the requests.
+package otel
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/jobstoit/httpio"
+	otelglobal "go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// ScopeName is the instrumentation scope of the spans
+const ScopeName = "github.com/jobstoit/httpio/otel"
+
+type config struct {
+	provider   trace.TracerProvider
+	propagator propagation.TextMapPropagator
+}
+
+// Option configures the tracing
+type Option func(*config)
+
+// WithTracerProvider sets the provider of the tracer, which is the global
+// provider by default
+func WithTracerProvider(provider trace.TracerProvider) Option {
+	return func(c *config) {
+		c.provider = provider
+	}
+}
+
+// WithPropagator sets the propagator injecting the trace context into the
+// requests, which is the global propagator by default
+func WithPropagator(propagator propagation.TextMapPropagator) Option {
+	return func(c *config) {
+		c.propagator = propagator
+	}
+}
+
+// WithTracing traces the download of the file with a span covering the
+// download and a child span for the requests of every chunk
+func WithTracing(opts ...Option) httpio.Option {
+	c := &config{
+		provider:   otelglobal.GetTracerProvider(),
+		propagator: otelglobal.GetTextMapPropagator(),
+	}
+
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	return httpio.Options(
+		httpio.WithTracer(&tracer{tracer: c.provider.Tracer(ScopeName)}),
+		httpio.WithTransportWrapper(func(rt http.RoundTripper) http.RoundTripper {
+			return &transport{base: rt, propagator: c.propagator}
+		}),
+	)
+}
+
+// tracer implements httpio.Tracer with spans
+type tracer struct {
+	tracer trace.Tracer
+}
+
+func (t *tracer) StartDownload(ctx context.Context, url string) (context.Context, func(error)) {
+	ctx, span := t.tracer.Start(ctx, "httpio.download",
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attribute.String("url.full", url)),
+	)
+
+	return ctx, func(err error) { end(span, err) }
+}
+
+func (t *tracer) StartChunk(ctx context.Context, c httpio.Chunk) (context.Context, func(error)) {
+	ctx, span := t.tracer.Start(ctx, "httpio.chunk",
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			attribute.Int("httpio.chunk.index", c.Index),
+			attribute.Int64("httpio.chunk.offset", c.Offset),
+			attribute.Int64("httpio.chunk.length", c.Length),
+		),
+	)
+
+	return ctx, func(err error) { end(span, err) }
+}
+
+// end ends the span, recording the error it failed with
+func end(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+
+	span.End()
+}
+
+// transport injects the trace context of the requests into their headers
+type transport struct {
+	base       http.RoundTripper
+	propagator propagation.TextMapPropagator
+}
+
+func (t *transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	t.propagator.Inject(req.Context(), propagation.HeaderCarrier(req.Header))
+
+	return t.base.RoundTrip(req)
+}
@@ -0,0 +1,84 @@
+package otel_test
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/jobstoit/httpio"
+	"github.com/jobstoit/httpio/otel"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestWithTracing(t *testing.T) {
+	content := bytes.Repeat([]byte("httpio"), 1024*1024)
+
+	var propagated atomic.Int32
+	svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Traceparent") != "" {
+			propagated.Add(1)
+		}
+
+		http.ServeContent(w, r, "file", time.Time{}, bytes.NewReader(content))
+	}))
+	defer svr.Close()
+
+	exporter := tracetest.NewInMemoryExporter()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+
+	remoteFile, err := httpio.Get(svr.URL,
+		httpio.WithChunkSize(1024*1024),
+		otel.WithTracing(otel.WithTracerProvider(provider), otel.WithPropagator(propagation.TraceContext{})),
+	)
+	if err != nil {
+		t.Fatalf("failed to setup request: %v", err)
+	}
+
+	body, err := io.ReadAll(remoteFile)
+	if err != nil {
+		t.Fatalf("unable to read file: %v", err)
+	}
+	remoteFile.Close()
+
+	if !bytes.Equal(content, body) {
+		t.Errorf("mismatched content")
+	}
+
+	spans := exporter.GetSpans()
+
+	var download sdktrace.ReadOnlySpan
+	chunks := 0
+	for _, span := range spans.Snapshots() {
+		switch span.Name() {
+		case "httpio.download":
+			download = span
+		case "httpio.chunk":
+			chunks++
+		}
+	}
+
+	if download == nil {
+		t.Fatalf("expected a download span, got %d spans", len(spans))
+	}
+
+	for _, span := range spans.Snapshots() {
+		if span.Name() == "httpio.chunk" && span.Parent().SpanID() != download.SpanContext().SpanID() {
+			t.Errorf("expected the chunk spans to be children of the download span")
+		}
+	}
+
+	if e, a := 6, chunks; e != a {
+		t.Errorf("expected %d chunk spans, got %d", e, a)
+	}
+
+	// the metadata request and the chunk requests
+	if e, a := int32(7), propagated.Load(); e != a {
+		t.Errorf("expected the trace context in %d requests, got %d", e, a)
+	}
+}
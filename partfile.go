@@ -0,0 +1,71 @@
+package httpio
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// PartSuffix is appended to the download path for the file DownloadFile
+// writes to, it's renamed to the download path once it's complete
+const PartSuffix = ".part"
+
+// WithFileMode sets the permissions of the file downloaded with
+// DownloadFile, regardless of the umask. It's 0644 by default.
+func WithFileMode(mode os.FileMode) Option {
+	return func(f *RemoteFile) error {
+		f.fileMode = mode.Perm()
+
+		return nil
+	}
+}
+
+// WithFsync makes DownloadFile flush the file to disk before renaming it
+// into place, followed by its directory, so the file survives a crash once
+// DownloadFile returns
+func WithFsync() Option {
+	return func(f *RemoteFile) error {
+		f.fsync = true
+
+		return nil
+	}
+}
+
+// WithPreallocate makes DownloadFile allocate the disk space of the whole
+// file before downloading, which avoids fragmentation and fails early when
+// the disk is too small. Where it's unsupported the file is only extended.
+func WithPreallocate() Option {
+	return func(f *RemoteFile) error {
+		f.preallocate = true
+
+		return nil
+	}
+}
+
+// finishFile applies the file mode and flushes the file when configured
+// with WithFileMode and WithFsync
+func (f *RemoteFile) finishFile(out *os.File) error {
+	if f.fileMode != 0 {
+		if err := out.Chmod(f.fileMode); err != nil {
+			return err
+		}
+	}
+
+	if f.fsync {
+		return out.Sync()
+	}
+
+	return nil
+}
+
+// syncDir flushes the directory at the given path, so a rename in it is
+// durable. Directories can't be flushed on every platform, so failing to
+// open or flush it isn't an error.
+func syncDir(path string) {
+	dir, err := os.Open(filepath.Clean(path))
+	if err != nil {
+		return
+	}
+	defer dir.Close()
+
+	_ = dir.Sync()
+}
@@ -0,0 +1,276 @@
+package httpio
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	headerUserAgent = "User-Agent"
+	// maxRobotsSize is the number of bytes of a robots.txt that are parsed
+	maxRobotsSize = 512 * 1024
+)
+
+// Politeness makes bulk downloads behave like a well-mannered crawler, it
+// identifies itself with the user agent, follows the rules of robots.txt
+// and spaces the requests to a host. Share it between downloads to apply
+// the spacing to all of them.
+type Politeness struct {
+	userAgent string
+	delay     time.Duration
+
+	mu    sync.Mutex
+	hosts map[string]*politeHost
+}
+
+// NewPoliteness returns a Politeness sending requests to a host at most
+// once per delay, or less often when the host's robots.txt asks for a
+// larger Crawl-delay
+func NewPoliteness(userAgent string, delay time.Duration) *Politeness {
+	return &Politeness{
+		userAgent: userAgent,
+		delay:     delay,
+		hosts:     map[string]*politeHost{},
+	}
+}
+
+type politeHost struct {
+	robotsMu sync.Mutex
+	robots   *robots
+
+	mu   sync.Mutex
+	next time.Time
+}
+
+func (p *Politeness) host(u *url.URL) *politeHost {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	key := u.Scheme + "://" + u.Host
+	h, ok := p.hosts[key]
+	if !ok {
+		h = &politeHost{}
+		p.hosts[key] = h
+	}
+
+	return h
+}
+
+// politeTransport applies the Politeness to the requests it sends
+type politeTransport struct {
+	base   http.RoundTripper
+	polite *Politeness
+}
+
+func (t *politeTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	h := t.polite.host(req.URL)
+	rules := t.robots(req, h)
+
+	if !rules.allowed(req.URL.EscapedPath()) {
+		return nil, fmt.Errorf("%w: %s", ErrRobotsDisallowed, req.URL)
+	}
+
+	if err := sleep(req.Context(), h.wait(max(t.polite.delay, rules.crawlDelay))); err != nil {
+		return nil, err
+	}
+
+	req = req.Clone(req.Context())
+	req.Header.Set(headerUserAgent, t.polite.userAgent)
+
+	return t.base.RoundTrip(req)
+}
+
+// wait reserves the next request slot of the host, returning the time until it
+func (h *politeHost) wait(delay time.Duration) time.Duration {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	now := time.Now()
+	at := h.next
+	if at.Before(now) {
+		at = now
+	}
+	h.next = at.Add(delay)
+
+	return at.Sub(now)
+}
+
+// robots returns the robots.txt rules of the host, fetching them on first
+// use. Rules of a canceled fetch aren't kept.
+func (t *politeTransport) robots(req *http.Request, h *politeHost) *robots {
+	h.robotsMu.Lock()
+	defer h.robotsMu.Unlock()
+
+	if h.robots != nil {
+		return h.robots
+	}
+
+	rules := t.fetchRobots(req)
+	if req.Context().Err() == nil {
+		h.robots = rules
+	}
+
+	return rules
+}
+
+// fetchRobots fetches the robots.txt of the request's host. As in RFC 9309
+// a missing robots.txt allows everything and an unreachable one disallows
+// everything.
+func (t *politeTransport) fetchRobots(req *http.Request) *robots {
+	robotsURL := &url.URL{Scheme: req.URL.Scheme, Host: req.URL.Host, Path: "/robots.txt"}
+
+	robotsReq, err := http.NewRequestWithContext(req.Context(), http.MethodGet, robotsURL.String(), nil)
+	if err != nil {
+		return &robots{disallowAll: true}
+	}
+	robotsReq.Header.Set(headerUserAgent, t.polite.userAgent)
+
+	res, err := t.base.RoundTrip(robotsReq)
+	if err != nil {
+		return &robots{disallowAll: true}
+	}
+	defer res.Body.Close()
+
+	switch {
+	case res.StatusCode >= 500:
+		return &robots{disallowAll: true}
+	case res.StatusCode != http.StatusOK:
+		return &robots{}
+	}
+
+	return parseRobots(io.LimitReader(res.Body, maxRobotsSize), t.polite.userAgent)
+}
+
+// robots are the rules of a robots.txt that apply to a user agent
+type robots struct {
+	disallowAll bool
+	rules       []robotsRule
+	crawlDelay  time.Duration
+}
+
+type robotsRule struct {
+	allow   bool
+	pattern string
+	match   *regexp.Regexp
+}
+
+// allowed reports whether the path may be requested, the longest matching
+// rule applies and allow rules win ties
+func (r *robots) allowed(path string) bool {
+	if r.disallowAll {
+		return false
+	}
+
+	allowed, longest := true, -1
+	for _, rule := range r.rules {
+		if !rule.match.MatchString(path) {
+			continue
+		}
+
+		if n := len(rule.pattern); n > longest || (n == longest && rule.allow) {
+			allowed, longest = rule.allow, n
+		}
+	}
+
+	return allowed
+}
+
+// compileRobots compiles a robots.txt path pattern with the wildcard *
+// and the end anchor $
+func compileRobots(pattern string) *regexp.Regexp {
+	anchored := strings.HasSuffix(pattern, "$")
+	parts := strings.Split(strings.TrimSuffix(pattern, "$"), "*")
+	for i, part := range parts {
+		parts[i] = regexp.QuoteMeta(part)
+	}
+
+	expr := "^" + strings.Join(parts, ".*")
+	if anchored {
+		expr += "$"
+	}
+
+	return regexp.MustCompile(expr)
+}
+
+// parseRobots parses the group of the robots.txt that applies to the user
+// agent, falling back to the group for all user agents
+func parseRobots(rd io.Reader, userAgent string) *robots {
+	token := strings.ToLower(userAgent)
+	if i := strings.IndexAny(token, "/ "); i >= 0 {
+		token = token[:i]
+	}
+
+	var (
+		specific, wildcard *robots
+		current            []*robots
+		inAgents           bool
+	)
+
+	scanner := bufio.NewScanner(rd)
+	for scanner.Scan() {
+		line, _, _ := strings.Cut(scanner.Text(), "#")
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		key, value = strings.ToLower(strings.TrimSpace(key)), strings.TrimSpace(value)
+
+		if key == "user-agent" {
+			if !inAgents {
+				current = nil
+			}
+			inAgents = true
+
+			agent := strings.ToLower(value)
+			switch {
+			case agent == "*":
+				if wildcard == nil {
+					wildcard = &robots{}
+				}
+				current = append(current, wildcard)
+			case token != "" && strings.Contains(agent, token):
+				if specific == nil {
+					specific = &robots{}
+				}
+				current = append(current, specific)
+			}
+
+			continue
+		}
+		inAgents = false
+
+		for _, group := range current {
+			switch key {
+			case "allow", "disallow":
+				if value != "" {
+					group.rules = append(group.rules, robotsRule{
+						allow:   key == "allow",
+						pattern: value,
+						match:   compileRobots(value),
+					})
+				}
+			case "crawl-delay":
+				if seconds, err := strconv.ParseFloat(value, 64); err == nil && seconds > 0 {
+					group.crawlDelay = time.Duration(seconds * float64(time.Second))
+				}
+			}
+		}
+	}
+
+	switch {
+	case specific != nil:
+		return specific
+	case wildcard != nil:
+		return wildcard
+	default:
+		return &robots{}
+	}
+}
@@ -0,0 +1,86 @@
+package httpio_test
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jobstoit/httpio"
+)
+
+func TestGetPoliteness(t *testing.T) {
+	const robotsTxt = `User-agent: *
+Disallow: /
+
+User-agent: testbot
+Disallow: /assets/test_
+Allow: /assets/test_5mb$
+Disallow: /*.png$
+`
+
+	var (
+		mu     sync.Mutex
+		times  []time.Time
+		agents = map[string]bool{}
+	)
+	svr := newTestServerWithHandler(func(h http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			mu.Lock()
+			times = append(times, time.Now())
+			agents[r.UserAgent()] = true
+			mu.Unlock()
+
+			if r.URL.Path == "/robots.txt" {
+				fmt.Fprint(w, robotsTxt)
+				return
+			}
+
+			h.ServeHTTP(w, r)
+		})
+	})
+	defer svr.Close()
+
+	delay := time.Millisecond * 50
+	polite := httpio.NewPoliteness("testbot/1.0", delay)
+
+	remoteFile, err := httpio.Get(svr.URL().JoinPath("assets", "test_5mb").String(),
+		httpio.WithPoliteness(polite),
+		httpio.WithChunkSize(1024*1024),
+	)
+	if err != nil {
+		t.Fatalf("failed to setup request: %v", err)
+	}
+
+	if _, err := io.Copy(io.Discard, remoteFile); err != nil {
+		t.Fatalf("unable to read file: %v", err)
+	}
+
+	for _, name := range []string{"test_12mb", "GitHub_logo.png"} {
+		_, err := httpio.Get(svr.URL().JoinPath("assets", name).String(), httpio.WithPoliteness(polite))
+		if !errors.Is(err, httpio.ErrRobotsDisallowed) {
+			t.Errorf("expected '%s' to be disallowed, got: %v", name, err)
+		}
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	// the robots.txt, the preflight and 5 chunks
+	if e, a := 7, len(times); e != a {
+		t.Fatalf("expected %d requests, got %d", e, a)
+	}
+
+	for i := 2; i < len(times); i++ {
+		if gap := times[i].Sub(times[i-1]); gap < delay-time.Millisecond*5 {
+			t.Errorf("expected requests at least %s apart, got %s", delay, gap)
+		}
+	}
+
+	if len(agents) != 1 || !agents["testbot/1.0"] {
+		t.Errorf("expected all requests to identify as testbot/1.0, got %v", agents)
+	}
+}
@@ -0,0 +1,45 @@
+package httpio
+
+import (
+	"io"
+	"sync"
+)
+
+// copyBufferSize is the size of the buffers copying the chunks
+const copyBufferSize = 32 * 1024
+
+// copyBuffers are the buffers copying the chunks, shared by all downloads
+var copyBuffers = sync.Pool{
+	New: func() any {
+		buf := make([]byte, copyBufferSize)
+		return &buf
+	},
+}
+
+// chunkBuffers are the buffers holding chunks fetched ahead, shared by all
+// downloads. The buffers are of the chunk sizes they were allocated for.
+var chunkBuffers sync.Pool
+
+// copyBuffer copies from src to dst like io.Copy, with a buffer from the
+// shared pool
+func copyBuffer(dst io.Writer, src io.Reader) (int64, error) {
+	buf := copyBuffers.Get().(*[]byte)
+	defer copyBuffers.Put(buf)
+
+	return io.CopyBuffer(dst, src, *buf)
+}
+
+// getChunkBuffer returns a buffer of the given length from the shared pool,
+// allocating a new one when the pooled one is too small
+func getChunkBuffer(length int64) []byte {
+	if buf, ok := chunkBuffers.Get().(*[]byte); ok && int64(cap(*buf)) >= length {
+		return (*buf)[:length]
+	}
+
+	return make([]byte, length)
+}
+
+// putChunkBuffer returns the buffer to the shared pool once it's written
+func putChunkBuffer(buf []byte) {
+	chunkBuffers.Put(&buf)
+}
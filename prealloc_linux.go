@@ -0,0 +1,18 @@
+package httpio
+
+import (
+	"errors"
+	"os"
+	"syscall"
+)
+
+// preallocate allocates the disk space for the file up to the given size,
+// falling back to extending the file on filesystems without fallocate
+func preallocate(file *os.File, size int64) error {
+	err := syscall.Fallocate(int(file.Fd()), 0, 0, size)
+	if errors.Is(err, syscall.EOPNOTSUPP) || errors.Is(err, syscall.ENOSYS) {
+		return file.Truncate(size)
+	}
+
+	return err
+}
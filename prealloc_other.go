@@ -0,0 +1,11 @@
+//go:build !linux
+
+package httpio
+
+import "os"
+
+// preallocate extends the file to the given size, the disk space isn't
+// allocated up front on this platform
+func preallocate(file *os.File, size int64) error {
+	return file.Truncate(size)
+}
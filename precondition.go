@@ -0,0 +1,45 @@
+package httpio
+
+import (
+	"net/http"
+	"time"
+)
+
+const (
+	headerIfMatch           = "If-Match"
+	headerIfUnmodifiedSince = "If-Unmodified-Since"
+)
+
+// WithIfMatch only downloads the file while its entity tag matches the
+// given one, otherwise the download fails with a PreconditionFailedError
+func WithIfMatch(etag string) Option {
+	return func(f *RemoteFile) error {
+		f.req.Header.Set(headerIfMatch, etag)
+
+		return nil
+	}
+}
+
+// WithIfUnmodifiedSince only downloads the file while it's unmodified since
+// the given time, otherwise the download fails with a PreconditionFailedError
+func WithIfUnmodifiedSince(t time.Time) Option {
+	return func(f *RemoteFile) error {
+		f.req.Header.Set(headerIfUnmodifiedSince, t.UTC().Format(http.TimeFormat))
+
+		return nil
+	}
+}
+
+// checkPrecondition returns a PreconditionFailedError when the server
+// responded with 412 Precondition Failed
+func checkPrecondition(res *http.Response) error {
+	if res.StatusCode != http.StatusPreconditionFailed {
+		return nil
+	}
+
+	return &PreconditionFailedError{
+		URL:          res.Request.URL.String(),
+		ETag:         res.Header.Get(headerETag),
+		LastModified: res.Header.Get(headerLastModified),
+	}
+}
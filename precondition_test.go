@@ -0,0 +1,88 @@
+package httpio_test
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/jobstoit/httpio"
+)
+
+func TestGetIfMatch(t *testing.T) {
+	var requests atomic.Int32
+	svr := newTestServerWithHandler(func(h http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			// the file changes after the third request
+			if requests.Add(1) > 3 {
+				w.Header().Set("ETag", `"v2"`)
+			} else {
+				w.Header().Set("ETag", `"v1"`)
+			}
+
+			h.ServeHTTP(w, r)
+		})
+	})
+	defer svr.Close()
+
+	u := svr.URL().JoinPath("assets", "test_5mb").String()
+
+	var precondErr *httpio.PreconditionFailedError
+	if _, err := httpio.Get(u, httpio.WithIfMatch(`"v0"`)); !errors.As(err, &precondErr) {
+		t.Fatalf("expected a PreconditionFailedError, got: %v", err)
+	}
+
+	if precondErr.ETag != `"v1"` {
+		t.Errorf("expected the current entity tag, got: %s", precondErr.ETag)
+	}
+
+	remoteFile, err := httpio.Get(u, httpio.WithIfMatch(`"v1"`), httpio.WithConcurrency(1), httpio.WithChunkSize(1024*1024))
+	if err != nil {
+		t.Fatalf("unable to get file: %v", err)
+	}
+
+	if _, err := io.Copy(io.Discard, remoteFile); !errors.As(err, &precondErr) {
+		t.Errorf("expected a PreconditionFailedError once the file changed, got: %v", err)
+	}
+}
+
+func TestGetIfUnmodifiedSince(t *testing.T) {
+	modified := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+
+	svr := newTestServerWithHandler(func(h http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			since, err := http.ParseTime(r.Header.Get("If-Unmodified-Since"))
+			if err != nil || since.Before(modified) {
+				w.Header().Set("Last-Modified", modified.Format(http.TimeFormat))
+				w.WriteHeader(http.StatusPreconditionFailed)
+
+				return
+			}
+
+			h.ServeHTTP(w, r)
+		})
+	})
+	defer svr.Close()
+
+	u := svr.URL().JoinPath("assets", "test_5mb").String()
+
+	var precondErr *httpio.PreconditionFailedError
+	if _, err := httpio.Get(u, httpio.WithIfUnmodifiedSince(modified.Add(-time.Hour))); !errors.As(err, &precondErr) {
+		t.Fatalf("expected a PreconditionFailedError, got: %v", err)
+	}
+
+	if precondErr.LastModified != modified.Format(http.TimeFormat) {
+		t.Errorf("expected the current modification time, got: %s", precondErr.LastModified)
+	}
+
+	remoteFile, err := httpio.Get(u, httpio.WithIfUnmodifiedSince(modified))
+	if err != nil {
+		t.Fatalf("unable to get file: %v", err)
+	}
+
+	if _, err := io.Copy(io.Discard, remoteFile); err != nil {
+		t.Errorf("unable to read file: %v", err)
+	}
+}
@@ -0,0 +1,107 @@
+package httpio
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// DefaultPrefetchConcurrency is the number of prefetches that run at the same time
+const DefaultPrefetchConcurrency = 2
+
+// prefetchSlots limits the prefetches over all downloads, keeping them
+// from competing with the latency sensitive downloads
+var prefetchSlots = make(chan struct{}, DefaultPrefetchConcurrency)
+
+// ByteRange is a range of Length bytes starting at Offset,
+// a negative Length means until the end of the file
+type ByteRange struct {
+	Offset int64
+	Length int64
+}
+
+// String returns the range as the value of a Range header
+func (r ByteRange) String() string {
+	if r.Length < 0 {
+		return fmt.Sprintf("bytes=%d-", r.Offset)
+	}
+
+	return fmt.Sprintf("bytes=%d-%d", r.Offset, r.Offset+r.Length-1)
+}
+
+// Prefetch warms the cache configured with WithHTTPCache in the background,
+// fetching the whole file or only the given ranges, nil fetches the whole
+// file. Later chunk requests are served from a prefetched range containing
+// them, so ranges aligned to the chunk size are served completely. The
+// ranges are fetched one at a time and only a limited number of prefetches
+// run at once. The returned channel receives the result once the prefetch
+// is done.
+func Prefetch(ctx context.Context, url string, ranges []ByteRange, opts ...Option) <-chan error {
+	done := make(chan error, 1)
+
+	go func() {
+		defer close(done)
+		done <- prefetch(ctx, url, ranges, opts...)
+	}()
+
+	return done
+}
+
+func prefetch(ctx context.Context, url string, ranges []ByteRange, opts ...Option) error {
+	f, err := newRemoteFile(ctx, url, opts...)
+	if err != nil {
+		return err
+	}
+	defer f.closeIdleConnections()
+
+	if f.httpCache == nil {
+		return ErrNoCache
+	}
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case prefetchSlots <- struct{}{}:
+	}
+	defer func() {
+		<-prefetchSlots
+	}()
+
+	if len(ranges) == 0 {
+		return f.prefetchRange(ctx, "")
+	}
+
+	for _, r := range ranges {
+		if err := f.prefetchRange(ctx, r.String()); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (f *RemoteFile) prefetchRange(ctx context.Context, rangeHeader string) error {
+	req := f.newRequest(ctx)
+	if rangeHeader != "" {
+		req.Header.Set(headerRange, rangeHeader)
+	}
+
+	res, err := f.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if err := checkPrecondition(res); err != nil {
+		return err
+	}
+
+	if res.StatusCode != http.StatusOK && res.StatusCode != http.StatusPartialContent {
+		return newStatusError(res)
+	}
+
+	_, err = io.Copy(io.Discard, res.Body)
+
+	return err
+}
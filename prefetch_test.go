@@ -0,0 +1,174 @@
+package httpio_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/jobstoit/httpio"
+)
+
+func TestPrefetch(t *testing.T) {
+	content := bytes.Repeat([]byte("prefetched"), 1024*128)
+
+	var ranged atomic.Int32
+	svr := newTestServerWithHandler(func(http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Header.Get("Range") != "" {
+				ranged.Add(1)
+			}
+
+			w.Header().Set("Cache-Control", "max-age=60")
+			http.ServeContent(w, r, "file", time.Time{}, bytes.NewReader(content))
+		})
+	})
+	defer svr.Close()
+
+	u := svr.URL().JoinPath("file").String()
+	cache := httpio.NewMemoryCache()
+
+	if err := <-httpio.Prefetch(context.Background(), u, nil, httpio.WithHTTPCache(cache)); err != nil {
+		t.Fatalf("unable to prefetch: %v", err)
+	}
+
+	remoteFile, err := httpio.Get(u, httpio.WithHTTPCache(cache), httpio.WithChunkSize(1024*256))
+	if err != nil {
+		t.Fatalf("failed to setup request: %v", err)
+	}
+
+	actual, err := io.ReadAll(remoteFile)
+	if err != nil {
+		t.Fatalf("unable to read file: %v", err)
+	}
+
+	if !bytes.Equal(content, actual) {
+		t.Errorf("mismatched content")
+	}
+
+	if ranged.Load() != 0 {
+		t.Errorf("expected the chunks to be served from the prefetched object, got %d range requests", ranged.Load())
+	}
+}
+
+func TestPrefetchRanges(t *testing.T) {
+	content := bytes.Repeat([]byte("prefetched"), 1024)
+
+	var ranged atomic.Int32
+	svr := newTestServerWithHandler(func(http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Header.Get("Range") != "" {
+				ranged.Add(1)
+			}
+
+			w.Header().Set("Cache-Control", "max-age=60")
+			http.ServeContent(w, r, "file", time.Time{}, bytes.NewReader(content))
+		})
+	})
+	defer svr.Close()
+
+	u := svr.URL().JoinPath("file").String()
+	cache := httpio.NewMemoryCache()
+	ranges := []httpio.ByteRange{{Offset: 0, Length: 4096}, {Offset: 4096, Length: -1}}
+
+	if err := <-httpio.Prefetch(context.Background(), u, ranges, httpio.WithHTTPCache(cache)); err != nil {
+		t.Fatalf("unable to prefetch: %v", err)
+	}
+
+	if e, a := int32(2), ranged.Load(); e != a {
+		t.Fatalf("expected %d range requests, got %d", e, a)
+	}
+
+	remoteFile, err := httpio.Get(u, httpio.WithHTTPCache(cache), httpio.WithChunkSize(1024))
+	if err != nil {
+		t.Fatalf("failed to setup request: %v", err)
+	}
+
+	actual, err := io.ReadAll(remoteFile)
+	if err != nil {
+		t.Fatalf("unable to read file: %v", err)
+	}
+
+	if !bytes.Equal(content, actual) {
+		t.Errorf("mismatched content")
+	}
+
+	if e, a := int32(2), ranged.Load(); e != a {
+		t.Errorf("expected the chunks to be served from the prefetched ranges, got %d range requests", a-e)
+	}
+
+	if err := <-httpio.Prefetch(context.Background(), u, nil); !errors.Is(err, httpio.ErrNoCache) {
+		t.Errorf("expected ErrNoCache without a cache, got: %v", err)
+	}
+}
+
+// readCountingCache counts the bytes read from the cache
+type readCountingCache struct {
+	*httpio.MemoryCache
+	read atomic.Int64
+}
+
+func (c *readCountingCache) Get(key string) ([]byte, bool) {
+	value, ok := c.MemoryCache.Get(key)
+	c.read.Add(int64(len(value)))
+
+	return value, ok
+}
+
+func TestPrefetchManyRanges(t *testing.T) {
+	content := bytes.Repeat([]byte("prefetched"), 1024*16)
+
+	var ranged atomic.Int32
+	svr := newTestServerWithHandler(func(http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Header.Get("Range") != "" {
+				ranged.Add(1)
+			}
+
+			w.Header().Set("Cache-Control", "max-age=60")
+			http.ServeContent(w, r, "file", time.Time{}, bytes.NewReader(content))
+		})
+	})
+	defer svr.Close()
+
+	u := svr.URL().JoinPath("file").String()
+	cache := &readCountingCache{MemoryCache: httpio.NewMemoryCache()}
+
+	var ranges []httpio.ByteRange
+	for offset := int64(0); offset < int64(len(content)); offset += 4096 {
+		ranges = append(ranges, httpio.ByteRange{Offset: offset, Length: min(4096, int64(len(content))-offset)})
+	}
+
+	if err := <-httpio.Prefetch(context.Background(), u, ranges, httpio.WithHTTPCache(cache)); err != nil {
+		t.Fatalf("unable to prefetch: %v", err)
+	}
+	prefetched := ranged.Load()
+	cache.read.Store(0)
+
+	remoteFile, err := httpio.Get(u, httpio.WithHTTPCache(cache), httpio.WithChunkSize(1024))
+	if err != nil {
+		t.Fatalf("failed to setup request: %v", err)
+	}
+
+	actual, err := io.ReadAll(remoteFile)
+	if err != nil {
+		t.Fatalf("unable to read file: %v", err)
+	}
+
+	if !bytes.Equal(content, actual) {
+		t.Errorf("mismatched content")
+	}
+
+	if a := ranged.Load() - prefetched; a != 0 {
+		t.Errorf("expected the chunks to be served from the prefetched ranges, got %d range requests", a)
+	}
+
+	// every chunk decodes the range containing it, not all of the ranges
+	if max, a := int64(len(content))*8, cache.read.Load(); a > max {
+		t.Errorf("expected at most %d bytes read from the cache, got %d", max, a)
+	}
+}
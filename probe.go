@@ -0,0 +1,92 @@
+package httpio
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+)
+
+// probeRange is the range of the GET request probing the size of a file
+const probeRange = "bytes=0-0"
+
+// WithProbe requests the metadata of the file with a GET request for its
+// first byte instead of a HEAD request. This suits URLs that are only
+// valid for GET requests, like presigned S3 and GCS URLs. Without it the
+// probe is used when the server rejects the HEAD request.
+func WithProbe() Option {
+	return func(f *RemoteFile) error {
+		f.probeGet = true
+
+		return nil
+	}
+}
+
+// headRejected reports whether the server refused the HEAD request itself
+// rather than the file, in which case a GET request might be allowed
+func headRejected(res *http.Response) bool {
+	switch res.StatusCode {
+	case http.StatusForbidden, http.StatusMethodNotAllowed, http.StatusNotImplemented:
+		return true
+	default:
+		return false
+	}
+}
+
+// probeMetadata requests the metadata of the file with a GET request for its first
+// byte, the size of the file is taken from the Content-Range of the response
+func (f *RemoteFile) probeMetadata(ctx context.Context) (*Metadata, error) {
+	f.logDebug("probing with a range request")
+
+	req := f.req.Clone(f.traceContext(ctx))
+	req.Header.Set(headerRange, probeRange)
+	f.ifChanged.setConditions(req.Header)
+
+	res, err := f.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("unable to probe content range: %w", err)
+	}
+	defer res.Body.Close()
+
+	f.pacer.update(res.Header)
+
+	if res.StatusCode == http.StatusNotModified {
+		return nil, ErrNotModified
+	}
+
+	if err := checkPrecondition(res); err != nil {
+		return nil, err
+	}
+
+	if err := checkAcceptRanges(res.Header.Get(headerAcceptRanges)); err != nil {
+		return nil, err
+	}
+
+	f.located(res)
+	meta := newMetadata(f.req.URL.String(), res)
+
+	switch res.StatusCode {
+	case http.StatusPartialContent, http.StatusRequestedRangeNotSatisfiable:
+		// an empty file can't satisfy the range and is reported as "bytes */0"
+		contentRange := res.Header.Get(headerContentRange)
+		if err := checkRangeUnit(contentRange); err != nil {
+			return nil, err
+		}
+
+		size, ok := parseContentRangeSize(contentRange)
+		if !ok {
+			return nil, fmt.Errorf("invalid Content-Range: '%s'", contentRange)
+		}
+		meta.Size = size
+
+		if meta.AcceptRanges == "" {
+			meta.AcceptRanges = rangeUnitBytes
+		}
+	case http.StatusOK:
+		// the server ignored the range, so the file is downloaded sequentially
+		meta.AcceptRanges = rangeUnitNone
+	default:
+		return nil, newStatusError(res)
+	}
+
+	return meta, nil
+}
@@ -0,0 +1,140 @@
+package httpio_test
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/jobstoit/httpio"
+)
+
+func TestGetHeadRejected(t *testing.T) {
+	data := bytes.Repeat([]byte("0123456789"), 1000)
+
+	for _, status := range []int{http.StatusForbidden, http.StatusMethodNotAllowed} {
+		t.Run(http.StatusText(status), func(t *testing.T) {
+			svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.Method == http.MethodHead {
+					w.WriteHeader(status)
+					return
+				}
+
+				http.ServeContent(w, r, "file", time.Time{}, bytes.NewReader(data))
+			}))
+			defer svr.Close()
+
+			remoteFile, err := httpio.Get(svr.URL, httpio.WithChunkSize(1024))
+			if err != nil {
+				t.Fatalf("failed to setup request: %v", err)
+			}
+			defer remoteFile.Close()
+
+			if remoteFile.Size() != int64(len(data)) {
+				t.Errorf("expected size %d, got %d", len(data), remoteFile.Size())
+			}
+
+			body, err := io.ReadAll(remoteFile)
+			if err != nil {
+				t.Fatalf("unable to read file: %v", err)
+			}
+
+			if !bytes.Equal(body, data) {
+				t.Errorf("expected %d bytes of the file, got %d", len(data), len(body))
+			}
+		})
+	}
+}
+
+func TestGetWithProbe(t *testing.T) {
+	data := bytes.Repeat([]byte("0123456789"), 1000)
+
+	var heads atomic.Int32
+	svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodHead {
+			heads.Add(1)
+		}
+
+		http.ServeContent(w, r, "file", time.Time{}, bytes.NewReader(data))
+	}))
+	defer svr.Close()
+
+	remoteFile, err := httpio.Get(svr.URL, httpio.WithProbe(), httpio.WithChunkSize(1024))
+	if err != nil {
+		t.Fatalf("failed to setup request: %v", err)
+	}
+	defer remoteFile.Close()
+
+	body, err := io.ReadAll(remoteFile)
+	if err != nil {
+		t.Fatalf("unable to read file: %v", err)
+	}
+
+	if !bytes.Equal(body, data) {
+		t.Errorf("expected %d bytes of the file, got %d", len(data), len(body))
+	}
+
+	if heads.Load() != 0 {
+		t.Errorf("expected no HEAD requests, got %d", heads.Load())
+	}
+}
+
+func TestGetWithProbeEmptyFile(t *testing.T) {
+	svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.ServeContent(w, r, "file", time.Time{}, bytes.NewReader(nil))
+	}))
+	defer svr.Close()
+
+	remoteFile, err := httpio.Get(svr.URL, httpio.WithProbe())
+	if err != nil {
+		t.Fatalf("failed to setup request: %v", err)
+	}
+	defer remoteFile.Close()
+
+	if remoteFile.Size() != 0 {
+		t.Errorf("expected an empty file, got size %d", remoteFile.Size())
+	}
+
+	body, err := io.ReadAll(remoteFile)
+	if err != nil || len(body) != 0 {
+		t.Errorf("expected an empty body, got %d bytes: %v", len(body), err)
+	}
+}
+
+func TestGetWithProbeRangeIgnored(t *testing.T) {
+	data := bytes.Repeat([]byte("0123456789"), 1000)
+
+	svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write(data)
+	}))
+	defer svr.Close()
+
+	remoteFile, err := httpio.Get(svr.URL, httpio.WithProbe(), httpio.WithChunkSize(1024))
+	if err != nil {
+		t.Fatalf("failed to setup request: %v", err)
+	}
+	defer remoteFile.Close()
+
+	body, err := io.ReadAll(remoteFile)
+	if err != nil {
+		t.Fatalf("unable to read file: %v", err)
+	}
+
+	if !bytes.Equal(body, data) {
+		t.Errorf("expected %d bytes of the file, got %d", len(data), len(body))
+	}
+}
+
+func TestGetProbeForbidden(t *testing.T) {
+	svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer svr.Close()
+
+	if _, err := httpio.Get(svr.URL); err == nil {
+		t.Errorf("expected an error for a forbidden file")
+	}
+}
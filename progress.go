@@ -0,0 +1,34 @@
+package httpio
+
+import "io"
+
+// WithProgress calls the function with the number of bytes downloaded and
+// the size of the file every time a chunk completes, or every write when
+// the file is downloaded sequentially. The size is -1 when the server
+// didn't report it. The calls are serialized but made from the goroutines
+// of the download, so the function should return quickly.
+func WithProgress(fn func(downloaded, total int64)) Option {
+	return func(f *RemoteFile) error {
+		f.progress = fn
+
+		return nil
+	}
+}
+
+// progressWriter reports the progress of a sequential download
+type progressWriter struct {
+	wr     io.Writer
+	file   *RemoteFile
+	offset int64
+}
+
+func (w *progressWriter) Write(p []byte) (int, error) {
+	n, err := w.wr.Write(p)
+	w.offset += int64(n)
+
+	if n > 0 {
+		w.file.progress(w.offset, w.file.size)
+	}
+
+	return n, err
+}
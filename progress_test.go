@@ -0,0 +1,82 @@
+package httpio_test
+
+import (
+	"io"
+	"net/http"
+	"sync"
+	"testing"
+
+	"github.com/jobstoit/httpio"
+)
+
+func TestGetProgress(t *testing.T) {
+	tests := []struct {
+		name   string
+		ranges bool
+	}{
+		{"chunked", true},
+		{"sequential", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svr := newTestServerWithHandler(func(h http.Handler) http.Handler {
+				return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					if !tt.ranges {
+						r.Header.Del("Range")
+					}
+
+					h.ServeHTTP(w, r)
+				})
+			})
+			defer svr.Close()
+
+			var mu sync.Mutex
+			var calls [][2]int64
+			progress := func(downloaded, total int64) {
+				mu.Lock()
+				defer mu.Unlock()
+
+				calls = append(calls, [2]int64{downloaded, total})
+			}
+
+			opts := []httpio.Option{httpio.WithChunkSize(1024 * 1024), httpio.WithProgress(progress)}
+			if !tt.ranges {
+				opts = append(opts, httpio.WithProbe())
+			}
+
+			u := svr.URL().JoinPath("assets", "test_5mb").String()
+			remoteFile, err := httpio.Get(u, opts...)
+			if err != nil {
+				t.Fatalf("failed to setup request: %v", err)
+			}
+			defer remoteFile.Close()
+
+			if _, err := io.Copy(io.Discard, remoteFile); err != nil {
+				t.Fatalf("unable to read file: %v", err)
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+
+			if len(calls) == 0 {
+				t.Fatalf("expected progress to be reported")
+			}
+
+			size := remoteFile.Size()
+			for i, call := range calls {
+				if call[1] != size {
+					t.Errorf("expected total %d, got %d", size, call[1])
+				}
+
+				if i > 0 && call[0] <= calls[i-1][0] {
+					t.Errorf("expected the progress to increase, got %d after %d", call[0], calls[i-1][0])
+				}
+			}
+
+			if e, a := size, calls[len(calls)-1][0]; e != a {
+				t.Errorf("expected the last progress to be %d, got %d", e, a)
+			}
+		})
+	}
+}
@@ -0,0 +1,264 @@
+package httpio
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+const (
+	// maxFollowUps limits the follow-up requests for a single chunk
+	maxFollowUps = 64
+	// drainLimit is the number of bytes read past the end of a chunk to
+	// reach the end of the response, completing its trailer
+	drainLimit = 4 * 1024
+)
+
+// chunkReader reads a chunk from its responses. Some servers cap the bytes
+// served per range request or serve a range shifted from the requested
+// one, so the bytes of the response before the requested offset are
+// discarded and the parts of the chunk a response doesn't cover are
+// requested with follow-up requests.
+type chunkReader struct {
+	file *RemoteFile
+	ctx  context.Context
+	lim  *limiter
+	c    Chunk
+
+	res *http.Response
+	// trailers holds the trailers of the previous responses
+	trailers http.Header
+	// offset is the file position of the next byte of the body
+	offset int64
+	// last is the position of the last byte of the current response
+	last int64
+
+	// pending is a response starting after the current offset, it's used
+	// once the gap before it has been read
+	pending      *http.Response
+	pendingFirst int64
+	pendingLast  int64
+	followUps    int
+
+	// retries counts the responses that broke off, broken marks the
+	// current one to be continued with a follow-up request
+	retries int
+	broken  bool
+}
+
+// newChunkReader returns the reader of the chunk starting with the given response
+func (f *RemoteFile) newChunkReader(ctx context.Context, lim *limiter, c Chunk, res *http.Response) (*chunkReader, error) {
+	r := &chunkReader{
+		file:     f,
+		ctx:      ctx,
+		lim:      lim,
+		c:        c,
+		res:      res,
+		trailers: http.Header{},
+		offset:   c.Offset,
+	}
+
+	return r, r.use(res)
+}
+
+func (r *chunkReader) end() int64 {
+	return r.c.Offset + r.c.Length - 1
+}
+
+func (r *chunkReader) Read(p []byte) (int, error) {
+	for {
+		if r.offset > r.end() {
+			if err := r.drain(); err != nil {
+				return 0, err
+			}
+
+			return 0, io.EOF
+		}
+
+		if r.broken {
+			if err := r.resume(); err != nil {
+				return 0, err
+			}
+
+			continue
+		}
+
+		if r.offset > r.last {
+			if err := r.advance(); err != nil {
+				return 0, err
+			}
+
+			continue
+		}
+
+		if remaining := min(r.end(), r.last) - r.offset + 1; int64(len(p)) > remaining {
+			p = p[:remaining]
+		}
+
+		n, err := r.res.Body.Read(p)
+		r.offset += int64(n)
+
+		// a response ending before its own last byte is a truncated
+		// transfer rather than a capped range
+		if err == io.EOF && r.offset > r.last {
+			err = nil
+		}
+
+		if err != nil && r.offset <= r.last && r.retries < r.file.retries && r.ctx.Err() == nil {
+			r.file.logDebug("continuing chunk", "chunk", r.c.Index, "offset", r.offset, "error", err)
+
+			r.retries++
+			r.broken = true
+			r.file.retried(r.c, err, r.retries)
+			err = nil
+		}
+
+		if n > 0 || err != nil {
+			return n, err
+		}
+	}
+}
+
+// resume continues the chunk at the current offset after the current
+// response broke off
+func (r *chunkReader) resume() error {
+	r.broken = false
+	r.res.Body.Close()
+
+	if err := sleep(r.ctx, r.file.retryDelay(r.retries-1)); err != nil {
+		return err
+	}
+
+	return r.next()
+}
+
+// drain reads the end of the current response, so its trailer is complete
+// and its checksums are verified
+func (r *chunkReader) drain() error {
+	_, err := io.Copy(io.Discard, io.LimitReader(r.res.Body, drainLimit))
+
+	return err
+}
+
+// advance continues with the pending response when the gap before it has
+// been read or requests the remainder of the chunk otherwise
+func (r *chunkReader) advance() error {
+	if err := r.drain(); err != nil {
+		return err
+	}
+	r.res.Body.Close()
+
+	for name, values := range r.res.Trailer {
+		r.trailers[name] = append(r.trailers[name], values...)
+	}
+
+	if r.pending != nil && r.offset == r.pendingFirst {
+		r.res, r.last = r.pending, r.pendingLast
+		r.pending = nil
+
+		return nil
+	}
+
+	return r.next()
+}
+
+// next requests the part of the chunk from the current offset up to the
+// pending response or the end of the chunk
+func (r *chunkReader) next() error {
+	r.followUps++
+	if r.followUps > maxFollowUps {
+		return fmt.Errorf("chunk %d still incomplete after %d follow-up requests", r.c.Index, maxFollowUps)
+	}
+
+	end := r.end()
+	if r.pending != nil {
+		end = r.pendingFirst - 1
+	}
+
+	rest := Chunk{
+		Index:  r.c.Index,
+		Offset: r.offset,
+		Length: end - r.offset + 1,
+	}
+
+	r.file.logDebug("requesting the rest of chunk", "chunk", r.c.Index, "range", rest.Range())
+
+	res, err := r.file.fetchChunk(r.ctx, r.lim, rest)
+	if err != nil {
+		return err
+	}
+	r.res = res
+
+	if err := checkPrecondition(res); err != nil {
+		return err
+	}
+
+	if res.StatusCode != http.StatusPartialContent {
+		return newStatusError(res)
+	}
+
+	return r.use(res)
+}
+
+// use continues reading from the response, reconciling the range it covers
+// with the current offset
+func (r *chunkReader) use(res *http.Response) error {
+	r.res = res
+	r.last = r.end()
+
+	if res.StatusCode != http.StatusPartialContent {
+		return nil
+	}
+
+	first, last, _, ok := parseContentRange(res.Header.Get(headerContentRange))
+	if !ok {
+		return fmt.Errorf("response for chunk %d has an invalid Content-Range: '%s'", r.c.Index, res.Header.Get(headerContentRange))
+	}
+
+	switch {
+	case last < r.offset || first > r.end():
+		return fmt.Errorf("response for chunk %d covers %d-%d, expected %d-%d", r.c.Index, first, last, r.offset, r.end())
+	case first < r.offset:
+		if _, err := io.CopyN(io.Discard, res.Body, r.offset-first); err != nil {
+			return err
+		}
+	case first > r.offset:
+		if r.pending != nil {
+			return fmt.Errorf("response for chunk %d starts at %d, expected %d", r.c.Index, first, r.offset)
+		}
+
+		r.pending, r.pendingFirst, r.pendingLast = res, first, last
+
+		return r.next()
+	}
+
+	if r.pending != nil && last >= r.pendingFirst {
+		r.pending.Body.Close()
+		r.pending = nil
+	}
+
+	r.last = last
+
+	return nil
+}
+
+// trailer returns the trailers of the chunk's responses, the trailer of
+// the current response is only complete once it has been read to the end
+func (r *chunkReader) trailer() http.Header {
+	header := r.trailers.Clone()
+	for name, values := range r.res.Trailer {
+		header[name] = append(header[name], values...)
+	}
+
+	return header
+}
+
+// Close closes the current and the pending response
+func (r *chunkReader) Close() error {
+	if r.pending != nil {
+		r.pending.Body.Close()
+	}
+
+	return r.res.Body.Close()
+}
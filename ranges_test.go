@@ -0,0 +1,105 @@
+package httpio_test
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/jobstoit/httpio"
+)
+
+// capRanges serves at most max bytes per range request
+func capRanges(max int64, requests *atomic.Int32) func(http.Handler) http.Handler {
+	return func(h http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if spec, ok := strings.CutPrefix(r.Header.Get("Range"), "bytes="); ok {
+				requests.Add(1)
+
+				startStr, endStr, _ := strings.Cut(spec, "-")
+				start, _ := strconv.ParseInt(startStr, 10, 64)
+				end, err := strconv.ParseInt(endStr, 10, 64)
+				if err != nil || end-start+1 > max {
+					r.Header.Set("Range", fmt.Sprintf("bytes=%d-%d", start, start+max-1))
+				}
+			}
+
+			h.ServeHTTP(w, r)
+		})
+	}
+}
+
+func TestGetCappedRanges(t *testing.T) {
+	var requests atomic.Int32
+	svr := newTestServerWithHandler(capRanges(300*1024, &requests))
+	defer svr.Close()
+
+	u := svr.URL().JoinPath("assets", "test_5mb")
+	remoteFile, err := httpio.Get(u.String(), httpio.WithChunkSize(1024*1024))
+	if err != nil {
+		t.Fatalf("failed to setup request: %v", err)
+	}
+
+	expected, _ := testdata.ReadFile("testdata/test_5mb")
+	actual, err := io.ReadAll(remoteFile)
+	if err != nil {
+		t.Fatalf("unable to read file: %v", err)
+	}
+
+	if !bytes.Equal(expected, actual) {
+		t.Errorf("mismatched content")
+	}
+
+	if requests.Load() <= 5 {
+		t.Errorf("expected follow-up requests for the capped ranges, got %d requests", requests.Load())
+	}
+}
+
+// shiftRanges serves the first request for every range start shifted by
+// the given number of bytes
+func shiftRanges(shift int64) func(http.Handler) http.Handler {
+	var seen sync.Map
+	return func(h http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if spec, ok := strings.CutPrefix(r.Header.Get("Range"), "bytes="); ok {
+				startStr, endStr, _ := strings.Cut(spec, "-")
+				start, _ := strconv.ParseInt(startStr, 10, 64)
+				if _, ok := seen.LoadOrStore(start, struct{}{}); !ok && start+shift >= 0 {
+					r.Header.Set("Range", fmt.Sprintf("bytes=%d-%s", start+shift, endStr))
+				}
+			}
+
+			h.ServeHTTP(w, r)
+		})
+	}
+}
+
+func TestGetShiftedRanges(t *testing.T) {
+	for _, shift := range []int64{-100, 100} {
+		t.Run(strconv.FormatInt(shift, 10), func(t *testing.T) {
+			svr := newTestServerWithHandler(shiftRanges(shift))
+			defer svr.Close()
+
+			u := svr.URL().JoinPath("assets", "test_5mb")
+			remoteFile, err := httpio.Get(u.String(), httpio.WithChunkSize(1024*1024))
+			if err != nil {
+				t.Fatalf("failed to setup request: %v", err)
+			}
+
+			expected, _ := testdata.ReadFile("testdata/test_5mb")
+			actual, err := io.ReadAll(remoteFile)
+			if err != nil {
+				t.Fatalf("unable to read file: %v", err)
+			}
+
+			if !bytes.Equal(expected, actual) {
+				t.Errorf("mismatched content")
+			}
+		})
+	}
+}
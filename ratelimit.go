@@ -0,0 +1,164 @@
+package httpio
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const headerRetryAfter = "Retry-After"
+
+// resetEpochThreshold separates reset values given as a unix timestamp
+// from reset values given in seconds from now
+const resetEpochThreshold = 1_000_000_000
+
+// pacer spaces out requests to stay within the rate limit budget the server
+// advertises with the X-RateLimit-*, RateLimit-* or RateLimit headers
+type pacer struct {
+	mu        sync.Mutex
+	remaining int
+	reset     time.Time
+	next      time.Time
+}
+
+func newPacer() *pacer {
+	return &pacer{remaining: -1}
+}
+
+// wait blocks until the next request fits within the advertised budget,
+// the remaining requests are spread evenly over the time until the reset
+func (p *pacer) wait(ctx context.Context) error {
+	p.mu.Lock()
+
+	now := time.Now()
+	var delay time.Duration
+	if p.remaining >= 0 && now.Before(p.reset) {
+		if p.remaining == 0 {
+			delay = p.reset.Sub(now)
+		} else {
+			at := now
+			if p.next.After(now) {
+				at = p.next
+			}
+
+			delay = at.Sub(now)
+			p.next = at.Add(p.reset.Sub(now) / time.Duration(p.remaining))
+			p.remaining--
+		}
+	}
+
+	p.mu.Unlock()
+
+	if delay <= 0 {
+		return nil
+	}
+
+	return sleep(ctx, delay)
+}
+
+// update updates the budget from the rate limit headers of a response
+func (p *pacer) update(header http.Header) {
+	remaining, reset, ok := parseRateLimit(header, time.Now())
+	if !ok {
+		return
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.remaining = remaining
+	p.reset = reset
+}
+
+// parseRateLimit parses the remaining requests and the time of the reset
+// from the rate limit headers
+func parseRateLimit(header http.Header, now time.Time) (int, time.Time, bool) {
+	var remainingStr, resetStr string
+	for _, prefix := range []string{"X-RateLimit-", "RateLimit-"} {
+		if v := header.Get(prefix + "Remaining"); v != "" {
+			remainingStr, resetStr = v, header.Get(prefix+"Reset")
+			break
+		}
+	}
+
+	if remainingStr == "" {
+		remainingStr, resetStr = parseRateLimitFields(header.Get("RateLimit"))
+	}
+
+	remaining, err := strconv.Atoi(strings.TrimSpace(remainingStr))
+	if err != nil || remaining < 0 {
+		return 0, time.Time{}, false
+	}
+
+	reset, err := strconv.ParseInt(strings.TrimSpace(resetStr), 10, 64)
+	if err != nil || reset < 0 {
+		return 0, time.Time{}, false
+	}
+
+	if reset >= resetEpochThreshold {
+		return remaining, time.Unix(reset, 0), true
+	}
+
+	return remaining, now.Add(time.Duration(reset) * time.Second), true
+}
+
+// parseRateLimitFields parses the remaining and reset values of the
+// combined RateLimit header, both in the "remaining=5, reset=30" and
+// in the structured `"policy";r=5;t=30` form
+func parseRateLimitFields(value string) (string, string) {
+	var remaining, reset string
+	for _, field := range strings.FieldsFunc(value, func(r rune) bool { return r == ',' || r == ';' }) {
+		key, val, ok := strings.Cut(strings.TrimSpace(field), "=")
+		if !ok {
+			continue
+		}
+
+		switch strings.ToLower(key) {
+		case "remaining", "r":
+			remaining = val
+		case "reset", "t":
+			reset = val
+		}
+	}
+
+	return remaining, reset
+}
+
+// throttled reports whether the response asks to slow down, with 429 Too
+// Many Requests or with 503 Service Unavailable and a Retry-After header
+func throttled(res *http.Response) bool {
+	if res == nil {
+		return false
+	}
+
+	return res.StatusCode == http.StatusTooManyRequests ||
+		res.StatusCode == http.StatusServiceUnavailable && res.Header.Get(headerRetryAfter) != ""
+}
+
+// parseRetryAfter parses the delay of the Retry-After header given either
+// in seconds or as an HTTP date
+func parseRetryAfter(header http.Header, now time.Time) (time.Duration, bool) {
+	value := strings.TrimSpace(header.Get(headerRetryAfter))
+	if value == "" {
+		return 0, false
+	}
+
+	if seconds, err := strconv.ParseInt(value, 10, 64); err == nil {
+		if seconds < 0 || seconds > math.MaxInt64/int64(time.Second) {
+			return 0, false
+		}
+
+		return time.Duration(seconds) * time.Second, true
+	}
+
+	at, err := http.ParseTime(value)
+	if err != nil {
+		return 0, false
+	}
+
+	return max(at.Sub(now), 0), true
+}
@@ -0,0 +1,107 @@
+package httpio
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestParseRateLimit(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+
+	tests := []struct {
+		name      string
+		header    http.Header
+		remaining int
+		reset     time.Time
+		ok        bool
+	}{
+		{
+			"x-ratelimit delta",
+			http.Header{"X-Ratelimit-Remaining": {"10"}, "X-Ratelimit-Reset": {"30"}},
+			10, now.Add(time.Second * 30), true,
+		},
+		{
+			"x-ratelimit epoch",
+			http.Header{"X-Ratelimit-Remaining": {"0"}, "X-Ratelimit-Reset": {"1700000060"}},
+			0, time.Unix(1_700_000_060, 0), true,
+		},
+		{
+			"ratelimit fields",
+			http.Header{"Ratelimit-Remaining": {"5"}, "Ratelimit-Reset": {"2"}},
+			5, now.Add(time.Second * 2), true,
+		},
+		{
+			"combined ratelimit",
+			http.Header{"Ratelimit": {"limit=100, remaining=50, reset=60"}},
+			50, now.Add(time.Minute), true,
+		},
+		{
+			"structured ratelimit",
+			http.Header{"Ratelimit": {`"default";r=7;t=10`}},
+			7, now.Add(time.Second * 10), true,
+		},
+		{
+			"absent",
+			http.Header{},
+			0, time.Time{}, false,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			remaining, reset, ok := parseRateLimit(test.header, now)
+			if ok != test.ok || remaining != test.remaining || !reset.Equal(test.reset) {
+				t.Errorf("expected (%d, %v, %t), got (%d, %v, %t)", test.remaining, test.reset, test.ok, remaining, reset, ok)
+			}
+		})
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		value string
+		delay time.Duration
+		ok    bool
+	}{
+		{"120", time.Minute * 2, true},
+		{now.Add(time.Second * 30).Format(http.TimeFormat), time.Second * 30, true},
+		{now.Add(-time.Hour).Format(http.TimeFormat), 0, true},
+		{"-1", 0, false},
+		{"soon", 0, false},
+		{"", 0, false},
+	}
+
+	for _, test := range tests {
+		delay, ok := parseRetryAfter(http.Header{"Retry-After": {test.value}}, now)
+		if delay != test.delay || ok != test.ok {
+			t.Errorf("expected (%s, %t) for '%s', got (%s, %t)", test.delay, test.ok, test.value, delay, ok)
+		}
+	}
+}
+
+func TestPacerSpreadsBudget(t *testing.T) {
+	p := newPacer()
+	p.update(http.Header{"X-Ratelimit-Remaining": {"2"}, "X-Ratelimit-Reset": {"1"}})
+
+	start := time.Now()
+	for range 2 {
+		if err := p.wait(context.Background()); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	if elapsed := time.Since(start); elapsed < time.Millisecond*400 {
+		t.Errorf("expected the second request to be delayed, took %v", elapsed)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*10)
+	defer cancel()
+
+	if err := p.wait(ctx); err == nil {
+		t.Errorf("expected to wait for the reset once the budget is spent")
+	}
+}
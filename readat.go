@@ -0,0 +1,281 @@
+package httpio
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"slices"
+	"sync"
+)
+
+// maxReadAtBlocks is the number of blocks ReadAt keeps
+const maxReadAtBlocks = 8
+
+// readAtState holds the limiter of the ReadAt requests and the blocks
+// fetched when coalescing or reading ahead
+type readAtState struct {
+	once sync.Once
+	lim  *limiter
+
+	mu sync.Mutex
+	// blocks are the blocks fetched or in flight, oldest first, last is
+	// the end of the last read to detect sequential reads
+	blocks []*readAtBlock
+	last   int64
+}
+
+// readAtBlock is a block of the file fetched for ReadAt, its buf and err
+// are set once done is closed
+type readAtBlock struct {
+	offset int64
+	length int64
+	ahead  bool
+	done   chan struct{}
+	buf    []byte
+	err    error
+}
+
+func (b *readAtBlock) covers(off int64) bool {
+	return off >= b.offset && off < b.offset+b.length
+}
+
+// WithReadAtBlockSize makes ReadAt request at least the given number of
+// bytes and keep the blocks, so small adjacent reads like the ones of
+// zip.NewReader are served by a single request
+func WithReadAtBlockSize(size int) Option {
+	return func(f *RemoteFile) error {
+		f.readAtBlock = int64(max(size, 0))
+
+		return nil
+	}
+}
+
+// WithReadAhead makes ReadAt fetch the given number of bytes past a read
+// in the background when it continues where the last read ended, so
+// sequential reads like the ones of a column of a parquet file are served
+// without waiting on a request. Reads of the bytes in flight wait for them
+// rather than requesting them again.
+func WithReadAhead(size int) Option {
+	return func(f *RemoteFile) error {
+		f.readAhead = int64(max(size, 0))
+
+		return nil
+	}
+}
+
+// ReadAt reads len(p) bytes at the given offset with a range request of
+// its own, independent of the position of Read. It's safe to call
+// concurrently, the requests in flight are limited to the concurrency.
+// With WithReadAtBlockSize or WithReadAhead the reads are served from the
+// blocks fetched before, and concurrent reads share the blocks in flight.
+// Reading at an offset is unsupported with WithEncryption and WithDecompress.
+func (f *RemoteFile) ReadAt(p []byte, off int64) (int, error) {
+	if off < 0 {
+		return 0, errors.New("negative offset")
+	}
+
+	if err := f.checkRandomAccess(); err != nil {
+		return 0, err
+	}
+
+	if len(p) == 0 {
+		return 0, nil
+	}
+
+	size := f.size
+	if off >= size {
+		return 0, io.EOF
+	}
+
+	if f.readAtBlock == 0 && f.readAhead == 0 {
+		buf, err := f.fetchRange(Chunk{Offset: off, Length: min(int64(len(p)), size-off)})
+		if err != nil {
+			return 0, err
+		}
+
+		n := copy(p, buf)
+
+		return n, readAtErr(n, p)
+	}
+
+	end := min(off+int64(len(p)), size)
+	sequential := f.readAt.sequential(off, end)
+
+	var last *readAtBlock
+	for pos := off; pos < end; {
+		b, claimed := f.readAt.claim(pos, min(max(end-pos, f.readAtBlock), size-pos), false)
+		if claimed {
+			f.fetchBlock(b)
+		}
+		<-b.done
+
+		if b.err != nil {
+			// a failed read-ahead is dropped, the read requests the bytes itself
+			if b.ahead {
+				continue
+			}
+
+			return int(pos - off), b.err
+		}
+
+		pos += int64(copy(p[pos-off:end-off], b.buf[pos-b.offset:]))
+		last = b
+	}
+
+	if sequential && f.readAhead > 0 && last != nil {
+		f.readAheadOf(last)
+	}
+
+	n := int(end - off)
+
+	return n, readAtErr(n, p)
+}
+
+// checkRandomAccess returns an error when the bytes at an offset of the
+// remote file aren't the bytes of the file as it's read
+func (f *RemoteFile) checkRandomAccess() error {
+	if f.aead != nil {
+		return errors.New("unable to read an encrypted stream at an offset")
+	}
+
+	if f.decompress {
+		return errors.New("unable to read a decompressed stream at an offset")
+	}
+
+	return nil
+}
+
+// readAtErr returns io.EOF for reads ending at the end of the file
+func readAtErr(n int, p []byte) error {
+	if n < len(p) {
+		return io.EOF
+	}
+
+	return nil
+}
+
+// readAheadOf fetches the bytes following the block in the background,
+// unless they're fetched already
+func (f *RemoteFile) readAheadOf(b *readAtBlock) {
+	off := b.offset + b.length
+	if off >= f.size {
+		return
+	}
+
+	ahead, claimed := f.readAt.claim(off, min(f.readAhead, f.size-off), true)
+	if claimed {
+		go f.fetchBlock(ahead)
+	}
+}
+
+// fetchBlock fetches the claimed block, dropping it when it fails
+func (f *RemoteFile) fetchBlock(b *readAtBlock) {
+	b.buf, b.err = f.fetchRange(Chunk{Offset: b.offset, Length: b.length})
+	if b.err != nil {
+		f.readAt.drop(b)
+	}
+	close(b.done)
+}
+
+// sequential records the end of the read and reports whether the read
+// continues where the last one ended
+func (s *readAtState) sequential(off, end int64) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sequential := off == s.last
+	s.last = end
+
+	return sequential
+}
+
+// claim returns the block covering the offset, or claims a new block of
+// the given length at the offset which the caller has to fetch. The oldest
+// blocks that are done are evicted to keep the number of blocks limited.
+func (s *readAtState) claim(off, length int64, ahead bool) (*readAtBlock, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i := len(s.blocks) - 1; i >= 0; i-- {
+		if s.blocks[i].covers(off) {
+			return s.blocks[i], false
+		}
+	}
+
+	b := &readAtBlock{offset: off, length: length, ahead: ahead, done: make(chan struct{})}
+	s.blocks = append(s.blocks, b)
+
+	for i := 0; i < len(s.blocks) && len(s.blocks) > maxReadAtBlocks; {
+		select {
+		case <-s.blocks[i].done:
+			s.blocks = slices.Delete(s.blocks, i, i+1)
+		default:
+			i++
+		}
+	}
+
+	return b, true
+}
+
+// drop removes the block
+func (s *readAtState) drop(b *readAtBlock) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if i := slices.Index(s.blocks, b); i >= 0 {
+		s.blocks = slices.Delete(s.blocks, i, i+1)
+	}
+}
+
+// fetchRange fetches the bytes of the chunk
+func (f *RemoteFile) fetchRange(c Chunk) ([]byte, error) {
+	ctx := f.ctx
+	lim := f.readAtLimiter()
+
+	if err := lim.acquire(ctx); err != nil {
+		return nil, err
+	}
+	defer lim.release()
+
+	res, err := f.fetchChunk(f.traceContext(ctx), lim, c)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := checkPrecondition(res); err != nil {
+		res.Body.Close()
+		return nil, err
+	}
+
+	if res.StatusCode == http.StatusOK {
+		res.Body.Close()
+		return nil, ErrRangeIgnored
+	}
+
+	if res.StatusCode != http.StatusPartialContent {
+		res.Body.Close()
+		return nil, newStatusError(res)
+	}
+
+	body, err := f.newChunkReader(ctx, lim, c, res)
+	defer body.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	buf := make([]byte, c.Length)
+	if _, err := io.ReadFull(body, buf); err != nil {
+		return nil, err
+	}
+
+	return buf, nil
+}
+
+// readAtLimiter returns the limiter of the requests of ReadAt and ReadRanges
+func (f *RemoteFile) readAtLimiter() *limiter {
+	f.readAt.once.Do(func() {
+		f.readAt.lim = newLimiter(f.concurrency)
+	})
+
+	return f.readAt.lim
+}
@@ -0,0 +1,216 @@
+package httpio_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/jobstoit/httpio"
+)
+
+func TestReadAt(t *testing.T) {
+	svr := newTestServer()
+	defer svr.Close()
+
+	u := svr.URL().JoinPath("assets", "test_5mb").String()
+	expected, _ := testdata.ReadFile("testdata/test_5mb")
+
+	remoteFile, err := httpio.Get(u)
+	if err != nil {
+		t.Fatalf("unable to get file: %v", err)
+	}
+
+	buf := make([]byte, 1000)
+	for _, off := range []int64{0, 1024 * 1024 * 3, int64(len(expected)) - 1000} {
+		if n, err := remoteFile.ReadAt(buf, off); err != nil || n != len(buf) {
+			t.Errorf("unable to read at %d: %d, %v", off, n, err)
+		}
+
+		if !bytes.Equal(buf, expected[off:off+1000]) {
+			t.Errorf("mismatched content at %d", off)
+		}
+	}
+
+	if n, err := remoteFile.ReadAt(buf, int64(len(expected))-10); n != 10 || err != io.EOF {
+		t.Errorf("expected 10 bytes and io.EOF at the end, got: %d, %v", n, err)
+	}
+
+	if n, err := remoteFile.ReadAt(nil, 100); n != 0 || err != nil {
+		t.Errorf("expected an empty read to succeed, got: %d, %v", n, err)
+	}
+
+	if _, err := io.Copy(io.Discard, remoteFile); err != nil {
+		t.Errorf("expected reading to be independent of ReadAt: %v", err)
+	}
+}
+
+func TestReadAtZip(t *testing.T) {
+	archive := &bytes.Buffer{}
+	zw := zip.NewWriter(archive)
+	for _, name := range []string{"a.txt", "b.txt", "c.txt"} {
+		w, _ := zw.Create(name)
+		_, _ = w.Write(bytes.Repeat([]byte(name), 1024))
+	}
+	zw.Close()
+
+	var ranges atomic.Int32
+	svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Range") != "" {
+			ranges.Add(1)
+		}
+
+		http.ServeContent(w, r, "archive.zip", time.Time{}, bytes.NewReader(archive.Bytes()))
+	}))
+	defer svr.Close()
+
+	open := func(opts ...httpio.Option) *zip.Reader {
+		remoteFile, err := httpio.Get(svr.URL, opts...)
+		if err != nil {
+			t.Fatalf("unable to get file: %v", err)
+		}
+		t.Cleanup(func() { remoteFile.Close() })
+
+		zr, err := zip.NewReader(remoteFile, int64(archive.Len()))
+		if err != nil {
+			t.Fatalf("unable to read the zip: %v", err)
+		}
+
+		return zr
+	}
+
+	zr := open(httpio.WithConcurrency(1))
+	if len(zr.File) != 3 {
+		t.Fatalf("expected 3 files, got %d", len(zr.File))
+	}
+
+	rc, err := zr.Open("b.txt")
+	if err != nil {
+		t.Fatalf("unable to open b.txt: %v", err)
+	}
+	defer rc.Close()
+
+	if content, _ := io.ReadAll(rc); !bytes.Equal(content, bytes.Repeat([]byte("b.txt"), 1024)) {
+		t.Error("mismatched content of b.txt")
+	}
+
+	ranges.Store(0)
+	open(httpio.WithConcurrency(1), httpio.WithReadAtBlockSize(64*1024))
+
+	// the whole archive fits in a block, one range request for the
+	// directory and one for the chunk of the stream
+	if n := ranges.Load(); n > 2 {
+		t.Errorf("expected the reads of the directory to be coalesced, got %d range requests", n)
+	}
+}
+
+func TestReadAtReadAhead(t *testing.T) {
+	expected, _ := testdata.ReadFile("testdata/test_5mb")
+
+	var ranges atomic.Int32
+	svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Range") != "" {
+			ranges.Add(1)
+		}
+
+		http.ServeContent(w, r, "test_5mb", time.Time{}, bytes.NewReader(expected))
+	}))
+	defer svr.Close()
+
+	files, err := httpio.GetAll(context.Background(), []string{svr.URL}, httpio.WithReadAhead(512*1024))
+	if err != nil {
+		t.Fatalf("unable to get file: %v", err)
+	}
+	remoteFile := files[0]
+	defer remoteFile.Close()
+
+	r := io.NewSectionReader(remoteFile, 0, 2*1024*1024)
+	buf := make([]byte, 4096)
+	var actual []byte
+	for {
+		n, err := r.Read(buf)
+		actual = append(actual, buf[:n]...)
+		if err == io.EOF {
+			break
+		}
+
+		if err != nil {
+			t.Fatalf("unable to read: %v", err)
+		}
+	}
+
+	if !bytes.Equal(actual, expected[:2*1024*1024]) {
+		t.Error("mismatched content")
+	}
+
+	// the first read and a request per window read ahead
+	if n := ranges.Load(); n > 6 {
+		t.Errorf("expected the sequential reads to be read ahead, got %d range requests", n)
+	}
+}
+
+func TestReadAtSharedBlocks(t *testing.T) {
+	expected, _ := testdata.ReadFile("testdata/test_5mb")
+
+	var ranges atomic.Int32
+	svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Range") != "" {
+			ranges.Add(1)
+			time.Sleep(50 * time.Millisecond)
+		}
+
+		http.ServeContent(w, r, "test_5mb", time.Time{}, bytes.NewReader(expected))
+	}))
+	defer svr.Close()
+
+	files, err := httpio.GetAll(context.Background(), []string{svr.URL}, httpio.WithReadAtBlockSize(64*1024))
+	if err != nil {
+		t.Fatalf("unable to get file: %v", err)
+	}
+	remoteFile := files[0]
+	defer remoteFile.Close()
+
+	// the first read claims the block the others wait on while it's in flight
+	var wg sync.WaitGroup
+	for i := range 16 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			if i > 0 {
+				time.Sleep(10 * time.Millisecond)
+			}
+
+			off := int64(i * 1000)
+			buf := make([]byte, 1000)
+			if _, err := remoteFile.ReadAt(buf, off); err != nil {
+				t.Errorf("unable to read at %d: %v", off, err)
+			}
+
+			if !bytes.Equal(buf, expected[off:off+1000]) {
+				t.Errorf("mismatched content at %d", off)
+			}
+		}()
+	}
+	wg.Wait()
+
+	// a read spanning the end of the block only requests the rest
+	buf := make([]byte, 2000)
+	if _, err := remoteFile.ReadAt(buf, 64*1024-1000); err != nil {
+		t.Fatalf("unable to read: %v", err)
+	}
+
+	if !bytes.Equal(buf, expected[64*1024-1000:64*1024+1000]) {
+		t.Error("mismatched content across blocks")
+	}
+
+	if n := ranges.Load(); n != 2 {
+		t.Errorf("expected the reads to share 2 blocks, got %d range requests", n)
+	}
+}
@@ -0,0 +1,65 @@
+package httpio
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sync"
+)
+
+// WithURLRefresher refreshes the URL of the file with the given refresher
+// when a chunk request is answered with 403 Forbidden, as a presigned URL
+// that expired is, and retries the chunk at the fresh URL. Concurrent chunks
+// that are forbidden at the same URL share a single refresh. A chunk that's
+// forbidden at the fresh URL as well fails with a StatusError.
+func WithURLRefresher(refresh func(ctx context.Context) (string, error)) Option {
+	return func(f *RemoteFile) error {
+		f.urlRefresher = &urlRefresher{refresh: refresh}
+
+		return nil
+	}
+}
+
+// urlRefresher serializes the refreshes of the URL of a file
+type urlRefresher struct {
+	refresh func(ctx context.Context) (string, error)
+	mu      sync.Mutex
+}
+
+// expired reports whether the response is one to refresh the URL for
+func (f *RemoteFile) expired(res *http.Response) bool {
+	return f.urlRefresher != nil && res.StatusCode == http.StatusForbidden
+}
+
+// refreshURL replaces the given URL a request was forbidden at with a
+// fresh one, unless another request has refreshed it already
+func (f *RemoteFile) refreshURL(ctx context.Context, forbidden *url.URL) error {
+	r := f.urlRefresher
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	current := f.req.URL
+	if location := f.location.Load(); location != nil {
+		current = location
+	}
+
+	if current.String() != forbidden.String() {
+		return nil
+	}
+
+	raw, err := r.refresh(ctx)
+	if err != nil {
+		return fmt.Errorf("unable to refresh url: %w", err)
+	}
+
+	fresh, err := url.Parse(raw)
+	if err != nil {
+		return fmt.Errorf("unable to refresh url: %w", err)
+	}
+
+	f.location.Store(fresh)
+	f.logDebug("refreshed url", "location", fresh.String())
+
+	return nil
+}
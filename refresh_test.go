@@ -0,0 +1,124 @@
+package httpio_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sync/atomic"
+	"testing"
+
+	"github.com/jobstoit/httpio"
+)
+
+// newExpiringTestServer serves the files to requests signed with the
+// current signature, which changes after the given number of requests
+func newExpiringTestServer(requests int32) *testServer {
+	var served, signature atomic.Int32
+	signature.Store(1)
+
+	svr := newTestServerWithHandler(func(h http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if served.Add(1) > requests {
+				signature.CompareAndSwap(1, 2)
+			}
+
+			if r.URL.Query().Get("sig") != fmt.Sprint(signature.Load()) {
+				w.WriteHeader(http.StatusForbidden)
+				return
+			}
+
+			h.ServeHTTP(w, r)
+		})
+	})
+
+	return svr
+}
+
+func TestGetURLRefresher(t *testing.T) {
+	svr := newExpiringTestServer(3)
+	defer svr.Close()
+
+	signed := func(sig int) string {
+		u := svr.URL().JoinPath("assets", "test_5mb")
+		u.RawQuery = fmt.Sprintf("sig=%d", sig)
+
+		return u.String()
+	}
+	expected, _ := testdata.ReadFile("testdata/test_5mb")
+
+	var refreshes atomic.Int32
+	refresh := func(context.Context) (string, error) {
+		refreshes.Add(1)
+		return signed(2), nil
+	}
+
+	remoteFile, err := httpio.Get(signed(1), httpio.WithChunkSize(512*1024), httpio.WithConcurrency(4), httpio.WithURLRefresher(refresh))
+	if err != nil {
+		t.Fatalf("unable to get file: %v", err)
+	}
+	defer remoteFile.Close()
+
+	actual, err := io.ReadAll(remoteFile)
+	if err != nil {
+		t.Fatalf("unable to read file: %v", err)
+	}
+
+	if !bytes.Equal(expected, actual) {
+		t.Error("mismatched content")
+	}
+
+	if n := refreshes.Load(); n != 1 {
+		t.Errorf("expected the forbidden chunks to share a refresh, got %d refreshes", n)
+	}
+
+	if remoteFile.URL() != signed(2) {
+		t.Errorf("expected the refreshed url, got '%s'", remoteFile.URL())
+	}
+}
+
+func TestGetURLRefresherForbidden(t *testing.T) {
+	svr := newExpiringTestServer(1)
+	defer svr.Close()
+
+	u := svr.URL().JoinPath("assets", "test_5mb")
+	u.RawQuery = "sig=1"
+
+	remoteFile, err := httpio.Get(u.String(), httpio.WithURLRefresher(func(context.Context) (string, error) {
+		return u.String(), nil
+	}))
+	if err != nil {
+		t.Fatalf("unable to get file: %v", err)
+	}
+	defer remoteFile.Close()
+
+	_, err = io.Copy(io.Discard, remoteFile)
+
+	var statusErr *httpio.StatusError
+	if !errors.As(err, &statusErr) || statusErr.Code != http.StatusForbidden {
+		t.Errorf("expected a StatusError with status %d, got: %v", http.StatusForbidden, err)
+	}
+}
+
+func TestGetURLRefresherError(t *testing.T) {
+	svr := newExpiringTestServer(1)
+	defer svr.Close()
+
+	u := svr.URL().JoinPath("assets", "test_5mb")
+	u.RawQuery = "sig=1"
+	errExpired := errors.New("credentials expired")
+
+	remoteFile, err := httpio.Get(u.String(), httpio.WithURLRefresher(func(context.Context) (string, error) {
+		return "", errExpired
+	}))
+	if err != nil {
+		t.Fatalf("unable to get file: %v", err)
+	}
+	defer remoteFile.Close()
+
+	if _, err := io.Copy(io.Discard, remoteFile); !errors.Is(err, errExpired) {
+		t.Errorf("expected the error of the refresher, got: %v", err)
+	}
+}
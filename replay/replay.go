@@ -0,0 +1,116 @@
+// Package replay records the responses of real downloads to disk and
+// serves them back, so integration tests can run offline against
+// realistic origins.
+//
+// Responses are stored per method, URL and Range header, so a replayed
+// download has to use the same chunk size as the recorded one.
+package replay
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+
+	"github.com/jobstoit/httpio"
+)
+
+const headerRange = "Range"
+
+// ErrNotRecorded is returned for requests without a recorded response
+var ErrNotRecorded = errors.New("no recorded response")
+
+// Recorder stores the responses of the requests it sends in Dir
+type Recorder struct {
+	// Base is the underlying transport, http.DefaultTransport when nil
+	Base http.RoundTripper
+	// Dir is the directory the responses are written to
+	Dir string
+}
+
+// RoundTrip implements http.RoundTripper
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	base := r.Base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+
+	res, err := base.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := io.ReadAll(res.Body)
+	res.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	res.Body = io.NopCloser(bytes.NewReader(body))
+
+	buf := &bytes.Buffer{}
+	if err := res.Write(buf); err != nil {
+		return nil, fmt.Errorf("unable to record response: %w", err)
+	}
+	res.Body = io.NopCloser(bytes.NewReader(body))
+
+	if err := os.MkdirAll(r.Dir, 0o755); err != nil {
+		return nil, err
+	}
+
+	if err := os.WriteFile(filepath.Join(r.Dir, fileName(req)), buf.Bytes(), 0o644); err != nil {
+		return nil, fmt.Errorf("unable to record response: %w", err)
+	}
+
+	return res, nil
+}
+
+// Transport serves the responses recorded in Dir without sending any requests
+type Transport struct {
+	// Dir is the directory the responses were recorded to
+	Dir string
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	data, err := os.ReadFile(filepath.Join(t.Dir, fileName(req)))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("%w for %s %s %s", ErrNotRecorded, req.Method, req.URL, req.Header.Get(headerRange))
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return http.ReadResponse(bufio.NewReader(bytes.NewReader(data)), req)
+}
+
+// fileName is the name of the recording of the request
+func fileName(req *http.Request) string {
+	key := req.Method + " " + req.URL.String() + " " + req.Header.Get(headerRange)
+
+	return fmt.Sprintf("%x.http", sha256.Sum256([]byte(key)))
+}
+
+// WithRecording records the preflight and all chunk responses to the given directory
+func WithRecording(dir string) httpio.Option {
+	return httpio.WithTransportWrapper(func(rt http.RoundTripper) http.RoundTripper {
+		return &Recorder{
+			Base: rt,
+			Dir:  dir,
+		}
+	})
+}
+
+// WithReplay serves the preflight and all chunk responses from the
+// recordings in the given directory
+func WithReplay(dir string) httpio.Option {
+	return httpio.WithTransportWrapper(func(http.RoundTripper) http.RoundTripper {
+		return &Transport{
+			Dir: dir,
+		}
+	})
+}
@@ -0,0 +1,56 @@
+package replay_test
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/jobstoit/httpio"
+	"github.com/jobstoit/httpio/replay"
+)
+
+func TestReplay(t *testing.T) {
+	content := bytes.Repeat([]byte("replayed"), 1024*64)
+
+	svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.ServeContent(w, r, "file", time.Time{}, bytes.NewReader(content))
+	}))
+	u := svr.URL + "/file"
+	dir := t.TempDir()
+
+	get := func(opts ...httpio.Option) ([]byte, error) {
+		remoteFile, err := httpio.Get(u, append(opts, httpio.WithChunkSize(1024*128))...)
+		if err != nil {
+			return nil, err
+		}
+
+		return io.ReadAll(remoteFile)
+	}
+
+	recorded, err := get(replay.WithRecording(dir))
+	if err != nil {
+		t.Fatalf("unable to record download: %v", err)
+	}
+	svr.Close()
+
+	if !bytes.Equal(content, recorded) {
+		t.Errorf("mismatched recorded content")
+	}
+
+	replayed, err := get(replay.WithReplay(dir))
+	if err != nil {
+		t.Fatalf("unable to replay download: %v", err)
+	}
+
+	if !bytes.Equal(content, replayed) {
+		t.Errorf("mismatched replayed content")
+	}
+
+	if _, err := get(replay.WithReplay(t.TempDir())); !errors.Is(err, replay.ErrNotRecorded) {
+		t.Errorf("expected ErrNotRecorded without recordings, got: %v", err)
+	}
+}
@@ -0,0 +1,161 @@
+package httpio
+
+import (
+	"encoding/json"
+	"os"
+	"sync"
+)
+
+// StateSuffix is appended to the download path for the state of a download
+// to a file, which is kept while downloading so an interrupted download is
+// resumed by the next DownloadFile to the same path
+const StateSuffix = ".state.json"
+
+// downloadState is the state of a download to a file, Completed is a
+// bitmap of the chunks that have been written to the file
+type downloadState struct {
+	URL          string `json:"url"`
+	ETag         string `json:"etag,omitempty"`
+	LastModified string `json:"last_modified,omitempty"`
+	Size         int64  `json:"size"`
+	ChunkSize    int64  `json:"chunk_size"`
+	Completed    []byte `json:"completed"`
+}
+
+// resumeState persists the state of a download to a file after every chunk
+type resumeState struct {
+	path string
+	out  *os.File
+
+	mu    sync.Mutex
+	state downloadState
+}
+
+// resumable reports whether a download to a file can be resumed, which
+// takes a revision of the file to validate the state with and chunks that
+// are written to the file right away
+func (f *RemoteFile) resumable() bool {
+	return !f.sequential && f.aead == nil && !f.decompress && len(f.inspectors) == 0 && len(f.checksums) == 0 &&
+		len(f.meta.Checksums) == 0 && (f.meta.ETag != "" || f.meta.LastModified != "")
+}
+
+// resume prepares the file for the download, keeping the chunks written by
+// an earlier download of the same revision of the file recorded in the
+// state next to it, or starting over otherwise. The state may be of another
+// url, it's recorded with the url the download resumes from.
+func (f *RemoteFile) resume(path string, out *os.File) (*resumeState, error) {
+	r := &resumeState{
+		path: path + StateSuffix,
+		out:  out,
+		state: downloadState{
+			URL:          f.req.URL.String(),
+			ETag:         f.meta.ETag,
+			LastModified: f.meta.LastModified,
+			Size:         f.size,
+		},
+	}
+
+	if prev, ok := readState(r.path); ok && r.matches(prev) {
+		// the chunks have to be planned the way they were before
+		f.chunkSize = prev.ChunkSize
+		f.tuneStore = nil
+
+		if prev.URL != r.state.URL {
+			f.logDebug("resuming download of another url", "url", prev.URL)
+		}
+		prev.URL = r.state.URL
+		r.state = *prev
+
+		return r, nil
+	}
+
+	if err := out.Truncate(0); err != nil {
+		return nil, err
+	}
+
+	return r, out.Truncate(f.size)
+}
+
+// matches reports whether the earlier state is of the same revision of the
+// file and the file still has the size it was given then. Only the
+// validators and the size are compared, not the url, so a download resumes
+// from a mirror or a renewed signed url of the same file.
+func (r *resumeState) matches(prev *downloadState) bool {
+	info, err := r.out.Stat()
+	if err != nil || info.Size() != prev.Size || prev.Size != r.state.Size || prev.ChunkSize < 1 {
+		return false
+	}
+
+	if int64(len(prev.Completed)) != (chunkCount(prev.Size, prev.ChunkSize)+7)/8 {
+		return false
+	}
+
+	return prev.ETag == r.state.ETag && prev.LastModified == r.state.LastModified
+}
+
+// plan returns whether each of the chunks of the download has been written
+// already, starting a new state for a download that's starting over
+func (r *resumeState) plan(chunkSize int64, chunks int) []bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.state.ChunkSize == 0 {
+		r.state.ChunkSize = chunkSize
+		r.state.Completed = make([]byte, (chunks+7)/8)
+	}
+
+	done := make([]bool, chunks)
+	for i := range done {
+		done[i] = r.state.Completed[i/8]&(1<<(i%8)) != 0
+	}
+
+	return done
+}
+
+// complete records the chunk as written once it's synced to disk, so the
+// state never claims chunks that might get lost
+func (r *resumeState) complete(c Chunk) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := r.out.Sync(); err != nil {
+		return err
+	}
+
+	r.state.Completed[c.Index/8] |= 1 << (c.Index % 8)
+
+	data, err := json.Marshal(r.state)
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(r.path, data, 0o644)
+}
+
+// remove removes the state once the download is complete
+func (r *resumeState) remove() error {
+	if err := os.Remove(r.path); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	return nil
+}
+
+func readState(path string) (*downloadState, bool) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, false
+	}
+
+	state := &downloadState{}
+	if err := json.Unmarshal(data, state); err != nil {
+		return nil, false
+	}
+
+	return state, true
+}
+
+// chunkCount returns the number of chunks of the given size in a file
+func chunkCount(size, chunkSize int64) int64 {
+	return (size + chunkSize - 1) / chunkSize
+}
@@ -0,0 +1,185 @@
+package httpio_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/jobstoit/httpio"
+)
+
+// newResumeServer serves the data with the given entity tag, failing the
+// range requests from the failing offset while fail is set, and records
+// the ranges it was requested
+func newResumeServer(data []byte, etag *atomic.Value, fail *atomic.Bool, failAt string) (*httptest.Server, *[]string, *sync.Mutex) {
+	var mu sync.Mutex
+	var ranges []string
+
+	svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if rng := r.Header.Get("Range"); rng != "" {
+			if fail.Load() && strings.HasPrefix(rng, failAt) {
+				http.Error(w, "unavailable", http.StatusInternalServerError)
+				return
+			}
+
+			mu.Lock()
+			ranges = append(ranges, rng)
+			mu.Unlock()
+		}
+
+		w.Header().Set("ETag", etag.Load().(string))
+		http.ServeContent(w, r, "", time.Time{}, bytes.NewReader(data))
+	}))
+
+	return svr, &ranges, &mu
+}
+
+func TestDownloadFileResume(t *testing.T) {
+	data := bytes.Repeat([]byte("0123456789abcdef"), 64*1024)
+
+	var etag atomic.Value
+	etag.Store(`"v1"`)
+	var fail atomic.Bool
+	fail.Store(true)
+
+	svr, ranges, mu := newResumeServer(data, &etag, &fail, "bytes=524288-")
+	defer svr.Close()
+
+	path := filepath.Join(t.TempDir(), "file")
+	opts := []httpio.Option{httpio.WithChunkSize(128 * 1024), httpio.WithConcurrency(1)}
+
+	if err := httpio.DownloadFile(context.Background(), svr.URL, path, opts...); err == nil {
+		t.Fatalf("expected the download to fail")
+	}
+
+	if _, err := os.Stat(path + httpio.StateSuffix); err != nil {
+		t.Fatalf("expected the state of the interrupted download: %v", err)
+	}
+
+	fail.Store(false)
+	mu.Lock()
+	*ranges = nil
+	mu.Unlock()
+
+	if err := httpio.DownloadFile(context.Background(), svr.URL, path, opts...); err != nil {
+		t.Fatalf("unable to resume download: %v", err)
+	}
+
+	actual, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("unable to read downloaded file: %v", err)
+	}
+
+	if !bytes.Equal(actual, data) {
+		t.Errorf("mismatched downloaded content")
+	}
+
+	mu.Lock()
+	if len(*ranges) != 4 || (*ranges)[0] != "bytes=524288-655359" {
+		t.Errorf("expected only the 4 missing chunks to be requested, got %v", *ranges)
+	}
+	mu.Unlock()
+
+	if _, err := os.Stat(path + httpio.StateSuffix); !os.IsNotExist(err) {
+		t.Errorf("expected the state to be removed, got: %v", err)
+	}
+}
+
+func TestDownloadFileResumeChanged(t *testing.T) {
+	data := bytes.Repeat([]byte("0123456789abcdef"), 64*1024)
+
+	var etag atomic.Value
+	etag.Store(`"v1"`)
+	var fail atomic.Bool
+	fail.Store(true)
+
+	svr, ranges, mu := newResumeServer(data, &etag, &fail, "bytes=524288-")
+	defer svr.Close()
+
+	path := filepath.Join(t.TempDir(), "file")
+	opts := []httpio.Option{httpio.WithChunkSize(128 * 1024), httpio.WithConcurrency(1)}
+
+	if err := httpio.DownloadFile(context.Background(), svr.URL, path, opts...); err == nil {
+		t.Fatalf("expected the download to fail")
+	}
+
+	fail.Store(false)
+	etag.Store(`"v2"`)
+	mu.Lock()
+	*ranges = nil
+	mu.Unlock()
+
+	if err := httpio.DownloadFile(context.Background(), svr.URL, path, opts...); err != nil {
+		t.Fatalf("unable to download: %v", err)
+	}
+
+	mu.Lock()
+	if len(*ranges) != 8 {
+		t.Errorf("expected the changed file to be downloaded again, got %v", *ranges)
+	}
+	mu.Unlock()
+}
+
+func TestDownloadFileResumeOtherURL(t *testing.T) {
+	data := bytes.Repeat([]byte("0123456789abcdef"), 64*1024)
+
+	var etag atomic.Value
+	etag.Store(`"v1"`)
+	var failA, failB atomic.Bool
+	failA.Store(true)
+	failB.Store(true)
+
+	svrA, _, _ := newResumeServer(data, &etag, &failA, "bytes=524288-")
+	defer svrA.Close()
+
+	svrB, ranges, mu := newResumeServer(data, &etag, &failB, "bytes=786432-")
+	defer svrB.Close()
+
+	path := filepath.Join(t.TempDir(), "file")
+	opts := []httpio.Option{httpio.WithChunkSize(128 * 1024), httpio.WithConcurrency(1)}
+
+	if err := httpio.DownloadFile(context.Background(), svrA.URL, path, opts...); err == nil {
+		t.Fatalf("expected the download to fail")
+	}
+
+	// the download resumes from the other url and is interrupted again
+	if err := httpio.DownloadFile(context.Background(), svrB.URL, path, opts...); err == nil {
+		t.Fatalf("expected the resumed download to fail")
+	}
+
+	mu.Lock()
+	if len(*ranges) != 2 || (*ranges)[0] != "bytes=524288-655359" {
+		t.Errorf("expected the download to resume from the chunks of the other url, got %v", *ranges)
+	}
+	mu.Unlock()
+
+	saved, err := os.ReadFile(path + httpio.StateSuffix)
+	if err != nil {
+		t.Fatalf("expected the state of the interrupted download: %v", err)
+	}
+
+	var state struct {
+		URL string `json:"url"`
+	}
+	if err := json.Unmarshal(saved, &state); err != nil || state.URL != svrB.URL {
+		t.Errorf("expected the state to record the url %s, got '%s': %v", svrB.URL, state.URL, err)
+	}
+
+	failB.Store(false)
+	if err := httpio.DownloadFile(context.Background(), svrB.URL, path, opts...); err != nil {
+		t.Fatalf("unable to resume download: %v", err)
+	}
+
+	if actual, _ := os.ReadFile(path); !bytes.Equal(actual, data) {
+		t.Errorf("mismatched downloaded content")
+	}
+}
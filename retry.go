@@ -0,0 +1,71 @@
+package httpio
+
+import (
+	"context"
+	"math/rand/v2"
+	"net/http"
+	"time"
+)
+
+// Backoff returns the delay before the given retry, the first retry is 0
+type Backoff func(retry int) time.Duration
+
+// ExponentialBackoff doubles the delay from the base delay up to the
+// maximum delay for every retry, each delay is jittered between half and
+// the full delay so retrying workers don't hit the server at once
+func ExponentialBackoff(base, maxDelay time.Duration) Backoff {
+	return func(retry int) time.Duration {
+		delay := base << retry
+		if delay <= 0 || delay > maxDelay {
+			delay = maxDelay
+		}
+
+		half := delay / 2
+
+		return half + rand.N(half+1)
+	}
+}
+
+// defaultBackoff is used when retrying without a Backoff set with WithBackoff
+var defaultBackoff = ExponentialBackoff(throttleBaseDelay, throttleMaxDelay)
+
+// WithRetry retries a chunk request up to the given number of times when it
+// fails with a network error or a 5xx response, a chunk whose response
+// breaks off is continued from where it broke off up to the same number of
+// times. Requests aren't retried by default.
+func WithRetry(n int) Option {
+	return func(f *RemoteFile) error {
+		f.retries = max(n, 0)
+
+		return nil
+	}
+}
+
+// WithBackoff sets the delay between the retries of WithRetry, which
+// defaults to an ExponentialBackoff from 250ms up to 30s
+func WithBackoff(backoff Backoff) Option {
+	return func(f *RemoteFile) error {
+		f.backoff = backoff
+
+		return nil
+	}
+}
+
+// retryDelay returns the delay before the given retry
+func (f *RemoteFile) retryDelay(retry int) time.Duration {
+	if f.backoff == nil {
+		return defaultBackoff(retry)
+	}
+
+	return f.backoff(retry)
+}
+
+// retryable reports whether a request that failed with the error or got the
+// response can be retried, nothing is retried once the context is done
+func retryable(ctx context.Context, res *http.Response, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+
+	return err != nil || res.StatusCode >= 500
+}
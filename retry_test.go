@@ -0,0 +1,109 @@
+package httpio_test
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jobstoit/httpio"
+)
+
+func TestGetRetry(t *testing.T) {
+	// every range fails twice before it's served
+	var mu sync.Mutex
+	failures := map[string]int{}
+
+	svr := newTestServerWithHandler(func(h http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if rng := r.Header.Get("Range"); rng != "" {
+				mu.Lock()
+				failures[rng]++
+				n := failures[rng]
+				mu.Unlock()
+
+				if n <= 2 {
+					http.Error(w, "unavailable", http.StatusBadGateway)
+					return
+				}
+			}
+
+			h.ServeHTTP(w, r)
+		})
+	})
+	defer svr.Close()
+
+	u := svr.URL().JoinPath("assets", "test_5mb").String()
+	expected, _ := testdata.ReadFile("testdata/test_5mb")
+	noDelay := httpio.WithBackoff(func(int) time.Duration { return 0 })
+
+	remoteFile, err := httpio.Get(u, httpio.WithRetry(1), noDelay)
+	if err != nil {
+		t.Fatalf("unable to get file: %v", err)
+	}
+
+	if _, err := io.Copy(io.Discard, remoteFile); err == nil {
+		t.Error("expected the download to fail once the retries ran out")
+	}
+
+	mu.Lock()
+	clear(failures)
+	mu.Unlock()
+
+	remoteFile, err = httpio.Get(u, httpio.WithRetry(2), noDelay)
+	if err != nil {
+		t.Fatalf("unable to get file: %v", err)
+	}
+
+	actual, err := io.ReadAll(remoteFile)
+	if err != nil {
+		t.Fatalf("expected the retries to recover the download: %v", err)
+	}
+
+	if !bytes.Equal(expected, actual) {
+		t.Error("mismatched content")
+	}
+}
+
+func TestGetRetryChaos(t *testing.T) {
+	svr := newTestServer()
+	defer svr.Close()
+
+	u := svr.URL().JoinPath("assets", "test_5mb").String()
+	expected, _ := testdata.ReadFile("testdata/test_5mb")
+
+	remoteFile, err := httpio.Get(u,
+		httpio.WithChaos(httpio.ChaosConfig{Seed: 3, FailRate: 0.3, TruncateRate: 0.3}),
+		httpio.WithChunkSize(1024*512),
+		httpio.WithRetry(20),
+		httpio.WithBackoff(httpio.ExponentialBackoff(time.Millisecond, time.Millisecond*5)),
+	)
+	if err != nil {
+		t.Fatalf("unable to get file: %v", err)
+	}
+
+	actual, err := io.ReadAll(remoteFile)
+	if err != nil {
+		t.Fatalf("expected failed and truncated chunks to be retried: %v", err)
+	}
+
+	if !bytes.Equal(expected, actual) {
+		t.Error("mismatched content")
+	}
+}
+
+func TestExponentialBackoff(t *testing.T) {
+	backoff := httpio.ExponentialBackoff(time.Millisecond*100, time.Second)
+
+	for retry, full := range []time.Duration{100, 200, 400, 800, 1000, 1000} {
+		full *= time.Millisecond
+
+		for range 10 {
+			if d := backoff(retry); d < full/2 || d > full {
+				t.Errorf("expected retry %d to wait between %s and %s, got %s", retry, full/2, full, d)
+			}
+		}
+	}
+}
@@ -0,0 +1,76 @@
+package httpio
+
+import "fmt"
+
+// Chunk is a part of the file that's fetched with a single range request
+type Chunk struct {
+	Index  int
+	Offset int64
+	Length int64
+}
+
+// Range returns the value of the Range header requesting the chunk
+func (c Chunk) Range() string {
+	return fmt.Sprintf("bytes=%d-%d", c.Offset, c.Offset+c.Length-1)
+}
+
+// Scheduler decides the order in which the chunks of a file are fetched.
+// The calls to a scheduler are serialized, so implementations don't have to
+// be safe for concurrent use. Chunks are always delivered to the reader in
+// order, chunks fetched ahead of a chunk that isn't being fetched yet are
+// buffered in memory.
+type Scheduler interface {
+	// NextChunk returns the next chunk to fetch, or false when there's no
+	// chunk to fetch right now. NextChunk is called again when a chunk
+	// completes, returning false while no chunk is in flight fails the
+	// download with ErrSchedulerStalled.
+	NextChunk() (Chunk, bool)
+	// OnComplete is called when a chunk has been fetched
+	OnComplete(c Chunk)
+	// OnError is called when fetching a chunk failed, which stops the download
+	OnError(c Chunk, err error)
+}
+
+// NewSchedulerFunc creates the scheduler for a download from its planned chunks
+type NewSchedulerFunc func(chunks []Chunk) Scheduler
+
+type sequentialScheduler struct {
+	chunks []Chunk
+	next   int
+}
+
+// NewSequentialScheduler returns the default scheduler, which fetches the
+// chunks in order
+func NewSequentialScheduler(chunks []Chunk) Scheduler {
+	return &sequentialScheduler{chunks: chunks}
+}
+
+func (s *sequentialScheduler) NextChunk() (Chunk, bool) {
+	if s.next >= len(s.chunks) {
+		return Chunk{}, false
+	}
+
+	c := s.chunks[s.next]
+	s.next++
+
+	return c, true
+}
+
+func (s *sequentialScheduler) OnComplete(Chunk) {}
+
+func (s *sequentialScheduler) OnError(Chunk, error) {}
+
+// planChunks splits the file of the given size from the start offset into
+// chunks of the given size
+func planChunks(start, size, chunkSize int64) []Chunk {
+	var chunks []Chunk
+	for offset := start; offset < size; offset += chunkSize {
+		chunks = append(chunks, Chunk{
+			Index:  len(chunks),
+			Offset: offset,
+			Length: min(chunkSize, size-offset),
+		})
+	}
+
+	return chunks
+}
@@ -0,0 +1,127 @@
+package httpio_test
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jobstoit/httpio"
+)
+
+// reverseScheduler fetches the chunks from the last to the first
+type reverseScheduler struct {
+	chunks    []httpio.Chunk
+	completed []int
+}
+
+func (s *reverseScheduler) NextChunk() (httpio.Chunk, bool) {
+	if len(s.chunks) == 0 {
+		return httpio.Chunk{}, false
+	}
+
+	c := s.chunks[len(s.chunks)-1]
+	s.chunks = s.chunks[:len(s.chunks)-1]
+
+	return c, true
+}
+
+func (s *reverseScheduler) OnComplete(c httpio.Chunk) {
+	s.completed = append(s.completed, c.Index)
+}
+
+func (s *reverseScheduler) OnError(httpio.Chunk, error) {}
+
+func TestGetScheduler(t *testing.T) {
+	content := bytes.Repeat([]byte("scheduled"), 1024*64)
+
+	var (
+		mu     sync.Mutex
+		ranges []string
+	)
+	svr := newTestServerWithHandler(func(http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if rng := r.Header.Get("Range"); rng != "" {
+				mu.Lock()
+				ranges = append(ranges, rng)
+				mu.Unlock()
+			}
+
+			http.ServeContent(w, r, "file", time.Time{}, bytes.NewReader(content))
+		})
+	})
+	defer svr.Close()
+
+	sched := &reverseScheduler{}
+	remoteFile, err := httpio.Get(svr.URL().JoinPath("file").String(),
+		httpio.WithChunkSize(1024*64),
+		httpio.WithConcurrency(1),
+		httpio.WithScheduler(func(chunks []httpio.Chunk) httpio.Scheduler {
+			sched.chunks = chunks
+			return sched
+		}),
+	)
+	if err != nil {
+		t.Fatalf("failed to setup request: %v", err)
+	}
+
+	actual, err := io.ReadAll(remoteFile)
+	if err != nil {
+		t.Fatalf("unable to read file: %v", err)
+	}
+
+	if !bytes.Equal(content, actual) {
+		t.Errorf("mismatched content")
+	}
+
+	if e, a := "bytes=524288-589823", ranges[0]; e != a {
+		t.Errorf("expected the last chunk to be fetched first, got '%s'", a)
+	}
+
+	if e, a := 9, len(sched.completed); e != a || sched.completed[0] != 8 {
+		t.Errorf("expected %d chunks completed in reverse, got %v", e, sched.completed)
+	}
+}
+
+type stubScheduler struct {
+	chunk httpio.Chunk
+	ok    bool
+}
+
+func (s *stubScheduler) NextChunk() (httpio.Chunk, bool) { return s.chunk, s.ok }
+
+func (s *stubScheduler) OnComplete(httpio.Chunk) {}
+
+func (s *stubScheduler) OnError(httpio.Chunk, error) {}
+
+func TestGetSchedulerErrors(t *testing.T) {
+	svr := newTestServer()
+	defer svr.Close()
+
+	u := svr.URL().JoinPath("assets", "test_5mb").String()
+
+	read := func(sched httpio.Scheduler) error {
+		remoteFile, err := httpio.Get(u, httpio.WithScheduler(func([]httpio.Chunk) httpio.Scheduler {
+			return sched
+		}))
+		if err != nil {
+			t.Fatalf("failed to setup request: %v", err)
+		}
+
+		_, err = io.ReadAll(remoteFile)
+
+		return err
+	}
+
+	if err := read(&stubScheduler{}); !errors.Is(err, httpio.ErrSchedulerStalled) {
+		t.Errorf("expected ErrSchedulerStalled, got: %v", err)
+	}
+
+	if err := read(&stubScheduler{chunk: httpio.Chunk{Index: 99}, ok: true}); err == nil || !strings.Contains(err.Error(), "invalid chunk") {
+		t.Errorf("expected an invalid chunk error, got: %v", err)
+	}
+}
@@ -0,0 +1,86 @@
+package httpio
+
+import (
+	"context"
+	"errors"
+	"io"
+)
+
+// errSeeked stops the run abandoned by seeking
+var errSeeked = errors.New("download abandoned by seeking")
+
+// Seek sets the position of the next Read. Short forward seeks read past
+// the bytes in flight, other seeks abandon the current download and start
+// downloading at the new position. Seeking is unsupported with WithEncryption
+// and WithDecompress.
+func (f *RemoteFile) Seek(offset int64, whence int) (int64, error) {
+	var abs int64
+	switch whence {
+	case io.SeekStart:
+		abs = offset
+	case io.SeekCurrent:
+		abs = f.pos + offset
+	case io.SeekEnd:
+		abs = f.size + offset
+	default:
+		return f.pos, errors.New("invalid whence")
+	}
+
+	if abs < 0 {
+		return f.pos, errors.New("negative position")
+	}
+
+	if abs == f.pos {
+		return abs, nil
+	}
+
+	if f.aead != nil {
+		return f.pos, errors.New("unable to seek an encrypted stream")
+	}
+
+	if f.decompress {
+		return f.pos, errors.New("unable to seek a decompressed stream")
+	}
+
+	f.mu.Lock()
+	begun := f.begun
+	f.mu.Unlock()
+
+	// the first run starts at the position
+	if !begun {
+		f.pos = abs
+
+		return abs, nil
+	}
+
+	if abs > f.pos && abs-f.pos <= f.chunkSize {
+		if _, err := io.CopyN(io.Discard, f, abs-f.pos); err == nil {
+			return abs, nil
+		}
+	}
+
+	f.restart(abs)
+
+	return abs, nil
+}
+
+// restart abandons the current run and starts downloading at the offset
+func (f *RemoteFile) restart(offset int64) {
+	rd, wr := io.Pipe()
+	ctx, cancel := context.WithCancel(f.ctx)
+	r := &run{cancel: cancel, done: make(chan struct{})}
+
+	f.mu.Lock()
+	prev := f.run
+	f.run = r
+	f.mu.Unlock()
+
+	prev.cancel()
+	f.pr.CloseWithError(errSeeked)
+
+	f.rd, f.pr = rd, rd
+	f.peeked = nil
+	f.pos = offset
+
+	go f.download(ctx, wr, offset, r)
+}
@@ -0,0 +1,107 @@
+package httpio_test
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/jobstoit/httpio"
+)
+
+func TestSeek(t *testing.T) {
+	svr := newTestServer()
+	defer svr.Close()
+
+	u := svr.URL().JoinPath("assets", "test_5mb").String()
+	expected, _ := testdata.ReadFile("testdata/test_5mb")
+
+	remoteFile, err := httpio.Get(u, httpio.WithChunkSize(1024*256))
+	if err != nil {
+		t.Fatalf("unable to get file: %v", err)
+	}
+
+	buf := make([]byte, 1000)
+	read := func(off int64) {
+		t.Helper()
+
+		if _, err := io.ReadFull(remoteFile, buf); err != nil {
+			t.Fatalf("unable to read at %d: %v", off, err)
+		}
+
+		if !bytes.Equal(buf, expected[off:off+1000]) {
+			t.Errorf("mismatched content at %d", off)
+		}
+	}
+
+	read(0)
+
+	// a short seek forward reads past the bytes
+	if pos, err := remoteFile.Seek(1000, io.SeekCurrent); err != nil || pos != 2000 {
+		t.Fatalf("unable to seek: %d, %v", pos, err)
+	}
+	read(2000)
+
+	if pos, err := remoteFile.Seek(1024*1024*3, io.SeekStart); err != nil || pos != 1024*1024*3 {
+		t.Fatalf("unable to seek: %d, %v", pos, err)
+	}
+	read(1024 * 1024 * 3)
+
+	if pos, err := remoteFile.Seek(-1000, io.SeekEnd); err != nil || pos != int64(len(expected))-1000 {
+		t.Fatalf("unable to seek: %d, %v", pos, err)
+	}
+	read(int64(len(expected)) - 1000)
+
+	if _, err := remoteFile.Seek(0, io.SeekStart); err != nil {
+		t.Fatalf("unable to seek: %v", err)
+	}
+
+	actual, err := io.ReadAll(remoteFile)
+	if err != nil {
+		t.Fatalf("unable to read file: %v", err)
+	}
+
+	if !bytes.Equal(actual, expected) {
+		t.Error("mismatched content after seeking back to the start")
+	}
+}
+
+func TestSeekServeContent(t *testing.T) {
+	svr := newTestServer()
+	defer svr.Close()
+
+	u := svr.URL().JoinPath("assets", "test_5mb").String()
+	expected, _ := testdata.ReadFile("testdata/test_5mb")
+
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		remoteFile, err := httpio.Get(u, httpio.WithChunkSize(1024*256))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadGateway)
+			return
+		}
+		defer remoteFile.Close()
+
+		http.ServeContent(w, r, "test_5mb", time.Time{}, remoteFile)
+	}))
+	defer proxy.Close()
+
+	req, _ := http.NewRequest(http.MethodGet, proxy.URL, nil)
+	req.Header.Set("Range", "bytes=4000000-4000999")
+
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("unable to request range: %v", err)
+	}
+	defer res.Body.Close()
+
+	actual, err := io.ReadAll(res.Body)
+	if err != nil || res.StatusCode != http.StatusPartialContent {
+		t.Fatalf("unable to read range: %d, %v", res.StatusCode, err)
+	}
+
+	if !bytes.Equal(actual, expected[4000000:4001000]) {
+		t.Error("mismatched content of the served range")
+	}
+}
@@ -0,0 +1,96 @@
+package httpio
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// WithoutSequentialFallback fails the download with ErrRangeIgnored when
+// the server responds to a range request with the whole file, instead of
+// continuing the download sequentially
+func WithoutSequentialFallback() Option {
+	return func(f *RemoteFile) error {
+		f.strictRanges = true
+
+		return nil
+	}
+}
+
+// rangesUnsupported reports whether the file has to be downloaded with a
+// single request, because the server doesn't support ranges or the size
+// of the file is unknown
+func rangesUnsupported(meta *Metadata) bool {
+	return meta.Size < 0 || strings.EqualFold(strings.TrimSpace(meta.AcceptRanges), rangeUnitNone)
+}
+
+// stream downloads the file with a single request without a range,
+// skipping the bytes before the offset that were written already
+func (f *RemoteFile) stream(ctx context.Context, w io.Writer, offset int64) error {
+	if f.end >= 0 && offset >= f.end {
+		return nil
+	}
+
+	f.logDebug("downloading sequentially", "offset", offset)
+
+	if err := f.pacer.wait(ctx); err != nil {
+		return err
+	}
+
+	res, err := f.do(f.newRequest(f.traceContext(ctx)))
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	f.pacer.update(res.Header)
+
+	if err := checkPrecondition(res); err != nil {
+		return err
+	}
+
+	if res.StatusCode != http.StatusOK {
+		return newStatusError(res)
+	}
+
+	if err := f.validators.check(res); err != nil {
+		return err
+	}
+	verifyBody(res)
+
+	if _, err := io.CopyN(io.Discard, res.Body, offset); err != nil {
+		return err
+	}
+
+	if f.progress != nil {
+		w = &progressWriter{wr: w, file: f, offset: offset}
+	}
+
+	var body io.Reader = res.Body
+	if f.end >= 0 && (f.size < 0 || f.end < f.size) {
+		body = io.LimitReader(body, f.end-offset)
+	}
+
+	if f.sniff && offset == 0 {
+		length := f.size
+		if length < 0 {
+			length = sniffLen
+		}
+
+		if body, err = sniffContentType(body, length, f.allowedTypes); err != nil {
+			return err
+		}
+	}
+
+	n, err := copyBuffer(w, body)
+	if err != nil {
+		return err
+	}
+
+	if f.size >= 0 && offset+n != f.end {
+		return fmt.Errorf("response has length %d, expected %d", offset+n, f.end)
+	}
+
+	return nil
+}
@@ -0,0 +1,145 @@
+package httpio_test
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/jobstoit/httpio"
+)
+
+func TestGetRangeIgnored(t *testing.T) {
+	var requests atomic.Int32
+	svr := newTestServerWithHandler(func(h http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method == http.MethodGet {
+				requests.Add(1)
+			}
+
+			r.Header.Del("Range")
+			h.ServeHTTP(w, r)
+		})
+	})
+	defer svr.Close()
+
+	u := svr.URL().JoinPath("assets", "test_5mb").String()
+	expected, _ := testdata.ReadFile("testdata/test_5mb")
+
+	remoteFile, err := httpio.Get(u, httpio.WithChunkSize(1024*1024), httpio.WithConcurrency(2))
+	if err != nil {
+		t.Fatalf("unable to get file: %v", err)
+	}
+
+	actual, err := io.ReadAll(remoteFile)
+	if err != nil {
+		t.Fatalf("expected the download to continue sequentially: %v", err)
+	}
+
+	if !bytes.Equal(expected, actual) {
+		t.Error("mismatched content")
+	}
+
+	// the range requests in flight and the sequential request
+	if n := requests.Load(); n > 3 {
+		t.Errorf("expected the chunked download to stop, got %d requests", n)
+	}
+}
+
+func TestGetWithoutRanges(t *testing.T) {
+	expected, _ := testdata.ReadFile("testdata/test_5mb")
+
+	var ranged atomic.Int32
+	svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Range") != "" {
+			ranged.Add(1)
+		}
+
+		w.Header().Set("Accept-Ranges", "none")
+		w.WriteHeader(http.StatusOK)
+
+		if r.Method == http.MethodGet {
+			// flushing before the body is written leaves the size unknown
+			w.(http.Flusher).Flush()
+			_, _ = w.Write(expected)
+		}
+	}))
+	defer svr.Close()
+
+	remoteFile, err := httpio.Get(svr.URL, httpio.WithChunkSize(1024*1024))
+	if err != nil {
+		t.Fatalf("unable to get file: %v", err)
+	}
+
+	actual, err := io.ReadAll(remoteFile)
+	if err != nil {
+		t.Fatalf("unable to read file: %v", err)
+	}
+
+	if !bytes.Equal(expected, actual) {
+		t.Error("mismatched content")
+	}
+
+	if n := ranged.Load(); n != 0 {
+		t.Errorf("expected no range requests, got %d", n)
+	}
+}
+
+func TestGetRangeIgnoredStrict(t *testing.T) {
+	svr := newTestServerWithHandler(func(h http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			r.Header.Del("Range")
+			h.ServeHTTP(w, r)
+		})
+	})
+	defer svr.Close()
+
+	u := svr.URL().JoinPath("assets", "test_5mb").String()
+
+	remoteFile, err := httpio.Get(u, httpio.WithChunkSize(1024*1024), httpio.WithoutSequentialFallback())
+	if err != nil {
+		t.Fatalf("unable to get file: %v", err)
+	}
+	defer remoteFile.Close()
+
+	if _, err := remoteFile.ReadAt(make([]byte, 10), 1000); !errors.Is(err, httpio.ErrRangeIgnored) {
+		t.Errorf("expected ReadAt to fail with ErrRangeIgnored, got: %v", err)
+	}
+
+	if _, err := io.Copy(io.Discard, remoteFile); !errors.Is(err, httpio.ErrRangeIgnored) {
+		t.Errorf("expected ErrRangeIgnored, got: %v", err)
+	}
+
+	if _, err := remoteFile.ReadAt(make([]byte, 10), 1000); !errors.Is(err, httpio.ErrRangeNotSupported) {
+		t.Errorf("expected ErrRangeIgnored to match ErrRangeNotSupported, got: %v", err)
+	}
+}
+
+func TestGetInvalidContentRange(t *testing.T) {
+	svr := newTestServerWithHandler(func(h http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Header.Get("Range") == "" {
+				h.ServeHTTP(w, r)
+				return
+			}
+
+			w.WriteHeader(http.StatusPartialContent)
+			_, _ = w.Write(make([]byte, 1024*1024))
+		})
+	})
+	defer svr.Close()
+
+	u := svr.URL().JoinPath("assets", "test_5mb").String()
+
+	remoteFile, err := httpio.Get(u, httpio.WithChunkSize(1024*1024))
+	if err != nil {
+		t.Fatalf("unable to get file: %v", err)
+	}
+
+	if _, err := io.Copy(io.Discard, remoteFile); err == nil {
+		t.Error("expected a partial response without Content-Range to fail")
+	}
+}
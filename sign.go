@@ -0,0 +1,102 @@
+package httpio
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"hash"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	DefaultSignatureHeader = "X-Signature"
+	DefaultTimestampHeader = "X-Signature-Timestamp"
+)
+
+// Signer signs a request right before it's sent, including the Range
+// header of the chunk requests
+type Signer interface {
+	Sign(req *http.Request) error
+}
+
+// SignerFunc is a function implementing Signer
+type SignerFunc func(req *http.Request) error
+
+// Sign implements Signer
+func (fn SignerFunc) Sign(req *http.Request) error {
+	return fn(req)
+}
+
+// HMACSigner is a reference Signer that signs the timestamp, method,
+// path and range of a request using HMAC. The signed string is the
+// newline separated unix timestamp, method, request uri and Range header.
+type HMACSigner struct {
+	// Key is the secret key
+	Key []byte
+	// Hash is the hash function, defaults to sha256.New
+	Hash func() hash.Hash
+	// SignatureHeader defaults to DefaultSignatureHeader
+	SignatureHeader string
+	// TimestampHeader defaults to DefaultTimestampHeader
+	TimestampHeader string
+	// Now defaults to time.Now
+	Now func() time.Time
+}
+
+// Sign implements Signer
+func (s *HMACSigner) Sign(req *http.Request) error {
+	now := time.Now
+	if s.Now != nil {
+		now = s.Now
+	}
+
+	timestamp := strconv.FormatInt(now().Unix(), 10)
+
+	req.Header.Set(valueOr(s.TimestampHeader, DefaultTimestampHeader), timestamp)
+	req.Header.Set(valueOr(s.SignatureHeader, DefaultSignatureHeader), s.Signature(timestamp, req))
+
+	return nil
+}
+
+// Signature returns the hex encoded signature of the request for the given timestamp
+func (s *HMACSigner) Signature(timestamp string, req *http.Request) string {
+	h := sha256.New
+	if s.Hash != nil {
+		h = s.Hash
+	}
+
+	mac := hmac.New(h, s.Key)
+	mac.Write([]byte(strings.Join([]string{
+		timestamp,
+		req.Method,
+		req.URL.RequestURI(),
+		req.Header.Get(headerRange),
+	}, "\n")))
+
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+type signingTransport struct {
+	base   http.RoundTripper
+	signer Signer
+}
+
+func (t *signingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	if err := t.signer.Sign(req); err != nil {
+		return nil, err
+	}
+
+	return t.base.RoundTrip(req)
+}
+
+func valueOr(value, fallback string) string {
+	if value == "" {
+		return fallback
+	}
+
+	return value
+}
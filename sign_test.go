@@ -0,0 +1,57 @@
+package httpio_test
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/jobstoit/httpio"
+)
+
+func TestGetHMACSigner(t *testing.T) {
+	key := []byte("secret")
+	content := bytes.Repeat([]byte("signed"), 1024*256)
+
+	var signed atomic.Int32
+	svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mac := hmac.New(sha256.New, key)
+		mac.Write([]byte(r.Header.Get(httpio.DefaultTimestampHeader) + "\n" + r.Method + "\n" + r.URL.RequestURI() + "\n" + r.Header.Get("Range")))
+
+		if r.Header.Get(httpio.DefaultSignatureHeader) != hex.EncodeToString(mac.Sum(nil)) {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+
+		signed.Add(1)
+		http.ServeContent(w, r, "file", time.Time{}, bytes.NewReader(content))
+	}))
+	defer svr.Close()
+
+	remoteFile, err := httpio.Get(svr.URL+"/file?version=1",
+		httpio.WithSigner(&httpio.HMACSigner{Key: key}),
+		httpio.WithChunkSize(1024*256),
+	)
+	if err != nil {
+		t.Fatalf("failed to setup request: %v", err)
+	}
+
+	actual, err := io.ReadAll(remoteFile)
+	if err != nil {
+		t.Fatalf("unable to read file: %v", err)
+	}
+
+	if !bytes.Equal(content, actual) {
+		t.Errorf("mismatched content")
+	}
+
+	if signed.Load() < 2 {
+		t.Errorf("expected the preflight and chunk requests to be signed, got %d", signed.Load())
+	}
+}
@@ -0,0 +1,50 @@
+package httpio
+
+import (
+	"errors"
+	"io"
+	"net/http"
+)
+
+// sniffLen is the number of bytes http.DetectContentType considers
+const sniffLen = 512
+
+// Peek returns the next n bytes without advancing the reader, the bytes are
+// buffered and returned by the subsequent reads. When the file is shorter
+// than n bytes the remaining bytes are returned together with io.EOF.
+func (f *RemoteFile) Peek(n int) ([]byte, error) {
+	if n < 0 {
+		return nil, errors.New("negative count")
+	}
+
+	f.begin()
+
+	if have := len(f.peeked); have < n {
+		buf := make([]byte, n)
+		copy(buf, f.peeked)
+
+		read, err := io.ReadFull(f.rd, buf[have:])
+		f.peeked = buf[:have+read]
+
+		if errors.Is(err, io.ErrUnexpectedEOF) {
+			err = io.EOF
+		}
+
+		if err != nil {
+			return f.peeked, err
+		}
+	}
+
+	return f.peeked[:n], nil
+}
+
+// DetectContentType detects the content type of the file from its first
+// 512 bytes using http.DetectContentType, without consuming them
+func (f *RemoteFile) DetectContentType() (string, error) {
+	head, err := f.Peek(sniffLen)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return "", err
+	}
+
+	return http.DetectContentType(head), nil
+}
@@ -0,0 +1,79 @@
+package httpio
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+)
+
+// SourceSuffix is appended to the download path for the sidecar file
+// holding the Source where extended attributes are unsupported
+const SourceSuffix = ".source.json"
+
+// The extended attributes holding the Source, the url uses the name of
+// the freedesktop.org recommendation so file managers show it
+const (
+	xattrURL          = "user.xdg.origin.url"
+	xattrETag         = "user.httpio.etag"
+	xattrLastModified = "user.httpio.last_modified"
+	xattrSHA256       = "user.httpio.sha256"
+)
+
+// Source describes where a file downloaded with WithSourceAttributes
+// came from
+type Source struct {
+	URL          string `json:"url"`
+	ETag         string `json:"etag,omitempty"`
+	LastModified string `json:"last_modified,omitempty"`
+	SHA256       string `json:"sha256"`
+}
+
+// WithSourceAttributes stores the Source of a file downloaded with
+// DownloadFile in its extended attributes, or in a sidecar file where
+// extended attributes are unsupported. It's read back with ReadSource.
+// Downloads split into parts record their source in the manifest instead.
+func WithSourceAttributes() Option {
+	return func(f *RemoteFile) error {
+		f.sourceAttrs = true
+
+		return nil
+	}
+}
+
+// ReadSource reads the Source stored with WithSourceAttributes for the file
+// at the given path
+func ReadSource(path string) (*Source, error) {
+	src, err := getSourceAttrs(path)
+	if err == nil {
+		return src, nil
+	}
+
+	data, serr := os.ReadFile(path + SourceSuffix)
+	if serr != nil {
+		return nil, fmt.Errorf("no source stored for '%s': %w", path, errors.Join(err, serr))
+	}
+
+	src = &Source{}
+	if err := json.Unmarshal(data, src); err != nil {
+		return nil, fmt.Errorf("invalid source: %w", err)
+	}
+
+	return src, nil
+}
+
+// writeSource stores the source in the extended attributes of the file at
+// the given path, falling back to the sidecar file
+func writeSource(path string, src *Source) error {
+	err := setSourceAttrs(path, src)
+	if !errors.Is(err, errors.ErrUnsupported) {
+		return err
+	}
+
+	data, err := json.MarshalIndent(src, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(path+SourceSuffix, data, 0o644)
+}
@@ -0,0 +1,50 @@
+package httpio_test
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"path/filepath"
+	"testing"
+
+	"github.com/jobstoit/httpio"
+)
+
+func TestDownloadFileSourceAttributes(t *testing.T) {
+	svr := newTestServerWithHandler(func(h http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("ETag", `"v1"`)
+			h.ServeHTTP(w, r)
+		})
+	})
+	defer svr.Close()
+
+	path := filepath.Join(t.TempDir(), "test_5mb")
+	u := svr.URL().JoinPath("assets", "test_5mb").String()
+
+	if err := httpio.DownloadFile(context.Background(), u, path, httpio.WithSourceAttributes()); err != nil {
+		t.Fatalf("unable to download file: %v", err)
+	}
+
+	src, err := httpio.ReadSource(path)
+	if err != nil {
+		t.Fatalf("unable to read source: %v", err)
+	}
+
+	expected, _ := testdata.ReadFile("testdata/test_5mb")
+	sum := sha256.Sum256(expected)
+
+	if src.URL != u || src.ETag != `"v1"` || src.SHA256 != hex.EncodeToString(sum[:]) {
+		t.Errorf("unexpected source: %+v", src)
+	}
+
+	other := filepath.Join(t.TempDir(), "test_5mb")
+	if err := httpio.DownloadFile(context.Background(), u, other); err != nil {
+		t.Fatalf("unable to download file: %v", err)
+	}
+
+	if _, err := httpio.ReadSource(other); err == nil {
+		t.Error("expected no source without WithSourceAttributes")
+	}
+}
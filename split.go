@@ -0,0 +1,254 @@
+package httpio
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"hash"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// ManifestSuffix is appended to the download path for the manifest of a
+// download split into part files
+const ManifestSuffix = ".manifest.json"
+
+// Manifest describes a download that's split into part files
+type Manifest struct {
+	URL      string         `json:"url"`
+	Size     int64          `json:"size"`
+	PartSize int64          `json:"part_size"`
+	SHA256   string         `json:"sha256"`
+	Parts    []ManifestPart `json:"parts"`
+}
+
+// ManifestPart describes a single part file, the name is relative to
+// the directory of the manifest
+type ManifestPart struct {
+	Name   string `json:"name"`
+	Size   int64  `json:"size"`
+	SHA256 string `json:"sha256"`
+}
+
+// ReadManifest reads the manifest of the download split into parts at the given path
+func ReadManifest(path string) (*Manifest, error) {
+	data, err := os.ReadFile(path + ManifestSuffix)
+	if err != nil {
+		return nil, err
+	}
+
+	manifest := &Manifest{}
+	if err := json.Unmarshal(data, manifest); err != nil {
+		return nil, fmt.Errorf("invalid manifest: %w", err)
+	}
+
+	return manifest, nil
+}
+
+// JoinParts concatenates the part files of a download split with
+// WithPartSize back into the file at the given path, verifying the checksum
+// of every part and of the whole file against the manifest. The parts are
+// joined into a temporary file that only replaces the file at the path
+// once it's verified.
+func JoinParts(path string) error {
+	manifest, err := ReadManifest(path)
+	if err != nil {
+		return err
+	}
+
+	dir := filepath.Dir(path)
+	out, err := os.CreateTemp(dir, filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+
+	if err := out.Chmod(0o644); err != nil {
+		out.Close()
+		os.Remove(out.Name())
+
+		return err
+	}
+
+	if err := joinParts(out, dir, manifest); err != nil {
+		out.Close()
+		os.Remove(out.Name())
+
+		return err
+	}
+
+	if err := out.Close(); err != nil {
+		os.Remove(out.Name())
+		return err
+	}
+
+	if err := os.Rename(out.Name(), path); err != nil {
+		os.Remove(out.Name())
+		return err
+	}
+
+	return nil
+}
+
+func joinParts(w io.Writer, dir string, manifest *Manifest) error {
+	whole := sha256.New()
+	w = io.MultiWriter(w, whole)
+
+	for _, part := range manifest.Parts {
+		// parts are only read from the manifest's directory
+		if part.Name == "" || filepath.Base(part.Name) != part.Name || part.Name == ".." {
+			return fmt.Errorf("invalid part name '%s'", part.Name)
+		}
+
+		if err := copyPart(w, filepath.Join(dir, part.Name), part); err != nil {
+			return err
+		}
+	}
+
+	if sum := fmt.Sprintf("%x", whole.Sum(nil)); sum != manifest.SHA256 {
+		return &ChecksumError{Name: manifest.URL, Expected: manifest.SHA256, Actual: sum}
+	}
+
+	return nil
+}
+
+func copyPart(w io.Writer, path string, part ManifestPart) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	h := sha256.New()
+	n, err := io.Copy(io.MultiWriter(w, h), file)
+	if err != nil {
+		return err
+	}
+
+	if n != part.Size {
+		return fmt.Errorf("part '%s' has size %d, expected %d", part.Name, n, part.Size)
+	}
+
+	if sum := fmt.Sprintf("%x", h.Sum(nil)); sum != part.SHA256 {
+		return &ChecksumError{Name: part.Name, Expected: part.SHA256, Actual: sum}
+	}
+
+	return nil
+}
+
+// partName returns the name of the part file with the given index
+func partName(path string, index int) string {
+	return fmt.Sprintf("%s.%03d", path, index)
+}
+
+// downloadParts writes the file as numbered part files of the configured
+// part size next to the given path, followed by the locked manifest
+func (f *RemoteFile) downloadParts(path string, manifest *os.File) error {
+	pw := &partWriter{
+		path:     path,
+		partSize: f.partSize,
+		whole:    sha256.New(),
+		manifest: &Manifest{
+			URL:      f.req.URL.String(),
+			PartSize: f.partSize,
+		},
+	}
+
+	if _, err := io.Copy(pw, f); err != nil {
+		pw.closePart()
+		return err
+	}
+
+	if err := pw.closePart(); err != nil {
+		return err
+	}
+
+	pw.manifest.SHA256 = fmt.Sprintf("%x", pw.whole.Sum(nil))
+
+	data, err := json.MarshalIndent(pw.manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err := manifest.Truncate(0); err != nil {
+		return err
+	}
+
+	_, err = manifest.WriteAt(data, 0)
+
+	return err
+}
+
+// partWriter writes to consecutive part files of a fixed size
+type partWriter struct {
+	path     string
+	partSize int64
+	manifest *Manifest
+	whole    hash.Hash
+	file     *os.File
+	part     hash.Hash
+	written  int64
+}
+
+func (w *partWriter) Write(p []byte) (int, error) {
+	var n int
+	for len(p) > 0 {
+		if w.file == nil {
+			if err := w.openPart(); err != nil {
+				return n, err
+			}
+		}
+
+		size := min(int64(len(p)), w.partSize-w.written)
+		m, err := w.file.Write(p[:size])
+		w.part.Write(p[:m])
+		w.whole.Write(p[:m])
+		w.written += int64(m)
+		w.manifest.Size += int64(m)
+		n += m
+
+		if err != nil {
+			return n, err
+		}
+
+		if w.written == w.partSize {
+			if err := w.closePart(); err != nil {
+				return n, err
+			}
+		}
+
+		p = p[m:]
+	}
+
+	return n, nil
+}
+
+func (w *partWriter) openPart() error {
+	file, err := os.Create(partName(w.path, len(w.manifest.Parts)))
+	if err != nil {
+		return err
+	}
+
+	w.file = file
+	w.part = sha256.New()
+	w.written = 0
+
+	return nil
+}
+
+func (w *partWriter) closePart() error {
+	if w.file == nil {
+		return nil
+	}
+
+	w.manifest.Parts = append(w.manifest.Parts, ManifestPart{
+		Name:   filepath.Base(w.file.Name()),
+		Size:   w.written,
+		SHA256: fmt.Sprintf("%x", w.part.Sum(nil)),
+	})
+
+	err := w.file.Close()
+	w.file = nil
+
+	return err
+}
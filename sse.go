@@ -0,0 +1,32 @@
+package httpio
+
+import (
+	"crypto/md5"
+	"encoding/base64"
+	"fmt"
+)
+
+const (
+	headerSSECustomerAlgorithm = "X-Amz-Server-Side-Encryption-Customer-Algorithm"
+	headerSSECustomerKey       = "X-Amz-Server-Side-Encryption-Customer-Key"
+	headerSSECustomerKeyMD5    = "X-Amz-Server-Side-Encryption-Customer-Key-Md5"
+)
+
+// WithSSECustomerKey sends the 32 byte customer provided key of an S3
+// object encrypted with SSE-C on the preflight and every chunk request, so
+// the server decrypts the object for the download
+func WithSSECustomerKey(key []byte) Option {
+	return func(f *RemoteFile) error {
+		if len(key) != 32 {
+			return fmt.Errorf("SSE-C key must be 32 bytes, got %d", len(key))
+		}
+
+		sum := md5.Sum(key)
+
+		f.req.Header.Set(headerSSECustomerAlgorithm, "AES256")
+		f.req.Header.Set(headerSSECustomerKey, base64.StdEncoding.EncodeToString(key))
+		f.req.Header.Set(headerSSECustomerKeyMD5, base64.StdEncoding.EncodeToString(sum[:]))
+
+		return nil
+	}
+}
@@ -0,0 +1,54 @@
+package httpio_test
+
+import (
+	"bytes"
+	"crypto/md5"
+	"encoding/base64"
+	"io"
+	"net/http"
+	"sync/atomic"
+	"testing"
+
+	"github.com/jobstoit/httpio"
+)
+
+func TestGetSSECustomerKey(t *testing.T) {
+	key := bytes.Repeat([]byte{0x07}, 32)
+	sum := md5.Sum(key)
+
+	var missing atomic.Int32
+	svr := newTestServerWithHandler(func(h http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Header.Get("X-Amz-Server-Side-Encryption-Customer-Algorithm") != "AES256" ||
+				r.Header.Get("X-Amz-Server-Side-Encryption-Customer-Key") != base64.StdEncoding.EncodeToString(key) ||
+				r.Header.Get("X-Amz-Server-Side-Encryption-Customer-Key-MD5") != base64.StdEncoding.EncodeToString(sum[:]) {
+				missing.Add(1)
+				http.Error(w, "missing customer key", http.StatusBadRequest)
+
+				return
+			}
+
+			h.ServeHTTP(w, r)
+		})
+	})
+	defer svr.Close()
+
+	u := svr.URL().JoinPath("assets", "test_5mb").String()
+
+	remoteFile, err := httpio.Get(u, httpio.WithSSECustomerKey(key), httpio.WithChunkSize(1024*1024))
+	if err != nil {
+		t.Fatalf("unable to get file: %v", err)
+	}
+
+	if _, err := io.Copy(io.Discard, remoteFile); err != nil {
+		t.Errorf("unable to read file: %v", err)
+	}
+
+	if missing.Load() != 0 {
+		t.Errorf("expected every request to carry the customer key, %d didn't", missing.Load())
+	}
+
+	if _, err := httpio.Get(u, httpio.WithSSECustomerKey(key[:16])); err == nil {
+		t.Error("expected a key of the wrong size to be refused")
+	}
+}
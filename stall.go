@@ -0,0 +1,207 @@
+package httpio
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// DefaultMinSpeedWindow is the window of WithMinSpeed when it's given none
+const DefaultMinSpeedWindow = time.Second * 30
+
+// stallChecks is the number of times per window a response is checked
+const stallChecks = 4
+
+// WithMinSpeed fails a response with ErrStalled when it delivers fewer than
+// bytesPerSec bytes per second over the given window, or no bytes at all
+// within the window, so a server that stops sending while keeping the
+// connection open doesn't hang the download. Only the time spent waiting on
+// the response counts, not the time the reader takes. A stalled chunk is
+// retried like a broken off one with WithRetry.
+func WithMinSpeed(bytesPerSec int64, window time.Duration) Option {
+	return func(f *RemoteFile) error {
+		if window <= 0 {
+			window = DefaultMinSpeedWindow
+		}
+
+		f.minSpeed = max(bytesPerSec, 0)
+		f.minSpeedWindow = window
+
+		return nil
+	}
+}
+
+// do sends the request, watching its response for stalls when a minimum
+// speed is set with WithMinSpeed, and counts the bytes of its body as
+// downloaded, reading it within the limit of WithBandwidthLimit
+func (f *RemoteFile) do(req *http.Request) (*http.Response, error) {
+	var watch *stallWatch
+	if f.minSpeedWindow > 0 {
+		var ctx context.Context
+		ctx, watch = newStallWatch(req.Context(), f.minSpeed, f.minSpeedWindow)
+		req = req.WithContext(ctx)
+	}
+
+	res, err := f.client.Do(req)
+	if watch != nil {
+		res, err = watch.response(res, err)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	res.Body = &countedBody{ReadCloser: res.Body, stats: &f.stats}
+	if f.bandwidth != nil {
+		res.Body = &bandwidthBody{ReadCloser: res.Body, ctx: req.Context(), bandwidth: f.bandwidth}
+	}
+
+	return res, nil
+}
+
+// stallWatch cancels a request when the bytes it received fall short of
+// the minimum speed for the time spent waiting on it
+type stallWatch struct {
+	ctx      context.Context
+	cancel   context.CancelCauseFunc
+	done     chan struct{}
+	stopOnce sync.Once
+	minSpeed int64
+	window   time.Duration
+
+	mu sync.Mutex
+	// waiting reports whether the request is being waited on since the
+	// given time, waited is the time waited in the window before that
+	waiting bool
+	since   time.Time
+	waited  time.Duration
+	bytes   int64
+}
+
+func newStallWatch(ctx context.Context, minSpeed int64, window time.Duration) (context.Context, *stallWatch) {
+	ctx, cancel := context.WithCancelCause(ctx)
+	w := &stallWatch{
+		ctx:      ctx,
+		cancel:   cancel,
+		done:     make(chan struct{}),
+		minSpeed: minSpeed,
+		window:   window,
+		waiting:  true,
+		since:    time.Now(),
+	}
+
+	go w.run()
+
+	return ctx, w
+}
+
+func (w *stallWatch) run() {
+	ticker := time.NewTicker(w.window / stallChecks)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-w.done:
+			return
+		case <-w.ctx.Done():
+			return
+		case now := <-ticker.C:
+			if w.stalled(now) {
+				w.cancel(ErrStalled)
+				return
+			}
+		}
+	}
+}
+
+// stalled reports whether the request received too few bytes once it has
+// been waited on for a whole window, after which the next window starts
+func (w *stallWatch) stalled(now time.Time) bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	waited := w.waited
+	if w.waiting {
+		waited += now.Sub(w.since)
+	}
+
+	if waited < w.window {
+		return false
+	}
+
+	if w.bytes < max(int64(float64(w.minSpeed)*waited.Seconds()), 1) {
+		return true
+	}
+
+	w.bytes, w.waited, w.since = 0, 0, now
+
+	return false
+}
+
+func (w *stallWatch) begin() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.waiting, w.since = true, time.Now()
+}
+
+func (w *stallWatch) end(n int) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.waiting = false
+	w.waited += time.Since(w.since)
+	w.bytes += int64(n)
+}
+
+func (w *stallWatch) stop() {
+	w.stopOnce.Do(func() {
+		close(w.done)
+		w.cancel(nil)
+	})
+}
+
+// err returns ErrStalled for the errors caused by a stall
+func (w *stallWatch) err(err error) error {
+	if err != nil && err != io.EOF && errors.Is(context.Cause(w.ctx), ErrStalled) {
+		return ErrStalled
+	}
+
+	return err
+}
+
+// response watches the body of the response once the headers arrived
+func (w *stallWatch) response(res *http.Response, err error) (*http.Response, error) {
+	w.end(0)
+	if err != nil {
+		w.stop()
+		return nil, w.err(err)
+	}
+
+	res.Body = &stallReader{ReadCloser: res.Body, watch: w}
+
+	return res, nil
+}
+
+// stallReader measures the time spent waiting on the reads of a body
+type stallReader struct {
+	io.ReadCloser
+	watch *stallWatch
+}
+
+func (r *stallReader) Read(p []byte) (int, error) {
+	r.watch.begin()
+	n, err := r.ReadCloser.Read(p)
+	r.watch.end(n)
+
+	return n, r.watch.err(err)
+}
+
+func (r *stallReader) Close() error {
+	defer r.watch.stop()
+
+	return r.ReadCloser.Close()
+}
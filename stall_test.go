@@ -0,0 +1,134 @@
+package httpio_test
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/jobstoit/httpio"
+)
+
+// stallWriter stops writing after the given number of bytes, keeping the
+// connection open until the request is canceled
+type stallWriter struct {
+	http.ResponseWriter
+	r     *http.Request
+	after int
+}
+
+func (w *stallWriter) Write(p []byte) (int, error) {
+	if len(p) <= w.after {
+		w.after -= len(p)
+		return w.ResponseWriter.Write(p)
+	}
+
+	n, _ := w.ResponseWriter.Write(p[:w.after])
+	w.after = 0
+	w.ResponseWriter.(http.Flusher).Flush()
+
+	<-w.r.Context().Done()
+
+	return n, w.r.Context().Err()
+}
+
+func TestGetMinSpeed(t *testing.T) {
+	tests := []struct {
+		name  string
+		stall func(w http.ResponseWriter, r *http.Request) http.ResponseWriter
+	}{
+		{"headers", func(w http.ResponseWriter, r *http.Request) http.ResponseWriter {
+			<-r.Context().Done()
+			return w
+		}},
+		{"body", func(w http.ResponseWriter, r *http.Request) http.ResponseWriter {
+			return &stallWriter{ResponseWriter: w, r: r, after: 64 * 1024}
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// the second chunk stalls the first time it's requested
+			var stalled atomic.Bool
+			svr := newTestServerWithHandler(func(h http.Handler) http.Handler {
+				return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					if strings.HasPrefix(r.Header.Get("Range"), "bytes=1048576-") && stalled.CompareAndSwap(false, true) {
+						w = tt.stall(w, r)
+					}
+
+					h.ServeHTTP(w, r)
+				})
+			})
+			defer svr.Close()
+
+			u := svr.URL().JoinPath("assets", "test_5mb").String()
+			opts := []httpio.Option{
+				httpio.WithChunkSize(1024 * 1024),
+				httpio.WithMinSpeed(1024, time.Millisecond*200),
+			}
+
+			remoteFile, err := httpio.Get(u, opts...)
+			if err != nil {
+				t.Fatalf("failed to setup request: %v", err)
+			}
+
+			start := time.Now()
+			if _, err := io.ReadAll(remoteFile); !errors.Is(err, httpio.ErrStalled) {
+				t.Errorf("expected ErrStalled, got: %v", err)
+			}
+			remoteFile.Close()
+
+			if elapsed := time.Since(start); elapsed > time.Second*5 {
+				t.Errorf("expected the stall to be detected fast, took %v", elapsed)
+			}
+
+			stalled.Store(false)
+			remoteFile, err = httpio.Get(u, append(opts, httpio.WithRetry(1), httpio.WithBackoff(func(int) time.Duration { return 0 }))...)
+			if err != nil {
+				t.Fatalf("failed to setup request: %v", err)
+			}
+			defer remoteFile.Close()
+
+			body, err := io.ReadAll(remoteFile)
+			if err != nil {
+				t.Fatalf("expected the stalled chunk to be retried, got: %v", err)
+			}
+
+			expected, _ := testdata.ReadFile("testdata/test_5mb")
+			if !bytes.Equal(expected, body) {
+				t.Errorf("mismatched content")
+			}
+		})
+	}
+}
+
+func TestGetMinSpeedSlowReader(t *testing.T) {
+	svr := newTestServer()
+	defer svr.Close()
+
+	u := svr.URL().JoinPath("assets", "test_5mb").String()
+	remoteFile, err := httpio.Get(u, httpio.WithChunkSize(1024*1024), httpio.WithMinSpeed(1024, time.Millisecond*100))
+	if err != nil {
+		t.Fatalf("failed to setup request: %v", err)
+	}
+	defer remoteFile.Close()
+
+	// the time the reader takes doesn't count against the responses
+	buf := make([]byte, 1024*1024)
+	for {
+		_, err := io.ReadFull(remoteFile, buf)
+		if err == io.EOF {
+			break
+		}
+
+		if err != nil {
+			t.Fatalf("unable to read file: %v", err)
+		}
+
+		time.Sleep(time.Millisecond * 250)
+	}
+}
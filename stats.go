@@ -0,0 +1,256 @@
+package httpio
+
+import (
+	"context"
+	"crypto/tls"
+	"io"
+	"net/http"
+	"net/http/httptrace"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// throughputWindow is the period the throughput of Stats is measured over
+const throughputWindow = time.Second * 5
+
+// Stats is a snapshot of the transfer statistics of a RemoteFile, or of an
+// upload with PutContext
+type Stats struct {
+	// TLSHandshakes is the number of TLS handshakes done for this file
+	TLSHandshakes int64
+	// TLSResumed is the number of TLS handshakes that resumed a previous session
+	TLSResumed int64
+	// Throttled is the number of chunk requests that got a 429 Too Many
+	// Requests response, or a 503 Service Unavailable with Retry-After
+	Throttled int64
+	// CacheHits is the number of requests served from the HTTP cache or the
+	// chunk cache
+	CacheHits int64
+	// Connections is the number of new connections used by the requests
+	Connections int64
+	// ConnectionsReused is the number of requests that reused a connection
+	ConnectionsReused int64
+	// Hedged is the number of duplicate requests sent for slow chunks
+	Hedged int64
+	// BytesDownloaded is the number of bytes received in the bodies of
+	// the responses
+	BytesDownloaded int64
+	// BytesDelivered is the number of bytes returned by Read
+	BytesDelivered int64
+	// BytesUploaded is the number of bytes sent in the bodies of the
+	// requests of an upload, including the parts that were sent again
+	BytesUploaded int64
+	// Throughput is the number of bytes per second received or sent over
+	// the last few seconds
+	Throughput float64
+	// ActiveChunks is the number of chunks being fetched
+	ActiveChunks int64
+	// Retries is the number of retried requests, including the follow-up
+	// requests of responses that broke off and the retried parts of an
+	// upload
+	Retries int64
+	// Remaining is the estimated time until the file is downloaded or
+	// uploaded at the current throughput, it's 0 when unknown
+	Remaining time.Duration
+	// Chunks are the connection details of the fetched chunks in the order
+	// they were fetched
+	Chunks []ChunkStats
+}
+
+// ChunkStats are the connection details of the response of a chunk
+type ChunkStats struct {
+	Chunk Chunk
+	// Protocol is the protocol of the response, like HTTP/1.1 or HTTP/2.0
+	Protocol string
+	// TLSVersion is the name of the TLS version, empty without TLS
+	TLSVersion string
+	// Reused reports whether the request reused a connection
+	Reused bool
+}
+
+type stats struct {
+	tlsHandshakes atomic.Int64
+	tlsResumed    atomic.Int64
+	throttled     atomic.Int64
+	cacheHits     atomic.Int64
+	connections   atomic.Int64
+	reused        atomic.Int64
+	hedged        atomic.Int64
+	downloaded    atomic.Int64
+	delivered     atomic.Int64
+	uploaded      atomic.Int64
+	active        atomic.Int64
+	retries       atomic.Int64
+
+	mu     sync.Mutex
+	chunks []ChunkStats
+	// samples are the bytes transferred at the times the statistics were
+	// taken within the throughput window
+	samples []statsSample
+}
+
+// statsSample is the number of bytes transferred at a point in time
+type statsSample struct {
+	at    time.Time
+	bytes int64
+}
+
+// chunk records the connection details of the chunk's response
+func (s *stats) chunk(c Chunk, res *http.Response, reused bool) {
+	cs := ChunkStats{
+		Chunk:    c,
+		Protocol: res.Proto,
+		Reused:   reused,
+	}
+
+	if res.TLS != nil {
+		cs.TLSVersion = tls.VersionName(res.TLS.Version)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.chunks = append(s.chunks, cs)
+}
+
+// Stats returns a snapshot of the current transfer statistics,
+// it's safe to call while the file is being downloaded
+func (f *RemoteFile) Stats() Stats {
+	downloaded, uploaded := f.stats.downloaded.Load(), f.stats.uploaded.Load()
+	transferred := downloaded + uploaded
+	throughput := f.stats.throughput(time.Now(), transferred)
+
+	var remaining time.Duration
+	if f.size >= 0 && throughput > 0 {
+		remaining = time.Duration(float64(max(f.size-transferred, 0)) / throughput * float64(time.Second))
+	}
+
+	return Stats{
+		TLSHandshakes:     f.stats.tlsHandshakes.Load(),
+		TLSResumed:        f.stats.tlsResumed.Load(),
+		Throttled:         f.stats.throttled.Load(),
+		CacheHits:         f.stats.cacheHits.Load(),
+		Connections:       f.stats.connections.Load(),
+		ConnectionsReused: f.stats.reused.Load(),
+		Hedged:            f.stats.hedged.Load(),
+		BytesDownloaded:   downloaded,
+		BytesDelivered:    f.stats.delivered.Load(),
+		BytesUploaded:     uploaded,
+		Throughput:        throughput,
+		ActiveChunks:      f.stats.active.Load(),
+		Retries:           f.stats.retries.Load(),
+		Remaining:         remaining,
+		Chunks:            f.stats.chunkStats(),
+	}
+}
+
+// begin takes the first sample of the throughput when the transfer starts
+func (s *stats) begin(now time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(s.samples) == 0 {
+		s.samples = append(s.samples, statsSample{at: now, bytes: s.downloaded.Load() + s.uploaded.Load()})
+	}
+}
+
+// throughput takes a sample and returns the bytes per second transferred
+// since the oldest sample within the throughput window
+func (s *stats) throughput(now time.Time, transferred int64) float64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(s.samples) == 0 {
+		return 0
+	}
+
+	s.samples = append(s.samples, statsSample{at: now, bytes: transferred})
+	for len(s.samples) > 2 && now.Sub(s.samples[1].at) >= throughputWindow {
+		s.samples = s.samples[1:]
+	}
+
+	oldest := s.samples[0]
+	elapsed := now.Sub(oldest.at).Seconds()
+	if elapsed <= 0 {
+		return 0
+	}
+
+	return float64(transferred-oldest.bytes) / elapsed
+}
+
+func (s *stats) chunkStats() []ChunkStats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return slices.Clone(s.chunks)
+}
+
+// countedBody counts the bytes read from a response body as downloaded
+type countedBody struct {
+	io.ReadCloser
+	stats *stats
+}
+
+func (b *countedBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	b.stats.downloaded.Add(int64(n))
+
+	return n, err
+}
+
+// countedReader counts the bytes read from a request body as uploaded
+type countedReader struct {
+	io.Reader
+	stats *stats
+}
+
+func (r *countedReader) Read(p []byte) (int, error) {
+	n, err := r.Reader.Read(p)
+	r.stats.uploaded.Add(int64(n))
+
+	return n, err
+}
+
+// WithStats calls the function with the statistics of a file downloaded
+// with DownloadFile or uploaded with PutContext once the transfer ends,
+// since neither returns the file to call Stats on
+func WithStats(fn func(Stats)) Option {
+	return func(f *RemoteFile) error {
+		f.onStats = fn
+
+		return nil
+	}
+}
+
+// reportStats calls the function set with WithStats with the statistics
+func (f *RemoteFile) reportStats() {
+	if f.onStats != nil {
+		f.onStats(f.Stats())
+	}
+}
+
+// traceContext returns a context that records the connection statistics
+// of the requests made with it
+func (f *RemoteFile) traceContext(ctx context.Context) context.Context {
+	return httptrace.WithClientTrace(ctx, &httptrace.ClientTrace{
+		GotConn: func(info httptrace.GotConnInfo) {
+			if info.Reused {
+				f.stats.reused.Add(1)
+			} else {
+				f.stats.connections.Add(1)
+			}
+		},
+		TLSHandshakeDone: func(state tls.ConnectionState, err error) {
+			if err != nil {
+				return
+			}
+
+			f.stats.tlsHandshakes.Add(1)
+			if state.DidResume {
+				f.stats.tlsResumed.Add(1)
+			}
+		},
+	})
+}
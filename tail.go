@@ -0,0 +1,109 @@
+package httpio
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+)
+
+// GetTail gets the last n bytes of the file with a single suffix range
+// request, like the central directory of a zip or the footer of a parquet
+// file, without requesting its metadata first. It returns the bytes and the
+// size of the file, which is -1 when the server didn't report it. A server
+// ignoring the range sends the whole file, of which only the last n bytes
+// are kept, or it fails with ErrRangeIgnored with WithoutSequentialFallback.
+func GetTail(ctx context.Context, url string, n int64, opts ...Option) ([]byte, int64, error) {
+	if n < 0 {
+		return nil, 0, errors.New("negative length")
+	}
+
+	f, err := newRemoteFile(ctx, url, opts...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer f.closeIdleConnections()
+
+	if n == 0 {
+		return []byte{}, -1, nil
+	}
+
+	req := f.newRequest(f.traceContext(ctx))
+	req.Header.Set(headerRange, fmt.Sprintf("bytes=-%d", n))
+
+	res, err := f.do(req)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer res.Body.Close()
+
+	if err := checkPrecondition(res); err != nil {
+		return nil, 0, err
+	}
+
+	switch res.StatusCode {
+	case http.StatusPartialContent:
+		contentRange := res.Header.Get(headerContentRange)
+		if err := checkRangeUnit(contentRange); err != nil {
+			return nil, 0, err
+		}
+
+		first, last, size, ok := parseContentRange(contentRange)
+		if !ok || last-first+1 > n {
+			return nil, 0, fmt.Errorf("invalid Content-Range for the last %d bytes: '%s'", n, contentRange)
+		}
+
+		buf := make([]byte, last-first+1)
+		if _, err := io.ReadFull(res.Body, buf); err != nil {
+			return nil, 0, err
+		}
+
+		return buf, size, nil
+	case http.StatusRequestedRangeNotSatisfiable:
+		// an empty file has no suffix to satisfy the range
+		size, ok := parseContentRangeSize(res.Header.Get(headerContentRange))
+		if !ok || size != 0 {
+			return nil, 0, newStatusError(res)
+		}
+
+		return []byte{}, 0, nil
+	case http.StatusOK:
+		if f.strictRanges {
+			return nil, 0, ErrRangeIgnored
+		}
+
+		return readTail(res.Body, n)
+	default:
+		return nil, 0, newStatusError(res)
+	}
+}
+
+// readTail reads the body to its end, keeping the last n bytes
+func readTail(r io.Reader, n int64) ([]byte, int64, error) {
+	ring := make([]byte, n)
+
+	var size int64
+	for {
+		read, err := r.Read(ring[size%n : min(size%n+32*1024, n)])
+		size += int64(read)
+
+		if err == io.EOF {
+			break
+		}
+
+		if err != nil {
+			return nil, 0, err
+		}
+	}
+
+	if size < n {
+		return ring[:size], size, nil
+	}
+
+	// the ring starts with the oldest byte at the current position
+	start := size % n
+
+	return slices.Concat(ring[start:], ring[:start]), size, nil
+}
@@ -0,0 +1,77 @@
+package httpio_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/jobstoit/httpio"
+)
+
+func TestGetTail(t *testing.T) {
+	svr := newTestServer()
+	defer svr.Close()
+
+	u := svr.URL().JoinPath("assets", "test_5mb").String()
+	expected, _ := testdata.ReadFile("testdata/test_5mb")
+
+	for _, n := range []int64{0, 1, 1000, int64(len(expected)), int64(len(expected)) + 10} {
+		tail, size, err := httpio.GetTail(context.Background(), u, n)
+		if err != nil {
+			t.Fatalf("unable to get the last %d bytes: %v", n, err)
+		}
+
+		want := expected[max(int64(len(expected))-n, 0):]
+		if !bytes.Equal(want, tail) {
+			t.Errorf("mismatched last %d bytes, got %d bytes", n, len(tail))
+		}
+
+		if n > 0 && size != int64(len(expected)) {
+			t.Errorf("expected size %d, got %d", len(expected), size)
+		}
+	}
+}
+
+func TestGetTailRangeIgnored(t *testing.T) {
+	svr := newTestServerWithHandler(func(h http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			r.Header.Del("Range")
+			h.ServeHTTP(w, r)
+		})
+	})
+	defer svr.Close()
+
+	u := svr.URL().JoinPath("assets", "test_5mb").String()
+	expected, _ := testdata.ReadFile("testdata/test_5mb")
+
+	tail, size, err := httpio.GetTail(context.Background(), u, 100_000)
+	if err != nil {
+		t.Fatalf("unable to get tail: %v", err)
+	}
+
+	if !bytes.Equal(expected[len(expected)-100_000:], tail) {
+		t.Error("mismatched tail")
+	}
+
+	if size != int64(len(expected)) {
+		t.Errorf("expected size %d, got %d", len(expected), size)
+	}
+
+	if _, _, err := httpio.GetTail(context.Background(), u, 10, httpio.WithoutSequentialFallback()); !errors.Is(err, httpio.ErrRangeIgnored) {
+		t.Errorf("expected ErrRangeIgnored, got: %v", err)
+	}
+}
+
+func TestGetTailNotFound(t *testing.T) {
+	svr := newTestServer()
+	defer svr.Close()
+
+	_, _, err := httpio.GetTail(context.Background(), svr.URL().JoinPath("assets", "missing").String(), 10)
+
+	var statusErr *httpio.StatusError
+	if !errors.As(err, &statusErr) || statusErr.Code != http.StatusNotFound {
+		t.Errorf("expected a StatusError with status %d, got: %v", http.StatusNotFound, err)
+	}
+}
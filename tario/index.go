@@ -0,0 +1,120 @@
+package tario
+
+import (
+	"archive/tar"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+
+	"github.com/jobstoit/httpio"
+)
+
+// DefaultBlockSize is the least number of bytes read at once while
+// indexing, so the headers of small entries are served by few requests.
+// It's overridden with httpio.WithReadAtBlockSize.
+const DefaultBlockSize = 64 * 1024
+
+// errCompressed is returned when indexing a compressed archive
+var errCompressed = errors.New("unable to index a compressed archive")
+
+// Entry is an entry of an indexed archive, Offset is the offset of its
+// content in the archive
+type Entry struct {
+	Header *tar.Header
+	Offset int64
+}
+
+// Index is an uncompressed remote tar archive of which the entries are
+// opened at random with range requests
+type Index struct {
+	file    *httpio.RemoteFile
+	entries []Entry
+	names   map[string]int
+}
+
+// OpenIndex indexes the uncompressed tar archive at the url, reading only
+// the headers of its entries
+func OpenIndex(ctx context.Context, url string, opts ...httpio.Option) (*Index, error) {
+	opts = append([]httpio.Option{httpio.WithReadAtBlockSize(DefaultBlockSize)}, opts...)
+
+	file, err := httpio.OpenContext(ctx, url, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	idx, err := index(file)
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+
+	return idx, nil
+}
+
+func index(file *httpio.RemoteFile) (*Index, error) {
+	if file.Size() < 0 {
+		return nil, fmt.Errorf("unknown size of the archive")
+	}
+
+	head := make([]byte, 6)
+	n, err := file.ReadAt(head, 0)
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+
+	if httpio.DetectCompression(head[:n]) != httpio.CompressionNone {
+		return nil, errCompressed
+	}
+
+	// the tar reader seeks past the contents of the entries
+	sr := io.NewSectionReader(file, 0, file.Size())
+	tr := tar.NewReader(sr)
+
+	idx := &Index{file: file, names: map[string]int{}}
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return idx, nil
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		offset, err := sr.Seek(0, io.SeekCurrent)
+		if err != nil {
+			return nil, err
+		}
+
+		idx.names[hdr.Name] = len(idx.entries)
+		idx.entries = append(idx.entries, Entry{Header: hdr, Offset: offset})
+	}
+}
+
+// Entries returns the entries of the archive in their order
+func (idx *Index) Entries() []Entry {
+	return idx.entries
+}
+
+// Open returns the content of the regular file with the given name, which
+// is read with range requests
+func (idx *Index) Open(name string) (*io.SectionReader, error) {
+	i, ok := idx.names[name]
+	if !ok {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+	}
+
+	e := idx.entries[i]
+	if e.Header.Typeflag != tar.TypeReg {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fmt.Errorf("not a regular file")}
+	}
+
+	return io.NewSectionReader(idx.file, e.Offset, e.Header.Size), nil
+}
+
+// Close closes the archive
+func (idx *Index) Close() error {
+	return idx.file.Close()
+}
@@ -0,0 +1,70 @@
+// Package tario reads remote tar archives, streaming their entries through
+// the chunked reader of httpio or opening the entries of an uncompressed
+// archive at random with range requests.
+package tario
+
+import (
+	"archive/tar"
+	"context"
+	"io"
+
+	"github.com/jobstoit/httpio"
+)
+
+// Archive streams the entries of a remote tar archive, which is
+// decompressed when it's compressed with a format httpio can decompress
+type Archive struct {
+	file *httpio.RemoteFile
+	tr   *tar.Reader
+	err  error
+}
+
+// OpenTar opens the tar archive at the url, downloading it in chunks as
+// its entries are read
+func OpenTar(ctx context.Context, url string, opts ...httpio.Option) (*Archive, error) {
+	file, err := httpio.GetContext(ctx, url, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	r, err := file.Decompress()
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+
+	return &Archive{file: file, tr: tar.NewReader(r)}, nil
+}
+
+// Entries returns an iterator over the entries of the archive, yielding the
+// header and the content of each entry, which is only valid until the next
+// entry. The iteration stops at the first error, which is returned by Err.
+func (a *Archive) Entries() func(yield func(*tar.Header, io.Reader) bool) {
+	return func(yield func(*tar.Header, io.Reader) bool) {
+		for a.err == nil {
+			hdr, err := a.tr.Next()
+			if err == io.EOF {
+				return
+			}
+
+			if err != nil {
+				a.err = err
+				return
+			}
+
+			if !yield(hdr, a.tr) {
+				return
+			}
+		}
+	}
+}
+
+// Err returns the error that stopped the iteration of the entries
+func (a *Archive) Err() error {
+	return a.err
+}
+
+// Close closes the archive
+func (a *Archive) Close() error {
+	return a.file.Close()
+}
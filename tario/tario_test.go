@@ -0,0 +1,224 @@
+package tario_test
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"io"
+	"io/fs"
+	"math/rand"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/jobstoit/httpio"
+	"github.com/jobstoit/httpio/tario"
+)
+
+type countingWriter struct {
+	http.ResponseWriter
+	n *atomic.Int64
+}
+
+func (w *countingWriter) Write(p []byte) (int, error) {
+	n, err := w.ResponseWriter.Write(p)
+	w.n.Add(int64(n))
+
+	return n, err
+}
+
+type entry struct {
+	name    string
+	content []byte
+}
+
+func newArchive(t *testing.T, entries []entry) []byte {
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	for _, e := range entries {
+		hdr := &tar.Header{Name: e.name, Mode: 0o644, Size: int64(len(e.content)), Typeflag: tar.TypeReg}
+		if err := tw.WriteHeader(hdr); err != nil {
+			t.Fatal(err)
+		}
+		tw.Write(e.content)
+	}
+
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	return buf.Bytes()
+}
+
+func newEntries() []entry {
+	rnd := rand.New(rand.NewSource(1))
+	entries := []entry{{name: "hello.txt", content: []byte("hello world")}}
+	for _, name := range []string{"a.bin", "b.bin", "dir/c.bin"} {
+		content := make([]byte, 3*1024*1024+17)
+		rnd.Read(content)
+		entries = append(entries, entry{name: name, content: content})
+	}
+
+	return entries
+}
+
+func serve(archive []byte, served *atomic.Int64) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.ServeContent(&countingWriter{ResponseWriter: w, n: served}, r, "archive", time.Time{}, bytes.NewReader(archive))
+	}))
+}
+
+func TestOpenTar(t *testing.T) {
+	entries := newEntries()
+	archive := newArchive(t, entries)
+
+	var gz bytes.Buffer
+	zw := gzip.NewWriter(&gz)
+	zw.Write(archive)
+	zw.Close()
+
+	for name, body := range map[string][]byte{"tar": archive, "tar.gz": gz.Bytes()} {
+		t.Run(name, func(t *testing.T) {
+			var served atomic.Int64
+			svr := serve(body, &served)
+			defer svr.Close()
+
+			a, err := tario.OpenTar(context.Background(), svr.URL, httpio.WithChunkSize(1024*1024))
+			if err != nil {
+				t.Fatalf("unable to open archive: %v", err)
+			}
+			defer a.Close()
+
+			i := 0
+			a.Entries()(func(hdr *tar.Header, r io.Reader) bool {
+				if i >= len(entries) {
+					t.Fatalf("unexpected entry %s", hdr.Name)
+				}
+
+				content, err := io.ReadAll(r)
+				if err != nil {
+					t.Fatalf("unable to read %s: %v", hdr.Name, err)
+				}
+
+				if hdr.Name != entries[i].name || !bytes.Equal(content, entries[i].content) {
+					t.Errorf("mismatched entry %d: %s", i, hdr.Name)
+				}
+				i++
+
+				return true
+			})
+
+			if err := a.Err(); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if i != len(entries) {
+				t.Errorf("expected %d entries but got %d", len(entries), i)
+			}
+		})
+	}
+}
+
+func TestOpenTarStop(t *testing.T) {
+	archive := newArchive(t, newEntries())
+	var served atomic.Int64
+	svr := serve(archive, &served)
+	defer svr.Close()
+
+	a, err := tario.OpenTar(context.Background(), svr.URL)
+	if err != nil {
+		t.Fatalf("unable to open archive: %v", err)
+	}
+	defer a.Close()
+
+	var names []string
+	a.Entries()(func(hdr *tar.Header, _ io.Reader) bool {
+		names = append(names, hdr.Name)
+		return len(names) < 2
+	})
+
+	if len(names) != 2 || a.Err() != nil {
+		t.Errorf("expected iteration to stop after 2 entries, got %v: %v", names, a.Err())
+	}
+}
+
+func TestOpenTarInvalid(t *testing.T) {
+	svr := serve(bytes.Repeat([]byte{'x'}, 2048), new(atomic.Int64))
+	defer svr.Close()
+
+	a, err := tario.OpenTar(context.Background(), svr.URL)
+	if err != nil {
+		t.Fatalf("unable to open archive: %v", err)
+	}
+	defer a.Close()
+
+	a.Entries()(func(*tar.Header, io.Reader) bool {
+		t.Fatal("unexpected entry")
+		return false
+	})
+
+	if a.Err() == nil {
+		t.Error("expected an error for an invalid archive")
+	}
+}
+
+func TestOpenIndex(t *testing.T) {
+	entries := newEntries()
+	archive := newArchive(t, entries)
+
+	var served atomic.Int64
+	svr := serve(archive, &served)
+	defer svr.Close()
+
+	idx, err := tario.OpenIndex(context.Background(), svr.URL)
+	if err != nil {
+		t.Fatalf("unable to index archive: %v", err)
+	}
+	defer idx.Close()
+
+	if n := len(idx.Entries()); n != len(entries) {
+		t.Fatalf("expected %d entries but got %d", len(entries), n)
+	}
+
+	if n := served.Load(); n > int64(len(archive)/4) {
+		t.Errorf("expected indexing to read only the headers, served %d of %d bytes", n, len(archive))
+	}
+
+	for _, e := range []entry{entries[0], entries[3]} {
+		r, err := idx.Open(e.name)
+		if err != nil {
+			t.Fatalf("unable to open %s: %v", e.name, err)
+		}
+
+		content, err := io.ReadAll(r)
+		if err != nil {
+			t.Fatalf("unable to read %s: %v", e.name, err)
+		}
+
+		if !bytes.Equal(content, e.content) {
+			t.Errorf("mismatched content of %s", e.name)
+		}
+	}
+
+	if _, err := idx.Open("missing"); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("expected fs.ErrNotExist but got %v", err)
+	}
+}
+
+func TestOpenIndexCompressed(t *testing.T) {
+	var gz bytes.Buffer
+	zw := gzip.NewWriter(&gz)
+	zw.Write(newArchive(t, []entry{{name: "hello.txt", content: []byte("hello")}}))
+	zw.Close()
+
+	svr := serve(gz.Bytes(), new(atomic.Int64))
+	defer svr.Close()
+
+	if _, err := tario.OpenIndex(context.Background(), svr.URL); err == nil {
+		t.Error("expected an error indexing a compressed archive")
+	}
+}
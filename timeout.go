@@ -0,0 +1,67 @@
+package httpio
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"time"
+)
+
+// WithChunkTimeout bounds every range request, from sending it until its
+// body has been read, independent of the deadline of the context. A chunk
+// request taking longer fails with ErrChunkTimeout and is retried with
+// WithRetry, continuing from where its body broke off.
+func WithChunkTimeout(d time.Duration) Option {
+	return func(f *RemoteFile) error {
+		f.chunkTimeout = max(d, 0)
+
+		return nil
+	}
+}
+
+// doChunk sends a range request bounded by the chunk timeout
+func (f *RemoteFile) doChunk(req *http.Request) (*http.Response, error) {
+	if f.chunkTimeout <= 0 {
+		return f.do(req)
+	}
+
+	ctx, cancel := context.WithTimeoutCause(req.Context(), f.chunkTimeout, ErrChunkTimeout)
+	res, err := f.do(req.WithContext(ctx))
+	if err != nil {
+		cancel()
+		return nil, timeoutErr(ctx, err)
+	}
+
+	res.Body = &timeoutReader{ReadCloser: res.Body, ctx: ctx, cancel: cancel}
+
+	return res, nil
+}
+
+// timeoutErr returns ErrChunkTimeout for the errors caused by the timeout
+func timeoutErr(ctx context.Context, err error) error {
+	if err != nil && err != io.EOF && errors.Is(context.Cause(ctx), ErrChunkTimeout) {
+		return ErrChunkTimeout
+	}
+
+	return err
+}
+
+// timeoutReader releases the timeout of a request once its body is closed
+type timeoutReader struct {
+	io.ReadCloser
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+func (r *timeoutReader) Read(p []byte) (int, error) {
+	n, err := r.ReadCloser.Read(p)
+
+	return n, timeoutErr(r.ctx, err)
+}
+
+func (r *timeoutReader) Close() error {
+	defer r.cancel()
+
+	return r.ReadCloser.Close()
+}
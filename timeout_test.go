@@ -0,0 +1,62 @@
+package httpio_test
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/jobstoit/httpio"
+)
+
+func TestGetChunkTimeout(t *testing.T) {
+	// the second chunk hangs halfway the first time it's requested
+	var hung atomic.Bool
+	svr := newTestServerWithHandler(func(h http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if strings.HasPrefix(r.Header.Get("Range"), "bytes=1048576-") && hung.CompareAndSwap(false, true) {
+				w = &stallWriter{ResponseWriter: w, r: r, after: 512 * 1024}
+			}
+
+			h.ServeHTTP(w, r)
+		})
+	})
+	defer svr.Close()
+
+	u := svr.URL().JoinPath("assets", "test_5mb").String()
+	opts := []httpio.Option{
+		httpio.WithChunkSize(1024 * 1024),
+		httpio.WithChunkTimeout(time.Millisecond * 300),
+	}
+
+	remoteFile, err := httpio.Get(u, opts...)
+	if err != nil {
+		t.Fatalf("failed to setup request: %v", err)
+	}
+
+	if _, err := io.ReadAll(remoteFile); !errors.Is(err, httpio.ErrChunkTimeout) {
+		t.Errorf("expected ErrChunkTimeout, got: %v", err)
+	}
+	remoteFile.Close()
+
+	hung.Store(false)
+	remoteFile, err = httpio.Get(u, append(opts, httpio.WithRetry(1), httpio.WithBackoff(func(int) time.Duration { return 0 }))...)
+	if err != nil {
+		t.Fatalf("failed to setup request: %v", err)
+	}
+	defer remoteFile.Close()
+
+	body, err := io.ReadAll(remoteFile)
+	if err != nil {
+		t.Fatalf("expected the chunk to be retried, got: %v", err)
+	}
+
+	expected, _ := testdata.ReadFile("testdata/test_5mb")
+	if !bytes.Equal(expected, body) {
+		t.Errorf("mismatched content")
+	}
+}
@@ -0,0 +1,47 @@
+package httpio
+
+import (
+	"os"
+	"time"
+)
+
+// WithTimestamping makes DownloadFile skip the download when the file at
+// the path is current, like wget -N: it has the size of the remote file and
+// isn't older than its Last-Modified. The downloaded file gets the
+// Last-Modified as its modification time, so the next download compares
+// against the revision it holds. A remote file without a Last-Modified is
+// always downloaded, and downloads split with WithPartSize aren't skipped.
+func WithTimestamping() Option {
+	return func(f *RemoteFile) error {
+		f.timestamping = true
+
+		return nil
+	}
+}
+
+// current reports whether the file at the path is as large as the remote
+// file and not older than its Last-Modified
+func (f *RemoteFile) current(path string) bool {
+	modified := parseHTTPTime(f.meta.LastModified)
+	if modified.IsZero() || f.size < 0 {
+		return false
+	}
+
+	info, err := os.Stat(path)
+	if err != nil || !info.Mode().IsRegular() {
+		return false
+	}
+
+	return info.Size() == f.size && !info.ModTime().Before(modified)
+}
+
+// stampModTime sets the modification time of the downloaded file to the
+// Last-Modified of the remote file
+func (f *RemoteFile) stampModTime(path string) error {
+	modified := parseHTTPTime(f.meta.LastModified)
+	if modified.IsZero() {
+		return nil
+	}
+
+	return os.Chtimes(path, time.Time{}, modified)
+}
@@ -0,0 +1,112 @@
+package httpio_test
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/jobstoit/httpio"
+)
+
+func TestTimestamping(t *testing.T) {
+	content := bytes.Repeat([]byte("timestamped "), 1024)
+	modified := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	var gets atomic.Int32
+	svr := newTestServerWithHandler(func(http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method == http.MethodGet {
+				gets.Add(1)
+			}
+
+			http.ServeContent(w, r, "file.txt", modified, bytes.NewReader(content))
+		})
+	})
+	defer svr.Close()
+
+	path := filepath.Join(t.TempDir(), "file.txt")
+	download := func() int32 {
+		gets.Store(0)
+		if err := httpio.DownloadFile(context.Background(), svr.URL().JoinPath("file.txt").String(), path, httpio.WithTimestamping()); err != nil {
+			t.Fatalf("unable to download: %v", err)
+		}
+
+		actual, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatalf("unable to read file: %v", err)
+		}
+
+		if !bytes.Equal(content, actual) {
+			t.Error("mismatched content")
+		}
+
+		return gets.Load()
+	}
+
+	if n := download(); n == 0 {
+		t.Fatal("expected the file to be downloaded")
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("unable to stat file: %v", err)
+	}
+
+	if !info.ModTime().Equal(modified) {
+		t.Errorf("expected the modification time %v but got %v", modified, info.ModTime())
+	}
+
+	if n := download(); n != 0 {
+		t.Errorf("expected the current file to be skipped, got %d requests", n)
+	}
+
+	// an older local copy is downloaded again
+	if err := os.Chtimes(path, time.Time{}, modified.Add(-time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+
+	if n := download(); n == 0 {
+		t.Error("expected the older file to be downloaded")
+	}
+
+	// so is a copy of another size
+	if err := os.WriteFile(path, []byte("truncated"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if n := download(); n == 0 {
+		t.Error("expected the file of another size to be downloaded")
+	}
+}
+
+func TestTimestampingWithoutLastModified(t *testing.T) {
+	content := []byte("unversioned")
+
+	var gets atomic.Int32
+	svr := newTestServerWithHandler(func(http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method == http.MethodGet {
+				gets.Add(1)
+			}
+
+			http.ServeContent(w, r, "file.txt", time.Time{}, bytes.NewReader(content))
+		})
+	})
+	defer svr.Close()
+
+	path := filepath.Join(t.TempDir(), "file.txt")
+	for range 2 {
+		if err := httpio.DownloadFile(context.Background(), svr.URL().JoinPath("file.txt").String(), path, httpio.WithTimestamping()); err != nil {
+			t.Fatalf("unable to download: %v", err)
+		}
+	}
+
+	if n := gets.Load(); n != 2 {
+		t.Errorf("expected the file to be downloaded twice, got %d requests", n)
+	}
+}
@@ -0,0 +1,65 @@
+package httpio
+
+import (
+	"context"
+	"sync"
+)
+
+// Tracer traces the downloads of files, the otel module implements it
+// with OpenTelemetry spans
+type Tracer interface {
+	// StartDownload starts tracing the download of the file at the url,
+	// the returned function ends it with the error the download ended with
+	StartDownload(ctx context.Context, url string) (context.Context, func(err error))
+	// StartChunk starts tracing the requests of a chunk of the download,
+	// the returned function ends it with the error the chunk failed with
+	StartChunk(ctx context.Context, c Chunk) (context.Context, func(err error))
+}
+
+// WithTracer traces the download with the tracer. The download is traced
+// from the metadata request until the file is downloaded, fails or is
+// closed, the requests of every chunk are traced within it.
+func WithTracer(tracer Tracer) Option {
+	return func(f *RemoteFile) error {
+		f.tracer = tracer
+
+		return nil
+	}
+}
+
+// tracing is the tracing of the download of a file
+type tracing struct {
+	end  func(error)
+	once sync.Once
+}
+
+// startTrace starts tracing the download when a tracer is set
+func (f *RemoteFile) startTrace(ctx context.Context) context.Context {
+	if f.tracer == nil {
+		return ctx
+	}
+
+	ctx, f.trace.end = f.tracer.StartDownload(ctx, f.req.URL.String())
+
+	return ctx
+}
+
+// endTrace ends tracing the download, only the first call counts
+func (f *RemoteFile) endTrace(err error) {
+	if f.trace.end == nil {
+		return
+	}
+
+	f.trace.once.Do(func() {
+		f.trace.end(err)
+	})
+}
+
+// traceChunk starts tracing the requests of the chunk when a tracer is set
+func (f *RemoteFile) traceChunk(ctx context.Context, c Chunk) (context.Context, func(error)) {
+	if f.tracer == nil {
+		return ctx, func(error) {}
+	}
+
+	return f.tracer.StartChunk(ctx, c)
+}
@@ -0,0 +1,78 @@
+package httpio_test
+
+import (
+	"context"
+	"io"
+	"sync"
+	"testing"
+
+	"github.com/jobstoit/httpio"
+)
+
+type spanKey struct{}
+
+type recordingTracer struct {
+	mu        sync.Mutex
+	downloads int
+	chunks    int
+	parented  int
+	ended     []error
+}
+
+func (t *recordingTracer) StartDownload(ctx context.Context, _ string) (context.Context, func(error)) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.downloads++
+
+	return context.WithValue(ctx, spanKey{}, "download"), t.end
+}
+
+func (t *recordingTracer) StartChunk(ctx context.Context, _ httpio.Chunk) (context.Context, func(error)) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.chunks++
+	if ctx.Value(spanKey{}) == "download" {
+		t.parented++
+	}
+
+	return ctx, func(error) {}
+}
+
+func (t *recordingTracer) end(err error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.ended = append(t.ended, err)
+}
+
+func TestGetTracer(t *testing.T) {
+	svr := newTestServer()
+	defer svr.Close()
+
+	tracer := &recordingTracer{}
+
+	u := svr.URL().JoinPath("assets", "test_5mb").String()
+	remoteFile, err := httpio.Get(u, httpio.WithChunkSize(1024*1024), httpio.WithTracer(tracer))
+	if err != nil {
+		t.Fatalf("failed to setup request: %v", err)
+	}
+
+	if _, err := io.Copy(io.Discard, remoteFile); err != nil {
+		t.Fatalf("unable to read file: %v", err)
+	}
+	remoteFile.Close()
+
+	tracer.mu.Lock()
+	defer tracer.mu.Unlock()
+
+	if tracer.downloads != 1 || tracer.chunks != 5 || tracer.parented != 5 {
+		t.Errorf("expected a download with 5 chunks traced within it, got %d downloads and %d chunks of which %d within it",
+			tracer.downloads, tracer.chunks, tracer.parented)
+	}
+
+	if len(tracer.ended) != 1 || tracer.ended[0] != nil {
+		t.Errorf("expected the download to end once without error, got %v", tracer.ended)
+	}
+}
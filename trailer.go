@@ -0,0 +1,56 @@
+package httpio
+
+import (
+	"net/http"
+	"slices"
+	"sync"
+)
+
+// ChunkTrailer is the trailer received on a chunk response
+type ChunkTrailer struct {
+	Chunk  Chunk
+	Header http.Header
+}
+
+type trailers struct {
+	mu     sync.Mutex
+	chunks []ChunkTrailer
+}
+
+func (t *trailers) add(c Chunk, header http.Header) {
+	if len(header) == 0 {
+		return
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.chunks = append(t.chunks, ChunkTrailer{Chunk: c, Header: header.Clone()})
+}
+
+// Trailers returns the trailers received on the chunk responses in chunk
+// order, chunks without a trailer are left out. All trailers are available
+// once Read returned io.EOF.
+func (f *RemoteFile) Trailers() []ChunkTrailer {
+	f.trailers.mu.Lock()
+	defer f.trailers.mu.Unlock()
+
+	chunks := slices.Clone(f.trailers.chunks)
+	slices.SortFunc(chunks, func(a, b ChunkTrailer) int {
+		return a.Chunk.Index - b.Chunk.Index
+	})
+
+	return chunks
+}
+
+// Trailer returns the trailers of all chunk responses merged in chunk order
+func (f *RemoteFile) Trailer() http.Header {
+	header := http.Header{}
+	for _, t := range f.Trailers() {
+		for name, values := range t.Header {
+			header[name] = append(header[name], values...)
+		}
+	}
+
+	return header
+}
@@ -0,0 +1,69 @@
+package httpio_test
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/jobstoit/httpio"
+)
+
+// trailerWriter drops the Content-Length so the response is chunked and
+// the trailer can be sent
+type trailerWriter struct {
+	http.ResponseWriter
+}
+
+func (w trailerWriter) WriteHeader(statusCode int) {
+	w.Header().Del("Content-Length")
+	w.ResponseWriter.WriteHeader(statusCode)
+}
+
+func TestGetTrailers(t *testing.T) {
+	content := bytes.Repeat([]byte("trailer"), 1024)
+
+	svr := newTestServerWithHandler(func(http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method == http.MethodHead {
+				http.ServeContent(w, r, "file", time.Time{}, bytes.NewReader(content))
+				return
+			}
+
+			w.Header().Set("Trailer", "Server-Timing")
+			http.ServeContent(trailerWriter{w}, r, "file", time.Time{}, bytes.NewReader(content))
+			w.Header().Set("Server-Timing", "storage;desc="+r.Header.Get("Range"))
+		})
+	})
+	defer svr.Close()
+
+	remoteFile, err := httpio.Get(svr.URL().JoinPath("file").String(), httpio.WithChunkSize(4096))
+	if err != nil {
+		t.Fatalf("failed to setup request: %v", err)
+	}
+
+	actual, err := io.ReadAll(remoteFile)
+	if err != nil {
+		t.Fatalf("unable to read file: %v", err)
+	}
+
+	if !bytes.Equal(content, actual) {
+		t.Errorf("mismatched content")
+	}
+
+	trailers := remoteFile.Trailers()
+	if e, a := 2, len(trailers); e != a {
+		t.Fatalf("expected %d chunk trailers, got %d", e, a)
+	}
+
+	for i, trailer := range trailers {
+		if e, a := "storage;desc="+trailer.Chunk.Range(), trailer.Header.Get("Server-Timing"); e != a {
+			t.Errorf("expected trailer '%s' of chunk %d, got '%s'", e, i, a)
+		}
+	}
+
+	if e, a := 2, len(remoteFile.Trailer().Values("Server-Timing")); e != a {
+		t.Errorf("expected %d merged trailer values, got %d", e, a)
+	}
+}
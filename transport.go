@@ -0,0 +1,257 @@
+package httpio
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+const (
+	dialTimeout   = time.Second * 30
+	dialKeepAlive = time.Second * 30
+)
+
+// configureClient clones the client's transport when options are set that
+// have to be applied on the transport itself, wraps it with the transport
+// wrappers and sets the cookie jar of WithCookieJar. Transport options are
+// ignored for clients with a transport other than *http.Transport.
+func (f *RemoteFile) configureClient() error {
+	rt := f.client.Transport
+	if rt == nil {
+		rt = http.DefaultTransport
+	}
+
+	transport, err := f.configureTransport(rt)
+	if err != nil {
+		return err
+	}
+
+	if f.transferLimit > 0 {
+		transport = &limitTransport{base: transport, file: f}
+	}
+
+	for _, wrap := range f.transportWrappers {
+		transport = wrap(transport)
+	}
+
+	if transport == rt && f.jar == nil {
+		return nil
+	}
+
+	client := *f.client
+	client.Transport = transport
+	if f.jar != nil {
+		client.Jar = f.jar
+	}
+	f.client = &client
+
+	return nil
+}
+
+func (f *RemoteFile) configureTransport(rt http.RoundTripper) (http.RoundTripper, error) {
+	if f.tlsSessionCache == nil && f.ipFamily == IPAuto && f.localAddr == "" &&
+		f.proxyFunc == nil && f.proxyAuth == "" {
+		return rt, nil
+	}
+
+	tr, ok := rt.(*http.Transport)
+	if !ok {
+		return rt, nil
+	}
+	tr = tr.Clone()
+	f.transport = tr
+
+	if f.tlsSessionCache != nil {
+		setSessionCache(tr, f.tlsSessionCache)
+	}
+
+	if f.ipFamily != IPAuto || f.localAddr != "" {
+		dialer := &net.Dialer{
+			Timeout:   dialTimeout,
+			KeepAlive: dialKeepAlive,
+		}
+
+		if f.localAddr != "" {
+			ip, err := localIP(f.localAddr, f.ipFamily)
+			if err != nil {
+				return nil, err
+			}
+
+			dialer.LocalAddr = &net.TCPAddr{IP: ip}
+		}
+
+		tr.DialContext = f.ipFamily.dialContext(dialer)
+	}
+
+	if f.proxyFunc != nil {
+		tr.Proxy = f.proxyFunc
+	}
+
+	if f.proxyAuth != "" {
+		return setProxyAuth(tr, f.proxyAuth), nil
+	}
+
+	return tr, nil
+}
+
+// closeIdleConnections closes the idle connections of the transport cloned
+// for this file, which isn't used by other downloads
+func (f *RemoteFile) closeIdleConnections() {
+	if f.transport != nil {
+		f.transport.CloseIdleConnections()
+	}
+}
+
+// limitTransport fails the responses once the bytes read from all
+// responses of the file exceed its transfer limit. It's the innermost
+// wrapper, so only bytes received from the network count.
+type limitTransport struct {
+	base http.RoundTripper
+	file *RemoteFile
+}
+
+func (t *limitTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	res, err := t.base.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	res.Body = &limitedBody{ReadCloser: res.Body, file: t.file}
+
+	return res, nil
+}
+
+type limitedBody struct {
+	io.ReadCloser
+	file *RemoteFile
+}
+
+func (b *limitedBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	if total := b.file.transferred.Add(int64(n)); total > b.file.transferLimit {
+		// the bytes beyond the limit aren't delivered
+		n -= int(min(total-b.file.transferLimit, int64(n)))
+
+		return n, fmt.Errorf("%w: read %d bytes, the limit is %d", ErrTransferLimit, total, b.file.transferLimit)
+	}
+
+	return n, err
+}
+
+// setProxyAuth sets the Proxy-Authorization header on the requests that are
+// sent through a proxy, both for tunneled and for plain http requests
+func setProxyAuth(tr *http.Transport, auth string) http.RoundTripper {
+	getHeader := tr.GetProxyConnectHeader
+	tr.GetProxyConnectHeader = func(ctx context.Context, proxyURL *url.URL, target string) (http.Header, error) {
+		header := http.Header{}
+		if getHeader != nil {
+			h, err := getHeader(ctx, proxyURL, target)
+			if err != nil {
+				return nil, err
+			}
+
+			if h != nil {
+				header = h.Clone()
+			}
+		} else if tr.ProxyConnectHeader != nil {
+			header = tr.ProxyConnectHeader.Clone()
+		}
+
+		header.Set(headerProxyAuthorization, auth)
+
+		return header, nil
+	}
+
+	return &proxyAuthTransport{tr: tr, auth: auth}
+}
+
+// proxyAuthTransport adds the Proxy-Authorization header to plain http
+// requests sent through a proxy, tunneled requests get the header on the
+// CONNECT request instead
+type proxyAuthTransport struct {
+	tr   *http.Transport
+	auth string
+}
+
+func (t *proxyAuthTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.URL.Scheme != "http" || t.tr.Proxy == nil {
+		return t.tr.RoundTrip(req)
+	}
+
+	proxyURL, err := t.tr.Proxy(req)
+	if err != nil || proxyURL == nil {
+		return t.tr.RoundTrip(req)
+	}
+
+	req = req.Clone(req.Context())
+	req.Header.Set(headerProxyAuthorization, t.auth)
+
+	return t.tr.RoundTrip(req)
+}
+
+// localIP returns the given IP address or the address of the interface with
+// the given name, preferring IPv6 addresses only when the IP family does
+func localIP(addr string, family IPFamily) (net.IP, error) {
+	if ip := net.ParseIP(addr); ip != nil {
+		return ip, nil
+	}
+
+	iface, err := net.InterfaceByName(addr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid local address '%s': %w", addr, err)
+	}
+
+	addrs, err := iface.Addrs()
+	if err != nil {
+		return nil, fmt.Errorf("unable to get addresses of interface '%s': %w", addr, err)
+	}
+
+	preferV6 := family == IPv6Only || family == IPv6Preferred
+
+	var fallback net.IP
+	for _, a := range addrs {
+		ipNet, ok := a.(*net.IPNet)
+		if !ok {
+			continue
+		}
+
+		if (ipNet.IP.To4() == nil) == preferV6 {
+			return ipNet.IP, nil
+		}
+
+		if fallback == nil && family != IPv4Only && family != IPv6Only {
+			fallback = ipNet.IP
+		}
+	}
+
+	if fallback == nil {
+		return nil, fmt.Errorf("no usable address on interface '%s'", addr)
+	}
+
+	return fallback, nil
+}
+
+func newDefaultTransport() http.RoundTripper {
+	tr, ok := http.DefaultTransport.(*http.Transport)
+	if !ok {
+		return http.DefaultTransport
+	}
+
+	tr = tr.Clone()
+	setSessionCache(tr, tls.NewLRUClientSessionCache(0))
+
+	return tr
+}
+
+func setSessionCache(tr *http.Transport, cache tls.ClientSessionCache) {
+	if tr.TLSClientConfig == nil {
+		tr.TLSClientConfig = &tls.Config{}
+	}
+
+	tr.TLSClientConfig.ClientSessionCache = cache
+}
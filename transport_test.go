@@ -0,0 +1,126 @@
+package httpio_test
+
+import (
+	"bytes"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/jobstoit/httpio"
+)
+
+func TestGetProxy(t *testing.T) {
+	tests := []struct {
+		name   string
+		opt    httpio.Option
+		expect string
+	}{
+		{"basic auth", httpio.WithProxyBasicAuth("user", "pass"), "Basic dXNlcjpwYXNz"},
+		{"bearer token", httpio.WithProxyBearerToken("token"), "Bearer token"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var proxied atomic.Int32
+			mux := newTestMux()
+			proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if a := r.Header.Get("Proxy-Authorization"); a != test.expect {
+					w.WriteHeader(http.StatusProxyAuthRequired)
+					return
+				}
+
+				proxied.Add(1)
+				mux.ServeHTTP(w, r)
+			}))
+			defer proxy.Close()
+
+			remoteFile, err := httpio.Get("http://origin.invalid/assets/test_5mb",
+				httpio.WithProxy(proxy.URL),
+				httpio.WithChunkSize(1024*1024),
+				test.opt,
+			)
+			if err != nil {
+				t.Fatalf("failed to setup request: %v", err)
+			}
+
+			expected, _ := testdata.ReadFile("testdata/test_5mb")
+			actual, err := io.ReadAll(remoteFile)
+			if err != nil {
+				t.Fatalf("unable to read file: %v", err)
+			}
+
+			if !bytes.Equal(expected, actual) {
+				t.Errorf("mismatched content through proxy")
+			}
+
+			if proxied.Load() < 2 {
+				t.Errorf("expected all requests to go through the proxy, got %d", proxied.Load())
+			}
+		})
+	}
+}
+
+func TestGetProxyFunc(t *testing.T) {
+	svr := newTestServer()
+	defer svr.Close()
+
+	var selected atomic.Int32
+	u := svr.URL().JoinPath("assets", "test_5mb")
+
+	remoteFile, err := httpio.Get(u.String(), httpio.WithProxyFunc(func(req *http.Request) (*url.URL, error) {
+		selected.Add(1)
+		return nil, nil
+	}))
+	if err != nil {
+		t.Fatalf("failed to setup request: %v", err)
+	}
+
+	if _, err := io.Copy(io.Discard, remoteFile); err != nil {
+		t.Fatalf("unable to read file: %v", err)
+	}
+
+	if selected.Load() == 0 {
+		t.Errorf("expected the proxy func to be called")
+	}
+}
+
+func TestGetClosesIdleConnections(t *testing.T) {
+	var open atomic.Int32
+	svr := httptest.NewUnstartedServer(newTestMux())
+	svr.Config.ConnState = func(conn net.Conn, state http.ConnState) {
+		switch state {
+		case http.StateNew:
+			open.Add(1)
+		case http.StateClosed, http.StateHijacked:
+			open.Add(-1)
+		}
+	}
+	svr.Start()
+	defer svr.Close()
+
+	remoteFile, err := httpio.Get(svr.URL+"/assets/test_5mb",
+		httpio.WithLocalAddr("127.0.0.1"),
+		httpio.WithChunkSize(1024*1024),
+	)
+	if err != nil {
+		t.Fatalf("failed to setup request: %v", err)
+	}
+
+	if _, err := io.Copy(io.Discard, remoteFile); err != nil {
+		t.Fatalf("unable to read file: %v", err)
+	}
+
+	deadline := time.Now().Add(time.Second * 5)
+	for open.Load() > 0 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond * 10)
+	}
+
+	if n := open.Load(); n != 0 {
+		t.Errorf("expected the download's connections to be closed, %d are open", n)
+	}
+}
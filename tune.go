@@ -0,0 +1,193 @@
+package httpio
+
+import (
+	"bytes"
+	"context"
+	"encoding/gob"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"sync"
+	"time"
+)
+
+const (
+	// probeSize is the size of the range requests measuring an origin
+	probeSize = 256 * 1024
+	// probeRequests is the number of probes sent one after the other and
+	// the number sent at once
+	probeRequests = 4
+	// latencyOverhead is the fraction of a chunk request the tuned chunk
+	// size allows to be spent waiting for the first byte
+	latencyOverhead = 0.1
+
+	minTunedChunkSize   = 256 * 1024
+	maxTunedChunkSize   = 64 * 1024 * 1024
+	maxTunedConcurrency = 32
+)
+
+// TuneSettings are the settings derived from measuring an origin
+type TuneSettings struct {
+	Concurrency int
+	ChunkSize   int
+	Measured    time.Time
+}
+
+// probeResult is the measurement of a single probe request
+type probeResult struct {
+	latency  time.Duration
+	duration time.Duration
+}
+
+// autoTune applies the stored settings of the file's host, the origin is
+// measured and the settings are stored when there are none yet. Files too
+// small to measure keep the configured settings.
+func (f *RemoteFile) autoTune(ctx context.Context) {
+	key := "httpio tune " + f.req.URL.Host
+
+	settings := &TuneSettings{}
+	if data, ok := f.tuneStore.Get(key); ok {
+		if err := gob.NewDecoder(bytes.NewReader(data)).Decode(settings); err == nil {
+			f.applyTuning(settings)
+			return
+		}
+	}
+
+	if f.size < probeSize*probeRequests*2 {
+		return
+	}
+
+	settings, err := f.measure(ctx)
+	if err != nil {
+		f.logDebug("unable to measure", "error", err)
+
+		return
+	}
+
+	buf := &bytes.Buffer{}
+	if err := gob.NewEncoder(buf).Encode(settings); err == nil {
+		f.tuneStore.Set(key, buf.Bytes())
+	}
+
+	f.applyTuning(settings)
+}
+
+func (f *RemoteFile) applyTuning(settings *TuneSettings) {
+	if settings.Concurrency > 0 {
+		f.concurrency = settings.Concurrency
+	}
+
+	if settings.ChunkSize > 0 {
+		f.chunkSize = int64(settings.ChunkSize)
+	}
+}
+
+// measure sends probes one after the other to measure the latency and the
+// throughput of a single connection, followed by concurrent probes to
+// measure how the throughput scales with more connections
+func (f *RemoteFile) measure(ctx context.Context) (*TuneSettings, error) {
+	var sequential []probeResult
+	for i := range probeRequests {
+		res, err := f.probe(ctx, int64(i)*probeSize)
+		if err != nil {
+			return nil, err
+		}
+
+		sequential = append(sequential, res)
+	}
+
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		probeErr error
+	)
+
+	start := time.Now()
+	for i := range probeRequests {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			if _, err := f.probe(ctx, int64(probeRequests+i)*probeSize); err != nil {
+				mu.Lock()
+				probeErr = err
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	elapsed := time.Since(start)
+
+	if probeErr != nil {
+		return nil, probeErr
+	}
+
+	return deriveSettings(sequential, elapsed), nil
+}
+
+// deriveSettings derives the chunk size that keeps the latency a small part
+// of every chunk request and the concurrency the throughput scaled up to
+// when sending probeRequests probes at once
+func deriveSettings(sequential []probeResult, concurrentElapsed time.Duration) *TuneSettings {
+	latency := median(sequential, func(r probeResult) time.Duration { return r.latency })
+	duration := median(sequential, func(r probeResult) time.Duration { return r.duration })
+
+	single := float64(probeSize) / max(duration, time.Millisecond).Seconds()
+	parallel := float64(probeSize*probeRequests) / max(concurrentElapsed, time.Millisecond).Seconds()
+
+	concurrency := int(parallel/single + 0.5)
+	if parallel >= single*probeRequests*0.75 {
+		// the origin scaled with every extra connection, so it likely
+		// scales beyond the number of probes as well
+		concurrency = probeRequests * 2
+	}
+
+	transfer := max(duration-latency, time.Millisecond)
+	chunkSize := int(float64(probeSize) / transfer.Seconds() * latency.Seconds() / latencyOverhead)
+	chunkSize = chunkSize / 1024 * 1024
+
+	return &TuneSettings{
+		Concurrency: min(max(concurrency, 1), maxTunedConcurrency),
+		ChunkSize:   min(max(chunkSize, minTunedChunkSize), maxTunedChunkSize),
+		Measured:    time.Now(),
+	}
+}
+
+// probe fetches a probe sized range at the given offset
+func (f *RemoteFile) probe(ctx context.Context, offset int64) (probeResult, error) {
+	req := f.newRequest(ctx)
+	req.Header.Set(headerRange, fmt.Sprintf("bytes=%d-%d", offset, offset+probeSize-1))
+
+	start := time.Now()
+	res, err := f.client.Do(req)
+	if err != nil {
+		return probeResult{}, err
+	}
+	defer res.Body.Close()
+
+	latency := time.Since(start)
+
+	if res.StatusCode != http.StatusPartialContent {
+		return probeResult{}, newStatusError(res)
+	}
+
+	if _, err := io.Copy(io.Discard, res.Body); err != nil {
+		return probeResult{}, err
+	}
+
+	return probeResult{
+		latency:  latency,
+		duration: time.Since(start),
+	}, nil
+}
+
+func median[T any](values []T, fn func(T) time.Duration) time.Duration {
+	durations := make([]time.Duration, len(values))
+	for i, v := range values {
+		durations[i] = fn(v)
+	}
+	slices.Sort(durations)
+
+	return durations[len(durations)/2]
+}
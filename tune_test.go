@@ -0,0 +1,61 @@
+package httpio_test
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/jobstoit/httpio"
+)
+
+func TestGetAutoTune(t *testing.T) {
+	var requested atomic.Int64
+	svr := newTestServerWithHandler(func(h http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if spec, ok := strings.CutPrefix(r.Header.Get("Range"), "bytes="); ok {
+				startStr, endStr, _ := strings.Cut(spec, "-")
+				start, _ := strconv.ParseInt(startStr, 10, 64)
+				end, _ := strconv.ParseInt(endStr, 10, 64)
+				requested.Add(end - start + 1)
+			}
+
+			h.ServeHTTP(w, r)
+		})
+	})
+	defer svr.Close()
+
+	u := svr.URL().JoinPath("assets", "test_12mb").String()
+	expected, _ := testdata.ReadFile("testdata/test_12mb")
+	store := httpio.NewMemoryCache()
+
+	get := func() {
+		remoteFile, err := httpio.Get(u, httpio.WithAutoTune(store))
+		if err != nil {
+			t.Fatalf("failed to setup request: %v", err)
+		}
+
+		actual, err := io.ReadAll(remoteFile)
+		if err != nil {
+			t.Fatalf("unable to read file: %v", err)
+		}
+
+		if !bytes.Equal(expected, actual) {
+			t.Errorf("mismatched content")
+		}
+	}
+
+	get()
+	if requested.Load() <= int64(len(expected)) {
+		t.Errorf("expected the first download to probe the origin")
+	}
+
+	requested.Store(0)
+	get()
+	if e, a := int64(len(expected)), requested.Load(); e != a {
+		t.Errorf("expected the second download to only request the file's %d bytes, got %d", e, a)
+	}
+}
@@ -0,0 +1,348 @@
+package httpio
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+)
+
+// PutContext uploads the content of the reader to the url with PUT
+// requests. Content larger than the chunk size is split into chunks that
+// are uploaded concurrently, each with a PUT request carrying the
+// Content-Range of the chunk, for servers assembling partial PUTs like some
+// WebDAV servers do, smaller content is uploaded with a single PUT. A
+// seekable reader of a known size, like an *os.File or a *bytes.Reader, is
+// uploaded from its position without buffering, other readers are read into
+// a chunk per request in flight and their chunks report the total size once
+// the last one is read. The headers, client, concurrency, chunk size and
+// retries of the options apply. WithProgress reports the bytes uploaded,
+// WithEvents the parts as chunks and WithStats the statistics of the upload.
+func PutContext(ctx context.Context, url string, r io.Reader, opts ...Option) error {
+	f, err := newRemoteFile(ctx, url, opts...)
+	if err != nil {
+		return err
+	}
+	defer f.closeIdleConnections()
+
+	u := &upload{file: f, total: -1}
+	err = u.upload(ctx, r)
+	u.finish(err)
+
+	return err
+}
+
+// Put uploads the content of the reader to the url, see PutContext
+func Put(url string, r io.Reader, opts ...Option) error {
+	return PutContext(context.Background(), url, r, opts...)
+}
+
+// upload uploads the content of the reader in parts
+func (u *upload) upload(ctx context.Context, r io.Reader) error {
+	f := u.file
+
+	if section, ok := sizedSection(r); ok {
+		u.begin(section.Size())
+		if u.total <= f.chunkSize {
+			return u.put(ctx, uploadPart{body: section, length: u.total, whole: true})
+		}
+
+		return u.run(ctx, func(parts chan<- uploadPart) error {
+			for off := int64(0); off < u.total; off += f.chunkSize {
+				length := min(f.chunkSize, u.total-off)
+				select {
+				case parts <- uploadPart{body: io.NewSectionReader(section, off, length), offset: off, length: length, total: u.total}:
+				case <-ctx.Done():
+					return ctx.Err()
+				}
+			}
+
+			return nil
+		})
+	}
+
+	first, err := readChunk(r, f.chunkSize)
+	if err != nil {
+		return err
+	}
+
+	// content read at once is uploaded with a single PUT
+	next, err := readChunk(r, f.chunkSize)
+	if err != nil {
+		return err
+	}
+
+	if len(next) == 0 {
+		u.begin(int64(len(first)))
+		return u.put(ctx, uploadPart{body: bytes.NewReader(first), length: u.total, whole: true})
+	}
+	u.begin(-1)
+
+	return u.run(ctx, func(parts chan<- uploadPart) error {
+		var off int64
+		for cur := first; len(cur) > 0; {
+			// the chunk after the current one tells whether it's the last
+			if next == nil {
+				if next, err = readChunk(r, f.chunkSize); err != nil {
+					return err
+				}
+			}
+
+			part := uploadPart{body: bytes.NewReader(cur), offset: off, length: int64(len(cur)), total: -1}
+			if len(next) == 0 {
+				part.total = off + int64(len(cur))
+				u.setTotal(part.total)
+			}
+
+			select {
+			case parts <- part:
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+
+			off += int64(len(cur))
+			cur, next = next, nil
+		}
+
+		return nil
+	})
+}
+
+// sizedSection returns the rest of a seekable reader with a size as a
+// section, which is read concurrently
+func sizedSection(r io.Reader) (*io.SectionReader, bool) {
+	ra, ok := r.(io.ReaderAt)
+	if !ok {
+		return nil, false
+	}
+
+	seeker, ok := r.(io.Seeker)
+	if !ok {
+		return nil, false
+	}
+
+	var size int64
+	switch v := r.(type) {
+	case interface{ Size() int64 }:
+		size = v.Size()
+	case *os.File:
+		info, err := v.Stat()
+		if err != nil || !info.Mode().IsRegular() {
+			return nil, false
+		}
+		size = info.Size()
+	default:
+		return nil, false
+	}
+
+	pos, err := seeker.Seek(0, io.SeekCurrent)
+	if err != nil || pos > size {
+		return nil, false
+	}
+
+	return io.NewSectionReader(ra, pos, size-pos), true
+}
+
+// readChunk reads up to size bytes, an empty chunk means the reader is done
+func readChunk(r io.Reader, size int64) ([]byte, error) {
+	buf := make([]byte, size)
+
+	n, err := io.ReadFull(r, buf)
+	if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		err = nil
+	}
+
+	return buf[:n], err
+}
+
+// uploadPart is a chunk of an upload, total is the size of the content
+// when it's known and whole marks content uploaded with a single request
+type uploadPart struct {
+	body   io.ReadSeeker
+	offset int64
+	length int64
+	total  int64
+	whole  bool
+}
+
+// chunk returns the part as the chunk reported to the observers
+func (p uploadPart) chunk(chunkSize int64) Chunk {
+	return Chunk{Index: int(p.offset / chunkSize), Offset: p.offset, Length: p.length}
+}
+
+// contentRange returns the Content-Range of the part
+func (p uploadPart) contentRange() string {
+	if p.total < 0 {
+		return fmt.Sprintf("bytes %d-%d/*", p.offset, p.offset+p.length-1)
+	}
+
+	return fmt.Sprintf("bytes %d-%d/%d", p.offset, p.offset+p.length-1, p.total)
+}
+
+// upload uploads the parts of a PutContext
+type upload struct {
+	file  *RemoteFile
+	total int64
+
+	begun    bool
+	mu       sync.Mutex
+	uploaded int64
+}
+
+// begin reports the start of the upload of content of the given size,
+// which is -1 until the last chunk of a reader without a size is read
+func (u *upload) begin(total int64) {
+	u.total, u.file.size = total, total
+	u.begun = true
+
+	u.file.stats.begin(time.Now())
+	u.file.observe().OnStart(0, total)
+}
+
+// finish reports the end of the upload
+func (u *upload) finish(err error) {
+	if u.begun {
+		u.file.observe().OnComplete(err)
+	}
+
+	u.file.reportStats()
+}
+
+// run uploads the parts sent by produce with the concurrency of the file,
+// stopping at the first error
+func (u *upload) run(ctx context.Context, produce func(chan<- uploadPart) error) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	parts := make(chan uploadPart)
+
+	var (
+		wg   sync.WaitGroup
+		once sync.Once
+		ferr error
+	)
+
+	fail := func(err error) {
+		once.Do(func() {
+			ferr = err
+			cancel()
+		})
+	}
+
+	for range max(u.file.concurrency, 1) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			for part := range parts {
+				if err := u.put(ctx, part); err != nil {
+					fail(err)
+				}
+			}
+		}()
+	}
+
+	if err := produce(parts); err != nil {
+		fail(err)
+	}
+	close(parts)
+	wg.Wait()
+
+	return ferr
+}
+
+// put uploads the part, retrying as configured with WithRetry
+func (u *upload) put(ctx context.Context, part uploadPart) error {
+	f := u.file
+	c := part.chunk(f.chunkSize)
+
+	f.observe().OnChunkStart(c)
+	started := time.Now()
+
+	for retries := 0; ; retries++ {
+		if _, err := part.body.Seek(0, io.SeekStart); err != nil {
+			return err
+		}
+
+		body := io.NopCloser(&countedReader{Reader: part.body, stats: &f.stats})
+
+		req := f.req.Clone(f.traceContext(ctx))
+		req.Method = http.MethodPut
+		req.Body = body
+		req.ContentLength = part.length
+		req.GetBody = func() (io.ReadCloser, error) {
+			if _, err := part.body.Seek(0, io.SeekStart); err != nil {
+				return nil, err
+			}
+
+			return body, nil
+		}
+
+		// the download's Accept-Encoding has no meaning for an upload
+		req.Header.Del(headerAcceptEncoding)
+		if !part.whole {
+			req.Header.Set(headerContentRange, part.contentRange())
+		}
+
+		res, err := f.client.Do(req)
+		if retries < f.retries && retryable(ctx, res, err) {
+			retryErr := err
+			if err == nil {
+				res.Body.Close()
+				retryErr = errors.New(res.Status)
+			}
+
+			f.logDebug("retrying upload", "offset", part.offset, "attempt", retries+1, "error", retryErr)
+			f.retried(c, retryErr, retries+1)
+
+			if err := sleep(ctx, f.retryDelay(retries)); err != nil {
+				return err
+			}
+
+			continue
+		}
+
+		if err != nil {
+			return err
+		}
+
+		io.Copy(io.Discard, io.LimitReader(res.Body, drainLimit))
+		res.Body.Close()
+
+		if res.StatusCode < 200 || res.StatusCode > 299 {
+			return newStatusError(res)
+		}
+
+		f.observe().OnChunkComplete(c, part.length, time.Since(started))
+		u.progress(part.length)
+
+		return nil
+	}
+}
+
+// setTotal sets the size of the content once the last chunk of a reader
+// without a size is read
+func (u *upload) setTotal(total int64) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	u.total, u.file.size = total, total
+}
+
+// progress reports the uploaded part to the progress function
+func (u *upload) progress(n int64) {
+	if u.file.progress == nil {
+		return
+	}
+
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	u.uploaded += n
+	u.file.progress(u.uploaded, u.total)
+}
@@ -0,0 +1,247 @@
+package httpio_test
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/jobstoit/httpio"
+)
+
+// uploadServer assembles the partial PUTs it receives
+type uploadServer struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	content  []byte
+	ranges   []string
+	failures map[string]int
+}
+
+func newUploadServer() *uploadServer {
+	s := &uploadServer{failures: map[string]int{}}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPut {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		s.mu.Lock()
+		defer s.mu.Unlock()
+
+		contentRange := r.Header.Get("Content-Range")
+		s.ranges = append(s.ranges, contentRange)
+
+		if s.failures[contentRange] > 0 {
+			s.failures[contentRange]--
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+
+		if contentRange == "" {
+			s.content = body
+			w.WriteHeader(http.StatusCreated)
+			return
+		}
+
+		spec, total, _ := strings.Cut(strings.TrimPrefix(contentRange, "bytes "), "/")
+		firstStr, _, _ := strings.Cut(spec, "-")
+		first, _ := strconv.Atoi(firstStr)
+
+		if size, err := strconv.Atoi(total); err == nil && len(s.content) < size {
+			s.content = append(s.content, make([]byte, size-len(s.content))...)
+		}
+
+		if end := first + len(body); len(s.content) < end {
+			s.content = append(s.content, make([]byte, end-len(s.content))...)
+		}
+		copy(s.content[first:], body)
+
+		w.WriteHeader(http.StatusNoContent)
+	}))
+
+	return s
+}
+
+// onlyReader hides the other interfaces of the reader
+type onlyReader struct {
+	io.Reader
+}
+
+func TestPut(t *testing.T) {
+	expected, _ := testdata.ReadFile("testdata/test_5mb")
+
+	tests := []struct {
+		name   string
+		reader func() io.Reader
+		ranges int
+		total  string
+	}{
+		{"sized", func() io.Reader { return bytes.NewReader(expected) }, 5, "/" + strconv.Itoa(len(expected))},
+		{"stream", func() io.Reader { return onlyReader{bytes.NewReader(expected)} }, 5, "/*"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			svr := newUploadServer()
+			defer svr.Close()
+
+			var uploaded, total atomic.Int64
+			err := httpio.Put(svr.URL, test.reader(),
+				httpio.WithChunkSize(1024*1024+7),
+				httpio.WithConcurrency(3),
+				httpio.WithProgress(func(u, t int64) {
+					uploaded.Store(u)
+					total.Store(t)
+				}),
+			)
+			if err != nil {
+				t.Fatalf("unable to upload: %v", err)
+			}
+
+			if !bytes.Equal(svr.content, expected) {
+				t.Error("mismatched uploaded content")
+			}
+
+			if len(svr.ranges) != test.ranges {
+				t.Errorf("expected %d partial uploads but got %d: %v", test.ranges, len(svr.ranges), svr.ranges)
+			}
+
+			for _, r := range svr.ranges {
+				last := strings.HasPrefix(r, "bytes 4194332-")
+				if !last && !strings.HasSuffix(r, test.total) {
+					t.Errorf("expected the range %s to end with %s", r, test.total)
+				}
+			}
+
+			if uploaded.Load() != int64(len(expected)) || total.Load() != int64(len(expected)) {
+				t.Errorf("expected progress %d of %d, got %d of %d", len(expected), len(expected), uploaded.Load(), total.Load())
+			}
+		})
+	}
+}
+
+func TestPutSingle(t *testing.T) {
+	content := []byte("small content")
+
+	for _, r := range []io.Reader{bytes.NewReader(content), onlyReader{bytes.NewReader(content)}} {
+		svr := newUploadServer()
+
+		if err := httpio.Put(svr.URL, r); err != nil {
+			t.Fatalf("unable to upload: %v", err)
+		}
+
+		if !bytes.Equal(svr.content, content) || len(svr.ranges) != 1 || svr.ranges[0] != "" {
+			t.Errorf("expected a single PUT of '%s', got '%s' with %v", content, svr.content, svr.ranges)
+		}
+		svr.Close()
+	}
+}
+
+func TestPutFilePosition(t *testing.T) {
+	expected, _ := testdata.ReadFile("testdata/test_5mb")
+
+	path := filepath.Join(t.TempDir(), "upload.bin")
+	if err := os.WriteFile(path, expected, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	// the file is uploaded from its position
+	if _, err := file.Seek(1024*1024, io.SeekStart); err != nil {
+		t.Fatal(err)
+	}
+
+	svr := newUploadServer()
+	defer svr.Close()
+
+	if err := httpio.Put(svr.URL, file, httpio.WithChunkSize(1024*1024)); err != nil {
+		t.Fatalf("unable to upload: %v", err)
+	}
+
+	if !bytes.Equal(svr.content, expected[1024*1024:]) {
+		t.Error("mismatched uploaded content")
+	}
+}
+
+func TestPutRetry(t *testing.T) {
+	expected, _ := testdata.ReadFile("testdata/test_5mb")
+
+	svr := newUploadServer()
+	defer svr.Close()
+	svr.failures["bytes 1048576-2097151/"+strconv.Itoa(len(expected))] = 1
+
+	observer := &recordingObserver{done: make(chan error, 1)}
+
+	var stats httpio.Stats
+	err := httpio.Put(svr.URL, bytes.NewReader(expected),
+		httpio.WithChunkSize(1024*1024),
+		httpio.WithRetry(2),
+		httpio.WithEvents(observer),
+		httpio.WithStats(func(s httpio.Stats) { stats = s }),
+	)
+	if err != nil {
+		t.Fatalf("unable to upload: %v", err)
+	}
+
+	if !bytes.Equal(svr.content, expected) {
+		t.Error("mismatched uploaded content")
+	}
+
+	// the failed part is sent twice
+	if e, a := int64(len(expected))+1024*1024, stats.BytesUploaded; e != a {
+		t.Errorf("expected %d bytes uploaded, got %d", e, a)
+	}
+
+	if stats.Retries != 1 || stats.BytesDownloaded != 0 {
+		t.Errorf("expected a retry and no bytes downloaded, got: %+v", stats)
+	}
+
+	if err := <-observer.done; err != nil {
+		t.Errorf("expected the upload to complete, got: %v", err)
+	}
+
+	if len(observer.started) != 5 || len(observer.completed) != 5 || observer.bytes != int64(len(expected)) {
+		t.Errorf("expected the 5 parts as chunks, got %d started and %d completed of %d bytes",
+			len(observer.started), len(observer.completed), observer.bytes)
+	}
+
+	if len(observer.attempts) != 1 || observer.attempts[0] != 1 {
+		t.Errorf("expected a single retry, got: %v", observer.attempts)
+	}
+}
+
+func TestPutStatusError(t *testing.T) {
+	svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.Copy(io.Discard, r.Body)
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer svr.Close()
+
+	expected, _ := testdata.ReadFile("testdata/test_5mb")
+
+	var statusErr *httpio.StatusError
+	if err := httpio.Put(svr.URL, bytes.NewReader(expected), httpio.WithChunkSize(1024*1024)); !errors.As(err, &statusErr) || statusErr.Code != http.StatusForbidden {
+		t.Errorf("expected a 403 status error but got %v", err)
+	}
+}
@@ -0,0 +1,66 @@
+package httpio
+
+import (
+	"errors"
+	"syscall"
+)
+
+func setSourceAttrs(path string, src *Source) error {
+	attrs := map[string]string{
+		xattrURL:          src.URL,
+		xattrETag:         src.ETag,
+		xattrLastModified: src.LastModified,
+		xattrSHA256:       src.SHA256,
+	}
+
+	for name, value := range attrs {
+		if value == "" {
+			if err := syscall.Removexattr(path, name); err != nil && !errors.Is(err, syscall.ENODATA) {
+				return err
+			}
+
+			continue
+		}
+
+		if err := syscall.Setxattr(path, name, []byte(value), 0); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func getSourceAttrs(path string) (*Source, error) {
+	url, err := getxattr(path, xattrURL)
+	if err != nil {
+		return nil, err
+	}
+
+	src := &Source{URL: url}
+	for name, value := range map[string]*string{
+		xattrETag:         &src.ETag,
+		xattrLastModified: &src.LastModified,
+		xattrSHA256:       &src.SHA256,
+	} {
+		if *value, err = getxattr(path, name); err != nil && !errors.Is(err, syscall.ENODATA) {
+			return nil, err
+		}
+	}
+
+	return src, nil
+}
+
+func getxattr(path, name string) (string, error) {
+	size, err := syscall.Getxattr(path, name, nil)
+	if err != nil {
+		return "", err
+	}
+
+	buf := make([]byte, size)
+	size, err = syscall.Getxattr(path, name, buf)
+	if err != nil {
+		return "", err
+	}
+
+	return string(buf[:size]), nil
+}
@@ -0,0 +1,15 @@
+//go:build !linux
+
+package httpio
+
+import "errors"
+
+// setSourceAttrs is unsupported without extended attributes in the
+// standard library, the source is stored in the sidecar file instead
+func setSourceAttrs(string, *Source) error {
+	return errors.ErrUnsupported
+}
+
+func getSourceAttrs(string) (*Source, error) {
+	return nil, errors.ErrUnsupported
+}
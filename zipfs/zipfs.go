@@ -0,0 +1,50 @@
+// Package zipfs reads remote zip archives with range requests, downloading
+// only the central directory and the entries that are opened.
+package zipfs
+
+import (
+	"archive/zip"
+	"context"
+	"errors"
+
+	"github.com/jobstoit/httpio"
+)
+
+// DefaultBlockSize is the least number of bytes read at once from the
+// archive, so the many small reads of the central directory and the entries
+// are served by few requests. It's overridden with httpio.WithReadAtBlockSize.
+const DefaultBlockSize = 256 * 1024
+
+// Reader is a remote zip archive, it implements fs.FS through zip.Reader
+type Reader struct {
+	*zip.Reader
+	file *httpio.RemoteFile
+}
+
+// OpenZip opens the zip archive at the url, reading its central directory
+func OpenZip(ctx context.Context, url string, opts ...httpio.Option) (*Reader, error) {
+	opts = append([]httpio.Option{httpio.WithReadAtBlockSize(DefaultBlockSize)}, opts...)
+
+	file, err := httpio.OpenContext(ctx, url, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	if file.Size() < 0 {
+		file.Close()
+		return nil, errors.New("unknown size of the archive")
+	}
+
+	zr, err := zip.NewReader(file, file.Size())
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+
+	return &Reader{Reader: zr, file: file}, nil
+}
+
+// Close closes the archive
+func (r *Reader) Close() error {
+	return r.file.Close()
+}
@@ -0,0 +1,101 @@
+package zipfs_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"errors"
+	"io/fs"
+	"math/rand"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/jobstoit/httpio/zipfs"
+)
+
+type countingWriter struct {
+	http.ResponseWriter
+	n *atomic.Int64
+}
+
+func (w *countingWriter) Write(p []byte) (int, error) {
+	n, err := w.ResponseWriter.Write(p)
+	w.n.Add(int64(n))
+
+	return n, err
+}
+
+func newArchive(t *testing.T, entries map[string][]byte) []byte {
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for name, content := range entries {
+		w, err := zw.CreateHeader(&zip.FileHeader{Name: name, Method: zip.Store})
+		if err != nil {
+			t.Fatal(err)
+		}
+		w.Write(content)
+	}
+
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	return buf.Bytes()
+}
+
+func TestOpenZip(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	entries := map[string][]byte{}
+	for _, name := range []string{"a.bin", "b.bin", "dir/c.bin"} {
+		content := make([]byte, 4*1024*1024)
+		rnd.Read(content)
+		entries[name] = content
+	}
+	entries["hello.txt"] = []byte("hello world")
+	archive := newArchive(t, entries)
+
+	var served atomic.Int64
+	svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.ServeContent(&countingWriter{ResponseWriter: w, n: &served}, r, "archive.zip", time.Time{}, bytes.NewReader(archive))
+	}))
+	defer svr.Close()
+
+	zr, err := zipfs.OpenZip(context.Background(), svr.URL+"/archive.zip")
+	if err != nil {
+		t.Fatalf("unable to open archive: %v", err)
+	}
+	defer zr.Close()
+
+	for _, name := range []string{"hello.txt", "dir/c.bin"} {
+		content, err := fs.ReadFile(zr, name)
+		if err != nil {
+			t.Fatalf("unable to read %s: %v", name, err)
+		}
+
+		if !bytes.Equal(entries[name], content) {
+			t.Errorf("mismatched content of %s", name)
+		}
+	}
+
+	if n := served.Load(); n > int64(len(archive))/2 {
+		t.Errorf("expected only the read entries to be downloaded, got %d of %d bytes", n, len(archive))
+	}
+
+	if _, err := fs.ReadFile(zr, "missing"); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("expected fs.ErrNotExist, got: %v", err)
+	}
+}
+
+func TestOpenZipInvalid(t *testing.T) {
+	svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.ServeContent(w, r, "archive.zip", time.Time{}, bytes.NewReader(bytes.Repeat([]byte("not a zip"), 1000)))
+	}))
+	defer svr.Close()
+
+	if _, err := zipfs.OpenZip(context.Background(), svr.URL+"/archive.zip"); err == nil {
+		t.Error("expected an error for an invalid archive")
+	}
+}